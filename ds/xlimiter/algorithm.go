@@ -0,0 +1,168 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xlimiter
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ Algorithm = (*TokenBucket)(nil)
+var _ Algorithm = (*GCRA)(nil)
+var _ Algorithm = (*SlidingLog)(nil)
+
+// TokenBucket 令牌桶算法：桶的容量为 Rate.Burst，每 Rate.Period 补充 Rate.Limit 个令牌
+type TokenBucket struct {
+	Rate Rate `json:"Rate" yaml:"Rate"`
+}
+
+func (tb *TokenBucket) Name() string {
+	return "token_bucket"
+}
+
+func (tb *TokenBucket) TTL() time.Duration {
+	if !tb.Rate.valid() {
+		return 0
+	}
+	return time.Duration(tb.Rate.interval() * float64(tb.Rate.getBurst()))
+}
+
+// Take 状态格式为："剩余令牌数:上次更新时间(UnixNano)"
+func (tb *TokenBucket) Take(state string, now time.Time, n int64) (string, Result) {
+	capacity := float64(tb.Rate.getBurst())
+	ret := Result{Limit: tb.Rate.getBurst()}
+	if !tb.Rate.valid() {
+		return state, ret
+	}
+	interval := tb.Rate.interval()
+	nowNano := now.UnixNano()
+
+	tokens := capacity
+	if str1, str2, ok := strings.Cut(state, ":"); ok {
+		t, err1 := strconv.ParseFloat(str1, 64)
+		last, err2 := strconv.ParseInt(str2, 10, 64)
+		if err1 == nil && err2 == nil {
+			elapsed := max(0, nowNano-last)
+			tokens = min(capacity, t+float64(elapsed)/interval)
+		}
+	}
+	if tokens >= float64(n) {
+		tokens -= float64(n)
+		ret.Allowed = true
+	} else {
+		ret.RetryAfter = time.Duration(math.Ceil((float64(n) - tokens) * interval))
+	}
+	ret.Remaining = int64(math.Floor(tokens))
+	ret.ResetAfter = time.Duration(math.Ceil((capacity - tokens) * interval))
+	return strconv.FormatFloat(tokens, 'f', -1, 64) + ":" + strconv.FormatInt(nowNano, 10), ret
+}
+
+// GCRA 通用信元速率算法（Generic Cell Rate Algorithm）。
+//
+// 效果和令牌桶相同，但是状态只需要存储一个时间戳（理论到达时间，TAT）
+type GCRA struct {
+	Rate Rate `json:"Rate" yaml:"Rate"`
+}
+
+func (g *GCRA) Name() string {
+	return "gcra"
+}
+
+func (g *GCRA) TTL() time.Duration {
+	if !g.Rate.valid() {
+		return 0
+	}
+	return time.Duration(g.Rate.interval() * float64(g.Rate.getBurst()))
+}
+
+// Take 状态格式为：理论到达时间(UnixNano)
+func (g *GCRA) Take(state string, now time.Time, n int64) (string, Result) {
+	ret := Result{Limit: g.Rate.getBurst()}
+	if !g.Rate.valid() {
+		return state, ret
+	}
+	interval := g.Rate.interval()
+	burstOffset := interval * float64(g.Rate.getBurst())
+	nowNano := float64(now.UnixNano())
+
+	tat := nowNano
+	if v, err := strconv.ParseInt(state, 10, 64); err == nil && float64(v) > nowNano {
+		tat = float64(v)
+	}
+	newTat := tat + float64(n)*interval
+	diff := nowNano - (newTat - burstOffset)
+	if diff < 0 {
+		ret.RetryAfter = time.Duration(math.Ceil(-diff))
+		ret.ResetAfter = time.Duration(math.Ceil(tat - nowNano))
+		return state, ret
+	}
+	ret.Allowed = true
+	ret.Remaining = int64(math.Floor(diff / interval))
+	ret.ResetAfter = time.Duration(math.Ceil(newTat - nowNano))
+	return strconv.FormatInt(int64(newTat), 10), ret
+}
+
+// SlidingLog 滑动窗口日志算法：记录每个请求的时间，任意 Rate.Period 时间内的请求数不超过 Rate.Limit。
+//
+// 限流是精确的，但是每个 key 的状态需要存储最多 Rate.Limit 个时间戳，适用于 Limit 较小的场景
+type SlidingLog struct {
+	Rate Rate `json:"Rate" yaml:"Rate"`
+}
+
+func (s *SlidingLog) Name() string {
+	return "sliding_log"
+}
+
+func (s *SlidingLog) TTL() time.Duration {
+	return s.Rate.Period.Duration()
+}
+
+// Take 状态格式为：以逗号连接的请求时间(UnixNano)，按照时间升序
+func (s *SlidingLog) Take(state string, now time.Time, n int64) (string, Result) {
+	ret := Result{Limit: s.Rate.Limit}
+	if !s.Rate.valid() {
+		return state, ret
+	}
+	nowNano := now.UnixNano()
+	start := nowNano - int64(s.Rate.Period)
+	var logs []int64
+	if state != "" {
+		for _, str := range strings.Split(state, ",") {
+			v, err := strconv.ParseInt(str, 10, 64)
+			if err == nil && v > start {
+				logs = append(logs, v)
+			}
+		}
+	}
+	count := int64(len(logs))
+	if count+n <= s.Rate.Limit {
+		for i := int64(0); i < n; i++ {
+			logs = append(logs, nowNano)
+		}
+		ret.Allowed = true
+		ret.Remaining = s.Rate.Limit - count - n
+	} else {
+		ret.Remaining = max(0, s.Rate.Limit-count)
+		if n > s.Rate.Limit {
+			ret.RetryAfter = s.Rate.Period.Duration()
+		} else {
+			// 需要等待最早的 count+n-Limit 个请求移出窗口
+			ret.RetryAfter = time.Duration(logs[count+n-s.Rate.Limit-1] - start)
+		}
+	}
+	if len(logs) > 0 {
+		ret.ResetAfter = time.Duration(logs[len(logs)-1] - start)
+	}
+	var sb strings.Builder
+	for i, v := range logs {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatInt(v, 10))
+	}
+	return sb.String(), ret
+}
@@ -0,0 +1,15 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+// Package xlimiter 限流器
+//
+// 按照 key 限流使用 Limiter，内置的算法：
+//   - TokenBucket 令牌桶
+//   - GCRA 通用信元速率算法，效果和令牌桶一致，状态更小
+//   - SlidingLog 滑动窗口日志，精确限流
+//
+// 状态默认存储在进程内（MemoryStore），分布式的存储（xkv、Redis）见 xlimiterx 包。
+//
+// 另外还有不区分 key 的 Window（滑动窗口计数）和 Interval（固定间隔）。
+package xlimiter
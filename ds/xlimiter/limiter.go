@@ -0,0 +1,149 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xlimiter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xtype"
+)
+
+// Rate 限流的速率：在 Period 时间内，最多允许 Limit 个请求
+type Rate struct {
+	// Limit 周期内允许的请求数，必填
+	Limit int64 `json:"Limit" yaml:"Limit"`
+
+	// Period 周期，必填，配置文件中可以是 "1s"、"1m" 这样的格式，为数字时单位为毫秒
+	Period xtype.Duration `json:"Period" yaml:"Period"`
+
+	// Burst 允许的突发请求数，可选，默认等于 Limit。SlidingLog 算法不使用此字段
+	Burst int64 `json:"Burst" yaml:"Burst"`
+}
+
+func (r Rate) getBurst() int64 {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+	return r.Limit
+}
+
+func (r Rate) valid() bool {
+	return r.Limit > 0 && r.Period > 0
+}
+
+// interval 每产生一个配额的时间间隔（纳秒）
+func (r Rate) interval() float64 {
+	return float64(r.Period) / float64(r.Limit)
+}
+
+// PerSecond 每秒 n 个请求
+func PerSecond(n int64) Rate {
+	return Rate{Limit: n, Period: xtype.Duration(time.Second)}
+}
+
+// PerMinute 每分钟 n 个请求
+func PerMinute(n int64) Rate {
+	return Rate{Limit: n, Period: xtype.Duration(time.Minute)}
+}
+
+// PerHour 每小时 n 个请求
+func PerHour(n int64) Rate {
+	return Rate{Limit: n, Period: xtype.Duration(time.Hour)}
+}
+
+// Result 一次限流检查的结果
+type Result struct {
+	// Allowed 是否允许
+	Allowed bool
+
+	// Limit 配额总量
+	Limit int64
+
+	// Remaining 剩余可用的配额
+	Remaining int64
+
+	// RetryAfter 被拒绝时，需要等待多久之后重试
+	RetryAfter time.Duration
+
+	// ResetAfter 配额恢复到满额需要的时间
+	ResetAfter time.Duration
+}
+
+// Algorithm 限流算法。
+//
+// 算法以状态转换的方式定义，状态编码为字符串，以便在不同的 Store 中存储
+type Algorithm interface {
+	// Name 算法名称
+	Name() string
+
+	// Take 依据当前的状态 state（不存在时为空字符串），尝试获取 n 个配额，返回新的状态和结果
+	Take(state string, now time.Time, n int64) (string, Result)
+
+	// TTL 状态的有效期，超过此时间没有更新，状态和不存在等价，可以被清除
+	TTL() time.Duration
+}
+
+// Store 存储限流的状态，需要保证 Take 是原子的
+type Store interface {
+	Take(ctx context.Context, key string, alg Algorithm, now time.Time, n int64) (Result, error)
+}
+
+// Limiter 按照 key 限流，如：
+//
+//	l := &xlimiter.Limiter{
+//		Algorithm: &xlimiter.GCRA{Rate: xlimiter.PerSecond(10)},
+//	}
+//	ret, err := l.Allow(ctx, "user:123")
+type Limiter struct {
+	// Algorithm 限流算法，必填
+	Algorithm Algorithm
+
+	// Store 状态存储，可选，默认为进程内的 MemoryStore
+	Store Store
+
+	// KeyPrefix key 的前缀，可选
+	KeyPrefix string
+
+	// Now 可选，获取当前时间，默认为 time.Now
+	Now func() time.Time
+
+	once  sync.Once
+	store Store
+}
+
+var errNoAlgorithm = errors.New("xlimiter: Algorithm is nil")
+
+func (l *Limiter) getStore() Store {
+	if l.Store != nil {
+		return l.Store
+	}
+	l.once.Do(func() {
+		l.store = NewMemoryStore()
+	})
+	return l.store
+}
+
+func (l *Limiter) now() time.Time {
+	if l.Now != nil {
+		return l.Now()
+	}
+	return time.Now()
+}
+
+// Allow 获取一个配额
+func (l *Limiter) Allow(ctx context.Context, key string) (Result, error) {
+	return l.AllowN(ctx, key, 1)
+}
+
+// AllowN 获取 n 个配额，若配额不足，不会消耗配额
+func (l *Limiter) AllowN(ctx context.Context, key string, n int64) (Result, error) {
+	if l.Algorithm == nil {
+		return Result{}, errNoAlgorithm
+	}
+	return l.getStore().Take(ctx, l.KeyPrefix+key, l.Algorithm, l.now(), n)
+}
@@ -0,0 +1,106 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xlimiter_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xlimiter"
+	"github.com/xanygo/anygo/ds/xtype"
+	"github.com/xanygo/anygo/xt"
+)
+
+func newLimiter(alg xlimiter.Algorithm) (*xlimiter.Limiter, *time.Time) {
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	return &xlimiter.Limiter{
+		Algorithm: alg,
+		Now: func() time.Time {
+			return now
+		},
+	}, &now
+}
+
+func testBucket(t *testing.T, alg xlimiter.Algorithm) {
+	ctx := context.Background()
+	l, now := newLimiter(alg)
+	for i := 0; i < 3; i++ {
+		ret, err := l.Allow(ctx, "a")
+		xt.NoError(t, err)
+		xt.True(t, ret.Allowed)
+		xt.Equal(t, ret.Limit, int64(3))
+		xt.Equal(t, ret.Remaining, int64(2-i))
+	}
+	ret, err := l.Allow(ctx, "a")
+	xt.NoError(t, err)
+	xt.False(t, ret.Allowed)
+	xt.Equal(t, ret.Remaining, int64(0))
+	xt.Equal(t, ret.RetryAfter, time.Second)
+	xt.Equal(t, ret.ResetAfter, 3*time.Second)
+
+	// 其他 key 不受影响
+	ret, _ = l.Allow(ctx, "b")
+	xt.True(t, ret.Allowed)
+
+	*now = now.Add(time.Second)
+	ret, _ = l.Allow(ctx, "a")
+	xt.True(t, ret.Allowed)
+	xt.Equal(t, ret.Remaining, int64(0))
+
+	*now = now.Add(10 * time.Second)
+	ret, _ = l.AllowN(ctx, "a", 3)
+	xt.True(t, ret.Allowed)
+	ret, _ = l.AllowN(ctx, "a", 4)
+	xt.False(t, ret.Allowed)
+}
+
+func TestRate(t *testing.T) {
+	var r xlimiter.Rate
+	xt.NoError(t, json.Unmarshal([]byte(`{"Limit":100,"Period":"1m","Burst":10}`), &r))
+	xt.Equal(t, r, xlimiter.Rate{Limit: 100, Period: xtype.Duration(time.Minute), Burst: 10})
+	xt.Equal(t, xlimiter.PerMinute(100).Period, r.Period)
+}
+
+func TestTokenBucket(t *testing.T) {
+	testBucket(t, &xlimiter.TokenBucket{Rate: xlimiter.Rate{Limit: 1, Period: xtype.Duration(time.Second), Burst: 3}})
+}
+
+func TestGCRA(t *testing.T) {
+	testBucket(t, &xlimiter.GCRA{Rate: xlimiter.Rate{Limit: 1, Period: xtype.Duration(time.Second), Burst: 3}})
+}
+
+func TestSlidingLog(t *testing.T) {
+	ctx := context.Background()
+	l, now := newLimiter(&xlimiter.SlidingLog{Rate: xlimiter.PerMinute(3)})
+	for i := 0; i < 3; i++ {
+		ret, err := l.Allow(ctx, "a")
+		xt.NoError(t, err)
+		xt.True(t, ret.Allowed)
+		xt.Equal(t, ret.Remaining, int64(2-i))
+		*now = now.Add(10 * time.Second)
+	}
+	ret, _ := l.Allow(ctx, "a")
+	xt.False(t, ret.Allowed)
+	xt.Equal(t, ret.RetryAfter, 30*time.Second)
+	ret, _ = l.AllowN(ctx, "a", 2)
+	xt.Equal(t, ret.RetryAfter, 40*time.Second)
+
+	*now = now.Add(30 * time.Second)
+	ret, _ = l.Allow(ctx, "a")
+	xt.True(t, ret.Allowed)
+	xt.Equal(t, ret.Remaining, int64(0))
+	xt.Equal(t, ret.ResetAfter, time.Minute)
+}
+
+func TestWindow(t *testing.T) {
+	w := xlimiter.NewWindow(2, time.Minute, time.Second)
+	xt.True(t, w.Allow())
+	xt.Equal(t, w.Remaining(), int64(1))
+	xt.True(t, w.Allow())
+	xt.False(t, w.Allow())
+	xt.False(t, w.AllowN(2))
+}
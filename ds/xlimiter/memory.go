@@ -0,0 +1,90 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xlimiter
+
+import (
+	"context"
+	"hash/maphash"
+	"sync"
+	"time"
+)
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore 创建进程内的状态存储，过期的状态会被定期清理
+func NewMemoryStore() *MemoryStore {
+	ms := &MemoryStore{
+		seed: maphash.MakeSeed(),
+	}
+	for i := range ms.shards {
+		ms.shards[i].items = make(map[string]*memoryItem)
+	}
+	return ms
+}
+
+const memoryShards = 32
+
+// MemoryStore 进程内的状态存储
+type MemoryStore struct {
+	seed   maphash.Seed
+	shards [memoryShards]memoryShard
+}
+
+type memoryShard struct {
+	mu     sync.Mutex
+	items  map[string]*memoryItem
+	lastGC time.Time
+}
+
+type memoryItem struct {
+	state   string
+	expires time.Time
+}
+
+func (ms *MemoryStore) Take(ctx context.Context, key string, alg Algorithm, now time.Time, n int64) (Result, error) {
+	shard := &ms.shards[maphash.String(ms.seed, key)%memoryShards]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	shard.gc(now)
+
+	item := shard.items[key]
+	var state string
+	if item != nil && now.Before(item.expires) {
+		state = item.state
+	}
+	newState, ret := alg.Take(state, now, n)
+	if item == nil {
+		item = &memoryItem{}
+		shard.items[key] = item
+	}
+	item.state = newState
+	item.expires = now.Add(max(alg.TTL(), time.Second))
+	return ret, nil
+}
+
+// gc 每分钟最多执行一次，删除过期的状态
+func (s *memoryShard) gc(now time.Time) {
+	if now.Sub(s.lastGC) < time.Minute {
+		return
+	}
+	s.lastGC = now
+	for k, v := range s.items {
+		if !now.Before(v.expires) {
+			delete(s.items, k)
+		}
+	}
+}
+
+// Len 返回存储的 key 的数量（包括已过期但是还未被清理的）
+func (ms *MemoryStore) Len() int {
+	var total int
+	for i := range ms.shards {
+		ms.shards[i].mu.Lock()
+		total += len(ms.shards[i].items)
+		ms.shards[i].mu.Unlock()
+	}
+	return total
+}
@@ -0,0 +1,51 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xlimiter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xcounter"
+)
+
+// NewWindow 创建一个基于滑动窗口计数器的限流器：在 window 时间内，最多允许 limit 次。
+//
+// bucket 是计数的时间粒度，越小越精确，如 window=1m，bucket=1s
+func NewWindow(limit int64, window time.Duration, bucket time.Duration) *Window {
+	return &Window{
+		limit:   limit,
+		counter: xcounter.NewSlidingWindow(window, bucket),
+	}
+}
+
+// Window 进程内、单个资源的滑动窗口限流器，不区分 key，适用于保护单个下游等场景。
+// 按照 key 限流请使用 Limiter
+type Window struct {
+	mu      sync.Mutex
+	limit   int64
+	counter *xcounter.SlidingWindow
+}
+
+// Allow 判断是否允许，允许时会计数一次
+func (w *Window) Allow() bool {
+	return w.AllowN(1)
+}
+
+// AllowN 判断是否允许 n 次，允许时会计数 n 次
+func (w *Window) AllowN(n int64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.counter.WindowTotal()+n > w.limit {
+		return false
+	}
+	w.counter.IncrN(n)
+	return true
+}
+
+// Remaining 返回当前窗口剩余可用的次数
+func (w *Window) Remaining() int64 {
+	return max(0, w.limit-w.counter.WindowTotal())
+}
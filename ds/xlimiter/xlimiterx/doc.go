@@ -0,0 +1,6 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+// Package xlimiterx 扩展的 xlimiter 状态存储，包括：xkv 存储、Redis 存储(使用 Lua 脚本保证原子性)。
+package xlimiterx
@@ -0,0 +1,58 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xlimiterx
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xlimiter"
+	"github.com/xanygo/anygo/ds/xsync"
+	"github.com/xanygo/anygo/store/xkv"
+)
+
+var _ xlimiter.Store = (*KVStore)(nil)
+
+// KVStore 使用 xkv 存储限流的状态。
+//
+// xkv 没有提供原子的读写操作，KVStore 只能保证在同一个进程内是原子的，
+// 多个进程共享同一个存储时，并发的请求可能会超出限制，需要严格限流时请使用 RedisStore。
+// 另外 xkv 不支持过期时间，状态中记录了过期时间，过期的状态会被当作不存在
+type KVStore struct {
+	DB xkv.StringStorage // 必填
+
+	mu xsync.GroupMutex[string]
+}
+
+func (ks *KVStore) Take(ctx context.Context, key string, alg xlimiter.Algorithm, now time.Time, n int64) (ret xlimiter.Result, err error) {
+	ks.mu.Do(key, func() {
+		ret, err = ks.take(ctx, key, alg, now, n)
+	})
+	return ret, err
+}
+
+// take 存储的值格式为："过期时间(UnixNano)|算法状态"
+func (ks *KVStore) take(ctx context.Context, key string, alg xlimiter.Algorithm, now time.Time, n int64) (xlimiter.Result, error) {
+	str := ks.DB.String(key)
+	value, _, err := str.Get(ctx)
+	if err != nil {
+		return xlimiter.Result{}, err
+	}
+	var state string
+	if expires, data, ok := strings.Cut(value, "|"); ok {
+		if v, err1 := strconv.ParseInt(expires, 10, 64); err1 == nil && now.UnixNano() < v {
+			state = data
+		}
+	}
+	newState, ret := alg.Take(state, now, n)
+	if newState == state && state != "" {
+		return ret, nil
+	}
+	expires := now.Add(alg.TTL()).UnixNano()
+	err = str.Set(ctx, strconv.FormatInt(expires, 10)+"|"+newState)
+	return ret, err
+}
@@ -0,0 +1,45 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xlimiterx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xlimiter"
+	"github.com/xanygo/anygo/ds/xlimiter/xlimiterx"
+	"github.com/xanygo/anygo/store/xkv"
+	"github.com/xanygo/anygo/xt"
+)
+
+func testStore(t *testing.T, store xlimiter.Store) {
+	algs := []xlimiter.Algorithm{
+		&xlimiter.TokenBucket{Rate: xlimiter.PerMinute(2)},
+		&xlimiter.GCRA{Rate: xlimiter.PerMinute(2)},
+		&xlimiter.SlidingLog{Rate: xlimiter.PerMinute(2)},
+	}
+	ctx := context.Background()
+	for _, alg := range algs {
+		t.Run(alg.Name(), func(t *testing.T) {
+			l := &xlimiter.Limiter{Algorithm: alg, Store: store, KeyPrefix: alg.Name() + ":"}
+			for i := 0; i < 2; i++ {
+				ret, err := l.Allow(ctx, "k")
+				xt.NoError(t, err)
+				xt.True(t, ret.Allowed)
+				xt.Equal(t, ret.Remaining, int64(1-i))
+			}
+			ret, err := l.Allow(ctx, "k")
+			xt.NoError(t, err)
+			xt.False(t, ret.Allowed)
+			xt.Greater(t, ret.RetryAfter, 29*time.Second)
+			xt.LessOrEqual(t, ret.RetryAfter, time.Minute)
+		})
+	}
+}
+
+func TestKVStore(t *testing.T) {
+	testStore(t, &xlimiterx.KVStore{DB: xkv.NewMemoryStore()})
+}
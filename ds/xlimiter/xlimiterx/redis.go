@@ -0,0 +1,170 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xlimiterx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xlimiter"
+	"github.com/xanygo/anygo/store/xredis"
+)
+
+var _ xlimiter.Store = (*RedisStore)(nil)
+
+// RedisStore 使用 Redis 存储限流的状态，每种算法都有对应的 Lua 脚本，保证在多个进程之间是原子的。
+//
+// 支持的算法：*xlimiter.TokenBucket、*xlimiter.GCRA、*xlimiter.SlidingLog，
+// 时间使用调用方传入的时间（精确到微秒），所以多个进程之间需要保证时钟同步
+type RedisStore struct {
+	Client    *xredis.Client // 必填
+	KeyPrefix string         // 可选，key 的前缀
+}
+
+func (rs *RedisStore) Take(ctx context.Context, key string, alg xlimiter.Algorithm, now time.Time, n int64) (xlimiter.Result, error) {
+	script, args, limit, err := rs.script(alg, now, n)
+	if err != nil {
+		return xlimiter.Result{}, err
+	}
+	values, err := rs.Client.Eval(ctx, script, []string{rs.KeyPrefix + key}, args...).Int64Slice()
+	if err != nil {
+		return xlimiter.Result{}, err
+	}
+	if len(values) != 4 {
+		return xlimiter.Result{}, fmt.Errorf("invalid lua script result: %v", values)
+	}
+	return xlimiter.Result{
+		Allowed:    values[0] == 1,
+		Limit:      limit,
+		Remaining:  values[1],
+		RetryAfter: time.Duration(values[2]) * time.Microsecond,
+		ResetAfter: time.Duration(values[3]) * time.Microsecond,
+	}, nil
+}
+
+func (rs *RedisStore) script(alg xlimiter.Algorithm, now time.Time, n int64) (string, []any, int64, error) {
+	nowUS := strconv.FormatInt(now.UnixMicro(), 10)
+	switch a := alg.(type) {
+	case *xlimiter.TokenBucket:
+		burst := burstOf(a.Rate)
+		return luaTokenBucket, []any{burst, intervalUS(a.Rate), nowUS, n}, burst, checkRate(a.Rate)
+	case *xlimiter.GCRA:
+		burst := burstOf(a.Rate)
+		return luaGCRA, []any{burst, intervalUS(a.Rate), nowUS, n}, burst, checkRate(a.Rate)
+	case *xlimiter.SlidingLog:
+		window := strconv.FormatInt(a.Rate.Period.Microseconds(), 10)
+		return luaSlidingLog, []any{a.Rate.Limit, window, nowUS, n, randomID()}, a.Rate.Limit, checkRate(a.Rate)
+	default:
+		return "", nil, 0, fmt.Errorf("xlimiterx: algorithm %q not supported by RedisStore", alg.Name())
+	}
+}
+
+func checkRate(r xlimiter.Rate) error {
+	if r.Limit <= 0 || r.Period.Duration() < time.Microsecond {
+		return fmt.Errorf("xlimiterx: invalid rate %+v", r)
+	}
+	return nil
+}
+
+func burstOf(r xlimiter.Rate) int64 {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+	return r.Limit
+}
+
+// intervalUS 每产生一个配额的时间间隔（微秒），可能是小数
+func intervalUS(r xlimiter.Rate) string {
+	return strconv.FormatFloat(float64(r.Period.Microseconds())/float64(r.Limit), 'f', -1, 64)
+}
+
+func randomID() string {
+	bf := make([]byte, 8)
+	_, _ = rand.Read(bf)
+	return hex.EncodeToString(bf)
+}
+
+// Lua 脚本的返回值均为：{是否允许(0/1), 剩余配额, RetryAfter(微秒), ResetAfter(微秒)}。
+// Lua 中的 tostring 只保留 14 位有效数字，所以时间戳都使用 string.format("%.0f") 格式化
+
+// luaTokenBucket 参数：容量、间隔、当前时间、数量。状态存储在 Hash 中：t-剩余令牌数，ts-上次更新时间
+const luaTokenBucket = `
+local capacity = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local n = tonumber(ARGV[4])
+local s = redis.call("HMGET", KEYS[1], "t", "ts")
+local tokens = capacity
+if s[1] and s[2] then
+  local elapsed = math.max(0, now - tonumber(s[2]))
+  tokens = math.min(capacity, tonumber(s[1]) + elapsed / interval)
+end
+local allowed = 0
+local retry = 0
+if tokens >= n then
+  tokens = tokens - n
+  allowed = 1
+else
+  retry = math.ceil((n - tokens) * interval)
+end
+local reset = math.ceil((capacity - tokens) * interval)
+redis.call("HSET", KEYS[1], "t", string.format("%.17g", tokens), "ts", ARGV[3])
+redis.call("PEXPIRE", KEYS[1], math.max(1, math.ceil(capacity * interval / 1000)))
+return {allowed, math.floor(tokens), retry, reset}
+`
+
+// luaGCRA 参数：突发数、间隔、当前时间、数量。状态存储在 String 中：理论到达时间
+const luaGCRA = `
+local burst = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local n = tonumber(ARGV[4])
+local tat = tonumber(redis.call("GET", KEYS[1]))
+if not tat or tat < now then
+  tat = now
+end
+local new_tat = tat + n * interval
+local diff = now - (new_tat - burst * interval)
+if diff < 0 then
+  return {0, 0, math.ceil(-diff), math.ceil(tat - now)}
+end
+local ttl = math.max(1, math.ceil((new_tat - now) / 1000))
+redis.call("SET", KEYS[1], string.format("%.0f", new_tat), "PX", ttl)
+return {1, math.floor(diff / interval), 0, math.ceil(new_tat - now)}
+`
+
+// luaSlidingLog 参数：限制数、窗口、当前时间、数量、随机 ID。状态存储在 ZSet 中，score 是请求时间
+const luaSlidingLog = `
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local n = tonumber(ARGV[4])
+local start = now - window
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", string.format("%.0f", start))
+local count = redis.call("ZCARD", KEYS[1])
+if count + n <= limit then
+  for i = 1, n do
+    redis.call("ZADD", KEYS[1], ARGV[3], ARGV[5] .. ":" .. i)
+  end
+  redis.call("PEXPIRE", KEYS[1], math.max(1, math.ceil(window / 1000)))
+  return {1, limit - count - n, 0, window}
+end
+local retry = window
+if n <= limit then
+  local idx = count + n - limit - 1
+  local item = redis.call("ZRANGE", KEYS[1], idx, idx, "WITHSCORES")
+  retry = tonumber(item[2]) - start
+end
+local reset = 0
+local last = redis.call("ZRANGE", KEYS[1], -1, -1, "WITHSCORES")
+if last[2] then
+  reset = tonumber(last[2]) - start
+end
+return {0, math.max(0, limit - count), retry, reset}
+`
@@ -0,0 +1,27 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xlimiterx_test
+
+import (
+	"testing"
+
+	"github.com/xanygo/anygo/ds/xlimiter/xlimiterx"
+	"github.com/xanygo/anygo/internal/redistest"
+	"github.com/xanygo/anygo/store/xredis"
+	"github.com/xanygo/anygo/xt"
+)
+
+func TestRedisStore(t *testing.T) {
+	ts, errTs := redistest.NewServer()
+	if errTs != nil {
+		t.Skipf("create redis-server skipped: %v", errTs)
+		return
+	}
+	defer ts.Stop()
+
+	_, client, errClient := xredis.NewClientByURI("demo", ts.URI())
+	xt.NoError(t, errClient)
+	testStore(t, &xlimiterx.RedisStore{Client: client, KeyPrefix: "rl:"})
+}
@@ -26,3 +26,25 @@ func RetryPolicy(opt Reader) *xpolicy.Retry {
 	}
 	return xpolicy.DefaultRetry()
 }
+
+// SetRetryBudget 设置重试预算，重试和对冲请求都会消耗预算
+func SetRetryBudget(opt Writer, budget *xpolicy.RetryBudget) {
+	opt.Set(KeyRetryBudget, budget)
+}
+
+// RetryBudget 获取重试预算，未设置时返回 nil，即不限制
+func RetryBudget(opt Reader) *xpolicy.RetryBudget {
+	val, _ := GetAs[*xpolicy.RetryBudget](opt, KeyRetryBudget)
+	return val
+}
+
+// SetHedging 设置对冲请求策略
+func SetHedging(opt Writer, policy *xpolicy.Hedging) {
+	opt.Set(KeyHedging, policy)
+}
+
+// Hedging 获取对冲请求策略，未设置时返回 nil，即不发送对冲请求
+func Hedging(opt Reader) *xpolicy.Hedging {
+	val, _ := GetAs[*xpolicy.Hedging](opt, KeyHedging)
+	return val
+}
@@ -22,6 +22,8 @@ var (
 
 	KeyRetry       = NewKey("Retry")       // 重试次数
 	KeyRetryPolicy = NewKey("RetryPolicy") // 重试策略
+	KeyRetryBudget = NewKey("RetryBudget") // 重试预算
+	KeyHedging     = NewKey("Hedging")     // 对冲请求策略
 
 	KeyBalancer        = NewKey("Balancer") // 负载均衡策略名称
 	KeyMaxResponseSize = NewKey("MaxResponseSize")
@@ -0,0 +1,232 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/xanygo/anygo/ds/xslice"
+	"github.com/xanygo/anygo/store/xdb/dbtype"
+	"github.com/xanygo/anygo/store/xdb/dialect"
+)
+
+// BulkLoader 批量写入数据的实现
+type BulkLoader interface {
+	// BulkLoad 将 rows 写入到表 table 中，rows 的每一项和 columns 一一对应，值已经过编码。
+	// 返回写入的条数
+	BulkLoad(ctx context.Context, db HasDriver, table string, columns []string, rows [][]any) (int64, error)
+}
+
+var _ BulkLoader = ValuesLoader{}
+
+// ValuesLoader 使用 INSERT INTO ... VALUES (...),(...) 多行写入，
+// 会依据方言的绑定参数个数限制（dbtype.BindParamsDialect）和行数限制（dbtype.InsertRowsDialect）自动拆分为多条语句
+type ValuesLoader struct{}
+
+func (ValuesLoader) BulkLoad(ctx context.Context, db HasDriver, table string, columns []string, rows [][]any) (int64, error) {
+	if len(columns) == 0 {
+		return 0, errors.New("no columns")
+	}
+	d, err := dialect.Find(db.Driver())
+	if err != nil {
+		return 0, err
+	}
+	ex, ok := db.(Execer)
+	if !ok {
+		return 0, fmt.Errorf("client (%T) is not Execer", db)
+	}
+	size := maxRowsPerStatement(d, len(columns))
+	if size < 1 {
+		return 0, fmt.Errorf("too many columns (%d) for dialect %q", len(columns), d.Name())
+	}
+	qCols := strings.Join(xslice.MapFunc(columns, d.QuoteIdentifier), ",")
+	var num int64
+	for chunk := range slices.Chunk(rows, size) {
+		holders := make([]string, len(chunk))
+		args := make([]any, 0, len(chunk)*len(columns))
+		for i, row := range chunk {
+			if len(row) != len(columns) {
+				return num, fmt.Errorf("row values not eq columns (%d!=%d)", len(row), len(columns))
+			}
+			holders[i] = "(" + d.PlaceholderList(len(columns), i*len(columns)+1) + ")"
+			args = append(args, row...)
+		}
+		sqlStr := fmt.Sprintf(
+			"INSERT INTO %s (%s) VALUES %s",
+			d.QuoteIdentifier(table),
+			qCols,
+			strings.Join(holders, ", "),
+		)
+		n, err := RowsAffected(Exec(ctx, ex, sqlStr, args...))
+		num += n
+		if err != nil {
+			return num, err
+		}
+	}
+	return num, nil
+}
+
+// maxRowsPerStatement 单条语句最多可写入的行数，若方言没有参数个数和行数的限制，返回 math.MaxInt
+func maxRowsPerStatement(d dbtype.Dialect, columns int) int {
+	rows := math.MaxInt
+	if pd, ok := d.(dbtype.BindParamsDialect); ok {
+		rows = pd.MaxBindParams() / columns
+	}
+	if rd, ok := d.(dbtype.InsertRowsDialect); ok {
+		rows = min(rows, rd.MaxInsertRows())
+	}
+	return rows
+}
+
+// CopyFromFunc 使用数据库驱动原生的批量导入能力写入数据，如 pgx 的 CopyFrom（COPY FROM STDIN），
+// 或者 go-sql-driver/mysql 的 LOAD DATA LOCAL INFILE 'Reader::name'。
+//
+// driverConn 为 database/sql 驱动的原始连接，如 pgx 的 *stdlib.Conn
+type CopyFromFunc func(ctx context.Context, driverConn any, table string, columns []string, rows [][]any) (int64, error)
+
+var copyFromFuncs sync.Map
+
+// RegisterCopyFrom 注册驱动的 CopyFromFunc，注册后，Model.BulkInsert 默认会使用此方法写入数据。
+//
+// 如 pgx：
+//
+//	xdb.RegisterCopyFrom("pgx", func(ctx context.Context, dc any, table string, cols []string, rows [][]any) (int64, error) {
+//		conn := dc.(*stdlib.Conn).Conn()
+//		return conn.CopyFrom(ctx, pgx.Identifier{table}, cols, pgx.CopyFromRows(rows))
+//	})
+func RegisterCopyFrom(driver string, fn CopyFromFunc) {
+	copyFromFuncs.Store(driver, fn)
+}
+
+// FindCopyFrom 查找驱动注册的 CopyFromFunc
+func FindCopyFrom(driver string) (CopyFromFunc, bool) {
+	v, ok := copyFromFuncs.Load(driver)
+	if !ok {
+		return nil, false
+	}
+	return v.(CopyFromFunc), true
+}
+
+// HasRaw 可以获取驱动原始连接，如 *Client
+type HasRaw interface {
+	Raw(ctx context.Context, fn func(driverConn any) error) error
+}
+
+var _ HasRaw = (*Client)(nil)
+
+var _ BulkLoader = CopyFromLoader{}
+
+// CopyFromLoader 使用 CopyFromFunc 写入数据，db 需要实现 HasRaw 接口（如 *Client），
+// 由于事务中无法获取驱动的原始连接，所以不能在事务中使用
+type CopyFromLoader struct {
+	Copy CopyFromFunc
+}
+
+func (l CopyFromLoader) BulkLoad(ctx context.Context, db HasDriver, table string, columns []string, rows [][]any) (int64, error) {
+	if l.Copy == nil {
+		return 0, errors.New("CopyFromLoader.Copy is nil")
+	}
+	hr, ok := db.(HasRaw)
+	if !ok {
+		return 0, fmt.Errorf("client (%T) is not HasRaw", db)
+	}
+	var num int64
+	err := hr.Raw(ctx, func(driverConn any) error {
+		var err error
+		num, err = l.Copy(ctx, driverConn, table, columns, rows)
+		return err
+	})
+	return num, err
+}
+
+// defaultBulkLoader 若驱动注册了 CopyFromFunc 并且 db 支持获取原始连接，使用 CopyFromLoader，否则使用 ValuesLoader
+func defaultBulkLoader(db HasDriver) BulkLoader {
+	if _, ok := db.(HasRaw); ok {
+		if fn, ok := FindCopyFrom(db.Driver()); ok {
+			return CopyFromLoader{Copy: fn}
+		}
+	}
+	return ValuesLoader{}
+}
+
+// BulkOption 批量写入的参数
+type BulkOption struct {
+	// BatchSize 每个批次的最大条数，可选，<= 0 时使用默认值 1000。
+	// 使用 ValuesLoader 时，每个批次还会依据方言的参数个数限制拆分为多条语句
+	BatchSize int
+
+	// ChunkTx 每个批次是否在一个独立的事务中执行，可选。
+	// 仅当 client 支持 BeginTx（如 *Client）时有效，对 CopyFromLoader 无效（单条 COPY 语句本身即是原子的）
+	ChunkTx bool
+
+	// OnProgress 每个批次写入完成后的回调，可选。done：已写入的条数，total：总条数
+	OnProgress func(done int, total int)
+
+	// Loader 写入数据的实现，可选。
+	// 为空时，若驱动已通过 RegisterCopyFrom 注册，则使用 CopyFromLoader，否则使用 ValuesLoader
+	Loader BulkLoader
+}
+
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (TxExecutor, error)
+}
+
+var _ txBeginner = (*Client)(nil)
+
+// bulkLoad 按批次写入数据
+func bulkLoad(ctx context.Context, db HasDriver, opt BulkOption, table string, columns []string, rows [][]any) (int64, error) {
+	loader := opt.Loader
+	if loader == nil {
+		loader = defaultBulkLoader(db)
+	}
+	size := opt.BatchSize
+	if size <= 0 {
+		size = 1000
+	}
+	_, isCopy := loader.(CopyFromLoader)
+	tb, canTx := db.(txBeginner)
+	useTx := opt.ChunkTx && canTx && !isCopy
+
+	var done int
+	var num int64
+	for chunk := range slices.Chunk(rows, size) {
+		var n int64
+		var err error
+		if useTx {
+			var tx TxExecutor
+			tx, err = tb.BeginTx(ctx, nil)
+			if err != nil {
+				return num, err
+			}
+			err = WithTx(ctx, tx, func(ctx context.Context, tx TxCore) error {
+				var err1 error
+				n, err1 = loader.BulkLoad(ctx, tx, table, columns, chunk)
+				return err1
+			})
+			if err != nil {
+				// 事务已回滚，此批次未写入
+				n = 0
+			}
+		} else {
+			n, err = loader.BulkLoad(ctx, db, table, columns, chunk)
+		}
+		num += n
+		if err != nil {
+			return num, err
+		}
+		done += len(chunk)
+		if opt.OnProgress != nil {
+			opt.OnProgress(done, len(rows))
+		}
+	}
+	return num, nil
+}
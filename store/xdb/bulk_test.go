@@ -0,0 +1,129 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xdb_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/xanygo/anygo/store/xdb"
+	"github.com/xanygo/anygo/store/xdb/xtdr"
+	"github.com/xanygo/anygo/xt"
+)
+
+func newKeysetUsers(n int) []keysetUser {
+	users := make([]keysetUser, n)
+	for i := range users {
+		users[i] = keysetUser{ID: int64(i + 1), Name: "u", Score: i}
+	}
+	return users
+}
+
+type bulkTag struct {
+	ID   int64  `db:"id,pk"`
+	Name string `db:"name"`
+}
+
+func (bulkTag) TableName() string {
+	return "tag"
+}
+
+func TestModel_InsertBatch(t *testing.T) {
+	client := xdb.NewClient("sqlite3", "test", xtdr.MustOpen())
+	defer xtdr.Reset()
+
+	t.Run("split by params", func(t *testing.T) {
+		xtdr.Reset()
+		// sqlite3 最多 999 个参数，3 个字段，每条语句最多 333 条
+		xtdr.ExpectExec("wc:*", xtdr.ResultOf(0, 333), nil)
+		xtdr.ExpectExec("wc:*", xtdr.ResultOf(0, 333), nil)
+		xtdr.ExpectExec("wc:*", xtdr.ResultOf(0, 34), nil)
+		m := xdb.NewMode[keysetUser](client)
+		num, err := m.InsertBatch(t.Context(), newKeysetUsers(700)...)
+		xt.NoError(t, err)
+		xt.Equal(t, num, 700)
+		queries := xtdr.LastQueries()
+		xt.Len(t, queries, 3)
+		xt.Equal(t, strings.Count(queries[0], "(?,?,?)"), 333)
+		xt.Equal(t, strings.Count(queries[2], "(?,?,?)"), 34)
+	})
+
+	t.Run("split by rows", func(t *testing.T) {
+		xtdr.Reset()
+		// SQL Server 最多 2098 个参数，2 个字段时为 1049 条，但单条语句最多 1000 行
+		xtdr.ExpectExec("wc:*", xtdr.ResultOf(0, 1000), nil)
+		xtdr.ExpectExec("wc:*", xtdr.ResultOf(0, 500), nil)
+		rows := make([]bulkTag, 1500)
+		for i := range rows {
+			rows[i] = bulkTag{ID: int64(i + 1), Name: "t"}
+		}
+		m := xdb.NewMode[bulkTag](xdb.NewClient("sqlserver", "test", xtdr.MustOpen()))
+		num, err := m.InsertBatch(t.Context(), rows...)
+		xt.NoError(t, err)
+		xt.Equal(t, num, 1500)
+		queries := xtdr.LastQueries()
+		xt.Len(t, queries, 2)
+		xt.Equal(t, strings.Count(queries[0], "), ("), 999)
+		xt.Equal(t, strings.Count(queries[1], "), ("), 499)
+	})
+
+	t.Run("batch size", func(t *testing.T) {
+		xtdr.Reset()
+		xtdr.ExpectExec("wc:*", xtdr.ResultOf(0, 2), nil)
+		xtdr.ExpectExec("wc:*", xtdr.ResultOf(0, 1), nil)
+		m := xdb.NewMode[keysetUser](client).SetBatchSize(2)
+		num, err := m.InsertBatch(t.Context(), newKeysetUsers(3)...)
+		xt.NoError(t, err)
+		xt.Equal(t, num, 3)
+		xt.Len(t, xtdr.LastQueries(), 2)
+	})
+}
+
+func TestModel_BulkInsert(t *testing.T) {
+	client := xdb.NewClient("sqlite3", "test", xtdr.MustOpen())
+	defer xtdr.Reset()
+	m := xdb.NewMode[keysetUser](client)
+
+	t.Run("values", func(t *testing.T) {
+		xtdr.Reset()
+		xtdr.ExpectExec("wc:*", xtdr.ResultOf(0, 2), nil)
+		xtdr.ExpectExec("wc:*", xtdr.ResultOf(0, 1), nil)
+		var progress [][2]int
+		opt := xdb.BulkOption{
+			BatchSize: 2,
+			ChunkTx:   true,
+			OnProgress: func(done int, total int) {
+				progress = append(progress, [2]int{done, total})
+			},
+		}
+		num, err := m.BulkInsert(t.Context(), opt, newKeysetUsers(3)...)
+		xt.NoError(t, err)
+		xt.Equal(t, num, 3)
+		xt.Equal(t, progress, [][2]int{{2, 3}, {3, 3}})
+	})
+
+	t.Run("copy from", func(t *testing.T) {
+		xtdr.Reset()
+		var gotTable string
+		var gotRows int
+		copyFn := func(ctx context.Context, driverConn any, table string, columns []string, rows [][]any) (int64, error) {
+			xt.NotNil(t, driverConn)
+			xt.Len(t, columns, 3)
+			gotTable = table
+			gotRows += len(rows)
+			return int64(len(rows)), nil
+		}
+		opt := xdb.BulkOption{
+			Loader: xdb.CopyFromLoader{Copy: copyFn},
+		}
+		num, err := m.BulkInsert(t.Context(), opt, newKeysetUsers(5)...)
+		xt.NoError(t, err)
+		xt.Equal(t, num, 5)
+		xt.Equal(t, gotRows, 5)
+		xt.Equal(t, gotTable, "user")
+		xt.Empty(t, xtdr.LastQueries())
+	})
+}
@@ -0,0 +1,105 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xdb
+
+import (
+	"context"
+	"fmt"
+)
+
+// 数据变更类型
+const (
+	ChangeInsert = "insert"
+	ChangeUpdate = "update"
+	ChangeUpsert = "upsert"
+	ChangeDelete = "delete"
+)
+
+// Change Model 写入数据成功后产生的数据变更
+type Change struct {
+	Table  string
+	Action string // 变更类型，如 ChangeInsert
+
+	// PKValues 主键字段和值（已编码），Insert、UpdateByPK、DeleteByPK 等能确定主键值时不为空
+	PKValues map[string]any
+
+	// Values 写入的字段和值（已编码），Action 为 ChangeDelete 时为空。
+	// InsertBatch、Upsert 时，每一条数据对应一个 Change
+	Values map[string]any
+
+	// Where、Args 为 update、delete 的条件，使用 ? 占位符
+	Where string
+	Args  []any
+
+	RowsAffected int64
+}
+
+// ChangeHook 在 Model 写入数据成功后调用，db 为执行写入所使用的 client，若在事务中则为事务（TxCore）。
+//
+// 若返回 error，Model 的写入方法也会返回该 error，在 WithTx 中使用时会导致事务回滚。
+// 可以用于在同一个事务中写入 outbox 事件、审计日志等
+type ChangeHook func(ctx context.Context, db HasDriver, c Change) error
+
+// OnChange 添加数据变更的回调，Insert、InsertReturningID、InsertBatch、Upsert、Update 系列、Delete 系列方法写入成功后会调用。
+//
+// update、delete 影响行数为 0 时不会调用；BulkInsert 不会调用
+func (m *Model[T]) OnChange(hooks ...ChangeHook) *Model[T] {
+	m.hooks = append(m.hooks, hooks...)
+	return m
+}
+
+func (m *Model[T]) emitChange(ctx context.Context, c Change) error {
+	if len(m.hooks) == 0 {
+		return nil
+	}
+	c.Table = m.table
+	if c.PKValues == nil {
+		c.PKValues = m.changePK(c.Values)
+	}
+	for _, hook := range m.hooks {
+		if err := hook(ctx, m.client, c); err != nil {
+			return fmt.Errorf("change hook: %w", err)
+		}
+	}
+	return nil
+}
+
+// emitRows 批量写入时，每一条数据对应一个 Change
+func (m *Model[T]) emitRows(ctx context.Context, action string, cols []string, rows [][]any) error {
+	if len(m.hooks) == 0 {
+		return nil
+	}
+	for _, row := range rows {
+		values := make(map[string]any, len(cols))
+		for i, col := range cols {
+			values[col] = row[i]
+		}
+		c := Change{
+			Action:       action,
+			Values:       values,
+			RowsAffected: 1,
+		}
+		if err := m.emitChange(ctx, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changePK 变更对应的主键值：优先使用 UpdateByPK、DeleteByPK 传入的，其次从写入的值中读取
+func (m *Model[T]) changePK(values map[string]any) map[string]any {
+	if m.pkValues != nil || len(m.pk) == 0 {
+		return m.pkValues
+	}
+	result := make(map[string]any, len(m.pk))
+	for _, col := range m.pk {
+		v, ok := values[col.Name]
+		if !ok {
+			return nil
+		}
+		result[col.Name] = v
+	}
+	return result
+}
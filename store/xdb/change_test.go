@@ -0,0 +1,45 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xdb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/xanygo/anygo/store/xdb"
+	"github.com/xanygo/anygo/store/xdb/xtdr"
+	"github.com/xanygo/anygo/xt"
+)
+
+func TestModel_OnChange(t *testing.T) {
+	m := xtdr.NewMock()
+	client := xdb.NewClient("sqlite3", "test", m.DB())
+	var changes []xdb.Change
+	um := xdb.NewMode[keysetUser](client).OnChange(func(ctx context.Context, db xdb.HasDriver, c xdb.Change) error {
+		changes = append(changes, c)
+		return nil
+	})
+
+	m.ExpectExec("wc:INSERT *").WillReturnResult(xtdr.ResultOf(0, 2))
+	_, err := um.InsertBatch(t.Context(), newKeysetUsers(2)...)
+	xt.NoError(t, err)
+	xt.Len(t, changes, 2)
+	xt.Equal(t, changes[1].Action, xdb.ChangeInsert)
+	xt.Equal(t, changes[1].Table, "user")
+	xt.Equal(t, changes[1].PKValues, map[string]any{"id": int64(2)})
+
+	changes = nil
+	m.ExpectExec("wc:DELETE *").WillReturnResult(xtdr.ResultOf(0, 0))
+	m.ExpectExec("wc:DELETE *").WillReturnResult(xtdr.ResultOf(0, 1))
+	_, err = um.Delete(t.Context(), "score>?", 10)
+	xt.NoError(t, err)
+	xt.Empty(t, changes) // 影响行数为 0
+	_, err = um.DeleteByPK(t.Context(), keysetUser{ID: 3})
+	xt.NoError(t, err)
+	xt.Len(t, changes, 1)
+	xt.Equal(t, changes[0].Action, xdb.ChangeDelete)
+	xt.Equal(t, changes[0].PKValues, map[string]any{"id": int64(3)})
+	xt.NoError(t, m.ExpectationsWereMet())
+}
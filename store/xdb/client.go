@@ -236,6 +236,31 @@ func (c *Client) QueryRowContext(ctx context.Context, query string, args ...any)
 	return c.db.QueryRowContext(ctx, query, args...)
 }
 
+// Raw 从连接池中取出一个连接，并使用数据库驱动的原始连接执行 fn，如 pgx 的 *stdlib.Conn。
+// 可用于调用驱动特有的功能，如 Postgres 的 COPY FROM STDIN
+func (c *Client) Raw(ctx context.Context, fn func(driverConn any) error) (err error) {
+	its := allInterceptors(ctx)
+	if len(its) > 0 {
+		event := Event{
+			Action: "Raw",
+			Start:  time.Now(),
+			Client: c.Name(),
+			Driver: c.Driver(),
+		}
+		defer func() {
+			event.End = time.Now()
+			event.Error = err
+			its.CallAfter(ctx, event)
+		}()
+	}
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.Raw(fn)
+}
+
 var _ io.Closer = (*Client)(nil)
 
 func (c *Client) Close() error {
@@ -75,6 +75,25 @@ type UpsertDialect interface {
 	UpsertSQL(table string, count int, cols, conflictCols, updateCols []string, returningCols []string) string
 }
 
+// RowValueDialect 行值（Row Value）比较支持，用于键集分页等场景
+type RowValueDialect interface {
+	// SupportRowValue 是否支持形如 (a, b) > (?, ?) 的行值比较，
+	// 若不支持或者未实现此接口，会展开为 a > ? OR (a = ? AND b > ?) 的形式
+	SupportRowValue() bool
+}
+
+// BindParamsDialect 单条 SQL 语句中绑定参数个数的限制，批量写入时会依据此拆分为多个批次
+type BindParamsDialect interface {
+	// MaxBindParams 返回单条语句最多允许的绑定参数个数
+	MaxBindParams() int
+}
+
+// InsertRowsDialect 单条 INSERT ... VALUES 语句中行数的限制，批量写入时会依据此拆分为多个批次
+type InsertRowsDialect interface {
+	// MaxInsertRows 返回单条 INSERT ... VALUES 语句最多允许的行数
+	MaxInsertRows() int
+}
+
 type CoderDialect interface {
 	// ColumnCodec 字段类型转换为方言类型。
 	// 返回值：类型，编解码器，数据库字段类型
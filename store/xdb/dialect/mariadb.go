@@ -76,6 +76,20 @@ func (d MariaDB) SupportLastInsertId() bool {
 	return true
 }
 
+var _ dbtype.RowValueDialect = MariaDB{}
+
+// SupportRowValue MariaDB 支持行值比较
+func (MariaDB) SupportRowValue() bool {
+	return true
+}
+
+var _ dbtype.BindParamsDialect = MariaDB{}
+
+// MaxBindParams MariaDB 预编译语句最多 65535 个参数
+func (MariaDB) MaxBindParams() int {
+	return 65535
+}
+
 // ReturningClause 返回 RETURNING 子句。
 // 如果 columns 为空，返回 RETURNING *。
 func (d MariaDB) ReturningClause(columns ...string) string {
@@ -95,6 +95,20 @@ func (MySQL) SupportLastInsertId() bool {
 	return true
 }
 
+var _ dbtype.RowValueDialect = MySQL{}
+
+// SupportRowValue MySQL 自 5.7 起可以使用索引优化行值比较
+func (MySQL) SupportRowValue() bool {
+	return true
+}
+
+var _ dbtype.BindParamsDialect = MySQL{}
+
+// MaxBindParams MySQL 预编译语句最多 65535 个参数
+func (MySQL) MaxBindParams() int {
+	return 65535
+}
+
 var _ dbtype.UpsertDialect = MySQL{}
 
 func (d MySQL) UpsertSQL(table string, count int, columns, conflictCols, updateCols []string, returningCols []string) string {
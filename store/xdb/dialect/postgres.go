@@ -93,6 +93,20 @@ func (Postgres) SupportLastInsertId() bool {
 	return false
 }
 
+var _ dbtype.RowValueDialect = Postgres{}
+
+// SupportRowValue Postgres 支持行值比较，并可以利用联合索引
+func (Postgres) SupportRowValue() bool {
+	return true
+}
+
+var _ dbtype.BindParamsDialect = Postgres{}
+
+// MaxBindParams Postgres 扩展协议中参数个数使用 uint16 表示，最多 65535 个
+func (Postgres) MaxBindParams() int {
+	return 65535
+}
+
 // ReturningClause 生成 RETURNING 子句
 func (d Postgres) ReturningClause(columns ...string) string {
 	if len(columns) == 0 {
@@ -89,6 +89,20 @@ func (SQLite3) SupportLastInsertId() bool {
 	return true
 }
 
+var _ dbtype.RowValueDialect = SQLite3{}
+
+// SupportRowValue 从 SQLite3 3.15.0 (2016-10) 起支持行值比较
+func (SQLite3) SupportRowValue() bool {
+	return true
+}
+
+var _ dbtype.BindParamsDialect = SQLite3{}
+
+// MaxBindParams SQLite3 默认的 SQLITE_MAX_VARIABLE_NUMBER 在 3.32.0 之前为 999，为了兼容性使用此值
+func (SQLite3) MaxBindParams() int {
+	return 999
+}
+
 // ReturningClause 生成 RETURNING 子句。
 // 空 columns 表示 RETURNING *。
 func (d SQLite3) ReturningClause(columns ...string) string {
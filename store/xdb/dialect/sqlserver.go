@@ -88,6 +88,27 @@ func (SQLServer) SupportLastInsertId() bool {
 	return false
 }
 
+var _ dbtype.RowValueDialect = SQLServer{}
+
+// SupportRowValue SQL Server 不支持行值比较
+func (SQLServer) SupportRowValue() bool {
+	return false
+}
+
+var _ dbtype.BindParamsDialect = SQLServer{}
+
+// MaxBindParams SQL Server 最多 2100 个参数，驱动使用 sp_executesql 执行时自身会占用 2 个
+func (SQLServer) MaxBindParams() int {
+	return 2098
+}
+
+var _ dbtype.InsertRowsDialect = SQLServer{}
+
+// MaxInsertRows SQL Server 的 INSERT ... VALUES 最多 1000 行
+func (SQLServer) MaxInsertRows() int {
+	return 1000
+}
+
 // ReturningClause SQL Server 用 OUTPUT 子句实现
 func (d SQLServer) ReturningClause(columns ...string) string {
 	if len(columns) == 0 {
@@ -301,3 +301,40 @@ func (e Encoder[T]) EncodeArgs(args ...any) ([]any, error) {
 	}
 	return result, nil
 }
+
+// ColumnValues 读取 obj 中指定字段的原始值（未经 Codec 编码），返回值和 names 一一对应
+func (e Encoder[T]) ColumnValues(obj T, names []string) ([]any, error) {
+	v, err := e.reflectValue(obj)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+	result := make([]any, len(names))
+	found := 0
+	err = e.rangeStructFields(v, func(fieldSchema dbtype.ColumnSchema, value reflect.Value) error {
+		if i, ok := index[fieldSchema.Name]; ok {
+			result[i] = value.Interface()
+			found++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found != len(names) {
+		return nil, fmt.Errorf("columns %q not all found in %T", names, obj)
+	}
+	return result, nil
+}
+
+// EncodeColumnValue 使用字段 name 的 Codec 对值 val 进行编码
+func (e Encoder[T]) EncodeColumnValue(name string, val any) (any, error) {
+	col, err := e.Schema.ColumnByName(name)
+	if err != nil {
+		return nil, err
+	}
+	return e.encodeStructFieldValue(col, val)
+}
@@ -0,0 +1,416 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xdb
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/xanygo/anygo/ds/xslice"
+	"github.com/xanygo/anygo/store/xdb/dbtype"
+	"github.com/xanygo/anygo/store/xdb/internal/encoder"
+	"github.com/xanygo/anygo/xcodec"
+)
+
+// CursorCodec 键集分页游标的编解码器，默认为 JSON + Base64(URL 安全，无填充)。
+//
+// 若不希望游标内容被客户端解读或者篡改，可替换为带加密的 Codec，
+// 如 xcodec.CodecWithCipher(xcodec.JSON, xcodec.Ciphers{aesCipher, xcodec.Base64{}})
+var CursorCodec xcodec.Codec = xcodec.CodecWithCipher(xcodec.JSON, xcodec.Base64{Encoder: base64.RawURLEncoding})
+
+// ErrInvalidCursor 游标无效：格式错误或者和查询的排序字段不匹配
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// KeysetColumn 键集分页的排序字段
+type KeysetColumn struct {
+	Name string // 数据库字段名，必填
+	Desc bool   // 是否倒序排列
+}
+
+// Keyset 键集（游标）分页的查询参数
+type Keyset struct {
+	// Columns 排序字段，可选，为空时使用主键升序。
+	// 排序字段的组合需要能唯一确定一行数据，若其中未包含主键字段，会自动追加主键字段（排序方向和最后一个字段相同）
+	Columns []KeysetColumn
+
+	// Size 每页条数，必填，>= 1
+	Size int
+
+	// Cursor 游标，可选，为空表示查询第一页。
+	// 值为上一次查询返回的 KeysetPage.Next 或者 KeysetPage.Prev
+	Cursor string
+}
+
+// KeysetPage 键集分页的查询结果
+type KeysetPage[T any] struct {
+	Items []T
+	Next  string // 下一页的游标，为空表示没有下一页
+	Prev  string // 上一页的游标，为空表示没有上一页
+}
+
+func (p KeysetPage[T]) HasNext() bool {
+	return p.Next != ""
+}
+
+func (p KeysetPage[T]) HasPrev() bool {
+	return p.Prev != ""
+}
+
+// keysetCursor 游标的内容
+type keysetCursor struct {
+	Columns  string            `json:"c"`           // 排序字段签名，用于校验游标和查询是否匹配
+	Values   []json.RawMessage `json:"v"`           // 边界行的排序字段值
+	Backward bool              `json:"b,omitempty"` // 是否向前翻页
+}
+
+func keysetNames(cols []KeysetColumn) []string {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.Name
+	}
+	return names
+}
+
+func keysetSign(cols []KeysetColumn) string {
+	return xslice.JoinFunc(cols, func(c KeysetColumn) string {
+		if c.Desc {
+			return "-" + c.Name
+		}
+		return c.Name
+	}, ",")
+}
+
+func encodeCursor(cols []KeysetColumn, values []any, backward bool) (string, error) {
+	kc := keysetCursor{
+		Columns:  keysetSign(cols),
+		Values:   make([]json.RawMessage, len(values)),
+		Backward: backward,
+	}
+	for i, v := range values {
+		bf, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("encode cursor column %q: %w", cols[i].Name, err)
+		}
+		kc.Values[i] = bf
+	}
+	return xcodec.EncodeToString(CursorCodec, kc)
+}
+
+func decodeCursor(cols []KeysetColumn, token string) (*keysetCursor, error) {
+	kc := &keysetCursor{}
+	if err := xcodec.DecodeFromString(CursorCodec, token, kc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+	if kc.Columns != keysetSign(cols) || len(kc.Values) != len(cols) {
+		return nil, fmt.Errorf("%w: columns mismatch, expect %q, got %q", ErrInvalidCursor, keysetSign(cols), kc.Columns)
+	}
+	return kc, nil
+}
+
+// keysetColumns 校验排序字段并补全主键字段
+func (m *Model[T]) keysetColumns(columns []KeysetColumn) ([]KeysetColumn, error) {
+	if len(columns) == 0 && len(m.pk) == 0 {
+		return nil, dbtype.ErrNoPK
+	}
+	result := slices.Clone(columns)
+	desc := len(result) > 0 && result[len(result)-1].Desc
+	for _, pk := range m.pk {
+		has := slices.ContainsFunc(result, func(c KeysetColumn) bool {
+			return c.Name == pk.Name
+		})
+		if !has {
+			result = append(result, KeysetColumn{Name: pk.Name, Desc: desc})
+		}
+	}
+	for _, c := range result {
+		if _, err := m.schema.ColumnByName(c.Name); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// keysetRawValues 读取数据 v 中排序字段的原始值
+func (m *Model[T]) keysetRawValues(cols []KeysetColumn, v T) ([]any, error) {
+	return m.getEncoder(encoder.ActionSelect).ColumnValues(v, keysetNames(cols))
+}
+
+// keysetCursorValues 将游标中的值解析为排序字段的原始类型
+func (m *Model[T]) keysetCursorValues(cols []KeysetColumn, kc *keysetCursor) ([]any, error) {
+	result := make([]any, len(cols))
+	for i, c := range cols {
+		col, err := m.schema.ColumnByName(c.Name)
+		if err != nil {
+			return nil, err
+		}
+		if col.ReflectType == nil {
+			var val any
+			if err = json.Unmarshal(kc.Values[i], &val); err != nil {
+				return nil, fmt.Errorf("%w: column %q: %w", ErrInvalidCursor, c.Name, err)
+			}
+			result[i] = val
+			continue
+		}
+		rv := reflect.New(col.ReflectType)
+		if err = json.Unmarshal(kc.Values[i], rv.Interface()); err != nil {
+			return nil, fmt.Errorf("%w: column %q: %w", ErrInvalidCursor, c.Name, err)
+		}
+		result[i] = rv.Elem().Interface()
+	}
+	return result, nil
+}
+
+// keysetCondition 生成键集分页的条件，如 ("a","b") > (?,?) 或者 "a" > ? OR ("a" = ? AND "b" > ?)
+func (m *Model[T]) keysetCondition(cols []KeysetColumn, rawValues []any, backward bool) (string, []any, error) {
+	enc := m.getEncoder(encoder.ActionSelect)
+	values := make([]any, len(cols))
+	for i, c := range cols {
+		val, err := enc.EncodeColumnValue(c.Name, rawValues[i])
+		if err != nil {
+			return "", nil, fmt.Errorf("encode column %q: %w", c.Name, err)
+		}
+		values[i] = val
+	}
+
+	ops := make([]string, len(cols))
+	for i, c := range cols {
+		if c.Desc == backward {
+			ops[i] = ">"
+		} else {
+			ops[i] = "<"
+		}
+	}
+
+	qCols := xslice.MapFunc(keysetNames(cols), m.dialect.QuoteIdentifier)
+
+	if len(cols) == 1 {
+		return qCols[0] + " " + ops[0] + " ?", values, nil
+	}
+
+	rd, ok := m.dialect.(dbtype.RowValueDialect)
+	sameOp := !slices.ContainsFunc(ops, func(op string) bool {
+		return op != ops[0]
+	})
+	if ok && rd.SupportRowValue() && sameOp {
+		str := fmt.Sprintf("(%s) %s (%s)", strings.Join(qCols, ","), ops[0], Placeholder(len(cols)))
+		return str, values, nil
+	}
+
+	// 不支持行值比较，或者排序方向不一致时，展开为：
+	// a > ? OR (a = ? AND b > ?) OR (a = ? AND b = ? AND c > ?)
+	ors := make([]string, 0, len(cols))
+	args := make([]any, 0, len(cols)*(len(cols)+1)/2)
+	for i := range cols {
+		ands := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, qCols[j]+" = ?")
+			args = append(args, values[j])
+		}
+		ands = append(ands, qCols[i]+" "+ops[i]+" ?")
+		args = append(args, values[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", args, nil
+}
+
+func (m *Model[T]) keysetOrderBy(cols []KeysetColumn, backward bool) string {
+	items := make([]string, len(cols))
+	for i, c := range cols {
+		if c.Desc == backward {
+			items[i] = m.dialect.QuoteIdentifier(c.Name) + " ASC"
+		} else {
+			items[i] = m.dialect.QuoteIdentifier(c.Name) + " DESC"
+		}
+	}
+	return "ORDER BY " + strings.Join(items, ", ")
+}
+
+// keysetFetch 查询边界行 rawValues 之后（或之前）的 limit 条数据，返回的数据总是按照 cols 定义的顺序排列
+func (m *Model[T]) keysetFetch(ctx context.Context, cols []KeysetColumn, limit int, rawValues []any, backward bool, where string, args []any) ([]T, error) {
+	where = strings.TrimSpace(where)
+	if reOrderBy.MatchString(where) {
+		return nil, errors.New("keyset where clause must not contain ORDER BY")
+	}
+	cond := &Condition{}
+	if where != "" {
+		cond.And("("+where+")", args...)
+	}
+	if len(rawValues) > 0 {
+		kw, kArgs, err := m.keysetCondition(cols, rawValues, backward)
+		if err != nil {
+			return nil, err
+		}
+		cond.And(kw, kArgs...)
+	}
+	where, args, err := cond.Build()
+	if err != nil {
+		return nil, err
+	}
+	if where != "" {
+		where += " "
+	}
+	where += m.keysetOrderBy(cols, backward)
+
+	where, args, err = m.buildWhere(0, where, args)
+	if err != nil {
+		return nil, err
+	}
+	field, err := m.getSelectFields()
+	if err != nil {
+		return nil, err
+	}
+	sqlStr := fmt.Sprintf(
+		"SELECT %s FROM %s %s",
+		field,
+		m.dialect.QuoteIdentifier(m.table),
+		m.whereLimitOffset(where, limit, 0),
+	)
+	db, ok := m.client.(Queryer)
+	if !ok {
+		return nil, fmt.Errorf("client (%T) is not Queryer", m.client)
+	}
+	items, err := QueryMany[T](ctx, db, sqlStr, args...)
+	if err != nil {
+		return nil, err
+	}
+	if backward {
+		slices.Reverse(items)
+	}
+	return items, nil
+}
+
+// ListKeyset 键集（游标）分页查询，支持向后（Next）和向前（Prev）翻页。
+//
+// 和 ListPage 相比，不需要执行 COUNT 和 OFFSET，查询性能不会随着页码增加而下降，适用于数据量大的表。
+//
+//	where 条件中不能包含 ORDER BY，排序由 ks.Columns 决定
+//	若使用 SetSelectFields、SetSelectIgnore 限制查询返回的字段，返回的字段中需要包含排序字段
+func (m *Model[T]) ListKeyset(ctx context.Context, ks Keyset, where string, args ...any) (KeysetPage[T], error) {
+	var page KeysetPage[T]
+	if m.err != nil {
+		return page, m.err
+	}
+	if ks.Size < 1 {
+		return page, fmt.Errorf("invalid size=%d", ks.Size)
+	}
+	cols, err := m.keysetColumns(ks.Columns)
+	if err != nil {
+		return page, err
+	}
+
+	var cursorValues []any
+	var backward bool
+	if ks.Cursor != "" {
+		kc, err := decodeCursor(cols, ks.Cursor)
+		if err != nil {
+			return page, err
+		}
+		cursorValues, err = m.keysetCursorValues(cols, kc)
+		if err != nil {
+			return page, err
+		}
+		backward = kc.Backward
+	}
+
+	items, err := m.keysetFetch(ctx, cols, ks.Size+1, cursorValues, backward, where, args)
+	if err != nil {
+		return page, err
+	}
+	hasMore := len(items) > ks.Size
+	if hasMore {
+		if backward {
+			items = items[1:]
+		} else {
+			items = items[:ks.Size]
+		}
+	}
+	page.Items = items
+
+	// 向后翻页时：是否有下一页取决于是否还有更多数据，只要有游标，就一定有上一页
+	// 向前翻页时：则相反
+	hasNext, hasPrev := hasMore, ks.Cursor != ""
+	if backward {
+		hasNext, hasPrev = true, hasMore
+	}
+
+	first, last := cursorValues, cursorValues
+	if len(items) > 0 {
+		if first, err = m.keysetRawValues(cols, items[0]); err != nil {
+			return page, err
+		}
+		if last, err = m.keysetRawValues(cols, items[len(items)-1]); err != nil {
+			return page, err
+		}
+	}
+	if hasNext && len(last) > 0 {
+		if page.Next, err = encodeCursor(cols, last, false); err != nil {
+			return page, err
+		}
+	}
+	if hasPrev && len(first) > 0 {
+		if page.Prev, err = encodeCursor(cols, first, true); err != nil {
+			return page, err
+		}
+	}
+	return page, nil
+}
+
+// Stream 使用键集分页的方式分批次遍历满足条件的全部数据。
+//
+// 每个批次都是一个独立的查询，不会持有长事务，也不会长时间占用数据库连接，
+// 适用于数据导出、数据回填等需要遍历全表的场景。
+//
+//	columns: 排序字段，可选，为空时使用主键升序，规则同 Keyset.Columns
+//	batchSize: 每批次查询的条数，<= 0 时使用默认值 1000
+//	where 条件中不能包含 ORDER BY，Limit、Offset 对此方法无效
+func (m *Model[T]) Stream(ctx context.Context, columns []KeysetColumn, batchSize int, where string, args ...any) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		if m.err != nil {
+			yield(zero, m.err)
+			return
+		}
+		if batchSize <= 0 {
+			batchSize = 1000
+		}
+		cols, err := m.keysetColumns(columns)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		var last []any
+		for {
+			if err = ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+			items, err := m.keysetFetch(ctx, cols, batchSize, last, false, where, args)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if len(items) < batchSize {
+				return
+			}
+			last, err = m.keysetRawValues(cols, items[len(items)-1])
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xdb_test
+
+import (
+	"testing"
+
+	"github.com/xanygo/anygo/store/xdb"
+	"github.com/xanygo/anygo/store/xdb/xtdr"
+	"github.com/xanygo/anygo/xt"
+)
+
+type keysetUser struct {
+	ID    int64  `db:"id,pk"`
+	Name  string `db:"name"`
+	Score int    `db:"score"`
+}
+
+func (keysetUser) TableName() string {
+	return "user"
+}
+
+func TestModel_ListKeyset(t *testing.T) {
+	client := xdb.NewClient("sqlite3", "test", xtdr.MustOpen())
+	defer xtdr.Reset()
+	cols := []string{"id", "name", "score"}
+	m := xdb.NewMode[keysetUser](client)
+
+	t.Run("first page", func(t *testing.T) {
+		xtdr.Reset()
+		xtdr.ExpectQuery("wc:*", cols, [][]any{{1, "a", 10}, {2, "b", 20}, {3, "c", 30}})
+		page, err := m.ListKeyset(t.Context(), xdb.Keyset{Size: 2}, "score > ?", 1)
+		xt.NoError(t, err)
+		xt.Equal(t, page.Items, []keysetUser{{ID: 1, Name: "a", Score: 10}, {ID: 2, Name: "b", Score: 20}})
+		xt.True(t, page.HasNext())
+		xt.False(t, page.HasPrev())
+		want := `SELECT "id","name","score" FROM "user"  where (score > ?) ORDER BY "id" ASC LIMIT 3`
+		xt.Equal(t, xtdr.LastQueries(), []string{want})
+
+		xtdr.Reset()
+		xtdr.ExpectQuery("wc:*", cols, [][]any{{3, "c", 30}})
+		page2, err := m.ListKeyset(t.Context(), xdb.Keyset{Size: 2, Cursor: page.Next}, "score > ?", 1)
+		xt.NoError(t, err)
+		xt.Equal(t, page2.Items, []keysetUser{{ID: 3, Name: "c", Score: 30}})
+		xt.False(t, page2.HasNext())
+		xt.True(t, page2.HasPrev())
+		want = `SELECT "id","name","score" FROM "user"  where (score > ?) AND "id" > ? ORDER BY "id" ASC LIMIT 3`
+		xt.Equal(t, xtdr.LastQueries(), []string{want})
+
+		xtdr.Reset()
+		// 向前翻页，查询结果为倒序
+		xtdr.ExpectQuery("wc:*", cols, [][]any{{2, "b", 20}, {1, "a", 10}})
+		page3, err := m.ListKeyset(t.Context(), xdb.Keyset{Size: 2, Cursor: page2.Prev}, "score > ?", 1)
+		xt.NoError(t, err)
+		xt.Equal(t, page3.Items, []keysetUser{{ID: 1, Name: "a", Score: 10}, {ID: 2, Name: "b", Score: 20}})
+		xt.True(t, page3.HasNext())
+		xt.False(t, page3.HasPrev())
+		want = `SELECT "id","name","score" FROM "user"  where (score > ?) AND "id" < ? ORDER BY "id" DESC LIMIT 3`
+		xt.Equal(t, xtdr.LastQueries(), []string{want})
+	})
+
+	t.Run("multi columns", func(t *testing.T) {
+		xtdr.Reset()
+		xtdr.ExpectQuery("wc:*", cols, [][]any{{1, "a", 30}, {2, "b", 20}})
+		ks := xdb.Keyset{Size: 1, Columns: []xdb.KeysetColumn{{Name: "score", Desc: true}}}
+		page, err := m.ListKeyset(t.Context(), ks, "")
+		xt.NoError(t, err)
+		xt.Equal(t, page.Items, []keysetUser{{ID: 1, Name: "a", Score: 30}})
+
+		xtdr.Reset()
+		xtdr.ExpectQuery("wc:*", cols, [][]any{{2, "b", 20}})
+		ks.Cursor = page.Next
+		_, err = m.ListKeyset(t.Context(), ks, "")
+		xt.NoError(t, err)
+		want := `SELECT "id","name","score" FROM "user"  where ("score","id") < (?,?) ORDER BY "score" DESC, "id" DESC LIMIT 2`
+		xt.Equal(t, xtdr.LastQueries(), []string{want})
+
+		ks.Columns = []xdb.KeysetColumn{{Name: "score", Desc: true}, {Name: "id"}}
+		_, err = m.ListKeyset(t.Context(), ks, "")
+		xt.ErrorIs(t, err, xdb.ErrInvalidCursor)
+	})
+
+	t.Run("sqlserver", func(t *testing.T) {
+		xtdr.Reset()
+		ms := xdb.NewMode[keysetUser](xdb.NewClient("sqlserver", "test", xtdr.MustOpen()))
+		xtdr.ExpectQuery("wc:*", cols, [][]any{{1, "a", 30}, {2, "b", 20}})
+		ks := xdb.Keyset{Size: 1, Columns: []xdb.KeysetColumn{{Name: "score", Desc: true}}}
+		page, err := ms.ListKeyset(t.Context(), ks, "")
+		xt.NoError(t, err)
+
+		xtdr.Reset()
+		xtdr.ExpectQuery("wc:*", cols, nil)
+		ks.Cursor = page.Next
+		page, err = ms.ListKeyset(t.Context(), ks, "")
+		xt.NoError(t, err)
+		xt.Empty(t, page.Items)
+		xt.False(t, page.HasNext())
+		xt.True(t, page.HasPrev())
+		want := `SELECT [id],[name],[score] FROM [user]  where (([score] < @p1) OR ([score] = @p2 AND [id] < @p3)) ` +
+			`ORDER BY [score] DESC, [id] DESC OFFSET 0 ROWS FETCH NEXT 2 ROWS ONLY`
+		xt.Equal(t, xtdr.LastQueries(), []string{want})
+	})
+}
+
+func TestModel_Stream(t *testing.T) {
+	client := xdb.NewClient("sqlite3", "test", xtdr.MustOpen())
+	defer xtdr.Reset()
+	cols := []string{"id", "name", "score"}
+	m := xdb.NewMode[keysetUser](client)
+
+	xtdr.ExpectQuery("wc:*", cols, [][]any{{1, "a", 10}, {2, "b", 20}})
+	xtdr.ExpectQuery("wc:*", cols, [][]any{{3, "c", 30}})
+	var ids []int64
+	for item, err := range m.Stream(t.Context(), nil, 2, "") {
+		xt.NoError(t, err)
+		ids = append(ids, item.ID)
+	}
+	xt.Equal(t, ids, []int64{1, 2, 3})
+	want := []string{
+		`SELECT "id","name","score" FROM "user" ORDER BY "id" ASC LIMIT 2`,
+		`SELECT "id","name","score" FROM "user"  where "id" > ? ORDER BY "id" ASC LIMIT 2`,
+	}
+	xt.Equal(t, xtdr.LastQueries(), want)
+}
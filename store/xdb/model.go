@@ -50,6 +50,10 @@ type Model[T any] struct {
 
 	table         string
 	limit, offset int
+	batchSize     int // 批量写入时每个批次的最大条数
+
+	hooks    []ChangeHook   // 数据变更回调
+	pkValues map[string]any // UpdateByPK、DeleteByPK 时的主键值，用于数据变更回调
 
 	upsertFields       []string // insert, update 的字段列表
 	upsertIgnoreFields []string // insert, update 忽略的字段列表
@@ -81,7 +85,7 @@ func (m *Model[T]) Client() HasDriver {
 
 // Reset 重置 limit、offset、upsertFields、upsertIgnore、selectFields、selectIgnore 等属性
 //
-// Table 属性会保留
+// Table、BatchSize、OnChange 属性会保留
 func (m *Model[T]) Reset() *Model[T] {
 	m.limit = 0
 	m.offset = 0
@@ -102,6 +106,9 @@ func (m *Model[T]) CloneBase() *Model[T] {
 		pk:      slices.Clone(m.pk),
 		schema:  m.schema,
 		err:     m.err,
+
+		batchSize: m.batchSize,
+		hooks:     slices.Clone(m.hooks),
 	}
 }
 
@@ -114,8 +121,10 @@ func (m *Model[T]) Clone() *Model[T] {
 		schema:  m.schema,
 		err:     m.err,
 
-		limit:  m.limit,
-		offset: m.offset,
+		limit:     m.limit,
+		offset:    m.offset,
+		batchSize: m.batchSize,
+		hooks:     slices.Clone(m.hooks),
 
 		upsertFields:       slices.Clone(m.upsertFields),
 		upsertIgnoreFields: slices.Clone(m.upsertIgnoreFields),
@@ -199,6 +208,23 @@ func (m *Model[T]) Offset(num int) *Model[T] {
 	return m
 }
 
+// SetBatchSize 设置 InsertBatch、Upsert 等批量写入时，每个批次（每条 SQL 语句）的最大条数。
+//
+// 默认为 0，即只受方言的绑定参数个数限制（如 SQLite 999、SQL Server 2100）,超出时会自动拆分为多条语句执行
+func (m *Model[T]) SetBatchSize(size int) *Model[T] {
+	m.batchSize = size
+	return m
+}
+
+// chunkSize 批量写入时，每条语句最多写入的条数
+func (m *Model[T]) chunkSize(columns int) int {
+	size := maxRowsPerStatement(m.dialect, columns)
+	if m.batchSize > 0 {
+		size = min(size, m.batchSize)
+	}
+	return size
+}
+
 func (m *Model[T]) getEncoder(action encoder.Action) encoder.Encoder[T] {
 	return encoder.Encoder[T]{
 		Schema:       m.schema,
@@ -240,8 +266,10 @@ func (m *Model[T]) Insert(ctx context.Context, v T) error {
 	if !ok {
 		return fmt.Errorf("client (%T) is not Execer", m.client)
 	}
-	_, err = Exec(ctx, db, sqlStr, args...)
-	return err
+	if _, err = Exec(ctx, db, sqlStr, args...); err != nil {
+		return err
+	}
+	return m.emitChange(ctx, Change{Action: ChangeInsert, Values: kv, RowsAffected: 1})
 }
 
 // QuoteIdentifier 将标识符转义
@@ -277,6 +305,17 @@ func (m *Model[T]) InsertReturningID(ctx context.Context, v T) (int64, error) {
 		strings.Join(qcols, ", "),
 		m.dialect.PlaceholderList(len(kv), 1),
 	)
+	id, err := m.insertReturningID(ctx, sqlStr, args)
+	if err != nil {
+		return 0, err
+	}
+	if id > 0 && len(m.pk) == 1 && len(m.hooks) > 0 {
+		kv[m.pk[0].Name] = id
+	}
+	return id, m.emitChange(ctx, Change{Action: ChangeInsert, Values: kv, RowsAffected: 1})
+}
+
+func (m *Model[T]) insertReturningID(ctx context.Context, sqlStr string, args []any) (int64, error) {
 	sli := m.dialect.SupportLastInsertId()
 	if !sli && m.dialect.SupportReturning() {
 		rd, ok := m.dialect.(dbtype.ReturningDialect)
@@ -311,6 +350,10 @@ func (m *Model[T]) execReturning(ctx context.Context, client HasDriver, sql stri
 	return id, err
 }
 
+// InsertBatch 批量写入数据，返回写入的条数。
+//
+// 当数据较多时，会依据 SetBatchSize 和方言的绑定参数个数限制，拆分为多条语句依次执行。
+// 多条语句之间不是原子的，若需要，请在事务中执行
 func (m *Model[T]) InsertBatch(ctx context.Context, vs ...T) (int64, error) {
 	if m.err != nil {
 		return 0, m.err
@@ -318,49 +361,64 @@ func (m *Model[T]) InsertBatch(ctx context.Context, vs ...T) (int64, error) {
 	if len(vs) == 0 {
 		return 0, errors.New("no values")
 	}
-	values, err := m.getEncoder(encoder.ActionInsert).EncodeBatch(vs...)
+	cols, rows, err := m.encodeRows(vs)
 	if err != nil {
 		return 0, err
 	}
-	cols := xmap.Keys(values[0])
-	if len(cols) == 0 {
-		return 0, errors.New("no columns")
+	size := m.chunkSize(len(cols))
+	if size < 1 {
+		return 0, fmt.Errorf("too many columns (%d) for dialect %q", len(cols), m.dialect.Name())
 	}
-
-	qCols := xslice.MapFunc(cols, m.dialect.QuoteIdentifier)
-
-	valuePlaceHolders := make([]string, len(values))
-	for i := range len(values) {
-		valuePlaceHolders[i] = "(" + m.dialect.PlaceholderList(len(cols), i*len(cols)+1) + ")"
+	opt := BulkOption{
+		BatchSize: size,
+		Loader:    ValuesLoader{},
 	}
-
-	sqlStr := fmt.Sprintf(
-		"INSERT INTO %s (%s) VALUES %s",
-		m.dialect.QuoteIdentifier(m.table),
-		strings.Join(qCols, ","),
-		strings.Join(valuePlaceHolders, ", "),
-	)
-
-	db, ok := m.client.(Execer)
-	if !ok {
-		return 0, fmt.Errorf("client (%T) is not Execer", m.client)
+	num, err := bulkLoad(ctx, m.client, opt, m.table, cols, rows)
+	if err != nil {
+		return num, err
 	}
+	return num, m.emitRows(ctx, ChangeInsert, cols, rows)
+}
 
-	vals := make([]any, 0, len(values)*len(cols))
-	for _, item := range values {
-		for _, col := range cols {
-			vals = append(vals, item[col])
+// encodeRows 将数据编码为字段列表和对应的值列表
+func (m *Model[T]) encodeRows(vs []T) ([]string, [][]any, error) {
+	values, err := m.getEncoder(encoder.ActionInsert).EncodeBatch(vs...)
+	if err != nil {
+		return nil, nil, err
+	}
+	cols := xmap.Keys(values[0])
+	if len(cols) == 0 {
+		return nil, nil, errors.New("no columns")
+	}
+	rows := make([][]any, len(values))
+	for i, item := range values {
+		row := make([]any, len(cols))
+		for j, col := range cols {
+			row[j] = item[col]
 		}
+		rows[i] = row
 	}
+	return cols, rows, nil
+}
 
-	ret, err := Exec(ctx, db, sqlStr, vals...)
+// BulkInsert 大批量写入数据，返回写入的条数。
+//
+// 和 InsertBatch 相比，支持使用驱动原生的批量导入能力（见 RegisterCopyFrom）、进度回调以及每个批次独立事务
+func (m *Model[T]) BulkInsert(ctx context.Context, opt BulkOption, vs ...T) (int64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	if len(vs) == 0 {
+		return 0, errors.New("no values")
+	}
+	cols, rows, err := m.encodeRows(vs)
 	if err != nil {
 		return 0, err
 	}
-	return ret.RowsAffected()
+	return bulkLoad(ctx, m.client, opt, m.table, cols, rows)
 }
 
-// Upsert 批量 insert or update
+// Upsert 批量 insert or update，数据较多时，和 InsertBatch 一样会拆分为多条语句依次执行
 //
 // 输入参数：
 //
@@ -400,15 +458,31 @@ func (m *Model[T]) Upsert(ctx context.Context, conflictCols []string, updateCols
 	if miss, ok := xslice.AllContains(cols, updateCols); !ok {
 		return 0, fmt.Errorf("invalid updateCols: %q not in %q", miss, cols)
 	}
-	args := make([]any, 0, len(values)*len(cols))
-	for _, item := range kvSlice {
-		for _, col := range cols {
-			args = append(args, item[col])
+	size := m.chunkSize(len(cols))
+	if size < 1 {
+		return 0, fmt.Errorf("too many columns (%d) for dialect %q", len(cols), m.dialect.Name())
+	}
+	var num int64
+	for chunk := range slices.Chunk(kvSlice, size) {
+		args := make([]any, 0, len(chunk)*len(cols))
+		for _, item := range chunk {
+			for _, col := range cols {
+				args = append(args, item[col])
+			}
+		}
+		sqlStr := dup.UpsertSQL(m.table, len(chunk), cols, conflictCols, updateCols, nil)
+		n, err := RowsAffected(Exec(ctx, db, sqlStr, args...))
+		num += n
+		if err != nil {
+			return num, err
 		}
 	}
-	sqlStr := dup.UpsertSQL(m.table, len(values), cols, conflictCols, updateCols, nil)
-	ret, err := Exec(ctx, db, sqlStr, args...)
-	return RowsAffected(ret, err)
+	for _, kv := range kvSlice {
+		if err = m.emitChange(ctx, Change{Action: ChangeUpsert, Values: kv, RowsAffected: 1}); err != nil {
+			return num, err
+		}
+	}
+	return num, nil
 }
 
 // UpsertByGroup 根据预定义的字段分组执行 Upsert
@@ -475,6 +549,7 @@ func (m *Model[T]) doUpdateMap(ctx context.Context, kv map[string]any, where str
 	if len(assigns) == 0 {
 		return 0, errors.New("no update values")
 	}
+	change := Change{Action: ChangeUpdate, Values: kv, Where: where, Args: args}
 	var err error
 	where, args, err = m.buildWhere(len(assigns), where, args)
 	if err != nil {
@@ -501,7 +576,11 @@ func (m *Model[T]) doUpdateMap(ctx context.Context, kv map[string]any, where str
 	if err != nil {
 		return 0, err
 	}
-	return ret.RowsAffected()
+	change.RowsAffected, err = ret.RowsAffected()
+	if err != nil || change.RowsAffected == 0 {
+		return change.RowsAffected, err
+	}
+	return change.RowsAffected, m.emitChange(ctx, change)
 }
 
 // UpdateByPK 使用主键更新数据
@@ -522,6 +601,7 @@ func (m *Model[T]) UpdateByPK(ctx context.Context, v T) (int64, error) {
 
 	m1 := m.Clone()
 	m1.AppendUpsertIgnore(xmap.Keys(pkData)...)
+	m1.pkValues = pkData
 	return m1.doUpdate(ctx, v, where, args...)
 }
 
@@ -646,6 +726,7 @@ func (m *Model[T]) Delete(ctx context.Context, where string, args ...any) (int64
 	if m.err != nil {
 		return 0, m.err
 	}
+	change := Change{Action: ChangeDelete, Where: where, Args: args}
 	var err error
 	where, args, err = m.buildWhere(0, where, args)
 	if err != nil {
@@ -667,7 +748,11 @@ func (m *Model[T]) Delete(ctx context.Context, where string, args ...any) (int64
 	if err != nil {
 		return 0, err
 	}
-	return ret.RowsAffected()
+	change.RowsAffected, err = ret.RowsAffected()
+	if err != nil || change.RowsAffected == 0 {
+		return change.RowsAffected, err
+	}
+	return change.RowsAffected, m.emitChange(ctx, change)
 }
 
 // DeleteByPK 使用主键删除数据
@@ -677,11 +762,17 @@ func (m *Model[T]) DeleteByPK(ctx context.Context, v T) (int64, error) {
 	if m.err != nil {
 		return 0, m.err
 	}
-	where, args, err := m.pkWhereArgs(v, encoder.ActionSelect)
+	pkData, err := m.getEncoder(encoder.ActionSelect).PKNameAndValues(v)
+	if err != nil {
+		return 0, err
+	}
+	where, args, err := m.mapWhere(pkData)
 	if err != nil {
 		return 0, err
 	}
-	return m.Delete(ctx, where, args...)
+	m1 := m.Clone()
+	m1.pkValues = pkData
+	return m1.Delete(ctx, where, args...)
 }
 
 func (m *Model[T]) mapWhere(data map[string]any) (string, []any, error) {
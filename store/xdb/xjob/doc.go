@@ -0,0 +1,10 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+// Package xjob 基于数据库（xdb）的分布式定时任务调度
+//
+// 任务的定义和租约存储在数据库表中，多个副本同时运行时，
+// 每一次任务运行只会有一个副本通过条件更新（UPDATE ... WHERE）抢到租约并执行，
+// 支持 MySQL、MariaDB、Postgres、SQLite、SQL Server。
+package xjob
@@ -0,0 +1,53 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xjob
+
+import (
+	"time"
+)
+
+// 默认的表名
+const (
+	DefaultJobTable = "xjob"
+	DefaultRunTable = "xjob_run"
+)
+
+// 任务运行结果状态
+const (
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+)
+
+// JobRecord 任务在数据库中的定义和租约
+//
+// 时间字段均以毫秒时间戳存储，以便在各种数据库中精确比较
+type JobRecord struct {
+	Name       string    `db:"name,pk,size=128"`
+	Spec       string    `db:"spec,size=255"`                  // 运行计划，Schedule.String() 的值
+	NextRun    time.Time `db:"next_run,codec=milliseconds"`    // 下一次运行时间
+	LeaseOwner string    `db:"lease_owner,size=128"`           // 当前持有租约的调度器
+	LeaseUntil time.Time `db:"lease_until,codec=milliseconds"` // 租约过期时间，早于当前时间表示没有运行中的实例
+	LastRun    time.Time `db:"last_run,codec=milliseconds"`    // 最近一次运行的开始时间
+	LastStatus string    `db:"last_status,size=32"`            // 最近一次运行的状态
+	LastError  string    `db:"last_error,size=1024"`           // 最近一次运行的错误信息
+	Updated    time.Time `db:"updated,codec=milliseconds"`     // 最后更新时间
+}
+
+// RunRecord 任务的一次运行记录
+type RunRecord struct {
+	ID       int64     `db:"id,pk,auto_inc"`
+	Job      string    `db:"job,size=128,index=idx_job_start[1]"`
+	Owner    string    `db:"owner,size=128"`
+	Start    time.Time `db:"start_time,codec=milliseconds,index=idx_job_start[2]"`
+	End      time.Time `db:"end_time,codec=milliseconds"`
+	Attempts int       `db:"attempts"` // 尝试次数，大于 1 表示有重试
+	Status   string    `db:"status,size=32"`
+	Error    string    `db:"error,size=1024"`
+}
+
+// Duration 运行时长
+func (r RunRecord) Duration() time.Duration {
+	return r.End.Sub(r.Start)
+}
@@ -0,0 +1,282 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xjob
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 任务的运行计划
+type Schedule interface {
+	// Next 返回晚于 t 的下一次运行时间，若没有下一次，返回零值
+	Next(t time.Time) time.Time
+
+	// String 计划的文本表示，会存储到数据库中
+	String() string
+}
+
+// Every 按照固定的时间间隔运行，最小间隔为 1 秒
+func Every(d time.Duration) Schedule {
+	return every(max(d, time.Second))
+}
+
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+func (e every) String() string {
+	return "@every " + time.Duration(e).String()
+}
+
+// MustParseCron 解析 cron 表达式，若解析失败会 panic
+func MustParseCron(spec string) Schedule {
+	s, err := ParseCron(spec)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// ParseCron 解析 cron 表达式
+//
+// 支持以下格式：
+//  1. 5 个字段：分 时 日 月 周，如 "*/5 * * * *"
+//  2. 6 个字段：秒 分 时 日 月 周，如 "0 30 8 * * 1-5"
+//  3. 预定义的：@yearly、@annually、@monthly、@weekly、@daily、@midnight、@hourly
+//  4. 固定间隔：@every {duration}，如 "@every 1m30s"
+//
+// 每个字段支持 *、?、数字、范围（1-5）、步长（*/5、10-30/5）和列表（1,3,5），
+// 月和周支持英文缩写，如 JAN、MON。周的取值为 0-7，0 和 7 都表示周日。
+// 当日和周都不是 * 时，满足任意一个即可。
+func ParseCron(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron spec %q: %w", spec, err)
+		}
+		return Every(d), nil
+	}
+	expr := spec
+	if strings.HasPrefix(expr, "@") {
+		var ok bool
+		expr, ok = cronDescriptors[strings.ToLower(expr)]
+		if !ok {
+			return nil, fmt.Errorf("invalid cron spec %q: unknown descriptor", spec)
+		}
+	}
+	fields := strings.Fields(expr)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("invalid cron spec %q: expect 5 or 6 fields, got %d", spec, len(fields))
+	}
+	cs := &cronSchedule{spec: spec}
+	var err error
+	for i, b := range cronBounds {
+		var mask uint64
+		if mask, err = b.parse(fields[i]); err != nil {
+			return nil, fmt.Errorf("invalid cron spec %q: %w", spec, err)
+		}
+		cs.fields[i] = mask
+	}
+	// 周日可以是 0 或者 7
+	if cs.fields[5]&(1<<7) != 0 {
+		cs.fields[5] |= 1
+	}
+	cs.domStar = isStar(fields[3])
+	cs.dowStar = isStar(fields[5])
+	return cs, nil
+}
+
+func isStar(field string) bool {
+	return field == "*" || field == "?"
+}
+
+type cronBound struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var cronBounds = [6]cronBound{
+	{name: "second", min: 0, max: 59},
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}},
+	{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}},
+}
+
+func (b cronBound) parse(field string) (uint64, error) {
+	var mask uint64
+	for _, part := range strings.Split(field, ",") {
+		m, err := b.parsePart(part)
+		if err != nil {
+			return 0, fmt.Errorf("%s %q: %w", b.name, field, err)
+		}
+		mask |= m
+	}
+	return mask, nil
+}
+
+func (b cronBound) parsePart(part string) (uint64, error) {
+	rangePart, stepPart, hasStep := strings.Cut(part, "/")
+	step := 1
+	if hasStep {
+		var err error
+		if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid step %q", stepPart)
+		}
+	}
+	start, end := b.min, b.max
+	if !isStar(rangePart) {
+		lo, hi, isRange := strings.Cut(rangePart, "-")
+		var err error
+		if start, err = b.value(lo); err != nil {
+			return 0, err
+		}
+		end = start
+		if isRange {
+			if end, err = b.value(hi); err != nil {
+				return 0, err
+			}
+		} else if hasStep {
+			end = b.max
+		}
+		if start > end {
+			return 0, fmt.Errorf("invalid range %q", rangePart)
+		}
+	}
+	var mask uint64
+	for i := start; i <= end; i += step {
+		mask |= 1 << uint(i)
+	}
+	return mask, nil
+}
+
+func (b cronBound) value(str string) (int, error) {
+	if num, ok := b.names[strings.ToLower(str)]; ok {
+		return num, nil
+	}
+	num, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", str)
+	}
+	if num < b.min || num > b.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", num, b.min, b.max)
+	}
+	return num, nil
+}
+
+// cronSchedule 解析后的 cron 表达式，fields 依次为：秒、分、时、日、月、周
+type cronSchedule struct {
+	spec    string
+	fields  [6]uint64
+	domStar bool
+	dowStar bool
+}
+
+func (c *cronSchedule) String() string {
+	return c.spec
+}
+
+func (c *cronSchedule) has(idx int, value int) bool {
+	return c.fields[idx]&(1<<uint(value)) != 0
+}
+
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	dom := c.has(3, t.Day())
+	dow := c.has(5, int(t.Weekday()))
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Next 依次从月、日、时、分、秒查找满足条件的时间，最多向后查找 5 年
+func (c *cronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Second).Add(time.Second)
+	yearLimit := t.Year() + 5
+	added := false
+
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+	for !c.has(4, int(t.Month())) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		}
+		t = t.AddDate(0, 1, 0)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+	for !c.dayMatches(t) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		}
+		t = t.AddDate(0, 0, 1)
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+	for !c.has(2, t.Hour()) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+		}
+		t = t.Add(time.Hour)
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+	for !c.has(1, t.Minute()) {
+		if !added {
+			added = true
+			t = t.Truncate(time.Minute)
+		}
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+	for !c.has(0, t.Second()) {
+		if !added {
+			added = true
+		}
+		t = t.Add(time.Second)
+		if t.Second() == 0 {
+			goto wrap
+		}
+	}
+	return t
+}
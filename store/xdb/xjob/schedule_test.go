@@ -0,0 +1,49 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xjob_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/store/xdb/xjob"
+	"github.com/xanygo/anygo/xt"
+)
+
+func TestParseCron(t *testing.T) {
+	base := time.Date(2026, 10, 18, 8, 30, 15, 0, time.UTC) // 周日
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{spec: "* * * * *", want: time.Date(2026, 10, 18, 8, 31, 0, 0, time.UTC)},
+		{spec: "*/15 * * * *", want: time.Date(2026, 10, 18, 8, 45, 0, 0, time.UTC)},
+		{spec: "0 3 * * *", want: time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)},
+		{spec: "30 * * * * *", want: time.Date(2026, 10, 18, 8, 30, 30, 0, time.UTC)},
+		{spec: "0 9 * * mon-fri", want: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		{spec: "0 0 1 jan *", want: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 31 * *", want: time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 1 * 7", want: time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 29 2 *", want: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{spec: "@hourly", want: time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)},
+		{spec: "@daily", want: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{spec: "@every 1m30s", want: base.Add(90 * time.Second)},
+	}
+	for _, tt := range cases {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := xjob.ParseCron(tt.spec)
+			xt.NoError(t, err)
+			xt.Equal(t, s.Next(base), tt.want)
+			xt.Equal(t, s.String(), tt.spec)
+		})
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "@minutely"} {
+		_, err := xjob.ParseCron(spec)
+		xt.Error(t, err)
+	}
+
+	xt.True(t, xjob.MustParseCron("0 0 30 2 *").Next(base).IsZero())
+}
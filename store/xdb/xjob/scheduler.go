@@ -0,0 +1,478 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xjob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xstr"
+	"github.com/xanygo/anygo/safely"
+	"github.com/xanygo/anygo/store/xdb"
+	"github.com/xanygo/anygo/xlog"
+	"github.com/xanygo/anygo/xnet/xpolicy"
+	"github.com/xanygo/anygo/xpp"
+)
+
+// Job 一个定时任务
+type Job struct {
+	Name     string                          // 必填，任务名称，全局唯一
+	Schedule Schedule                        // 必填，运行计划，如 Every(time.Minute)、MustParseCron("0 3 * * *")
+	Do       func(ctx context.Context) error // 必填，业务逻辑
+
+	Timeout time.Duration // 可选，单次运行的超时时间，默认为 5 分钟
+
+	// MaxAttempts 可选，单次运行最多尝试的次数，默认为 1，即失败后不重试
+	MaxAttempts int
+
+	// Retry 可选，失败后是否重试以及重试间隔的策略，当 MaxAttempts > 1 时生效，
+	// 默认为 xpolicy.AlwaysRetry()
+	Retry *xpolicy.Retry
+}
+
+func (j *Job) check() error {
+	if j.Name == "" {
+		return errors.New("empty job name")
+	}
+	if j.Schedule == nil {
+		return fmt.Errorf("job %q: nil Schedule", j.Name)
+	}
+	if j.Do == nil {
+		return fmt.Errorf("job %q: nil Do", j.Name)
+	}
+	return nil
+}
+
+func (j *Job) getTimeout() time.Duration {
+	if j.Timeout > 0 {
+		return j.Timeout
+	}
+	return 5 * time.Minute
+}
+
+func (j *Job) getRetry() *xpolicy.Retry {
+	if j.Retry != nil {
+		return j.Retry
+	}
+	return xpolicy.AlwaysRetry()
+}
+
+// leaseMargin 租约时长 = 任务超时时间 + leaseMargin，避免任务还未超时租约就已过期
+const leaseMargin = 30 * time.Second
+
+var _ xpp.Worker = (*Scheduler)(nil)
+
+// Scheduler 基于数据库的分布式任务调度器
+//
+// 多个副本使用同一个数据库表时，每一次任务运行只会由一个副本执行：
+// 调度器定期查询到期的任务，并使用条件更新抢占租约，
+// 即 UPDATE ... SET lease_owner=?,next_run=? WHERE name=? AND next_run=? AND lease_until=?，
+// 只有更新成功（影响行数为 1）的副本会执行此次任务。
+//
+// 使用前需要创建表，可以调用 Migrate 方法自动创建。
+type Scheduler struct {
+	Client xdb.DBCore // 必填，数据库
+
+	Owner    string        // 可选，调度器的唯一标识，默认为 "{hostname}-{pid}-{随机字符}"
+	JobTable string        // 可选，任务表名，默认为 DefaultJobTable
+	RunTable string        // 可选，运行记录表名，默认为 DefaultRunTable
+	Poll     time.Duration // 可选，查询到期任务的周期，默认为 1 秒
+
+	// DisableHistory 不记录运行记录（RunRecord）
+	DisableHistory bool
+
+	mux     sync.Mutex
+	jobs    map[string]*Job
+	owner   string
+	worker  *xpp.CycleWorker
+	running sync.WaitGroup
+}
+
+func (s *Scheduler) Name() string {
+	return "xjob_Scheduler"
+}
+
+// Register 注册任务，需要在 Start 之前调用
+func (s *Scheduler) Register(jobs ...*Job) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.jobs == nil {
+		s.jobs = make(map[string]*Job, len(jobs))
+	}
+	for _, job := range jobs {
+		if err := job.check(); err != nil {
+			return err
+		}
+		if _, has := s.jobs[job.Name]; has {
+			return fmt.Errorf("job %q already registered", job.Name)
+		}
+		s.jobs[job.Name] = job
+	}
+	return nil
+}
+
+func (s *Scheduler) getJob(name string) *Job {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.jobs[name]
+}
+
+func (s *Scheduler) allJobs() []*Job {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	result := make([]*Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		result = append(result, job)
+	}
+	return result
+}
+
+// GetOwner 返回调度器的唯一标识
+func (s *Scheduler) GetOwner() string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.owner != "" {
+		return s.owner
+	}
+	if s.Owner != "" {
+		s.owner = s.Owner
+	} else {
+		host, _ := os.Hostname()
+		s.owner = fmt.Sprintf("%s-%d-%s", host, os.Getpid(), xstr.RandNChar(6))
+	}
+	return s.owner
+}
+
+func (s *Scheduler) jobModel() *xdb.Model[JobRecord] {
+	table := s.JobTable
+	if table == "" {
+		table = DefaultJobTable
+	}
+	return xdb.NewMode[JobRecord](s.Client).Table(table)
+}
+
+func (s *Scheduler) runModel() *xdb.Model[RunRecord] {
+	table := s.RunTable
+	if table == "" {
+		table = DefaultRunTable
+	}
+	return xdb.NewMode[RunRecord](s.Client).Table(table)
+}
+
+// Migrate 创建或者更新任务表和运行记录表
+func (s *Scheduler) Migrate(ctx context.Context) error {
+	table := s.JobTable
+	if table == "" {
+		table = DefaultJobTable
+	}
+	if err := xdb.MigrateWithTable(ctx, s.Client, JobRecord{}, table); err != nil {
+		return err
+	}
+	if s.DisableHistory {
+		return nil
+	}
+	table = s.RunTable
+	if table == "" {
+		table = DefaultRunTable
+	}
+	return xdb.MigrateWithTable(ctx, s.Client, RunRecord{}, table)
+}
+
+// Start 将已注册的任务同步到数据库，并启动后台调度
+func (s *Scheduler) Start(ctx context.Context) error {
+	if err := s.Sync(ctx); err != nil {
+		return err
+	}
+	poll := s.Poll
+	if poll <= 0 {
+		poll = time.Second
+	}
+	s.mux.Lock()
+	if s.worker == nil {
+		s.worker = &xpp.CycleWorker{
+			WorkerName: s.Name(),
+			Cycle:      poll,
+			Do: func(ctx context.Context) error {
+				_, err := s.poll(ctx, false)
+				if err != nil {
+					xlog.Warn(ctx, "xjob poll failed", xlog.ErrorAttr("error", err))
+				}
+				return err
+			},
+		}
+	}
+	worker := s.worker
+	s.mux.Unlock()
+	return worker.Start(ctx)
+}
+
+// Stop 停止后台调度，并等待运行中的任务结束，直到 ctx 超时
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mux.Lock()
+	worker := s.worker
+	s.mux.Unlock()
+	if worker != nil {
+		if err := worker.Stop(ctx); err != nil {
+			return err
+		}
+	}
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// Sync 将已注册的任务同步到数据库：不存在的任务会新建，运行计划有变化的会更新
+func (s *Scheduler) Sync(ctx context.Context) error {
+	var errs []error
+	for _, job := range s.allJobs() {
+		if err := s.syncJob(ctx, job); err != nil {
+			errs = append(errs, fmt.Errorf("sync job %q: %w", job.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Scheduler) syncJob(ctx context.Context, job *Job) error {
+	now := time.Now()
+	spec := job.Schedule.String()
+	old, found, err := s.jobModel().First(ctx, "name=?", job.Name)
+	if err != nil {
+		return err
+	}
+	if !found {
+		rec := JobRecord{
+			Name:       job.Name,
+			Spec:       spec,
+			NextRun:    job.Schedule.Next(now),
+			LeaseUntil: now,
+			Updated:    now,
+		}
+		err = s.jobModel().Insert(ctx, rec)
+		if err == nil {
+			return nil
+		}
+		// 可能其他副本已经写入了
+		_, found, err2 := s.jobModel().First(ctx, "name=?", job.Name)
+		if err2 != nil || !found {
+			return err
+		}
+		return nil
+	}
+	if old.Spec == spec {
+		return nil
+	}
+	rec := JobRecord{
+		Spec:    spec,
+		NextRun: job.Schedule.Next(now),
+		Updated: now,
+	}
+	_, err = s.jobModel().SetUpsertFields("spec", "next_run", "updated").Update(ctx, rec, "name=? AND spec=?", job.Name, old.Spec)
+	return err
+}
+
+// Trigger 让任务在下一次调度时立即运行
+func (s *Scheduler) Trigger(ctx context.Context, name string) error {
+	now := time.Now()
+	rec := JobRecord{
+		NextRun: now,
+		Updated: now,
+	}
+	num, err := s.jobModel().SetUpsertFields("next_run", "updated").Update(ctx, rec, "name=?", name)
+	if err != nil {
+		return err
+	}
+	if num == 0 {
+		return fmt.Errorf("job %q not found", name)
+	}
+	return nil
+}
+
+// RunPending 查询并运行所有已到期的任务，会等待任务运行结束，返回此次运行的任务数量
+//
+// 一般情况下不需要调用，Start 之后后台会定期调度
+func (s *Scheduler) RunPending(ctx context.Context) (int, error) {
+	return s.poll(ctx, true)
+}
+
+func (s *Scheduler) poll(ctx context.Context, wait bool) (int, error) {
+	now := time.Now()
+	nowMs := now.UnixMilli()
+	list, err := s.jobModel().List(ctx, "next_run<=? AND lease_until<=?", nowMs, nowMs)
+	if err != nil {
+		return 0, err
+	}
+	var wg sync.WaitGroup
+	var num int
+	var errs []error
+	for _, rec := range list {
+		job := s.getJob(rec.Name)
+		if job == nil {
+			// 其他副本注册的任务
+			continue
+		}
+		ok, err := s.acquire(ctx, job, rec, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("acquire job %q: %w", job.Name, err))
+			continue
+		}
+		if !ok {
+			continue
+		}
+		num++
+		s.running.Add(1)
+		wg.Add(1)
+		go func() {
+			defer s.running.Done()
+			defer wg.Done()
+			s.run(context.WithoutCancel(ctx), job)
+		}()
+	}
+	if wait {
+		wg.Wait()
+	}
+	return num, errors.Join(errs...)
+}
+
+// acquire 使用条件更新抢占租约，返回是否抢占成功
+func (s *Scheduler) acquire(ctx context.Context, job *Job, old JobRecord, now time.Time) (bool, error) {
+	next := job.Schedule.Next(now)
+	if next.IsZero() {
+		// 没有下一次运行时间了
+		return false, nil
+	}
+	rec := JobRecord{
+		NextRun:    next,
+		LeaseOwner: s.GetOwner(),
+		LeaseUntil: now.Add(job.getTimeout() + leaseMargin),
+		Updated:    now,
+	}
+	num, err := s.jobModel().
+		SetUpsertFields("next_run", "lease_owner", "lease_until", "updated").
+		Update(ctx, rec, "name=? AND next_run=? AND lease_until=?", job.Name, old.NextRun.UnixMilli(), old.LeaseUntil.UnixMilli())
+	return num == 1, err
+}
+
+// renew 延长租约
+func (s *Scheduler) renew(ctx context.Context, job *Job) error {
+	now := time.Now()
+	rec := JobRecord{
+		LeaseUntil: now.Add(job.getTimeout() + leaseMargin),
+		Updated:    now,
+	}
+	_, err := s.jobModel().SetUpsertFields("lease_until", "updated").Update(ctx, rec, "name=? AND lease_owner=?", job.Name, s.GetOwner())
+	return err
+}
+
+func (s *Scheduler) run(ctx context.Context, job *Job) {
+	start := time.Now()
+	attempts, err := s.execute(ctx, job)
+	end := time.Now()
+
+	status := StatusSuccess
+	if err != nil {
+		status = StatusFailed
+		xlog.Warn(ctx, "xjob run failed", xlog.String("job", job.Name), xlog.ErrorAttr("error", err))
+	}
+	errMsg := truncate(errString(err), 1024)
+
+	// 释放租约，同时记录运行结果
+	rec := JobRecord{
+		LeaseUntil: end,
+		LastRun:    start,
+		LastStatus: status,
+		LastError:  errMsg,
+		Updated:    end,
+	}
+	_, err1 := s.jobModel().SetUpsertFields("lease_owner", "lease_until", "last_run", "last_status", "last_error", "updated").
+		Update(ctx, rec, "name=? AND lease_owner=?", job.Name, s.GetOwner())
+	if err1 != nil {
+		xlog.Warn(ctx, "xjob release lease failed", xlog.String("job", job.Name), xlog.ErrorAttr("error", err1))
+	}
+
+	if s.DisableHistory {
+		return
+	}
+	history := RunRecord{
+		Job:      job.Name,
+		Owner:    s.GetOwner(),
+		Start:    start,
+		End:      end,
+		Attempts: attempts,
+		Status:   status,
+		Error:    errMsg,
+	}
+	if err2 := s.runModel().Insert(ctx, history); err2 != nil {
+		xlog.Warn(ctx, "xjob save history failed", xlog.String("job", job.Name), xlog.ErrorAttr("error", err2))
+	}
+}
+
+// execute 运行任务，失败时依据重试策略重试，返回尝试的次数
+func (s *Scheduler) execute(ctx context.Context, job *Job) (int, error) {
+	maxAttempts := max(job.MaxAttempts, 1)
+	retry := job.getRetry()
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if err := s.renew(ctx, job); err != nil {
+				return attempt, err
+			}
+		}
+		err := s.runOnce(ctx, job)
+		if err == nil || attempt+1 >= maxAttempts || !retry.IsRetryable(ctx, job, attempt, err) {
+			return attempt + 1, err
+		}
+		if backoff := retry.GetBackoff(attempt); backoff > 0 {
+			tm := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				tm.Stop()
+				return attempt + 1, errors.Join(err, context.Cause(ctx))
+			case <-tm.C:
+			}
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job *Job) error {
+	ctx, cancel := context.WithTimeout(ctx, job.getTimeout())
+	defer cancel()
+	return safely.RunCtx(ctx, job.Do)
+}
+
+// Jobs 查询数据库中所有的任务
+func (s *Scheduler) Jobs(ctx context.Context) ([]JobRecord, error) {
+	return s.jobModel().List(ctx, "")
+}
+
+// History 查询任务最近的运行记录，按照开始时间倒序
+func (s *Scheduler) History(ctx context.Context, name string, limit int) ([]RunRecord, error) {
+	return s.runModel().Limit(limit).List(ctx, "job=? ORDER BY start_time DESC", name)
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func truncate(str string, size int) string {
+	if len(str) <= size {
+		return str
+	}
+	return strings.ToValidUTF8(str[:size], "")
+}
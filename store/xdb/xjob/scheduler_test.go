@@ -0,0 +1,145 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xjob_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/store/xdb"
+	"github.com/xanygo/anygo/store/xdb/xjob"
+	"github.com/xanygo/anygo/store/xdb/xtdr"
+	"github.com/xanygo/anygo/xt"
+)
+
+var jobColumns = []string{"name", "spec", "next_run", "lease_owner", "lease_until", "last_run", "last_status", "last_error", "updated"}
+
+func dueJob(name string) [][]any {
+	past := time.Now().Add(-time.Minute).UnixMilli()
+	return [][]any{{name, "@every 1m0s", past, "", past, 0, "", "", past}}
+}
+
+func TestScheduler_RunPending(t *testing.T) {
+	newScheduler := func(m *xtdr.Mock, job *xjob.Job) *xjob.Scheduler {
+		s := &xjob.Scheduler{
+			Client: xdb.NewClient("sqlite3", "test", m.DB()),
+			Owner:  "node-1",
+		}
+		xt.NoError(t, s.Register(job))
+		return s
+	}
+
+	t.Run("acquired", func(t *testing.T) {
+		m := xtdr.NewMock().InOrder(true)
+		m.ExpectQuery(`re:(?i)^SELECT .+ FROM "xjob"\s+where next_run<=\? AND lease_until<=\?$`).
+			WillReturnRows(jobColumns, dueJob("clean"))
+		m.ExpectExec(`re:^UPDATE "xjob" SET .+ WHERE name=\? AND next_run=\? AND lease_until=\?$`).
+			WillReturnResult(xtdr.ResultOf(0, 1))
+		m.ExpectExec(`re:^UPDATE "xjob" SET .+ WHERE name=\? AND lease_owner=\?$`).
+			WillReturnResult(xtdr.ResultOf(0, 1))
+		m.ExpectExec("wc:INSERT INTO *xjob_run*").WillReturnResult(xtdr.ResultOf(1, 1))
+
+		var runs int
+		s := newScheduler(m, &xjob.Job{
+			Name:     "clean",
+			Schedule: xjob.Every(time.Minute),
+			Do: func(ctx context.Context) error {
+				runs++
+				return nil
+			},
+		})
+		num, err := s.RunPending(t.Context())
+		xt.NoError(t, err)
+		xt.Equal(t, num, 1)
+		xt.Equal(t, runs, 1)
+		xt.NoError(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("lease taken by others", func(t *testing.T) {
+		m := xtdr.NewMock().InOrder(true)
+		m.ExpectQuery("wc:SELECT *").WillReturnRows(jobColumns, dueJob("clean"))
+		m.ExpectExec("wc:UPDATE *").WillReturnResult(xtdr.ResultOf(0, 0))
+
+		s := newScheduler(m, &xjob.Job{
+			Name:     "clean",
+			Schedule: xjob.Every(time.Minute),
+			Do: func(ctx context.Context) error {
+				t.Fatal("should not run")
+				return nil
+			},
+		})
+		num, err := s.RunPending(t.Context())
+		xt.NoError(t, err)
+		xt.Equal(t, num, 0)
+		xt.NoError(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		m := xtdr.NewMock().InOrder(true)
+		m.ExpectQuery("wc:SELECT *").WillReturnRows(jobColumns, dueJob("clean"))
+		m.ExpectExec("wc:UPDATE *").WillReturnResult(xtdr.ResultOf(0, 1))
+		// 重试前续约
+		m.ExpectExec("wc:UPDATE * SET *lease_until*").WillReturnResult(xtdr.ResultOf(0, 1))
+		m.ExpectExec("wc:UPDATE *").WillReturnResult(xtdr.ResultOf(0, 1))
+		m.ExpectExec("wc:INSERT INTO *")
+
+		var runs int
+		s := newScheduler(m, &xjob.Job{
+			Name:        "clean",
+			Schedule:    xjob.Every(time.Minute),
+			MaxAttempts: 3,
+			Do: func(ctx context.Context) error {
+				runs++
+				if runs == 1 {
+					return errors.New("busy")
+				}
+				return nil
+			},
+		})
+		num, err := s.RunPending(t.Context())
+		xt.NoError(t, err)
+		xt.Equal(t, num, 1)
+		xt.Equal(t, runs, 2)
+		xt.NoError(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("other jobs", func(t *testing.T) {
+		m := xtdr.NewMock().InOrder(true)
+		m.ExpectQuery("wc:SELECT *").WillReturnRows(jobColumns, dueJob("other"))
+		s := newScheduler(m, &xjob.Job{
+			Name:     "clean",
+			Schedule: xjob.Every(time.Minute),
+			Do: func(ctx context.Context) error {
+				return nil
+			},
+		})
+		num, err := s.RunPending(t.Context())
+		xt.NoError(t, err)
+		xt.Equal(t, num, 0)
+	})
+}
+
+func TestScheduler_Sync(t *testing.T) {
+	m := xtdr.NewMock().InOrder(true)
+	m.ExpectQuery("wc:SELECT *").WillReturnRows(jobColumns, nil)
+	m.ExpectExec("wc:INSERT INTO *xjob*").WillReturnResult(xtdr.ResultOf(0, 1))
+	s := &xjob.Scheduler{
+		Client: xdb.NewClient("sqlite3", "test", m.DB()),
+	}
+	err := s.Register(&xjob.Job{
+		Name:     "clean",
+		Schedule: xjob.MustParseCron("0 3 * * *"),
+		Do: func(ctx context.Context) error {
+			return nil
+		},
+	})
+	xt.NoError(t, err)
+	xt.Error(t, s.Register(&xjob.Job{Name: "clean"}))
+	xt.NoError(t, s.Sync(t.Context()))
+	xt.NoError(t, m.ExpectationsWereMet())
+	xt.NotEmpty(t, s.GetOwner())
+}
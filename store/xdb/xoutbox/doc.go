@@ -0,0 +1,20 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+// Package xoutbox 事务发件箱（Transactional Outbox）
+//
+// 业务数据和事件在同一个数据库事务中写入，再由 Relay 从发件箱表中读取事件并投递到 Sink
+// （如 xbus.Broker、Redis Stream、HTTP Webhook），避免同时写数据库和消息队列时丢失消息。
+//
+// 投递语义为至少一次（at-least-once），同一个聚合键（Event.Key）的事件按写入顺序投递。
+//
+//	ob := &xoutbox.Outbox{}
+//	err := xdb.WithTx(ctx, tx, func(ctx context.Context, tx xdb.TxCore) error {
+//		m := xdb.NewMode[User](tx).OnChange(ob.ChangeHook(nil))
+//		return m.Insert(ctx, user)
+//	})
+//
+//	relay := &xoutbox.Relay{Client: client, Sink: &xoutbox.WebhookSink{URL: "http://example.com/events"}}
+//	relay.Start(ctx)
+package xoutbox
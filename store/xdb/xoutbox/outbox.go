@@ -0,0 +1,176 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xoutbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xmap"
+	"github.com/xanygo/anygo/store/xdb"
+)
+
+// DefaultTable 默认的发件箱表名
+const DefaultTable = "xoutbox"
+
+// 发件箱中消息的状态
+const (
+	StatusPending   = 0 // 待投递
+	StatusDelivered = 1 // 已投递
+	StatusDead      = 2 // 超过最大尝试次数，不再投递
+)
+
+// Event 一个待发布的事件
+type Event struct {
+	ID      int64             // 发件箱中的 ID，写入时不需要赋值
+	Topic   string            // 必填，事件主题，如 "user.insert"
+	Key     string            // 可选，聚合键，如 "user:1"，相同 Key 的事件会按写入顺序投递
+	Payload []byte            // 事件内容
+	Headers map[string]string // 可选，附加信息
+	Created time.Time         // 写入时间，写入时不需要赋值
+}
+
+// Message 发件箱表中的一条消息
+type Message struct {
+	ID          int64             `db:"id,pk,auto_inc"`
+	Topic       string            `db:"topic,size=255"`
+	Key         string            `db:"agg_key,size=255"`
+	Payload     []byte            `db:"payload"`
+	Headers     map[string]string `db:"headers,codec=json"`
+	Created     time.Time         `db:"created,codec=milliseconds"`
+	Status      int               `db:"status,index=idx_status_id[1]"`
+	Attempts    int               `db:"attempts"`                        // 已投递失败的次数
+	NextAttempt time.Time         `db:"next_attempt,codec=milliseconds"` // 失败后，下一次投递的时间
+	LastError   string            `db:"last_error,size=1024"`
+}
+
+// Event 转换为事件
+func (m Message) Event() Event {
+	return Event{
+		ID:      m.ID,
+		Topic:   m.Topic,
+		Key:     m.Key,
+		Payload: m.Payload,
+		Headers: m.Headers,
+		Created: m.Created,
+	}
+}
+
+// Outbox 发件箱，用于在业务数据的事务中写入事件
+type Outbox struct {
+	Table string // 可选，发件箱表名，默认为 DefaultTable
+}
+
+func (o *Outbox) getTable() string {
+	if o == nil || o.Table == "" {
+		return DefaultTable
+	}
+	return o.Table
+}
+
+// Migrate 创建或者更新发件箱表
+func (o *Outbox) Migrate(ctx context.Context, db xdb.DBCore) error {
+	return xdb.MigrateWithTable(ctx, db, Message{}, o.getTable())
+}
+
+// Append 写入事件，db 应为业务数据所在的事务（xdb.TxCore），以保证事件和业务数据同时提交或回滚
+func (o *Outbox) Append(ctx context.Context, db xdb.HasDriver, events ...Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	now := time.Now()
+	msgs := make([]Message, 0, len(events))
+	for _, e := range events {
+		if e.Topic == "" {
+			return errors.New("empty event topic")
+		}
+		msgs = append(msgs, Message{
+			Topic:       e.Topic,
+			Key:         e.Key,
+			Payload:     e.Payload,
+			Headers:     e.Headers,
+			Created:     now,
+			Status:      StatusPending,
+			NextAttempt: now,
+		})
+	}
+	m := xdb.NewMode[Message](db).Table(o.getTable())
+	if len(msgs) == 1 {
+		return m.Insert(ctx, msgs[0])
+	}
+	_, err := m.InsertBatch(ctx, msgs...)
+	return err
+}
+
+// ChangeMapper 将 Model 的数据变更转换为事件
+type ChangeMapper func(ctx context.Context, c xdb.Change) ([]Event, error)
+
+// ChangeHook 返回可用于 xdb.Model.OnChange 的回调：Model 写入数据后，在同一个 client（事务）中写入事件
+//
+// mapper: 可选，为 nil 时使用 DefaultChangeMapper
+func (o *Outbox) ChangeHook(mapper ChangeMapper) xdb.ChangeHook {
+	if mapper == nil {
+		mapper = DefaultChangeMapper
+	}
+	return func(ctx context.Context, db xdb.HasDriver, c xdb.Change) error {
+		events, err := mapper(ctx, c)
+		if err != nil {
+			return err
+		}
+		return o.Append(ctx, db, events...)
+	}
+}
+
+// ChangePayload DefaultChangeMapper 生成的事件内容
+type ChangePayload struct {
+	Table        string         `json:"table"`
+	Action       string         `json:"action"`
+	Values       map[string]any `json:"values,omitempty"`
+	Where        string         `json:"where,omitempty"`
+	Args         []any          `json:"args,omitempty"`
+	RowsAffected int64          `json:"rows_affected"`
+}
+
+// DefaultChangeMapper 默认的数据变更到事件的转换规则：
+//   - Topic 为 "{表名}.{变更类型}"，如 "user.insert"
+//   - Key 为 "{表名}:{主键值}"，如 "user:1"，当无法获取主键值时（如使用自定义条件 Update、Delete）为表名
+//   - Payload 为 ChangePayload 的 JSON
+func DefaultChangeMapper(ctx context.Context, c xdb.Change) ([]Event, error) {
+	payload, err := json.Marshal(ChangePayload{
+		Table:        c.Table,
+		Action:       c.Action,
+		Values:       c.Values,
+		Where:        c.Where,
+		Args:         c.Args,
+		RowsAffected: c.RowsAffected,
+	})
+	if err != nil {
+		return nil, err
+	}
+	e := Event{
+		Topic:   c.Table + "." + c.Action,
+		Key:     changeKey(c),
+		Payload: payload,
+	}
+	return []Event{e}, nil
+}
+
+func changeKey(c xdb.Change) string {
+	if len(c.PKValues) == 0 {
+		return c.Table
+	}
+	names := xmap.Keys(c.PKValues)
+	slices.Sort(names)
+	values := make([]string, len(names))
+	for i, name := range names {
+		values[i] = fmt.Sprint(c.PKValues[name])
+	}
+	return c.Table + ":" + strings.Join(values, ",")
+}
@@ -0,0 +1,97 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xoutbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/xanygo/anygo/store/xdb"
+	"github.com/xanygo/anygo/store/xdb/xoutbox"
+	"github.com/xanygo/anygo/store/xdb/xtdr"
+	"github.com/xanygo/anygo/xt"
+)
+
+type user struct {
+	ID   int64  `db:"id,pk"`
+	Name string `db:"name"`
+}
+
+func (u user) TableName() string {
+	return "user"
+}
+
+func TestDefaultChangeMapper(t *testing.T) {
+	events, err := xoutbox.DefaultChangeMapper(context.Background(), xdb.Change{
+		Table:        "user",
+		Action:       xdb.ChangeInsert,
+		PKValues:     map[string]any{"id": 1},
+		Values:       map[string]any{"id": 1, "name": "hello"},
+		RowsAffected: 1,
+	})
+	xt.NoError(t, err)
+	xt.Len(t, events, 1)
+	xt.Equal(t, events[0].Topic, "user.insert")
+	xt.Equal(t, events[0].Key, "user:1")
+	var payload xoutbox.ChangePayload
+	xt.NoError(t, json.Unmarshal(events[0].Payload, &payload))
+	xt.Equal(t, payload.Values["name"], "hello")
+
+	events, err = xoutbox.DefaultChangeMapper(context.Background(), xdb.Change{
+		Table:  "user",
+		Action: xdb.ChangeDelete,
+		Where:  "name=?",
+		Args:   []any{"hello"},
+	})
+	xt.NoError(t, err)
+	xt.Equal(t, events[0].Key, "user")
+}
+
+func TestOutbox_ChangeHook(t *testing.T) {
+	ob := &xoutbox.Outbox{}
+	t.Run("commit", func(t *testing.T) {
+		m := xtdr.NewMock().InOrder(true)
+		m.ExpectBegin()
+		m.ExpectExec(`wc:INSERT INTO "user" *`).WillReturnResult(xtdr.ResultOf(0, 1))
+		m.ExpectExec(`wc:INSERT INTO "xoutbox" *`).WillReturnResult(xtdr.ResultOf(1, 1))
+		m.ExpectExec(`wc:UPDATE "user" SET *`).WillReturnResult(xtdr.ResultOf(0, 1))
+		m.ExpectExec(`wc:INSERT INTO "xoutbox" *`).WillReturnResult(xtdr.ResultOf(2, 1))
+		m.ExpectCommit()
+		client := xdb.NewClient("sqlite3", "test", m.DB())
+
+		tx, err := client.BeginTx(t.Context(), nil)
+		xt.NoError(t, err)
+		err = xdb.WithTx(t.Context(), tx, func(ctx context.Context, tx xdb.TxCore) error {
+			um := xdb.NewMode[user](tx).OnChange(ob.ChangeHook(nil))
+			if err := um.Insert(ctx, user{ID: 1, Name: "hello"}); err != nil {
+				return err
+			}
+			_, err := um.UpdateByPK(ctx, user{ID: 1, Name: "world"})
+			return err
+		})
+		xt.NoError(t, err)
+		xt.NoError(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		m := xtdr.NewMock().InOrder(true)
+		m.ExpectBegin()
+		m.ExpectExec(`wc:INSERT INTO "user" *`).WillReturnResult(xtdr.ResultOf(0, 1))
+		m.ExpectExec(`wc:INSERT INTO "xoutbox" *`).WillReturnError(errors.New("disk full"))
+		m.ExpectRollback()
+		client := xdb.NewClient("sqlite3", "test", m.DB())
+
+		tx, err := client.BeginTx(t.Context(), nil)
+		xt.NoError(t, err)
+		err = xdb.WithTx(t.Context(), tx, func(ctx context.Context, tx xdb.TxCore) error {
+			um := xdb.NewMode[user](tx).OnChange(ob.ChangeHook(nil))
+			return um.Insert(ctx, user{ID: 1, Name: "hello"})
+		})
+		xt.Error(t, err)
+		xt.NoError(t, m.ExpectationsWereMet())
+	})
+}
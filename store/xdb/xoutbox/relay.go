@@ -0,0 +1,220 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xoutbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xanygo/anygo/store/xdb"
+	"github.com/xanygo/anygo/store/xdb/xjob"
+	"github.com/xanygo/anygo/xlog"
+	"github.com/xanygo/anygo/xnet/xpolicy"
+	"github.com/xanygo/anygo/xpp"
+)
+
+var defaultBackoff = xpolicy.FullJitter(time.Second, 5*time.Minute)
+
+var _ xpp.Worker = (*Relay)(nil)
+
+// Relay 定期从发件箱表中读取待投递的事件，并投递到 Sink
+//
+// 事件按照 ID 顺序投递，若某个事件投递失败，同一批次中相同 Key 的后续事件不会投递，
+// 直到该事件投递成功或者被标记为 StatusDead，以保证相同 Key 的事件按顺序投递。
+//
+// 同一个发件箱表，同一时间只应有一个 Relay 在运行。
+// 多副本部署时，可以使用 Relay.Job 将其注册为 xjob 的任务，由 xjob 保证只有一个副本运行。
+type Relay struct {
+	Client xdb.DBCore // 必填，数据库
+	Sink   Sink       // 必填，投递目标
+
+	Table     string        // 可选，发件箱表名，默认为 DefaultTable
+	Poll      time.Duration // 可选，查询周期，默认为 1 秒
+	BatchSize int           // 可选，每次查询的最大条数，默认为 100
+
+	// MaxAttempts 可选，每个事件最多尝试投递的次数，超过后标记为 StatusDead，默认为 0，即不限制
+	MaxAttempts int
+
+	// Retry 可选，投递失败后是否重试以及重试间隔的策略，
+	// 默认所有错误都会重试，间隔为 1 秒到 5 分钟之间的随机退避
+	Retry *xpolicy.Retry
+
+	// KeepDelivered 投递成功后保留记录并标记为 StatusDelivered，默认会删除
+	KeepDelivered bool
+
+	mux    sync.Mutex
+	worker *xpp.CycleWorker
+}
+
+func (r *Relay) Name() string {
+	return "xoutbox_Relay"
+}
+
+func (r *Relay) getTable() string {
+	if r.Table == "" {
+		return DefaultTable
+	}
+	return r.Table
+}
+
+func (r *Relay) model() *xdb.Model[Message] {
+	return xdb.NewMode[Message](r.Client).Table(r.getTable())
+}
+
+// Start 启动后台投递
+func (r *Relay) Start(ctx context.Context) error {
+	if r.Sink == nil {
+		return errors.New("nil Sink")
+	}
+	poll := r.Poll
+	if poll <= 0 {
+		poll = time.Second
+	}
+	r.mux.Lock()
+	if r.worker == nil {
+		r.worker = &xpp.CycleWorker{
+			WorkerName: r.Name(),
+			Cycle:      poll,
+			Do: func(ctx context.Context) error {
+				_, err := r.RunOnce(ctx)
+				if err != nil {
+					xlog.Warn(ctx, "xoutbox relay failed", xlog.ErrorAttr("error", err))
+				}
+				return err
+			},
+		}
+	}
+	worker := r.worker
+	r.mux.Unlock()
+	return worker.Start(ctx)
+}
+
+func (r *Relay) Stop(ctx context.Context) error {
+	r.mux.Lock()
+	worker := r.worker
+	r.mux.Unlock()
+	if worker == nil {
+		return nil
+	}
+	return worker.Stop(ctx)
+}
+
+// Job 返回定期运行 RunOnce 的 xjob 任务，用于多副本部署时保证只有一个副本投递
+func (r *Relay) Job(name string, schedule xjob.Schedule) *xjob.Job {
+	return &xjob.Job{
+		Name:     name,
+		Schedule: schedule,
+		Do: func(ctx context.Context) error {
+			_, err := r.RunOnce(ctx)
+			return err
+		},
+	}
+}
+
+// RunOnce 查询一批待投递的事件并投递，返回投递成功的数量
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	batch := r.BatchSize
+	if batch <= 0 {
+		batch = 100
+	}
+	m := r.model()
+	table := m.QuoteIdentifier(r.getTable())
+	// 只查询已到投递时间的事件，以免还未到重试时间的事件占满一批；
+	// 若相同 Key 有更早的、还未到重试时间的事件，需要等待其投递完成，以保证相同 Key 的事件按顺序投递
+	where := "status=? AND next_attempt<=? AND (agg_key='' OR NOT EXISTS (SELECT 1 FROM " + table + " b" +
+		" WHERE b.agg_key=" + table + ".agg_key AND b.status=? AND b.next_attempt>? AND b.id<" + table + ".id))" +
+		" ORDER BY id"
+	now := time.Now().UnixMilli()
+	list, err := m.Limit(batch).List(ctx, where, StatusPending, now, StatusPending, now)
+	if err != nil {
+		return 0, err
+	}
+	blocked := make(map[string]bool)
+	var num int
+	for _, msg := range list {
+		if msg.Key != "" && blocked[msg.Key] {
+			continue
+		}
+		errDeliver := r.Sink.Deliver(ctx, msg.Event())
+		if errDeliver == nil {
+			if err = r.delivered(ctx, msg); err != nil {
+				return num, err
+			}
+			num++
+			continue
+		}
+		blocked[msg.Key] = true
+		if err = r.failed(ctx, msg, errDeliver); err != nil {
+			return num, err
+		}
+		if ctx.Err() != nil {
+			return num, context.Cause(ctx)
+		}
+	}
+	return num, nil
+}
+
+func (r *Relay) delivered(ctx context.Context, msg Message) error {
+	if !r.KeepDelivered {
+		_, err := r.model().Delete(ctx, "id=?", msg.ID)
+		return err
+	}
+	msg.Status = StatusDelivered
+	_, err := r.model().SetUpsertFields("status").Update(ctx, msg, "id=?", msg.ID)
+	return err
+}
+
+func (r *Relay) failed(ctx context.Context, msg Message, errDeliver error) error {
+	attempt := msg.Attempts
+	msg.Attempts++
+	msg.LastError = truncate(errDeliver.Error(), 1024)
+	if (r.MaxAttempts > 0 && msg.Attempts >= r.MaxAttempts) || !r.retryable(ctx, msg, attempt, errDeliver) {
+		msg.Status = StatusDead
+		xlog.Warn(ctx, "xoutbox event dead",
+			xlog.String("topic", msg.Topic),
+			xlog.String("key", msg.Key),
+			xlog.ErrorAttr("error", errDeliver),
+		)
+	} else {
+		msg.NextAttempt = time.Now().Add(r.backoff(attempt))
+	}
+	_, err := r.model().SetUpsertFields("status", "attempts", "next_attempt", "last_error").Update(ctx, msg, "id=?", msg.ID)
+	if err != nil {
+		return fmt.Errorf("update event %d: %w", msg.ID, err)
+	}
+	return nil
+}
+
+func (r *Relay) retryable(ctx context.Context, msg Message, attempt int, err error) bool {
+	if r.Retry == nil {
+		return true
+	}
+	return r.Retry.IsRetryable(ctx, msg.Event(), attempt, err)
+}
+
+func (r *Relay) backoff(attempt int) time.Duration {
+	// 避免指数退避时溢出
+	attempt = min(attempt, 20)
+	if r.Retry != nil && r.Retry.Backoff != nil {
+		return r.Retry.GetBackoff(attempt)
+	}
+	return defaultBackoff(attempt)
+}
+
+// Purge 删除写入时间早于 before 的、已投递（StatusDelivered）和不再投递（StatusDead）的事件
+func (r *Relay) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return r.model().Delete(ctx, "status<>? AND created<?", StatusPending, before.UnixMilli())
+}
+
+func truncate(str string, size int) string {
+	if len(str) <= size {
+		return str
+	}
+	return strings.ToValidUTF8(str[:size], "")
+}
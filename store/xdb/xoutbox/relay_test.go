@@ -0,0 +1,106 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xoutbox_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xbus"
+	"github.com/xanygo/anygo/store/xdb"
+	"github.com/xanygo/anygo/store/xdb/xoutbox"
+	"github.com/xanygo/anygo/store/xdb/xtdr"
+	"github.com/xanygo/anygo/xt"
+)
+
+var messageColumns = []string{"id", "topic", "agg_key", "payload", "headers", "created", "status", "attempts", "next_attempt", "last_error"}
+
+func message(id int64, key string) []any {
+	past := time.Now().Add(-time.Second).UnixMilli()
+	return []any{id, "user.insert", key, []byte("{}"), "{}", past, 0, 0, past, ""}
+}
+
+func TestRelay_RunOnce(t *testing.T) {
+	m := xtdr.NewMock().InOrder(true)
+	m.ExpectQuery(`re:(?i)^SELECT .+ FROM "xoutbox"\s+where status=\? AND next_attempt<=\? AND .+NOT EXISTS .+ ORDER BY id LIMIT 100`).
+		WithArgs(0, xtdr.AnyArg(), 0, xtdr.AnyArg()).
+		WillReturnRows(messageColumns, [][]any{message(1, "a"), message(2, "a"), message(3, "b")})
+	// 1 投递失败，2 和 1 的 Key 相同，不投递
+	m.ExpectExec(`wc:UPDATE "xoutbox" SET *`).WithArgs(xtdr.AnyArg(), xtdr.AnyArg(), xtdr.AnyArg(), xtdr.AnyArg(), int64(1)).
+		WillReturnResult(xtdr.ResultOf(0, 1))
+	m.ExpectExec(`DELETE FROM "xoutbox" WHERE id=?`).WithArgs(3).WillReturnResult(xtdr.ResultOf(0, 1))
+
+	var delivered []int64
+	relay := &xoutbox.Relay{
+		Client: xdb.NewClient("sqlite3", "test", m.DB()),
+		Sink: xoutbox.SinkFunc(func(ctx context.Context, e xoutbox.Event) error {
+			if e.ID == 1 {
+				return errors.New("unavailable")
+			}
+			delivered = append(delivered, e.ID)
+			return nil
+		}),
+	}
+	num, err := relay.RunOnce(t.Context())
+	xt.NoError(t, err)
+	xt.Equal(t, num, 1)
+	xt.Equal(t, delivered, []int64{3})
+	xt.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestBrokerSink(t *testing.T) {
+	topic := xbus.NewTopic("user.insert")
+	broker := xbus.NewBroker()
+	got := make(chan xbus.Message, 1)
+	broker.RegisterConsumer(topic, consumerFunc(func(ctx context.Context, msg xbus.Message) error {
+		got <- msg
+		return nil
+	}))
+	sink := xoutbox.NewBrokerSink(broker, topic)
+	broker.Start()
+	defer broker.Stop()
+
+	err := sink.Deliver(t.Context(), xoutbox.Event{ID: 1, Topic: "user.insert", Key: "user:1"})
+	xt.NoError(t, err)
+	msg := <-got
+	xt.Equal(t, msg.Key, "user:1")
+	xt.Equal(t, msg.Payload.(xoutbox.Event).ID, 1)
+}
+
+type consumerFunc func(ctx context.Context, msg xbus.Message) error
+
+func (fn consumerFunc) Consume(ctx context.Context, msg xbus.Message) error {
+	return fn(ctx, msg)
+}
+
+func TestWebhookSink(t *testing.T) {
+	var status = http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"id":1}` || r.Header.Get("X-Outbox-ID") != "7" || r.Header.Get("X-Trace") != "abc" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	sink := &xoutbox.WebhookSink{URL: ts.URL}
+	e := xoutbox.Event{
+		ID:      7,
+		Topic:   "user.insert",
+		Payload: []byte(`{"id":1}`),
+		Headers: map[string]string{"X-Trace": "abc"},
+	}
+	xt.NoError(t, sink.Deliver(t.Context(), e))
+
+	status = http.StatusInternalServerError
+	xt.Error(t, sink.Deliver(t.Context(), e))
+}
@@ -0,0 +1,175 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xoutbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/xanygo/anygo/ds/xbus"
+	"github.com/xanygo/anygo/store/xredis"
+	"github.com/xanygo/anygo/xhttp/xhttpc"
+	"github.com/xanygo/anygo/xnet/xrpc"
+	"github.com/xanygo/anygo/xnet/xservice"
+)
+
+// Sink 事件的投递目标
+//
+// Deliver 返回 nil 表示投递成功，否则 Relay 会稍后重试。由于是至少一次投递，
+// 同一个事件可能会被投递多次，下游可以使用 Event.ID 去重
+type Sink interface {
+	Deliver(ctx context.Context, e Event) error
+}
+
+// SinkFunc 函数类型的 Sink
+type SinkFunc func(ctx context.Context, e Event) error
+
+func (fn SinkFunc) Deliver(ctx context.Context, e Event) error {
+	return fn(ctx, e)
+}
+
+var (
+	_ Sink          = (*BrokerSink)(nil)
+	_ xbus.Producer = (*BrokerSink)(nil)
+)
+
+// NewBrokerSink 创建投递到 xbus.Broker 的 Sink，并将其作为 Producer 注册到 broker
+//
+// topics: 事件 Topic 和 xbus.Topic 的对应关系，使用 xbus.Topic.Name() 和 Event.Topic 匹配，
+// 若没有匹配的，则使用 xbus.AnyTopic
+func NewBrokerSink(broker *xbus.Broker, topics ...xbus.Topic) *BrokerSink {
+	s := &BrokerSink{
+		topics: make(map[string]xbus.Topic, len(topics)),
+		ch:     make(chan xbus.Message),
+	}
+	for _, t := range topics {
+		s.topics[t.Name()] = t
+	}
+	broker.RegisterProducer(s)
+	return s
+}
+
+// BrokerSink 将事件投递到 xbus.Broker，消息的 Key 为 Event.Key，Payload 为 Event
+//
+// 当 Broker 接收消息后即认为投递成功，Consumer 的处理结果不影响投递结果
+type BrokerSink struct {
+	topics map[string]xbus.Topic
+	ch     chan xbus.Message
+}
+
+func (s *BrokerSink) Messages() <-chan xbus.Message {
+	return s.ch
+}
+
+func (s *BrokerSink) Deliver(ctx context.Context, e Event) error {
+	topic, ok := s.topics[e.Topic]
+	if !ok {
+		topic = xbus.AnyTopic
+	}
+	msg := xbus.Message{
+		Topic:   topic,
+		Key:     e.Key,
+		Payload: e,
+	}
+	select {
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case s.ch <- msg:
+		return nil
+	}
+}
+
+var _ Sink = (*RedisStreamSink)(nil)
+
+// RedisStreamSink 将事件投递到 Redis Stream
+//
+// 消息的字段有：id、topic、key、payload、created（毫秒时间戳），以及 JSON 格式的 headers（若有）
+type RedisStreamSink struct {
+	Client *xredis.Client // 必填
+
+	// Stream 可选，Stream 的 key，默认为 "outbox:{Event.Topic}"
+	Stream func(e Event) string
+
+	// MaxLen 可选，Stream 的最大长度（近似裁剪），<=0 时不裁剪
+	MaxLen int64
+}
+
+func (s *RedisStreamSink) getStream(e Event) string {
+	if s.Stream != nil {
+		return s.Stream(e)
+	}
+	return "outbox:" + e.Topic
+}
+
+func (s *RedisStreamSink) Deliver(ctx context.Context, e Event) error {
+	fields := []string{
+		"id", strconv.FormatInt(e.ID, 10),
+		"topic", e.Topic,
+		"key", e.Key,
+		"payload", string(e.Payload),
+		"created", strconv.FormatInt(e.Created.UnixMilli(), 10),
+	}
+	if len(e.Headers) > 0 {
+		bf, err := json.Marshal(e.Headers)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, "headers", string(bf))
+	}
+	_, err := s.Client.XAddMaxLen(ctx, s.getStream(e), s.MaxLen, "*", fields...)
+	return err
+}
+
+var _ Sink = (*WebhookSink)(nil)
+
+// WebhookSink 使用 HTTP POST 将事件投递到 Webhook，响应状态码为 2xx 时表示投递成功
+//
+// 请求 Body 为 Event.Payload，并有如下 Header：
+//   - X-Outbox-ID: Event.ID，可用于去重
+//   - X-Outbox-Topic: Event.Topic
+//   - X-Outbox-Key: Event.Key
+//   - Event.Headers 中的所有内容
+type WebhookSink struct {
+	URL         string        // 必填，Webhook 地址
+	Service     any           // 可选，xservice 的服务名称或者服务，当为空时，会使用 Dummy
+	ContentType string        // 可选，默认为 application/json
+	Opts        []xrpc.Option // 可选，额外的 RPC Client 参数
+}
+
+func (s *WebhookSink) getService() any {
+	if s.Service == nil {
+		return xservice.GetDummyService()
+	}
+	return s.Service
+}
+
+func (s *WebhookSink) Deliver(ctx context.Context, e Event) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(e.Payload))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(e.Payload)), nil
+	}
+	contentType := s.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("X-Outbox-ID", strconv.FormatInt(e.ID, 10))
+	req.Header.Set("X-Outbox-Topic", e.Topic)
+	if e.Key != "" {
+		req.Header.Set("X-Outbox-Key", e.Key)
+	}
+	return xhttpc.Invoke(ctx, s.getService(), req, xhttpc.StatusRange(200, 299), s.Opts...)
+}
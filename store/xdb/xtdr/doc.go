@@ -3,4 +3,9 @@
 //  Date: 2025-11-08
 
 // Package xtdr 用于测试 sql.DB 的测试驱动
+//
+// 支持以下几种用法：
+//  1. 使用包级别的 ExpectQuery、ExpectExec 预埋期望，并使用 MustOpen 打开数据库
+//  2. 使用 NewMock 创建独立的一组期望，支持有序匹配、参数匹配、事务和预编译语句，并使用 Mock.DB 打开数据库
+//  3. 使用 NewRecorder 录制真实数据库（如 sqlite）的请求和响应到 golden 文件，再使用 Replay 回放，详见 OpenGolden
 package xtdr
@@ -5,41 +5,26 @@
 package xtdr
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
 	"io"
-	"slices"
 	"sync"
 	"time"
-
-	"github.com/xanygo/anygo/ds/xstr"
 )
 
+// defaultMock 使用 sql.Open(Name, dsn) 打开的数据库，共享此 Mock
+var defaultMock = NewMock()
+
 // ExpectQuery 提前预埋查询请求( db.Query )的 sql 对应的结果集
 func ExpectQuery(query string, columns []string, rows [][]any) {
-	mu.Lock()
-	defer mu.Unlock()
-	q := &queryExpectation{
-		query:   query,
-		columns: columns,
-		rows:    convertRows(rows),
-		typ:     expectQuery,
-	}
-	expectations = append(expectations, q)
+	defaultMock.ExpectQuery(query).WillReturnRows(columns, rows)
 }
 
 // ExpectExec 提前预埋提更新请求（ db.Exec ）的 sql 对应的结果集
 func ExpectExec(query string, res driver.Result, err error) {
-	mu.Lock()
-	defer mu.Unlock()
-	e := &queryExpectation{
-		query: query,
-		typ:   expectExec,
-		res:   res,
-		err:   err,
-	}
-	expectations = append(expectations, e)
+	defaultMock.ExpectExec(query).WillReturnResult(res).WillReturnError(err)
 }
 
 func ResultOf(lastInsertID, rowsAffected int64) driver.Result {
@@ -47,17 +32,17 @@ func ResultOf(lastInsertID, rowsAffected int64) driver.Result {
 }
 
 func LastQueries() []string {
-	mu.Lock()
-	defer mu.Unlock()
-	return slices.Clone(executedQueries)
+	return defaultMock.LastQueries()
 }
 
 // Reset clears expectations and recorded queries.
 func Reset() {
-	mu.Lock()
-	defer mu.Unlock()
-	expectations = nil
-	executedQueries = nil
+	defaultMock.Reset()
+}
+
+// Default 返回使用 sql.Open(Name, dsn) 打开的数据库所共享的 Mock
+func Default() *Mock {
+	return defaultMock
 }
 
 const Name = "test-driver"
@@ -70,29 +55,6 @@ func Register() {
 	})
 }
 
-type driverType int
-
-const (
-	expectQuery driverType = iota
-	expectExec
-)
-
-type queryExpectation struct {
-	query   string
-	columns []string
-	rows    [][]driver.Value // for query
-	typ     driverType
-	res     driver.Result // for exec
-	err     error
-	used    bool
-}
-
-var (
-	mu              sync.Mutex
-	expectations    []*queryExpectation
-	executedQueries []string
-)
-
 // convertRows converts [][]any to [][]driver.Value
 func convertRows(rows [][]any) [][]driver.Value {
 	out := make([][]driver.Value, 0, len(rows))
@@ -153,28 +115,110 @@ type Driver struct{}
 
 func (d *Driver) Open(name string) (driver.Conn, error) {
 	// name ignored
-	return &conn{}, nil
+	return &conn{mock: defaultMock}, nil
+}
+
+var _ driver.Connector = (*connector)(nil)
+
+type connector struct {
+	mock *Mock
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &conn{mock: c.mock}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return &Driver{}
 }
 
-var _ driver.Conn = (*conn)(nil)
+var (
+	_ driver.Conn           = (*conn)(nil)
+	_ driver.ConnBeginTx    = (*conn)(nil)
+	_ driver.QueryerContext = (*conn)(nil)
+	_ driver.ExecerContext  = (*conn)(nil)
+)
 
 // conn implements driver.Conn and Prepare/Begin/Close
-type conn struct{}
+type conn struct {
+	mock *Mock
+}
 
 func (c *conn) Prepare(query string) (driver.Stmt, error) {
-	return &stmt{query: query}, nil
+	e, err := c.mock.match(expectPrepare, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	if e != nil && e.err != nil {
+		return nil, e.err
+	}
+	return &stmt{conn: c, query: query}, nil
 }
 
 func (c *conn) Close() error { return nil }
 
 func (c *conn) Begin() (driver.Tx, error) {
-	return tx{}, nil
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	e, err := c.mock.match(expectBegin, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if e != nil && e.err != nil {
+		return nil, e.err
+	}
+	return &tx{mock: c.mock}, nil
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.query(query, namedValues(args))
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.exec(query, namedValues(args))
+}
+
+func (c *conn) query(query string, args []driver.Value) (driver.Rows, error) {
+	e, err := c.mock.match(expectQuery, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &rows{
+		cols: e.columns,
+		data: e.rows,
+		pos:  0,
+	}, nil
+}
+
+func (c *conn) exec(query string, args []driver.Value) (driver.Result, error) {
+	e, err := c.mock.match(expectExec, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return e.res, e.err
+}
+
+func namedValues(args []driver.NamedValue) []driver.Value {
+	if len(args) == 0 {
+		return nil
+	}
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
 }
 
 var _ driver.Stmt = (*stmt)(nil)
 
 // stmt implements driver.Stmt
 type stmt struct {
+	conn  *conn
 	query string
 }
 
@@ -183,20 +227,37 @@ func (s *stmt) Close() error { return nil }
 func (s *stmt) NumInput() int { return -1 } // -1 means variable
 
 func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
-	return execSQL(s.query)
+	return s.conn.exec(s.query, args)
 }
 
 func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
-	return querySQL(s.query)
+	return s.conn.query(s.query, args)
 }
 
 var _ driver.Tx = (*tx)(nil)
 
-type tx struct{}
+type tx struct {
+	mock *Mock
+}
+
+func (t *tx) Commit() error {
+	return t.end(expectCommit)
+}
 
-func (tx) Commit() error { return nil }
+func (t *tx) Rollback() error {
+	return t.end(expectRollback)
+}
 
-func (tx) Rollback() error { return nil }
+func (t *tx) end(kind expectKind) error {
+	e, err := t.mock.match(kind, "", nil)
+	if err != nil {
+		return err
+	}
+	if e != nil {
+		return e.err
+	}
+	return nil
+}
 
 var _ driver.Rows = (*rows)(nil)
 
@@ -241,44 +302,6 @@ func (d driverResult) RowsAffected() (int64, error) {
 	return d.rowsAffected, nil
 }
 
-func execSQL(query string) (driver.Result, error) {
-	mu.Lock()
-	defer mu.Unlock()
-	executedQueries = append(executedQueries, query)
-	for _, e := range expectations {
-		if e.used || e.typ != expectExec {
-			continue
-		}
-		if !xstr.Match(e.query, query) {
-			continue
-		}
-		e.used = true
-		return e.res, e.err
-	}
-	return nil, fmt.Errorf("no exec expectation for query: %q", query)
-}
-
-func querySQL(query string) (driver.Rows, error) {
-	mu.Lock()
-	defer mu.Unlock()
-	executedQueries = append(executedQueries, query)
-	for _, e := range expectations {
-		if e.used || e.typ != expectQuery {
-			continue
-		}
-		if !xstr.Match(e.query, query) {
-			continue
-		}
-		e.used = true
-		return &rows{
-			cols: slices.Clone(e.columns),
-			data: slices.Clone(e.rows),
-			pos:  0,
-		}, nil
-	}
-	return nil, fmt.Errorf("no query expectation for query: %q", query)
-}
-
 func init() {
 	Register()
 }
@@ -0,0 +1,86 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xtdr
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/xanygo/anygo/ds/xstr"
+)
+
+// MatchSQL 判断 SQL 语句 query 和规则 pattern 是否匹配
+//
+// pattern 的几种模式：
+//  1. 若有 "re:" 前缀，则将后面的内容当做正则表达式匹配，如 "re:(?i)^select .+ from user"
+//  2. 若有 "wc:" 前缀，则支持通配符：* 匹配任意长度字符串，? 匹配任意单个字符，如 "wc:select * from user"
+//  3. 其他情况，将 pattern 和 query 使用 NormalizeSQL 规范化之后比较是否相等
+func MatchSQL(pattern string, query string) bool {
+	if strings.HasPrefix(pattern, "re:") || strings.HasPrefix(pattern, "wc:") {
+		return xstr.Match(pattern, query)
+	}
+	return NormalizeSQL(pattern) == NormalizeSQL(query)
+}
+
+// NormalizeSQL 规范化 SQL 语句：将连续的空白字符合并为一个空格，并去除首尾的空白字符和末尾的分号
+func NormalizeSQL(query string) string {
+	var sb strings.Builder
+	sb.Grow(len(query))
+	space := false
+	for _, r := range strings.TrimSpace(query) {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			sb.WriteByte(' ')
+			space = false
+		}
+		sb.WriteRune(r)
+	}
+	return strings.TrimSpace(strings.TrimRight(sb.String(), ";"))
+}
+
+// ArgMatcher 参数匹配器，可以在 Expectation.WithArgs 中使用
+type ArgMatcher interface {
+	Match(v driver.Value) bool
+}
+
+// ArgMatcherFunc 函数类型的参数匹配器
+type ArgMatcherFunc func(v driver.Value) bool
+
+func (fn ArgMatcherFunc) Match(v driver.Value) bool {
+	return fn(v)
+}
+
+// AnyArg 匹配任意参数值
+func AnyArg() ArgMatcher {
+	return ArgMatcherFunc(func(driver.Value) bool {
+		return true
+	})
+}
+
+// ArgOfType 匹配和 example 类型相同的参数值，如 ArgOfType(time.Time{})
+func ArgOfType(example any) ArgMatcher {
+	want := reflect.TypeOf(convertToDriverValue(example))
+	return ArgMatcherFunc(func(v driver.Value) bool {
+		return reflect.TypeOf(v) == want
+	})
+}
+
+func matchArg(want any, got driver.Value) bool {
+	if am, ok := want.(ArgMatcher); ok {
+		return am.Match(got)
+	}
+	wv := convertToDriverValue(want)
+	if wt, ok := wv.(time.Time); ok {
+		gt, ok := got.(time.Time)
+		return ok && wt.Equal(gt)
+	}
+	return reflect.DeepEqual(wv, got)
+}
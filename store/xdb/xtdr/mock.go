@@ -0,0 +1,294 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xtdr
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type expectKind int
+
+const (
+	expectQuery expectKind = iota
+	expectExec
+	expectPrepare
+	expectBegin
+	expectCommit
+	expectRollback
+)
+
+func (k expectKind) String() string {
+	switch k {
+	case expectQuery:
+		return "query"
+	case expectExec:
+		return "exec"
+	case expectPrepare:
+		return "prepare"
+	case expectBegin:
+		return "begin"
+	case expectCommit:
+		return "commit"
+	case expectRollback:
+		return "rollback"
+	default:
+		return fmt.Sprintf("kind(%d)", int(k))
+	}
+}
+
+// hasSQL 此类型的请求是否有 SQL 语句
+func (k expectKind) hasSQL() bool {
+	return k == expectQuery || k == expectExec || k == expectPrepare
+}
+
+// NewMock 创建一组独立的期望，可通过 Mock.DB 获取对应的 *sql.DB
+func NewMock() *Mock {
+	return &Mock{}
+}
+
+// Mock 预埋的一组期望（请求及其对应的响应）
+//
+// 默认是无序匹配：请求会和尚未使用的同类期望依次比较，使用第一个匹配的期望。
+// 对于 Begin、Commit、Rollback、Prepare，若从未预埋过同类的期望，则总是成功。
+//
+// 调用 InOrder(true) 后为有序匹配：每个请求（包括 Begin、Commit、Rollback、Prepare）
+// 都必须和下一个尚未使用的期望匹配。
+type Mock struct {
+	mu      sync.Mutex
+	ordered bool
+	expects []*Expectation
+	queries []string
+}
+
+// InOrder 设置是否按照预埋的顺序匹配
+func (m *Mock) InOrder(ordered bool) *Mock {
+	m.mu.Lock()
+	m.ordered = ordered
+	m.mu.Unlock()
+	return m
+}
+
+// DB 返回使用此 Mock 的 *sql.DB
+func (m *Mock) DB() *sql.DB {
+	return sql.OpenDB(m.Connector())
+}
+
+// Connector 返回使用此 Mock 的 driver.Connector
+func (m *Mock) Connector() driver.Connector {
+	return &connector{mock: m}
+}
+
+func (m *Mock) add(kind expectKind, query string) *Expectation {
+	e := &Expectation{
+		kind:    kind,
+		pattern: query,
+	}
+	m.mu.Lock()
+	m.expects = append(m.expects, e)
+	m.mu.Unlock()
+	return e
+}
+
+// ExpectQuery 预埋查询请求 ( db.Query )
+//
+// query: SQL 语句的匹配规则，详见 MatchSQL
+func (m *Mock) ExpectQuery(query string) *Expectation {
+	return m.add(expectQuery, query)
+}
+
+// ExpectExec 预埋更新请求 ( db.Exec )，默认返回 ResultOf(0, 0)
+//
+// query: SQL 语句的匹配规则，详见 MatchSQL
+func (m *Mock) ExpectExec(query string) *Expectation {
+	return m.add(expectExec, query).WillReturnResult(ResultOf(0, 0))
+}
+
+// ExpectPrepare 预埋预编译请求 ( db.Prepare )
+//
+// query: SQL 语句的匹配规则，详见 MatchSQL
+func (m *Mock) ExpectPrepare(query string) *Expectation {
+	return m.add(expectPrepare, query)
+}
+
+// ExpectBegin 预埋开启事务请求 ( db.Begin )
+func (m *Mock) ExpectBegin() *Expectation {
+	return m.add(expectBegin, "")
+}
+
+// ExpectCommit 预埋提交事务请求 ( tx.Commit )
+func (m *Mock) ExpectCommit() *Expectation {
+	return m.add(expectCommit, "")
+}
+
+// ExpectRollback 预埋回滚事务请求 ( tx.Rollback )
+func (m *Mock) ExpectRollback() *Expectation {
+	return m.add(expectRollback, "")
+}
+
+// ExpectationsWereMet 检查是否所有的期望都已被使用
+func (m *Mock) ExpectationsWereMet() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var errs []error
+	for _, e := range m.expects {
+		if !e.used {
+			errs = append(errs, fmt.Errorf("expectation not met: %s", e))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LastQueries 返回已执行的 query、exec 请求的 SQL 语句
+func (m *Mock) LastQueries() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.queries)
+}
+
+// Reset 清空所有的期望和已执行的 SQL 语句
+func (m *Mock) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expects = nil
+	m.queries = nil
+}
+
+// match 查找和请求匹配的期望。
+// 当返回的 Expectation 和 error 都为 nil 时，表示不需要匹配（未预埋同类期望的 Begin 等请求）
+func (m *Mock) match(kind expectKind, query string, args []driver.Value) (*Expectation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if kind == expectQuery || kind == expectExec {
+		m.queries = append(m.queries, query)
+	}
+	if m.ordered {
+		return m.matchInOrder(kind, query, args)
+	}
+	var hasKind bool
+	var argErr error
+	for _, e := range m.expects {
+		if e.kind != kind {
+			continue
+		}
+		hasKind = true
+		if e.used || !e.matchQuery(query) {
+			continue
+		}
+		if err := e.matchArgs(args); err != nil {
+			argErr = err
+			continue
+		}
+		e.used = true
+		return e, nil
+	}
+	if !hasKind && kind != expectQuery && kind != expectExec {
+		return nil, nil
+	}
+	if argErr != nil {
+		return nil, fmt.Errorf("no %s expectation for query: %q, %w", kind, query, argErr)
+	}
+	if kind.hasSQL() {
+		return nil, fmt.Errorf("no %s expectation for query: %q", kind, query)
+	}
+	return nil, fmt.Errorf("no %s expectation", kind)
+}
+
+func (m *Mock) matchInOrder(kind expectKind, query string, args []driver.Value) (*Expectation, error) {
+	idx := slices.IndexFunc(m.expects, func(e *Expectation) bool {
+		return !e.used
+	})
+	if idx < 0 {
+		return nil, fmt.Errorf("unexpected %s %q: all expectations were already fulfilled", kind, query)
+	}
+	e := m.expects[idx]
+	if e.kind != kind || !e.matchQuery(query) {
+		return nil, fmt.Errorf("unexpected %s %q, next expectation is: %s", kind, query, e)
+	}
+	if err := e.matchArgs(args); err != nil {
+		return nil, fmt.Errorf("%s %q: %w", kind, query, err)
+	}
+	e.used = true
+	return e, nil
+}
+
+// Expectation 一个预埋的期望
+type Expectation struct {
+	kind    expectKind
+	pattern string
+	args    []any // 为 nil 时不校验参数
+	columns []string
+	rows    [][]driver.Value
+	res     driver.Result
+	err     error
+	used    bool
+}
+
+// WithArgs 设置期望的参数，参数值可以是普通的值，也可以是 ArgMatcher，如 AnyArg()
+func (e *Expectation) WithArgs(args ...any) *Expectation {
+	if args == nil {
+		args = []any{}
+	}
+	e.args = args
+	return e
+}
+
+// WillReturnRows 设置查询请求返回的结果集
+func (e *Expectation) WillReturnRows(columns []string, rows [][]any) *Expectation {
+	e.columns = slices.Clone(columns)
+	e.rows = convertRows(rows)
+	return e
+}
+
+// WillReturnResult 设置更新请求返回的结果
+func (e *Expectation) WillReturnResult(res driver.Result) *Expectation {
+	e.res = res
+	return e
+}
+
+// WillReturnError 设置请求返回的错误
+func (e *Expectation) WillReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+func (e *Expectation) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.kind.String())
+	if e.kind.hasSQL() {
+		fmt.Fprintf(&sb, " %q", e.pattern)
+	}
+	if e.args != nil {
+		fmt.Fprintf(&sb, " with args %v", e.args)
+	}
+	return sb.String()
+}
+
+func (e *Expectation) matchQuery(query string) bool {
+	if !e.kind.hasSQL() {
+		return true
+	}
+	return MatchSQL(e.pattern, query)
+}
+
+func (e *Expectation) matchArgs(args []driver.Value) error {
+	if e.args == nil {
+		return nil
+	}
+	if len(e.args) != len(args) {
+		return fmt.Errorf("expect %d args, got %d", len(e.args), len(args))
+	}
+	for i, want := range e.args {
+		if !matchArg(want, args[i]) {
+			return fmt.Errorf("arg[%d] not match, expect %#v, got %#v", i, want, args[i])
+		}
+	}
+	return nil
+}
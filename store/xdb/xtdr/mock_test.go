@@ -0,0 +1,95 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xtdr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/xanygo/anygo/store/xdb/xtdr"
+	"github.com/xanygo/anygo/xt"
+)
+
+func TestMock(t *testing.T) {
+	t.Run("unordered", func(t *testing.T) {
+		m := xtdr.NewMock()
+		m.ExpectExec("delete from user where id=?").WithArgs(2).WillReturnResult(xtdr.ResultOf(0, 1))
+		m.ExpectQuery("re:^select .+ from user").WithArgs(xtdr.AnyArg()).
+			WillReturnRows([]string{"id"}, [][]any{{1}})
+		db := m.DB()
+		defer db.Close()
+
+		var id int
+		xt.NoError(t, db.QueryRow("select id   from user where id=?;", 1).Scan(&id))
+		xt.Equal(t, id, 1)
+
+		_, err := db.Exec("delete from user where id=?", 3)
+		xt.Error(t, err)
+		xt.Error(t, m.ExpectationsWereMet())
+
+		ret, err := db.Exec("delete  from user\n where id=?", 2)
+		xt.NoError(t, err)
+		num, _ := ret.RowsAffected()
+		xt.Equal(t, num, 1)
+		xt.NoError(t, m.ExpectationsWereMet())
+		xt.Len(t, m.LastQueries(), 3)
+	})
+
+	t.Run("in order with tx", func(t *testing.T) {
+		m := xtdr.NewMock().InOrder(true)
+		m.ExpectBegin()
+		m.ExpectExec("wc:insert into *").WithArgs("a", xtdr.ArgOfType(0))
+		m.ExpectCommit()
+		m.ExpectBegin()
+		m.ExpectExec("wc:update *").WillReturnError(errors.New("locked"))
+		m.ExpectRollback()
+		db := m.DB()
+		defer db.Close()
+
+		tx, err := db.Begin()
+		xt.NoError(t, err)
+		_, err = tx.Exec("insert into user(name,age) values(?,?)", "a", 10)
+		xt.NoError(t, err)
+		xt.NoError(t, tx.Commit())
+
+		_, err = db.Exec("update user set age=1")
+		xt.Error(t, err)
+
+		tx, err = db.Begin()
+		xt.NoError(t, err)
+		_, err = tx.Exec("update user set age=1")
+		xt.Error(t, err)
+		xt.NoError(t, tx.Rollback())
+		xt.NoError(t, m.ExpectationsWereMet())
+	})
+
+	t.Run("prepare", func(t *testing.T) {
+		m := xtdr.NewMock()
+		m.ExpectPrepare("select name from user where id=?")
+		m.ExpectQuery("select name from user where id=?").WithArgs(1).
+			WillReturnRows([]string{"name"}, [][]any{{"hello"}})
+		db := m.DB()
+		defer db.Close()
+
+		st, err := db.Prepare("select name from user where id=?")
+		xt.NoError(t, err)
+		defer st.Close()
+		var name string
+		xt.NoError(t, st.QueryRow(1).Scan(&name))
+		xt.Equal(t, name, "hello")
+		xt.NoError(t, m.ExpectationsWereMet())
+
+		_, err = db.Prepare("select 1")
+		xt.Error(t, err)
+	})
+}
+
+func TestMatchSQL(t *testing.T) {
+	xt.True(t, xtdr.MatchSQL("select 1", " select\t1 ; "))
+	xt.True(t, xtdr.MatchSQL("re:^select \\d$", "select 2"))
+	xt.True(t, xtdr.MatchSQL("wc:select *", "select 2"))
+	xt.False(t, xtdr.MatchSQL("select 1", "select 2"))
+	xt.Equal(t, xtdr.NormalizeSQL("select  *\n from user;"), "select * from user")
+}
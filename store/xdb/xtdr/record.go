@@ -0,0 +1,551 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xtdr
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// 记录的请求类型
+const (
+	ActionQuery    = "query"
+	ActionExec     = "exec"
+	ActionPrepare  = "prepare"
+	ActionBegin    = "begin"
+	ActionCommit   = "commit"
+	ActionRollback = "rollback"
+)
+
+// Record 一次请求及其响应，可保存到 golden 文件中用于回放
+type Record struct {
+	Action       string    `json:"action"`
+	Query        string    `json:"query,omitempty"`
+	Args         []Value   `json:"args,omitempty"`
+	Columns      []string  `json:"columns,omitempty"`
+	Rows         [][]Value `json:"rows,omitempty"`
+	LastInsertID int64     `json:"last_insert_id,omitempty"`
+	RowsAffected int64     `json:"rows_affected,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// Value 可以 JSON 序列化的 driver.Value，序列化后会保留值的类型
+type Value struct {
+	V driver.Value
+}
+
+type jsonValue struct {
+	Int64   *int64     `json:"int64,omitempty"`
+	Float64 *float64   `json:"float64,omitempty"`
+	Bool    *bool      `json:"bool,omitempty"`
+	String  *string    `json:"string,omitempty"`
+	Bytes   *[]byte    `json:"bytes,omitempty"`
+	Time    *time.Time `json:"time,omitempty"`
+}
+
+func (v Value) MarshalJSON() ([]byte, error) {
+	var jv jsonValue
+	switch x := v.V.(type) {
+	case nil:
+		return []byte("null"), nil
+	case int64:
+		jv.Int64 = &x
+	case float64:
+		jv.Float64 = &x
+	case bool:
+		jv.Bool = &x
+	case string:
+		jv.String = &x
+	case []byte:
+		jv.Bytes = &x
+	case time.Time:
+		jv.Time = &x
+	default:
+		str := fmt.Sprint(x)
+		jv.String = &str
+	}
+	return json.Marshal(jv)
+}
+
+func (v *Value) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		v.V = nil
+		return nil
+	}
+	var jv jsonValue
+	if err := json.Unmarshal(data, &jv); err != nil {
+		return err
+	}
+	switch {
+	case jv.Int64 != nil:
+		v.V = *jv.Int64
+	case jv.Float64 != nil:
+		v.V = *jv.Float64
+	case jv.Bool != nil:
+		v.V = *jv.Bool
+	case jv.String != nil:
+		v.V = *jv.String
+	case jv.Bytes != nil:
+		v.V = *jv.Bytes
+	case jv.Time != nil:
+		v.V = *jv.Time
+	default:
+		return fmt.Errorf("invalid value: %s", data)
+	}
+	return nil
+}
+
+func toValues(values []driver.Value) []Value {
+	if len(values) == 0 {
+		return nil
+	}
+	result := make([]Value, len(values))
+	for i, v := range values {
+		if bf, ok := v.([]byte); ok {
+			v = slices.Clone(bf)
+		}
+		result[i] = Value{V: v}
+	}
+	return result
+}
+
+func fromValues(values []Value) []any {
+	result := make([]any, len(values))
+	for i, v := range values {
+		result[i] = v.V
+	}
+	return result
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// SaveGolden 将记录保存到 golden 文件
+func SaveGolden(name string, records []Record) error {
+	bf, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(name, bf, 0644)
+}
+
+// LoadGolden 读取 golden 文件中的记录
+func LoadGolden(name string) ([]Record, error) {
+	bf, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var records []Record
+	if err = json.Unmarshal(bf, &records); err != nil {
+		return nil, fmt.Errorf("parser golden file %q: %w", name, err)
+	}
+	return records, nil
+}
+
+// ExpectRecords 将记录按顺序添加为期望，并设置为有序匹配（InOrder(true)），用于回放
+func (m *Mock) ExpectRecords(records ...Record) error {
+	for i, r := range records {
+		var err error
+		if r.Error != "" {
+			err = errors.New(r.Error)
+		}
+		switch r.Action {
+		case ActionQuery:
+			rows := make([][]any, len(r.Rows))
+			for j, row := range r.Rows {
+				rows[j] = fromValues(row)
+			}
+			m.ExpectQuery(r.Query).WithArgs(fromValues(r.Args)...).WillReturnRows(r.Columns, rows).WillReturnError(err)
+		case ActionExec:
+			m.ExpectExec(r.Query).WithArgs(fromValues(r.Args)...).
+				WillReturnResult(ResultOf(r.LastInsertID, r.RowsAffected)).WillReturnError(err)
+		case ActionPrepare:
+			m.ExpectPrepare(r.Query).WillReturnError(err)
+		case ActionBegin:
+			m.ExpectBegin().WillReturnError(err)
+		case ActionCommit:
+			m.ExpectCommit().WillReturnError(err)
+		case ActionRollback:
+			m.ExpectRollback().WillReturnError(err)
+		default:
+			return fmt.Errorf("records[%d]: invalid action %q", i, r.Action)
+		}
+	}
+	m.InOrder(true)
+	return nil
+}
+
+// Replay 读取 golden 文件，并创建一个按顺序回放其中记录的 Mock
+func Replay(name string) (*Mock, error) {
+	records, err := LoadGolden(name)
+	if err != nil {
+		return nil, err
+	}
+	m := NewMock()
+	if err = m.ExpectRecords(records...); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// OpenGolden 打开一个用于单元测试的数据库：
+//   - record 为 true 时为录制模式：使用真实的驱动 base 和 dsn 访问数据库（如 sqlite），
+//     调用 done 时会关闭数据库，并将所有的请求和响应写入 golden 文件 name
+//   - 否则为回放模式：读取 golden 文件 name，按顺序回放其中的记录，
+//     调用 done 时会关闭数据库，并检查所有的记录是否都已被回放
+//
+// 回放模式下 base 和 dsn 不会被使用，可以为空
+func OpenGolden(name string, record bool, base driver.Driver, dsn string) (db *sql.DB, done func() error, err error) {
+	if record {
+		rec := NewRecorder(base, dsn)
+		db = rec.DB()
+		done = func() error {
+			err1 := db.Close()
+			err2 := rec.SaveGolden(name)
+			return errors.Join(err1, err2)
+		}
+		return db, done, nil
+	}
+	m, err := Replay(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	db = m.DB()
+	done = func() error {
+		err1 := db.Close()
+		err2 := m.ExpectationsWereMet()
+		return errors.Join(err1, err2)
+	}
+	return db, done, nil
+}
+
+// NewRecorder 创建一个录制器，使用真实的驱动 base 和 dsn 访问数据库，并记录所有的请求和响应
+//
+// 如记录 sqlite 的请求：
+//
+//	raw, _ := sql.Open("sqlite3", "./test.db")
+//	rec := xtdr.NewRecorder(raw.Driver(), "./test.db")
+//	db := rec.DB()
+func NewRecorder(base driver.Driver, dsn string) *Recorder {
+	return &Recorder{
+		base: base,
+		dsn:  dsn,
+	}
+}
+
+// Recorder 数据库请求录制器
+type Recorder struct {
+	base    driver.Driver
+	dsn     string
+	mu      sync.Mutex
+	records []Record
+}
+
+// DB 返回使用此录制器的 *sql.DB
+func (r *Recorder) DB() *sql.DB {
+	return sql.OpenDB(&recordConnector{rec: r})
+}
+
+// Records 返回已录制的记录
+func (r *Recorder) Records() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.records)
+}
+
+// SaveGolden 将已录制的记录保存到 golden 文件
+func (r *Recorder) SaveGolden(name string) error {
+	return SaveGolden(name, r.Records())
+}
+
+func (r *Recorder) add(record Record) {
+	r.mu.Lock()
+	r.records = append(r.records, record)
+	r.mu.Unlock()
+}
+
+var _ driver.Connector = (*recordConnector)(nil)
+
+type recordConnector struct {
+	rec *Recorder
+}
+
+func (c *recordConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	base, err := c.rec.base.Open(c.rec.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &recordConn{rec: c.rec, base: base}, nil
+}
+
+func (c *recordConnector) Driver() driver.Driver {
+	return c.rec.base
+}
+
+var (
+	_ driver.Conn               = (*recordConn)(nil)
+	_ driver.ConnBeginTx        = (*recordConn)(nil)
+	_ driver.QueryerContext     = (*recordConn)(nil)
+	_ driver.ExecerContext      = (*recordConn)(nil)
+	_ driver.NamedValueChecker  = (*recordConn)(nil)
+	_ driver.ConnPrepareContext = (*recordConn)(nil)
+)
+
+type recordConn struct {
+	rec  *Recorder
+	base driver.Conn
+}
+
+func (c *recordConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *recordConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	st, err := c.prepare(ctx, query)
+	c.rec.add(Record{Action: ActionPrepare, Query: query, Error: errString(err)})
+	if err != nil {
+		return nil, err
+	}
+	return &recordStmt{conn: c, base: st, query: query}, nil
+}
+
+func (c *recordConn) prepare(ctx context.Context, query string) (driver.Stmt, error) {
+	if pc, ok := c.base.(driver.ConnPrepareContext); ok {
+		return pc.PrepareContext(ctx, query)
+	}
+	return c.base.Prepare(query)
+}
+
+func (c *recordConn) Close() error {
+	return c.base.Close()
+}
+
+func (c *recordConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *recordConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var t driver.Tx
+	var err error
+	if bt, ok := c.base.(driver.ConnBeginTx); ok {
+		t, err = bt.BeginTx(ctx, opts)
+	} else {
+		t, err = c.base.Begin() //nolint:staticcheck
+	}
+	c.rec.add(Record{Action: ActionBegin, Error: errString(err)})
+	if err != nil {
+		return nil, err
+	}
+	return &recordTx{rec: c.rec, base: t}, nil
+}
+
+func (c *recordConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.base.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func (c *recordConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rs, err := c.query(ctx, query, args)
+	return c.rec.recordRows(query, args, rs, err)
+}
+
+func (c *recordConn) query(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if qc, ok := c.base.(driver.QueryerContext); ok {
+		rs, err := qc.QueryContext(ctx, query, args)
+		if !errors.Is(err, driver.ErrSkip) {
+			return rs, err
+		}
+	}
+	// 驱动不支持直接查询，使用预编译语句查询，并读取全部结果后关闭
+	st, err := c.prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer st.Close()
+	rs, err := stmtQuery(ctx, st, args)
+	if err != nil {
+		return nil, err
+	}
+	return readAll(rs)
+}
+
+func (c *recordConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	res, err := c.exec(ctx, query, args)
+	return c.rec.recordResult(query, args, res, err)
+}
+
+func (c *recordConn) exec(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if ec, ok := c.base.(driver.ExecerContext); ok {
+		res, err := ec.ExecContext(ctx, query, args)
+		if !errors.Is(err, driver.ErrSkip) {
+			return res, err
+		}
+	}
+	st, err := c.prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer st.Close()
+	return stmtExec(ctx, st, args)
+}
+
+func stmtQuery(ctx context.Context, st driver.Stmt, args []driver.NamedValue) (driver.Rows, error) {
+	if sq, ok := st.(driver.StmtQueryContext); ok {
+		return sq.QueryContext(ctx, args)
+	}
+	return st.Query(namedValues(args)) //nolint:staticcheck
+}
+
+func stmtExec(ctx context.Context, st driver.Stmt, args []driver.NamedValue) (driver.Result, error) {
+	if se, ok := st.(driver.StmtExecContext); ok {
+		return se.ExecContext(ctx, args)
+	}
+	return st.Exec(namedValues(args)) //nolint:staticcheck
+}
+
+// readAll 读取全部结果到内存中，并关闭 rs
+func readAll(rs driver.Rows) (*rows, error) {
+	defer rs.Close()
+	result := &rows{
+		cols: rs.Columns(),
+	}
+	for {
+		dest := make([]driver.Value, len(result.cols))
+		err := rs.Next(dest)
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range dest {
+			if bf, ok := v.([]byte); ok {
+				dest[i] = slices.Clone(bf)
+			}
+		}
+		result.data = append(result.data, dest)
+	}
+}
+
+func (r *Recorder) recordRows(query string, args []driver.NamedValue, rs driver.Rows, err error) (driver.Rows, error) {
+	record := Record{
+		Action: ActionQuery,
+		Query:  query,
+		Args:   toValues(namedValues(args)),
+	}
+	var all *rows
+	if err == nil {
+		all, err = readAll(rs)
+	}
+	if err != nil {
+		record.Error = err.Error()
+		r.add(record)
+		return nil, err
+	}
+	record.Columns = all.cols
+	for _, row := range all.data {
+		record.Rows = append(record.Rows, toValues(row))
+	}
+	r.add(record)
+	return all, nil
+}
+
+func (r *Recorder) recordResult(query string, args []driver.NamedValue, res driver.Result, err error) (driver.Result, error) {
+	record := Record{
+		Action: ActionExec,
+		Query:  query,
+		Args:   toValues(namedValues(args)),
+		Error:  errString(err),
+	}
+	if err == nil {
+		// 部分驱动（如 Postgres）不支持 LastInsertId，此时记录为 0
+		record.LastInsertID, _ = res.LastInsertId()
+		record.RowsAffected, _ = res.RowsAffected()
+	}
+	r.add(record)
+	return res, err
+}
+
+var _ driver.Stmt = (*recordStmt)(nil)
+
+type recordStmt struct {
+	conn  *recordConn
+	base  driver.Stmt
+	query string
+}
+
+func (s *recordStmt) Close() error {
+	return s.base.Close()
+}
+
+func (s *recordStmt) NumInput() int {
+	return s.base.NumInput()
+}
+
+func (s *recordStmt) Exec(args []driver.Value) (driver.Result, error) {
+	res, err := s.base.Exec(args) //nolint:staticcheck
+	return s.conn.rec.recordResult(s.query, toNamedValues(args), res, err)
+}
+
+func (s *recordStmt) Query(args []driver.Value) (driver.Rows, error) {
+	rs, err := s.base.Query(args) //nolint:staticcheck
+	return s.conn.rec.recordRows(s.query, toNamedValues(args), rs, err)
+}
+
+func (s *recordStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	res, err := stmtExec(ctx, s.base, args)
+	return s.conn.rec.recordResult(s.query, args, res, err)
+}
+
+func (s *recordStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	rs, err := stmtQuery(ctx, s.base, args)
+	return s.conn.rec.recordRows(s.query, args, rs, err)
+}
+
+func toNamedValues(args []driver.Value) []driver.NamedValue {
+	result := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		result[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return result
+}
+
+var _ driver.Tx = (*recordTx)(nil)
+
+type recordTx struct {
+	rec  *Recorder
+	base driver.Tx
+}
+
+func (t *recordTx) Commit() error {
+	err := t.base.Commit()
+	t.rec.add(Record{Action: ActionCommit, Error: errString(err)})
+	return err
+}
+
+func (t *recordTx) Rollback() error {
+	err := t.base.Rollback()
+	t.rec.add(Record{Action: ActionRollback, Error: errString(err)})
+	return err
+}
@@ -0,0 +1,69 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xtdr_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/store/xdb/xtdr"
+	"github.com/xanygo/anygo/xt"
+)
+
+func TestRecordReplay(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "testdata", "user.json")
+	now := time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)
+
+	run := func(t *testing.T, db *sql.DB) {
+		tx, err := db.Begin()
+		xt.NoError(t, err)
+		ret, err := tx.Exec("insert into user(name,created) values(?,?)", "hello", now)
+		xt.NoError(t, err)
+		id, _ := ret.LastInsertId()
+		xt.Equal(t, id, 7)
+		xt.NoError(t, tx.Commit())
+
+		var userName string
+		var created time.Time
+		var score sql.NullFloat64
+		err = db.QueryRow("select name,created,score from user where id=?", id).Scan(&userName, &created, &score)
+		xt.NoError(t, err)
+		xt.Equal(t, userName, "hello")
+		xt.True(t, created.Equal(now))
+		xt.False(t, score.Valid)
+	}
+
+	// 录制：使用默认的 Mock 模拟真实的数据库
+	xtdr.Reset()
+	defer xtdr.Reset()
+	xtdr.ExpectExec("wc:insert *", xtdr.ResultOf(7, 1), nil)
+	xtdr.ExpectQuery("wc:select *", []string{"name", "created", "score"}, [][]any{{"hello", now, nil}})
+	db, done, err := xtdr.OpenGolden(name, true, &xtdr.Driver{}, "")
+	xt.NoError(t, err)
+	run(t, db)
+	xt.NoError(t, done())
+
+	records, err := xtdr.LoadGolden(name)
+	xt.NoError(t, err)
+	xt.Len(t, records, 4)
+	xt.Equal(t, records[0].Action, xtdr.ActionBegin)
+	xt.Equal(t, records[1].Args[0].V, "hello")
+	xt.Equal(t, records[3].Rows[0][2].V, nil)
+
+	// 回放
+	db, done, err = xtdr.OpenGolden(name, false, nil, "")
+	xt.NoError(t, err)
+	run(t, db)
+	xt.NoError(t, done())
+
+	// 回放时请求和录制的不一致
+	db, done, err = xtdr.OpenGolden(name, false, nil, "")
+	xt.NoError(t, err)
+	_, err = db.Exec("delete from user")
+	xt.Error(t, err)
+	xt.Error(t, done())
+}
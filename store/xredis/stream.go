@@ -0,0 +1,55 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xredis
+
+import (
+	"context"
+	"errors"
+
+	"github.com/xanygo/anygo/store/xredis/resp3"
+)
+
+// XAdd 将一条消息追加到 Stream 中，返回消息的 ID
+//
+// https://redis.io/docs/latest/commands/xadd/
+//
+// id: 消息 ID，一般传入 "*"，由 Redis 自动生成
+// fieldValues: 消息内容，格式为 field1, value1, field2, value2 ...
+func (c *Client) XAdd(ctx context.Context, key string, id string, fieldValues ...string) (string, error) {
+	return c.XAddMaxLen(ctx, key, 0, id, fieldValues...)
+}
+
+// XAddMaxLen 将一条消息追加到 Stream 中，并使用近似裁剪（MAXLEN ~ maxLen）限制 Stream 的长度，返回消息的 ID
+//
+// maxLen: 当 <=0 时，不裁剪
+func (c *Client) XAddMaxLen(ctx context.Context, key string, maxLen int64, id string, fieldValues ...string) (string, error) {
+	if len(fieldValues) == 0 {
+		return "", errNoValues
+	}
+	if len(fieldValues)%2 != 0 {
+		return "", errors.New("fieldValues must be field-value pairs")
+	}
+	args := make([]any, 0, len(fieldValues)+6)
+	args = append(args, "XADD", key)
+	if maxLen > 0 {
+		args = append(args, "MAXLEN", "~", maxLen)
+	}
+	args = append(args, id)
+	for _, v := range fieldValues {
+		args = append(args, v)
+	}
+	cmd := resp3.NewRequest(resp3.DataTypeBulkString, args...)
+	resp := c.do(ctx, cmd)
+	return resp3.ToString(resp.result, resp.err)
+}
+
+// XLen 返回 Stream 中消息的数量，若 key 不存在，返回 0
+//
+// https://redis.io/docs/latest/commands/xlen/
+func (c *Client) XLen(ctx context.Context, key string) (int64, error) {
+	cmd := resp3.NewRequest(resp3.DataTypeInteger, "XLEN", key)
+	resp := c.do(ctx, cmd)
+	return resp3.ToInt64(resp.result, resp.err)
+}
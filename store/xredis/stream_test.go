@@ -0,0 +1,49 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xredis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/internal/redistest"
+	"github.com/xanygo/anygo/xt"
+)
+
+func TestClientStream(t *testing.T) {
+	ts, errTs := redistest.NewServer()
+	if errTs != nil {
+		t.Skipf("create redis-server skipped: %v", errTs)
+		return
+	}
+	defer ts.Stop()
+
+	_, client, errClient := NewClientByURI("demo", ts.URI())
+	xt.NoError(t, errClient)
+	ctx, cancel := context.WithTimeout(t.Context(), time.Minute)
+	defer cancel()
+
+	t.Run("XAdd", func(t *testing.T) {
+		got, err := client.XLen(ctx, "s1")
+		xt.NoError(t, err)
+		xt.Equal(t, got, 0)
+
+		id, err := client.XAdd(ctx, "s1", "*", "f1", "v1", "f2", "v2")
+		xt.NoError(t, err)
+		xt.NotEmpty(t, id)
+
+		id, err = client.XAddMaxLen(ctx, "s1", 100, "*", "f1", "v1")
+		xt.NoError(t, err)
+		xt.NotEmpty(t, id)
+
+		got, err = client.XLen(ctx, "s1")
+		xt.NoError(t, err)
+		xt.Equal(t, got, 2)
+
+		_, err = client.XAdd(ctx, "s1", "*", "f1")
+		xt.Error(t, err)
+	})
+}
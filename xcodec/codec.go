@@ -6,6 +6,7 @@ package xcodec
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"net/url"
@@ -17,6 +18,8 @@ import (
 var (
 	JSON = NewCodec("json", json.Marshal, json.Unmarshal, "application/json")
 
+	XML = NewCodec("xml", xml.Marshal, xml.Unmarshal, "application/xml")
+
 	Raw = NewCodec("raw", rawEncode, rawDecode, "application/octet-stream")
 
 	Form = &FormCodec{}
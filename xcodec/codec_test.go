@@ -100,3 +100,20 @@ func TestEncodeToString(t *testing.T) {
 		xt.Equal(t, string(s1), "hello")
 	})
 }
+
+func TestXML(t *testing.T) {
+	type user struct {
+		Name string `xml:"name,attr"`
+		Age  int    `xml:"age"`
+	}
+	bf, err := XML.Encode(user{Name: "hello", Age: 18})
+	xt.NoError(t, err)
+	xt.Equal(t, string(bf), `<user name="hello"><age>18</age></user>`)
+	ct, err := ContentType(XML)
+	xt.NoError(t, err)
+	xt.Equal(t, ct, "application/xml")
+
+	var got user
+	xt.NoError(t, Decode(XML, bf, &got))
+	xt.Equal(t, got, user{Name: "hello", Age: 18})
+}
@@ -6,23 +6,62 @@ package xhttp
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 
 	"github.com/xanygo/anygo/xcodec"
+	"github.com/xanygo/anygo/xerror"
 	"github.com/xanygo/anygo/xvalidator"
 )
 
+// DefaultMaxMemory 解析 multipart/form-data 时，默认在内存中保存的最大字节数，超出部分存储到临时文件
+const DefaultMaxMemory = 32 << 20
+
 func NewBinder(req *http.Request) *Binder {
 	return &Binder{
 		req: req,
 	}
 }
 
+// Binder 将请求数据绑定到结构体上
+//
+// 支持的结构体 tag：
+//
+//	json:   请求 body 为 application/json 时，使用 JSON 解码
+//	xml:    请求 body 为 application/xml 时，使用 XML 解码
+//	form:   请求 body 为 application/x-www-form-urlencoded 或 multipart/form-data 时的表单字段，
+//	        字段类型为 *multipart.FileHeader 或 []*multipart.FileHeader 时绑定上传的文件
+//	query:  URL 中的 query 参数
+//	path:   路由中的参数，即 http.Request.PathValue
+//	header: 请求头
+//	cookie: 请求的 Cookie
+//
+// tag 的第一个值是参数名称，为空时使用字段名，为 "-" 时忽略。此外还支持如下选项：
+//
+//	default=xxx:    参数不存在时使用的默认值，如 `query:"page,default=1"`，
+//	                BinJSON 和 BindXML 中，只对 body 解码后仍为零值的字段使用默认值
+//	sep=xxx:        将参数值拆分为多个，用于 slice 类型字段，如 `query:"ids,sep=|"`，
+//	                只写 sep 时使用逗号拆分，如 `query:"ids,sep"`
+//	max_size=xxx:   上传文件的大小上限，支持 KB、MB、GB 后缀，如 `form:"avatar,max_size=2MB"`
+//
+// 一个字段可以同时有多个 tag，按照 path、query、header、cookie、form 的顺序，使用第一个存在的参数值。
+// 没有任何上述 tag 的结构体字段（如匿名嵌入字段），会递归绑定其内部字段。
+//
+// 绑定完成后会使用 xvalidator.Validate 对 obj 进行校验。
 type Binder struct {
+	// MaxMemory 可选，解析 multipart/form-data 时在内存中保存的最大字节数，默认为 DefaultMaxMemory
+	MaxMemory int64
+
+	// MaxFileSize 可选，上传的单个文件的大小上限，<=0 时不限制。字段 tag 中的 max_size 优先
+	MaxFileSize int64
+
 	req *http.Request
 
 	once sync.Once
@@ -43,14 +82,34 @@ func (b *Binder) readBody() ([]byte, error) {
 	return b.body, b.err
 }
 
+// Bind 依据请求的 Content-Type 选择绑定方式：
+//
+//	application/json:                  BinJSON
+//	application/xml、text/xml:          BindXML
+//	application/x-www-form-urlencoded: BindForm
+//	multipart/form-data:               BindMultipart
+//	无 body（无 Content-Type）:          BindParams
 func (b *Binder) Bind(obj any) error {
 	contentType := b.req.Header.Get("Content-Type")
-	if strings.HasPrefix(contentType, "application/json") {
+	mt, _, _ := mime.ParseMediaType(contentType)
+	switch mt {
+	case "application/json":
 		return b.BinJSON(obj)
+	case "application/xml", "text/xml":
+		return b.BindXML(obj)
+	case "application/x-www-form-urlencoded":
+		return b.BindForm(obj)
+	case "multipart/form-data":
+		return b.BindMultipart(obj)
+	case "":
+		if contentType == "" {
+			return b.BindParams(obj)
+		}
 	}
 	return fmt.Errorf("not support Content-Type: %s", contentType)
 }
 
+// BinJSON 使用 JSON 解码请求 body，然后绑定 path、query、header、cookie 参数
 func (b *Binder) BinJSON(obj any) error {
 	data, err := b.readBody()
 	if err != nil {
@@ -60,23 +119,181 @@ func (b *Binder) BinJSON(obj any) error {
 	if err != nil {
 		return err
 	}
+	return b.bindDecoded(obj)
+}
+
+// BindXML 使用 XML 解码请求 body，然后绑定 path、query、header、cookie 参数
+func (b *Binder) BindXML(obj any) error {
+	data, err := b.readBody()
+	if err != nil {
+		return err
+	}
+	err = xcodec.Decode(xcodec.XML, data, obj)
+	if err != nil {
+		return err
+	}
+	return b.bindDecoded(obj)
+}
+
+// BindParams 绑定 path、query、header、cookie 参数
+func (b *Binder) BindParams(obj any) error {
+	return b.bind(obj, b.paramSources(), nil)
+}
+
+// BindForm 绑定 application/x-www-form-urlencoded 表单，以及 path、query、header、cookie 参数
+func (b *Binder) BindForm(obj any) error {
+	data, err := b.readBody()
+	if err != nil {
+		return err
+	}
+	form, err := url.ParseQuery(string(data))
+	if err != nil {
+		return fmt.Errorf("%w: %w", xerror.InvalidParam, err)
+	}
+	sources := append(b.paramSources(), valuesSource("form", form))
+	return b.bind(obj, sources, nil)
+}
+
+// BindMultipart 绑定 multipart/form-data 表单（包括上传的文件），以及 path、query、header、cookie 参数
+func (b *Binder) BindMultipart(obj any) error {
+	if b.req.MultipartForm == nil {
+		maxMemory := b.MaxMemory
+		if maxMemory <= 0 {
+			maxMemory = DefaultMaxMemory
+		}
+		if err := b.req.ParseMultipartForm(maxMemory); err != nil {
+			return fmt.Errorf("%w: %w", xerror.InvalidParam, err)
+		}
+	}
+	form := b.req.MultipartForm
+	sources := append(b.paramSources(), valuesSource("form", form.Value))
+	return b.bind(obj, sources, form.File)
+}
+
+func (b *Binder) paramSources() []bindSource {
+	req := b.req
+	return []bindSource{
+		{
+			tag: "path",
+			lookup: func(name string) ([]string, bool) {
+				v := req.PathValue(name)
+				return []string{v}, v != ""
+			},
+		},
+		valuesSource("query", req.URL.Query()),
+		{
+			tag: "header",
+			lookup: func(name string) ([]string, bool) {
+				vs := req.Header.Values(name)
+				return vs, len(vs) > 0
+			},
+		},
+		{
+			tag: "cookie",
+			lookup: func(name string) ([]string, bool) {
+				c, err := req.Cookie(name)
+				if err != nil {
+					return nil, false
+				}
+				return []string{c.Value}, true
+			},
+		},
+	}
+}
+
+func (b *Binder) bind(obj any, sources []bindSource, files map[string][]*multipart.FileHeader) error {
+	fb := &fieldBinder{
+		sources:     sources,
+		files:       files,
+		maxFileSize: b.MaxFileSize,
+	}
+	return b.doBind(obj, fb)
+}
+
+// bindDecoded 在 body 解码之后绑定 path、query、header、cookie 参数，
+// 默认值只用于仍为零值的字段，以免覆盖 body 中的值
+func (b *Binder) bindDecoded(obj any) error {
+	fb := &fieldBinder{
+		sources:     b.paramSources(),
+		maxFileSize: b.MaxFileSize,
+		keepNonZero: true,
+	}
+	return b.doBind(obj, fb)
+}
+
+func (b *Binder) doBind(obj any, fb *fieldBinder) error {
+	if err := fb.bind(obj); err != nil {
+		return err
+	}
 	return xvalidator.Validate(obj)
 }
 
+// Bind 将请求数据绑定到 obj 上，详见 Binder
 func Bind(r *http.Request, obj any) error {
-	ct := r.Header.Get("Content-Type")
-	if strings.HasPrefix(ct, "application/json") {
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			return err
+	return NewBinder(r).Bind(obj)
+}
+
+var _ error = (*BindError)(nil)
+
+// BindError 绑定请求参数失败的错误，包含所有失败字段的详情。
+//
+// 可使用 xerror.IsInvalidParam 判断
+type BindError struct {
+	Fields []*FieldError
+}
+
+func (e *BindError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("bind failed: ")
+	for i, f := range e.Fields {
+		if i > 0 {
+			sb.WriteString("; ")
 		}
-		r.Body = io.NopCloser(bytes.NewBuffer(body))
-		err = xcodec.Decode(xcodec.JSON, body, obj)
-		if err != nil {
-			return err
+		sb.WriteString(f.Error())
+	}
+	return sb.String()
+}
+
+func (e *BindError) Is(target error) bool {
+	return target == xerror.InvalidParam
+}
+
+func (e *BindError) Unwrap() []error {
+	errs := make([]error, len(e.Fields))
+	for i, f := range e.Fields {
+		errs[i] = f
+	}
+	return errs
+}
+
+// Field 查找指定结构体字段（如 "Address.City"）的错误，不存在时返回 nil
+func (e *BindError) Field(field string) *FieldError {
+	for _, f := range e.Fields {
+		if f.Field == field {
+			return f
 		}
-	} else {
-		return fmt.Errorf("not support Content-Type: %s", ct)
 	}
-	return xvalidator.Validate(obj)
+	return nil
 }
+
+var _ error = (*FieldError)(nil)
+
+// FieldError 单个字段的绑定错误
+type FieldError struct {
+	Field  string // 结构体字段路径，如 "Address.City"
+	Source string // 参数来源：form、query、path、header、cookie
+	Name   string // 参数名称
+	Value  string // 原始的参数值
+	Err    error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s %q (field %s): %v", e.Source, e.Name, e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ErrFileTooLarge 上传的文件超出大小限制
+var ErrFileTooLarge = errors.New("file too large")
@@ -0,0 +1,360 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttp
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xcast"
+	"github.com/xanygo/anygo/ds/xstruct"
+	"github.com/xanygo/anygo/ds/xurl"
+)
+
+// bindSource 一种参数来源，tag 为对应的结构体 tag 名称
+type bindSource struct {
+	tag    string
+	lookup func(name string) ([]string, bool)
+}
+
+func valuesSource(tag string, values url.Values) bindSource {
+	return bindSource{
+		tag: tag,
+		lookup: func(name string) ([]string, bool) {
+			vs, ok := values[name]
+			return vs, ok && len(vs) > 0
+		},
+	}
+}
+
+var (
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	fileHeaderType      = reflect.TypeFor[*multipart.FileHeader]()
+	fileHeadersType     = reflect.TypeFor[[]*multipart.FileHeader]()
+	durationType        = reflect.TypeFor[time.Duration]()
+)
+
+type fieldBinder struct {
+	sources     []bindSource
+	files       map[string][]*multipart.FileHeader
+	maxFileSize int64
+	keepNonZero bool // 为 true 时，不对非零值的字段使用默认值，用于 body 解码之后
+	errs        []*FieldError
+}
+
+func (fb *fieldBinder) bind(obj any) error {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind target must be a non-nil pointer to struct, got %T", obj)
+	}
+	fb.bindStruct(rv.Elem(), "")
+	if len(fb.errs) > 0 {
+		return &BindError{Fields: fb.errs}
+	}
+	return nil
+}
+
+// bindStruct 绑定结构体的所有字段，返回是否有字段被赋值
+func (fb *fieldBinder) bindStruct(rv reflect.Value, prefix string) bool {
+	var bound bool
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() && !(sf.Anonymous && sf.Type.Kind() == reflect.Struct) {
+			continue
+		}
+		fv := rv.Field(i)
+		path := prefix + sf.Name
+		if fb.bindField(sf, fv, path) {
+			bound = true
+			continue
+		}
+		if fb.hasTag(sf) || !isNestedStruct(sf.Type) {
+			continue
+		}
+		// 匿名嵌入字段的字段路径和外层相同
+		subPrefix := path + "."
+		if sf.Anonymous {
+			subPrefix = prefix
+		}
+		if sf.Type.Kind() != reflect.Pointer {
+			bound = fb.bindStruct(fv, subPrefix) || bound
+			continue
+		}
+		if !fv.IsNil() {
+			bound = fb.bindStruct(fv.Elem(), subPrefix) || bound
+			continue
+		}
+		if !fv.CanSet() {
+			continue
+		}
+		// 指针类型的字段，只有在有字段被赋值时才创建
+		nv := reflect.New(sf.Type.Elem())
+		if fb.bindStruct(nv.Elem(), subPrefix) {
+			fv.Set(nv)
+			bound = true
+		}
+	}
+	return bound
+}
+
+func (fb *fieldBinder) hasTag(sf reflect.StructField) bool {
+	for _, src := range fb.sources {
+		if _, ok := sf.Tag.Lookup(src.tag); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// bindField 绑定单个字段，返回是否找到了对应的参数（或默认值）
+func (fb *fieldBinder) bindField(sf reflect.StructField, fv reflect.Value, path string) bool {
+	var defTag *xstruct.Tag
+	var defSource string
+	for _, src := range fb.sources {
+		raw, ok := sf.Tag.Lookup(src.tag)
+		if !ok || raw == "-" {
+			continue
+		}
+		tag := xstruct.ParserTagCached(sf.Tag, src.tag)
+		name := tag.Name()
+		if name == "" {
+			name = sf.Name
+		}
+		if src.tag == "form" && (sf.Type == fileHeaderType || sf.Type == fileHeadersType) {
+			if fb.bindFiles(tag, name, fv, path) {
+				return true
+			}
+			continue
+		}
+		sep, hasSep := tagSep(tag)
+		if hasSep && !isSepSlice(sf.Type) {
+			fb.addError(path, src.tag, name, "", fmt.Errorf("sep option requires a slice field, got %s", sf.Type))
+			return true
+		}
+		values, found := src.lookup(name)
+		if found && hasSep {
+			values = xurl.Strings(url.Values{name: {strings.Join(values, sep)}}, name, sep)
+			// 值为空或者只有分隔符时，和参数不存在相同
+			found = len(values) > 0
+		}
+		if !found {
+			if defTag == nil && tag.Has("default") {
+				defTag = &tag
+				defSource = src.tag
+			}
+			continue
+		}
+		fb.set(fv, values, src.tag, name, path)
+		return true
+	}
+	if defTag == nil || (fb.keepNonZero && !fv.IsZero()) {
+		return false
+	}
+	name := defTag.Name()
+	if name == "" {
+		name = sf.Name
+	}
+	values := []string{defTag.Value("default")}
+	if sep, ok := tagSep(*defTag); ok {
+		values = xurl.Strings(url.Values{name: values}, name, sep)
+	}
+	fb.set(fv, values, defSource, name, path)
+	return true
+}
+
+// isSepSlice 是否可以使用 sep 选项的字段类型：除 []byte 外的切片
+func isSepSlice(rt reflect.Type) bool {
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	return rt.Kind() == reflect.Slice && rt.Elem().Kind() != reflect.Uint8
+}
+
+// tagSep 读取 tag 中的 sep 选项，值为空时使用逗号
+func tagSep(tag xstruct.Tag) (string, bool) {
+	sep, ok := tag.Get("sep")
+	if ok && sep == "" {
+		sep = ","
+	}
+	return sep, ok
+}
+
+func (fb *fieldBinder) bindFiles(tag xstruct.Tag, name string, fv reflect.Value, path string) bool {
+	files := fb.files[name]
+	if len(files) == 0 {
+		return false
+	}
+	limit := fb.maxFileSize
+	if str := tag.Value("max_size"); str != "" {
+		size, err := parseByteSize(str)
+		if err != nil {
+			fb.addError(path, "form", name, str, err)
+			return true
+		}
+		limit = size
+	}
+	if limit > 0 {
+		for _, f := range files {
+			if f.Size > limit {
+				err := fmt.Errorf("%w: %s is %d bytes, limit %d", ErrFileTooLarge, f.Filename, f.Size, limit)
+				fb.addError(path, "form", name, f.Filename, err)
+				return true
+			}
+		}
+	}
+	if fv.Type() == fileHeaderType {
+		fv.Set(reflect.ValueOf(files[0]))
+	} else {
+		fv.Set(reflect.ValueOf(files))
+	}
+	return true
+}
+
+func (fb *fieldBinder) set(fv reflect.Value, values []string, source string, name string, path string) {
+	if err := setValues(fv, values); err != nil {
+		fb.addError(path, source, name, strings.Join(values, ","), err)
+	}
+}
+
+func (fb *fieldBinder) addError(field, source, name, value string, err error) {
+	fb.errs = append(fb.errs, &FieldError{
+		Field:  field,
+		Source: source,
+		Name:   name,
+		Value:  value,
+		Err:    err,
+	})
+}
+
+// isNestedStruct 是否可以递归绑定的结构体类型
+func isNestedStruct(rt reflect.Type) bool {
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct {
+		return false
+	}
+	return !reflect.PointerTo(rt).Implements(textUnmarshalerType)
+}
+
+func setValues(fv reflect.Value, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	if fv.Kind() == reflect.Pointer {
+		nv := reflect.New(fv.Type().Elem())
+		if err := setValues(nv.Elem(), values); err != nil {
+			return err
+		}
+		fv.Set(nv)
+		return nil
+	}
+	if fv.CanAddr() && fv.Addr().Type().Implements(textUnmarshalerType) {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(values[0]))
+	}
+	if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+		sv := reflect.MakeSlice(fv.Type(), len(values), len(values))
+		for i, v := range values {
+			if err := setValues(sv.Index(i), []string{v}); err != nil {
+				return err
+			}
+		}
+		fv.Set(sv)
+		return nil
+	}
+	return setScalar(fv, values[0])
+}
+
+func setScalar(fv reflect.Value, str string) error {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(str)
+		return nil
+	case reflect.Slice:
+		// []byte
+		fv.SetBytes([]byte(str))
+		return nil
+	}
+	if str == "" {
+		// 空值（如未填写的表单项）保持零值
+		fv.SetZero()
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.Bool:
+		if str == "on" {
+			fv.SetBool(true)
+			return nil
+		}
+		b, ok := xcast.Bool(str)
+		if !ok {
+			return fmt.Errorf("invalid bool value %q", str)
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if fv.Type() == durationType {
+			d, err := time.ParseDuration(str)
+			if err != nil {
+				return err
+			}
+			fv.SetInt(int64(d))
+			return nil
+		}
+		n, ok := xcast.Integer[int64](str)
+		if !ok || fv.OverflowInt(n) {
+			return fmt.Errorf("invalid %s value %q", fv.Type(), str)
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := xcast.Integer[uint64](str)
+		if !ok || fv.OverflowUint(n) {
+			return fmt.Errorf("invalid %s value %q", fv.Type(), str)
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, ok := xcast.Float[float64](str)
+		if !ok || fv.OverflowFloat(f) {
+			return fmt.Errorf("invalid %s value %q", fv.Type(), str)
+		}
+		fv.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
+
+// parseByteSize 解析字节数，支持 KB、MB、GB 后缀（不区分大小写），如 "512", "100KB", "2MB"
+func parseByteSize(str string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(str))
+	unit := int64(1)
+	for _, u := range []struct {
+		suffix string
+		size   int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(s, u.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, u.suffix))
+			unit = u.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, errors.New("invalid max_size " + strconv.Quote(str))
+	}
+	return n * unit, nil
+}
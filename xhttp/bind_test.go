@@ -0,0 +1,199 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttp_test
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/xerror"
+	"github.com/xanygo/anygo/xhttp"
+	"github.com/xanygo/anygo/xt"
+)
+
+type bindPage struct {
+	Page int `query:"page,default=1"`
+	Size int `query:"size,default=20"`
+}
+
+type bindUser struct {
+	bindPage
+	ID      int64         `path:"id"`
+	Name    string        `form:"name"`
+	Age     *int          `form:"age"`
+	Agree   bool          `form:"agree"`
+	Tags    []string      `form:"tag"`
+	IDs     []int         `query:"ids,sep"`
+	Token   string        `header:"X-Token"`
+	Session string        `cookie:"sid"`
+	Timeout time.Duration `query:"timeout"`
+	Since   time.Time     `query:"since"`
+	Lang    string        `header:"Accept-Language" query:"lang"`
+	Ignore  string        `form:"-"`
+}
+
+func TestBinder_BindForm(t *testing.T) {
+	body := "name=hello&age=18&agree=on&tag=a&tag=b&Ignore=x"
+	req := httptest.NewRequest(http.MethodPost, "/user/100?ids=1,2,3&size=5&timeout=2s&since=2026-01-02T03:04:05Z&lang=en", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Token", "tk")
+	req.AddCookie(&http.Cookie{Name: "sid", Value: "s1"})
+	req.SetPathValue("id", "100")
+
+	var u bindUser
+	xt.NoError(t, xhttp.Bind(req, &u))
+	xt.Equal(t, u.ID, 100)
+	xt.Equal(t, u.Name, "hello")
+	xt.Equal(t, *u.Age, 18)
+	xt.True(t, u.Agree)
+	xt.Equal(t, u.Tags, []string{"a", "b"})
+	xt.Equal(t, u.IDs, []int{1, 2, 3})
+	xt.Equal(t, u.Token, "tk")
+	xt.Equal(t, u.Session, "s1")
+	xt.Equal(t, u.Timeout, 2*time.Second)
+	xt.Equal(t, u.Since.Unix(), time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).Unix())
+	xt.Equal(t, u.Lang, "en")
+	xt.Equal(t, u.Ignore, "")
+	xt.Equal(t, u.Page, 1)
+	xt.Equal(t, u.Size, 5)
+}
+
+func TestBinder_BindError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/?page=abc&size=1&ids=1,x", nil)
+	var u bindUser
+	err := xhttp.Bind(req, &u)
+	xt.Error(t, err)
+	xt.True(t, xerror.IsInvalidParam(err))
+
+	var be *xhttp.BindError
+	xt.True(t, errors.As(err, &be))
+	xt.Len(t, be.Fields, 2)
+	xt.Equal(t, be.Field("Page").Source, "query")
+	xt.Equal(t, be.Field("Page").Value, "abc")
+	xt.Equal(t, be.Field("IDs").Name, "ids")
+	xt.Nil(t, be.Field("Size"))
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("a"))
+	req.Header.Set("Content-Type", "text/plain")
+	xt.ErrorContains(t, xhttp.Bind(req, &u), "not support Content-Type")
+}
+
+func TestBinder_BindJSON(t *testing.T) {
+	type request struct {
+		Name  string `json:"name"`
+		Token string `json:"-" header:"X-Token"`
+		Page  int    `json:"-" query:"page"`
+	}
+	req := httptest.NewRequest(http.MethodPost, "/?page=3", strings.NewReader(`{"name":"hello"}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Token", "tk")
+	var r request
+	xt.NoError(t, xhttp.Bind(req, &r))
+	xt.Equal(t, r, request{Name: "hello", Token: "tk", Page: 3})
+
+	// 默认值不会覆盖 body 中的值
+	type listRequest struct {
+		Page int    `json:"page" query:"page,default=1"`
+		Size int    `json:"size" query:"size,default=20"`
+		Sort string `json:"sort" query:"sort,default=id"`
+	}
+	req = httptest.NewRequest(http.MethodPost, "/?sort=name", strings.NewReader(`{"page":2,"sort":"time"}`))
+	req.Header.Set("Content-Type", "application/json")
+	var lr listRequest
+	xt.NoError(t, xhttp.Bind(req, &lr))
+	xt.Equal(t, lr, listRequest{Page: 2, Size: 20, Sort: "name"})
+}
+
+func TestBinder_BindMultipart(t *testing.T) {
+	type upload struct {
+		Title  string                  `form:"title"`
+		Avatar *multipart.FileHeader   `form:"avatar,max_size=1KB"`
+		Files  []*multipart.FileHeader `form:"file"`
+		Addr   *struct {
+			City string `form:"city"`
+		}
+		Other *struct {
+			Zip string `form:"zip"`
+		}
+	}
+	newRequest := func(avatarSize int) *http.Request {
+		bf := &bytes.Buffer{}
+		mw := multipart.NewWriter(bf)
+		_ = mw.WriteField("title", "hello")
+		_ = mw.WriteField("city", "bj")
+		fw, _ := mw.CreateFormFile("avatar", "a.png")
+		_, _ = fw.Write(bytes.Repeat([]byte("a"), avatarSize))
+		for _, name := range []string{"1.txt", "2.txt"} {
+			fw, _ = mw.CreateFormFile("file", name)
+			_, _ = fw.Write([]byte(name))
+		}
+		_ = mw.Close()
+		req := httptest.NewRequest(http.MethodPost, "/upload", bf)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		return req
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		var u upload
+		xt.NoError(t, xhttp.Bind(newRequest(100), &u))
+		xt.Equal(t, u.Title, "hello")
+		xt.Equal(t, u.Avatar.Filename, "a.png")
+		xt.Equal(t, u.Avatar.Size, 100)
+		xt.Len(t, u.Files, 2)
+		xt.Equal(t, u.Files[1].Filename, "2.txt")
+		xt.Equal(t, u.Addr.City, "bj")
+		xt.Nil(t, u.Other)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		var u upload
+		err := xhttp.Bind(newRequest(2048), &u)
+		xt.ErrorIs(t, err, xhttp.ErrFileTooLarge)
+		xt.True(t, xerror.IsInvalidParam(err))
+	})
+
+	t.Run("binder limit", func(t *testing.T) {
+		var u upload
+		b := xhttp.NewBinder(newRequest(10))
+		b.MaxFileSize = 3
+		err := b.Bind(&u)
+		var be *xhttp.BindError
+		xt.True(t, errors.As(err, &be))
+		xt.Len(t, be.Fields, 1)
+		xt.Equal(t, be.Fields[0].Field, "Files")
+	})
+}
+
+func TestBinder_BindSep(t *testing.T) {
+	type request struct {
+		IDs  []int    `query:"ids,sep"`
+		Tags []string `query:"tags,sep=|,default=a|b"`
+	}
+	for _, query := range []string{"ids=&tags=", "ids=,,&tags=||"} {
+		req := httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+		var r request
+		xt.NoError(t, xhttp.Bind(req, &r))
+		xt.Empty(t, r.IDs)
+		xt.Equal(t, r.Tags, []string{"a", "b"})
+	}
+
+	type invalid struct {
+		Name string `query:"name,sep"`
+		Age  *int   `query:"age,sep"`
+	}
+	req := httptest.NewRequest(http.MethodGet, "/?name=&age=", nil)
+	var r invalid
+	err := xhttp.Bind(req, &r)
+	var be *xhttp.BindError
+	xt.True(t, errors.As(err, &be))
+	xt.Len(t, be.Fields, 2)
+	xt.ErrorContains(t, be.Field("Name"), "requires a slice field")
+}
@@ -354,30 +354,7 @@ func parserRegexpPattern(pattern string) (string, error) {
 		regPatternNew.WriteString(regexp.QuoteMeta(pattern[:leftIndex]))
 		names[name] = true
 		if ok { // {id:[0-9]+} 、{id:*}
-			if txt, ok1 := regexpAlias[reg]; ok1 {
-				reg = txt
-			} else {
-				switch reg {
-				case "*":
-					reg = ".*"
-				case "UUID":
-					reg = uuidReg
-				case "Base62":
-					reg = `[0-9a-zA-Z]+`
-				case "Base36":
-					reg = `[0-9a-z]+`
-				case "Base58":
-					reg = `[1-9A-HJ-NP-Za-km-z]+`
-				case "Base64URL":
-					reg = `[0-9a-zA-Z\-_]+`
-				case "UINT":
-					reg = `0|[1-9][0-9]*`
-				case "INT":
-					reg = `[-]?(0|[1-9][0-9]*)`
-				case "NZUInt": // > 0
-					reg = `[1-9][0-9]*`
-				}
-			}
+			reg = ResolveRegexp(reg)
 			regPatternNew.WriteString(fmt.Sprintf("(?P<%s>%s)", name, reg))
 		} else { // {id}
 			regPatternNew.WriteString(fmt.Sprintf("(?P<%s>%s)", name, "[^/]+"))
@@ -387,6 +364,35 @@ func parserRegexpPattern(pattern string) (string, error) {
 	return regPatternNew.String(), nil
 }
 
+// ResolveRegexp 将路由变量中的正则别名（如 UINT、UUID、* 以及使用 RegisterRegexpAlias 注册的）转换为正则表达式，
+// 不是别名时原样返回
+func ResolveRegexp(reg string) string {
+	if txt, ok := regexpAlias[reg]; ok {
+		return txt
+	}
+	switch reg {
+	case "*":
+		return ".*"
+	case "UUID":
+		return uuidReg
+	case "Base62":
+		return `[0-9a-zA-Z]+`
+	case "Base36":
+		return `[0-9a-z]+`
+	case "Base58":
+		return `[1-9A-HJ-NP-Za-km-z]+`
+	case "Base64URL":
+		return `[0-9a-zA-Z\-_]+`
+	case "UINT":
+		return `0|[1-9][0-9]*`
+	case "INT":
+		return `[-]?(0|[1-9][0-9]*)`
+	case "NZUInt": // > 0
+		return `[1-9][0-9]*`
+	}
+	return reg
+}
+
 var regexpAlias = map[string]string{}
 
 func RegisterRegexpAlias(name string, reg string) {
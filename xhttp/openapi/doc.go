@@ -0,0 +1,27 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+// Package openapi 依据 xhttp.Router 中注册的路由，生成 OpenAPI 3.1 的接口文档（JSON 格式）。
+//
+// 使用 xhttp.RouteInfo.Describe 给路由添加文档信息（请求、响应的类型和说明等）：
+//
+//	router.Get("/user/{id:UINT}", handler).Describe(xhttp.RouteDoc{
+//		Summary:  "查询用户",
+//		Request:  GetUserRequest{},
+//		Response: User{},
+//	})
+//
+//	spec := &openapi.Spec{Info: openapi.Info{Title: "demo", Version: "1.0"}}
+//	spec.Register(router, "/openapi.json")
+//
+// 请求、响应类型通过反射生成 JSON Schema：
+//
+//   - 请求参数的字段 tag 同 xhttp.Binder：path、query、header、cookie 字段生成为 parameters，
+//     form 字段生成为表单的 requestBody，其他字段按照 encoding/json 的规则生成为 JSON 的 requestBody
+//   - 字段的 validator tag（见 xvalidator.Engine）会转换为 required、minimum、maxLength、enum、pattern 等约束
+//   - 字段的 doc tag 作为字段的说明，如 `doc:"用户 ID"`
+//   - 路由中的变量，如 {id:UINT}、{id:UUID}，会转换为对应类型的 path 参数
+//
+// 路由的 meta 信息中有 openapi=no 时，不会输出到文档中；meta 中的 id 会作为 operationId。
+package openapi
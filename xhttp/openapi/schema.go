@@ -0,0 +1,405 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"mime/multipart"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xstruct"
+	"github.com/xanygo/anygo/xvalidator"
+)
+
+var (
+	timeType            = reflect.TypeFor[time.Time]()
+	rawMessageType      = reflect.TypeFor[json.RawMessage]()
+	fileHeaderType      = reflect.TypeFor[multipart.FileHeader]()
+	jsonMarshalerType   = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// paramTags 生成为 parameters 的字段 tag，同 xhttp.Binder
+var paramTags = []string{"path", "query", "header", "cookie"}
+
+// schemaBuilder 使用反射生成 JSON Schema，具名的结构体会放到 components 中，并使用 $ref 引用
+type schemaBuilder struct {
+	schemas   map[string]*Schema
+	names     map[reflect.Type]string
+	used      map[string]reflect.Type
+	refPrefix string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		schemas:   map[string]*Schema{},
+		names:     map[reflect.Type]string{},
+		used:      map[string]reflect.Type{},
+		refPrefix: "#/components/schemas/",
+	}
+}
+
+// JSONSchema 使用反射生成 v 的类型的 JSON Schema（2020-12），规则同 Spec（字段名同 encoding/json，
+// 包括 doc 和 validator tag 中的信息），可用于 MCP 工具的 inputSchema 等场景。
+// 根类型为结构体时，会直接展开，其他具名的结构体放在 $defs 中，并使用 $ref 引用
+func JSONSchema(v any) *Schema {
+	rt := reflect.TypeOf(v)
+	if rt == nil {
+		return &Schema{}
+	}
+	b := newSchemaBuilder()
+	b.refPrefix = "#/$defs/"
+	s := b.schemaOf(rt)
+	if ref, ok := strings.CutPrefix(s.Ref, b.refPrefix); ok {
+		root := *b.schemas[ref]
+		s = &root
+		if !b.isReferenced(b.refPrefix + ref) {
+			delete(b.schemas, ref)
+		}
+	}
+	if len(b.schemas) > 0 {
+		s.Defs = b.schemas
+	}
+	return s
+}
+
+// isReferenced 判断是否有 Schema 使用了 ref
+func (b *schemaBuilder) isReferenced(ref string) bool {
+	bf, _ := json.Marshal(b.schemas)
+	return strings.Contains(string(bf), strconv.Quote(ref))
+}
+
+func derefType(rt reflect.Type) reflect.Type {
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	return rt
+}
+
+func (b *schemaBuilder) schemaOf(rt reflect.Type) *Schema {
+	rt = derefType(rt)
+	switch rt {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	case fileHeaderType:
+		return &Schema{Type: "string", Format: "binary"}
+	}
+	if rt.Implements(jsonMarshalerType) || reflect.PointerTo(rt).Implements(jsonMarshalerType) {
+		return &Schema{}
+	}
+	if rt.Implements(textMarshalerType) || reflect.PointerTo(rt).Implements(textMarshalerType) {
+		return &Schema{Type: "string"}
+	}
+
+	switch rt.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer", Minimum: ptrOf(0.0)}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if rt.Elem().Kind() == reflect.Uint8 && rt.Kind() == reflect.Slice {
+			// 和 encoding/json 一致，[]byte 编码为 base64 字符串
+			return &Schema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &Schema{Type: "array", Items: b.schemaOf(rt.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schemaOf(rt.Elem())}
+	case reflect.Struct:
+		if rt.Name() == "" {
+			return b.structSchema(rt, false)
+		}
+		return &Schema{Ref: b.refPrefix + b.component(rt)}
+	default:
+		// interface 等类型，可以是任意值
+		return &Schema{}
+	}
+}
+
+// component 将具名的结构体放到 components 中，返回其名称
+func (b *schemaBuilder) component(rt reflect.Type) string {
+	if name, ok := b.names[rt]; ok {
+		return name
+	}
+	name := b.componentName(rt)
+	b.names[rt] = name
+	b.used[name] = rt
+
+	// 先占位，以支持递归引用的结构体
+	s := &Schema{}
+	b.schemas[name] = s
+	*s = *b.structSchema(rt, false)
+	return name
+}
+
+// genericPkgReg 泛型类型名称中的包路径，如 Page[github.com/a/b.User] 中的 "github.com/a/"
+var genericPkgReg = regexp.MustCompile(`[\w.\-]+/`)
+
+var invalidNameReg = regexp.MustCompile(`[^a-zA-Z0-9._\-]+`)
+
+func (b *schemaBuilder) componentName(rt reflect.Type) string {
+	name := rt.Name()
+	name = genericPkgReg.ReplaceAllString(name, "")
+	name = strings.Trim(invalidNameReg.ReplaceAllString(name, "_"), "_")
+	if _, ok := b.used[name]; !ok {
+		return name
+	}
+	// 不同包中的同名类型，使用包名区分
+	if pkg := path.Base(rt.PkgPath()); pkg != "" && pkg != "." {
+		name = pkg + "." + name
+	}
+	base := name
+	for i := 2; ; i++ {
+		if _, ok := b.used[name]; !ok {
+			return name
+		}
+		name = base + strconv.Itoa(i)
+	}
+}
+
+// structSchema 生成结构体的 Schema，字段按照 encoding/json 的规则处理。
+// skipParams 为 true 时，忽略 path、query、header、cookie、form 字段（用于生成请求的 JSON body）
+func (b *schemaBuilder) structSchema(rt reflect.Type, skipParams bool) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	b.addFields(s, rt, skipParams)
+	return s
+}
+
+func (b *schemaBuilder) addFields(s *Schema, rt reflect.Type, skipParams bool) {
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if skipParams && (hasParamTag(sf) || hasTag(sf, "form")) {
+			continue
+		}
+		tag := xstruct.ParserTagCached(sf.Tag, "json")
+		name := tag.Name()
+		if sf.Tag.Get("json") == "-" {
+			continue
+		}
+		if sf.Anonymous && name == "" {
+			if ft := derefType(sf.Type); ft.Kind() == reflect.Struct {
+				b.addFields(s, ft, skipParams)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fs := b.fieldSchema(sf)
+		if tag.Has("string") {
+			// `json:",string"` 的数字、bool 字段，编码为字符串
+			switch fs.Type {
+			case "integer", "number", "boolean":
+				fs = &Schema{Type: "string", Description: fs.Description}
+			}
+		}
+		s.Properties[name] = fs
+		if isRequired(sf) {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// fieldSchema 生成结构体字段的 Schema，包括字段的 doc 和 validator tag 中的信息
+func (b *schemaBuilder) fieldSchema(sf reflect.StructField) *Schema {
+	s := b.schemaOf(sf.Type)
+	s.Description = sf.Tag.Get("doc")
+	applyRules(s, sf.Type, sf.Tag.Get(xvalidator.DefaultTagName))
+	return s
+}
+
+func hasTag(sf reflect.StructField, name string) bool {
+	_, ok := sf.Tag.Lookup(name)
+	return ok
+}
+
+func hasParamTag(sf reflect.StructField) bool {
+	for _, name := range paramTags {
+		if hasTag(sf, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNestedStruct 同 xhttp.Binder，没有 tag 的结构体字段，会递归绑定其内部的字段
+func isNestedStruct(rt reflect.Type) bool {
+	rt = derefType(rt)
+	if rt.Kind() != reflect.Struct {
+		return false
+	}
+	return !reflect.PointerTo(rt).Implements(textUnmarshalerType)
+}
+
+// validatorRules 解析字段的 validator tag，返回字段自身的规则和 dive 之后（用于 slice、map 元素）的规则。
+// 使用 | 连接的多个规则（满足任意一个即可）无法转换为 Schema 的约束，会被忽略
+func validatorRules(tag string) (rules [][2]string, dive string) {
+	items := strings.Split(tag, ",")
+	for i, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "dive" {
+			return rules, strings.Join(items[i+1:], ",")
+		}
+		if item == "" || item == "omitempty" || strings.Contains(item, "|") {
+			continue
+		}
+		name, param, _ := strings.Cut(item, "=")
+		param = strings.NewReplacer("0x2C", ",", "0x7C", "|").Replace(param)
+		rules = append(rules, [2]string{strings.TrimSpace(name), param})
+	}
+	return rules, ""
+}
+
+func isRequired(sf reflect.StructField) bool {
+	rules, _ := validatorRules(sf.Tag.Get(xvalidator.DefaultTagName))
+	for _, rule := range rules {
+		if rule[0] == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// applyRules 将 validator tag 中的规则转换为 Schema 的约束
+func applyRules(s *Schema, rt reflect.Type, tag string) {
+	if tag == "" {
+		return
+	}
+	rt = derefType(rt)
+	rules, dive := validatorRules(tag)
+	for _, rule := range rules {
+		applyRule(s, rt, rule[0], rule[1])
+	}
+	if dive == "" {
+		return
+	}
+	switch {
+	case s.Items != nil:
+		applyRules(s.Items, rt.Elem(), dive)
+	case s.AdditionalProperties != nil:
+		applyRules(s.AdditionalProperties, rt.Elem(), dive)
+	}
+}
+
+func applyRule(s *Schema, rt reflect.Type, name string, param string) {
+	switch name {
+	case "email":
+		s.Format = "email"
+		return
+	case "url", "http_url":
+		s.Format = "uri"
+		return
+	case "ipv4", "ipv6":
+		s.Format = name
+		return
+	case "regexp":
+		s.Pattern = param
+		return
+	case "oneof":
+		for _, item := range strings.Fields(param) {
+			s.Enum = append(s.Enum, parseValue(rt, item))
+		}
+		return
+	}
+
+	switch s.Type {
+	case "integer", "number":
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			// 如 time.Duration 类型的 "1s"
+			return
+		}
+		switch name {
+		case "len", "eq":
+			s.Minimum, s.Maximum = ptrOf(n), ptrOf(n)
+		case "min", "gte":
+			s.Minimum = ptrOf(n)
+		case "max", "lte":
+			s.Maximum = ptrOf(n)
+		case "gt":
+			s.ExclusiveMinimum = ptrOf(n)
+		case "lt":
+			s.ExclusiveMaximum = ptrOf(n)
+		}
+	case "string", "array", "object":
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return
+		}
+		var minPtr, maxPtr **int
+		switch s.Type {
+		case "string":
+			if s.ContentEncoding != "" {
+				// []byte 的长度是字节数，和 base64 后的字符串长度不同
+				return
+			}
+			minPtr, maxPtr = &s.MinLength, &s.MaxLength
+		case "array":
+			minPtr, maxPtr = &s.MinItems, &s.MaxItems
+		default:
+			minPtr, maxPtr = &s.MinProperties, &s.MaxProperties
+		}
+		switch name {
+		case "len", "eq":
+			*minPtr, *maxPtr = ptrOf(n), ptrOf(n)
+		case "min", "gte":
+			*minPtr = ptrOf(n)
+		case "max", "lte":
+			*maxPtr = ptrOf(n)
+		case "gt":
+			*minPtr = ptrOf(n + 1)
+		case "lt":
+			*maxPtr = ptrOf(n - 1)
+		}
+	}
+}
+
+// parseValue 将字符串转换为 rt 类型的值（用于 enum、default），转换失败时返回原字符串
+func parseValue(rt reflect.Type, str string) any {
+	switch derefType(rt).Kind() {
+	case reflect.Bool:
+		if v, err := strconv.ParseBool(str); err == nil {
+			return v
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v, err := strconv.ParseInt(str, 10, 64); err == nil {
+			return v
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v, err := strconv.ParseUint(str, 10, 64); err == nil {
+			return v
+		}
+	case reflect.Float32, reflect.Float64:
+		if v, err := strconv.ParseFloat(str, 64); err == nil {
+			return v
+		}
+	}
+	return str
+}
+
+func ptrOf[T any](v T) *T {
+	return &v
+}
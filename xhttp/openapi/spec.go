@@ -0,0 +1,398 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/xanygo/anygo/ds/xstruct"
+	"github.com/xanygo/anygo/xhttp"
+	"github.com/xanygo/anygo/xhttp/internal/zroute"
+)
+
+var _ http.Handler = (*Spec)(nil)
+
+// Spec 依据 xhttp.Router 中注册的路由生成 OpenAPI 文档，同时也是输出 JSON 格式文档的 http.Handler
+type Spec struct {
+	// Router 必填，读取此 Router 中注册的路由，使用 Register 方法时，若为空会自动设置
+	Router *xhttp.Router
+
+	// Info 文档的基本信息，Title 默认为 "API"，Version 默认为 "1.0.0"
+	Info Info
+
+	// Servers 可选，接口服务的地址
+	Servers []Server
+
+	// All 可选，是否输出没有文档信息（即没有使用 RouteInfo.Describe）的路由，默认只输出有文档信息的路由
+	All bool
+
+	// AnyMethods 可选，注册时没有限定请求方法（Method 为 ANY）的路由，在文档中使用的请求方法，默认为 GET 和 POST
+	AnyMethods []string
+}
+
+// Register 将文档注册到 router 的 path 地址上（GET 请求），path 为空时使用 "/openapi.json"
+func (s *Spec) Register(router *xhttp.Router, path string) xhttp.RouteInfo {
+	if s.Router == nil {
+		s.Router = router
+	}
+	if path == "" {
+		path = "/openapi.json"
+	}
+	return router.Get(path+" meta|openapi=no", s)
+}
+
+func (s *Spec) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	xhttp.WriteJSON(w, s.Document())
+}
+
+func (s *Spec) getAnyMethods() []string {
+	if len(s.AnyMethods) > 0 {
+		return s.AnyMethods
+	}
+	return []string{http.MethodGet, http.MethodPost}
+}
+
+// Document 生成 OpenAPI 文档，每次调用都会重新生成
+func (s *Spec) Document() *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    s.Info,
+		Servers: s.Servers,
+		Paths:   map[string]PathItem{},
+	}
+	if doc.Info.Title == "" {
+		doc.Info.Title = "API"
+	}
+	if doc.Info.Version == "" {
+		doc.Info.Version = "1.0.0"
+	}
+	if s.Router == nil {
+		return doc
+	}
+	sb := newSchemaBuilder()
+	operationIDs := map[string]bool{}
+	for _, ri := range s.Router.Routes() {
+		if v, ok := ri.GetMeta("openapi"); ok && v == "no" {
+			continue
+		}
+		rd := ri.Doc()
+		if rd == nil && !s.All {
+			continue
+		}
+		if rd == nil {
+			rd = &xhttp.RouteDoc{}
+		}
+		methods := []string{ri.Method}
+		if ri.Method == zroute.MethodAny {
+			methods = s.getAnyMethods()
+		}
+		p, pathParams := parsePath(ri.Pattern)
+		item := doc.Paths[p]
+		if item == nil {
+			item = PathItem{}
+			doc.Paths[p] = item
+		}
+		for _, method := range methods {
+			method = strings.ToLower(method)
+			op := buildOperation(sb, method, pathParams, rd)
+			if ri.MetaID != "" {
+				op.OperationID = ri.MetaID
+				if operationIDs[op.OperationID] {
+					// operationId 需要是唯一的，同一个 pattern 注册了多个请求方法时会重复
+					op.OperationID += "_" + method
+				}
+				operationIDs[op.OperationID] = true
+			}
+			item[method] = op
+		}
+	}
+	if len(sb.schemas) > 0 {
+		doc.Components = &Components{Schemas: sb.schemas}
+	}
+	return doc
+}
+
+func buildOperation(sb *schemaBuilder, method string, pathParams []*Parameter, rd *xhttp.RouteDoc) *Operation {
+	op := &Operation{
+		Tags:        rd.Tags,
+		Summary:     rd.Summary,
+		Description: rd.Description,
+		Deprecated:  rd.Deprecated,
+		Responses:   map[string]*Response{},
+	}
+	params := make([]*Parameter, 0, len(pathParams))
+	for _, pp := range pathParams {
+		cp := *pp
+		params = append(params, &cp)
+	}
+	if rd.Request != nil {
+		rt := derefType(reflect.TypeOf(rd.Request))
+		if rt.Kind() == reflect.Struct {
+			params = mergeParams(params, structParams(sb, rt))
+		}
+		if hasBody(method) {
+			op.RequestBody = requestBody(sb, rt)
+		}
+	}
+	if len(params) > 0 {
+		op.Parameters = params
+	}
+
+	op.Responses["200"] = newResponse(sb, http.StatusOK, rd.Response)
+	for code, obj := range rd.Responses {
+		op.Responses[strconv.Itoa(code)] = newResponse(sb, code, obj)
+	}
+	return op
+}
+
+func hasBody(method string) bool {
+	switch method {
+	case "get", "head", "options", "trace":
+		return false
+	default:
+		return true
+	}
+}
+
+func newResponse(sb *schemaBuilder, code int, obj any) *Response {
+	resp := &Response{Description: http.StatusText(code)}
+	if resp.Description == "" {
+		resp.Description = strconv.Itoa(code)
+	}
+	if obj == nil {
+		return resp
+	}
+	resp.Content = map[string]*MediaType{
+		"application/json": {Schema: sb.schemaOf(reflect.TypeOf(obj))},
+	}
+	return resp
+}
+
+// requestBody 生成请求的 body，有 form 字段时为表单，否则为 JSON，若没有任何 body 字段，返回 nil
+func requestBody(sb *schemaBuilder, rt reflect.Type) *RequestBody {
+	if rt.Kind() != reflect.Struct {
+		return &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{"application/json": {Schema: sb.schemaOf(rt)}},
+		}
+	}
+	form := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	var hasFile bool
+	formFields(sb, rt, form, &hasFile)
+	if len(form.Properties) > 0 {
+		contentType := "application/x-www-form-urlencoded"
+		if hasFile {
+			contentType = "multipart/form-data"
+		}
+		return &RequestBody{
+			Required: len(form.Required) > 0,
+			Content:  map[string]*MediaType{contentType: {Schema: form}},
+		}
+	}
+
+	body := sb.structSchema(rt, true)
+	if len(body.Properties) == 0 {
+		return nil
+	}
+	return &RequestBody{
+		Required: len(body.Required) > 0,
+		Content:  map[string]*MediaType{"application/json": {Schema: body}},
+	}
+}
+
+func formFields(sb *schemaBuilder, rt reflect.Type, form *Schema, hasFile *bool) {
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() && !(sf.Anonymous && sf.Type.Kind() == reflect.Struct) {
+			continue
+		}
+		if _, ok := sf.Tag.Lookup("form"); !ok {
+			if !hasParamTag(sf) && isNestedStruct(sf.Type) {
+				formFields(sb, derefType(sf.Type), form, hasFile)
+			}
+			continue
+		}
+		tag := xstruct.ParserTagCached(sf.Tag, "form")
+		name := tag.Name()
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fs := paramSchema(sb, sf, tag)
+		if derefType(sf.Type) == fileHeaderType || (fs.Items != nil && fs.Items.Format == "binary") {
+			*hasFile = true
+		}
+		form.Properties[name] = fs
+		if isRequired(sf) {
+			form.Required = append(form.Required, name)
+		}
+	}
+}
+
+// structParams 读取结构体中的 path、query、header、cookie 字段
+func structParams(sb *schemaBuilder, rt reflect.Type) []*Parameter {
+	var result []*Parameter
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() && !(sf.Anonymous && sf.Type.Kind() == reflect.Struct) {
+			continue
+		}
+		if !hasParamTag(sf) {
+			if !hasTag(sf, "form") && isNestedStruct(sf.Type) {
+				result = append(result, structParams(sb, derefType(sf.Type))...)
+			}
+			continue
+		}
+		for _, in := range paramTags {
+			if !hasTag(sf, in) {
+				continue
+			}
+			tag := xstruct.ParserTagCached(sf.Tag, in)
+			name := tag.Name()
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = sf.Name
+			}
+			p := &Parameter{
+				Name:     name,
+				In:       in,
+				Required: in == "path" || isRequired(sf),
+				Schema:   paramSchema(sb, sf, tag),
+			}
+			// 说明放到 Parameter 上
+			p.Description, p.Schema.Description = p.Schema.Description, ""
+			if _, ok := tag.Get("sep"); ok {
+				// 使用分隔符连接的多个值，如 ids=1,2,3
+				if in == "query" || in == "cookie" {
+					p.Style = "form"
+				} else {
+					p.Style = "simple"
+				}
+				p.Explode = ptrOf(false)
+			}
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// paramSchema 生成参数字段的 Schema，包括 tag 中的 default 选项
+func paramSchema(sb *schemaBuilder, sf reflect.StructField, tag xstruct.Tag) *Schema {
+	s := sb.fieldSchema(sf)
+	def, ok := tag.Get("default")
+	if !ok {
+		return s
+	}
+	if s.Items == nil {
+		s.Default = parseValue(sf.Type, def)
+		return s
+	}
+	sep, ok := tag.Get("sep")
+	if !ok || sep == "" {
+		sep = ","
+	}
+	var values []any
+	for _, item := range strings.Split(def, sep) {
+		values = append(values, parseValue(derefType(sf.Type).Elem(), item))
+	}
+	s.Default = values
+	return s
+}
+
+// mergeParams 合并路由 pattern 中的 path 参数和结构体中的参数，对于同名的 path 参数，
+// 类型以 pattern 中的为准，并补充结构体字段中的说明
+func mergeParams(pathParams []*Parameter, fields []*Parameter) []*Parameter {
+	for _, f := range fields {
+		if f.In != "path" {
+			pathParams = append(pathParams, f)
+			continue
+		}
+		for _, pp := range pathParams {
+			if pp.In == "path" && pp.Name == f.Name {
+				pp.Description = f.Description
+				break
+			}
+		}
+	}
+	return pathParams
+}
+
+// parsePath 将路由的 pattern 转换为 OpenAPI 的路径，并返回路径中的参数，
+// 如 /user/{id:UINT}/* 转换为 /user/{id}/{p1}
+func parsePath(pattern string) (string, []*Parameter) {
+	var bf strings.Builder
+	var params []*Parameter
+	for len(pattern) > 0 {
+		idx := strings.IndexAny(pattern, "{*")
+		if idx == -1 {
+			bf.WriteString(pattern)
+			break
+		}
+		bf.WriteString(pattern[:idx])
+		var name, reg string
+		if pattern[idx] == '*' {
+			// 和 zroute 一致，* 变量的名称为 p + 变量序号
+			name = "p" + strconv.Itoa(len(params))
+			reg = "*"
+			pattern = pattern[idx+1:]
+		} else {
+			end := closeBrace(pattern, idx)
+			if end == -1 {
+				bf.WriteString(pattern[idx:])
+				break
+			}
+			name, reg, _ = strings.Cut(pattern[idx+1:end], ":")
+			pattern = pattern[end+1:]
+		}
+		bf.WriteString("{" + name + "}")
+		params = append(params, &Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   pathParamSchema(reg),
+		})
+	}
+	return bf.String(), params
+}
+
+// closeBrace 查找和 start 位置的 { 配对的 }，正则中可能有 {2} 这样的内容
+func closeBrace(str string, start int) int {
+	var depth int
+	for i := start; i < len(str); i++ {
+		switch str[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func pathParamSchema(reg string) *Schema {
+	switch reg {
+	case "", "*":
+		return &Schema{Type: "string"}
+	case "UINT":
+		return &Schema{Type: "integer", Format: "int64", Minimum: ptrOf(0.0)}
+	case "NZUInt":
+		return &Schema{Type: "integer", Format: "int64", Minimum: ptrOf(1.0)}
+	case "INT":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "UUID":
+		return &Schema{Type: "string", Format: "uuid"}
+	}
+	return &Schema{Type: "string", Pattern: "^(?:" + zroute.ResolveRegexp(reg) + ")$"}
+}
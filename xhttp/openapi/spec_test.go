@@ -0,0 +1,192 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package openapi_test
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/xhttp"
+	"github.com/xanygo/anygo/xhttp/openapi"
+	"github.com/xanygo/anygo/xt"
+)
+
+type testPage struct {
+	Page int `query:"page,default=1" validator:"min=1"`
+	Size int `query:"size" validator:"omitempty,max=100"`
+}
+
+type testUser struct {
+	ID        int64    `json:"id"`
+	Name      string   `json:"name" validator:"required,min=2,max=32" doc:"user name"`
+	Role      string   `json:"role,omitempty" validator:"oneof=admin user"`
+	Email     string   `json:"email" validator:"omitempty,email"`
+	Tags      []string `json:"tags" validator:"max=5,dive,max=10"`
+	Friends   []*testUser
+	CreatedAt time.Time `json:"created_at"`
+	secret    string
+}
+
+type testUpdateUser struct {
+	ID    uint64   `path:"id" doc:"user id"`
+	Token string   `header:"X-Token" validator:"required"`
+	IDs   []int    `query:"ids,sep=|,default=1|2"`
+	Name  string   `json:"name" validator:"required"`
+	Score *float64 `json:"score" validator:"gte=0,lt=100"`
+	Data  []byte   `json:"data"`
+}
+
+type testUpload struct {
+	Title string                `form:"title" validator:"required"`
+	File  *multipart.FileHeader `form:"file"`
+}
+
+type testError struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+func TestSpec(t *testing.T) {
+	noop := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	router := xhttp.NewRouter()
+	router.Get("/users meta|id=listUsers", noop).Describe(xhttp.RouteDoc{
+		Summary:  "list users",
+		Tags:     []string{"user"},
+		Request:  testPage{},
+		Response: []testUser{},
+	})
+	api := router.Prefix("/api/")
+	api.Put("/user/{id:UINT}", noop).Describe(xhttp.RouteDoc{
+		Request:   &testUpdateUser{},
+		Response:  testUser{},
+		Responses: map[int]any{404: testError{}, 204: nil},
+	})
+	api.Post("/upload/{name:[a-z]{2,4}}/*", noop).Describe(xhttp.RouteDoc{Request: testUpload{}})
+	api.Get("/hidden meta|openapi=no", noop).Describe(xhttp.RouteDoc{Summary: "hidden"})
+	router.Get("/nodoc", noop)
+	router.MustHandle("/any meta|id=any", noop)
+
+	spec := &openapi.Spec{Info: openapi.Info{Title: "demo"}}
+	spec.Register(router, "/doc.json")
+	doc := spec.Document()
+
+	xt.Equal(t, doc.OpenAPI, "3.1.0")
+	xt.Equal(t, doc.Info.Version, "1.0.0")
+	xt.Len(t, doc.Paths, 3)
+
+	t.Run("query params", func(t *testing.T) {
+		op := doc.Paths["/users"]["get"]
+		xt.Equal(t, op.OperationID, "listUsers")
+		xt.Equal(t, op.Tags, []string{"user"})
+		xt.Nil(t, op.RequestBody)
+		xt.Len(t, op.Parameters, 2)
+		xt.Equal(t, op.Parameters[0].Name, "page")
+		xt.Equal(t, op.Parameters[0].In, "query")
+		xt.Equal(t, op.Parameters[0].Schema.Default, any(int64(1)))
+		xt.Equal(t, *op.Parameters[0].Schema.Minimum, 1.0)
+		xt.Equal(t, *op.Parameters[1].Schema.Maximum, 100.0)
+
+		resp := op.Responses["200"].Content["application/json"].Schema
+		xt.Equal(t, resp.Type, "array")
+		xt.Equal(t, resp.Items.Ref, "#/components/schemas/testUser")
+
+		user := doc.Components.Schemas["testUser"]
+		xt.Equal(t, user.Required, []string{"name"})
+		xt.Equal(t, user.Properties["name"].Description, "user name")
+		xt.Equal(t, *user.Properties["name"].MinLength, 2)
+		xt.Equal(t, *user.Properties["name"].MaxLength, 32)
+		xt.Equal(t, user.Properties["role"].Enum, []any{"admin", "user"})
+		xt.Equal(t, user.Properties["email"].Format, "email")
+		xt.Equal(t, *user.Properties["tags"].MaxItems, 5)
+		xt.Equal(t, *user.Properties["tags"].Items.MaxLength, 10)
+		xt.Equal(t, user.Properties["Friends"].Items.Ref, "#/components/schemas/testUser")
+		xt.Equal(t, user.Properties["created_at"].Format, "date-time")
+		_, has := user.Properties["secret"]
+		xt.False(t, has)
+	})
+
+	t.Run("path params and json body", func(t *testing.T) {
+		op := doc.Paths["/api/user/{id}"]["put"]
+		xt.Equal(t, op.OperationID, "")
+		xt.Len(t, op.Parameters, 3)
+		id := op.Parameters[0]
+		xt.Equal(t, id.In, "path")
+		xt.True(t, id.Required)
+		xt.Equal(t, id.Description, "user id")
+		xt.Equal(t, id.Schema.Type, "integer")
+		xt.Equal(t, op.Parameters[1].Name, "X-Token")
+		xt.True(t, op.Parameters[1].Required)
+		ids := op.Parameters[2]
+		xt.Equal(t, ids.Style, "form")
+		xt.False(t, *ids.Explode)
+		xt.Equal(t, ids.Schema.Default, any([]any{int64(1), int64(2)}))
+
+		body := op.RequestBody.Content["application/json"].Schema
+		xt.True(t, op.RequestBody.Required)
+		xt.Len(t, body.Properties, 3)
+		xt.Equal(t, body.Required, []string{"name"})
+		xt.Equal(t, *body.Properties["score"].Minimum, 0.0)
+		xt.Equal(t, *body.Properties["score"].ExclusiveMaximum, 100.0)
+		xt.Equal(t, body.Properties["data"].ContentEncoding, "base64")
+
+		xt.Equal(t, op.Responses["404"].Content["application/json"].Schema.Ref, "#/components/schemas/testError")
+		xt.Equal(t, op.Responses["204"].Description, "No Content")
+		xt.Nil(t, op.Responses["204"].Content)
+	})
+
+	t.Run("form body", func(t *testing.T) {
+		op := doc.Paths["/api/upload/{name}/{p1}"]["post"]
+		xt.Len(t, op.Parameters, 2)
+		xt.Equal(t, op.Parameters[0].Schema.Pattern, "^(?:[a-z]{2,4})$")
+		xt.Equal(t, op.Parameters[1].Name, "p1")
+		form := op.RequestBody.Content["multipart/form-data"].Schema
+		xt.Equal(t, form.Required, []string{"title"})
+		xt.Equal(t, form.Properties["file"].Format, "binary")
+	})
+
+	t.Run("all", func(t *testing.T) {
+		spec := &openapi.Spec{Router: router, All: true}
+		doc := spec.Document()
+		xt.Len(t, doc.Paths, 5)
+		xt.Equal(t, doc.Paths["/any"]["get"].OperationID, "any")
+		xt.Equal(t, doc.Paths["/any"]["post"].OperationID, "any_post")
+		xt.Equal(t, doc.Paths["/nodoc"]["get"].Responses["200"].Description, "OK")
+	})
+
+	t.Run("serve", func(t *testing.T) {
+		ts := httptest.NewServer(router)
+		defer ts.Close()
+		resp, err := ts.Client().Get(ts.URL + "/doc.json")
+		xt.NoError(t, err)
+		defer resp.Body.Close()
+		xt.Equal(t, resp.StatusCode, http.StatusOK)
+		var got map[string]any
+		xt.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		xt.Equal(t, got["openapi"], any("3.1.0"))
+		xt.Len(t, got["paths"].(map[string]any), 3)
+	})
+}
+
+func TestJSONSchema(t *testing.T) {
+	s := openapi.JSONSchema(testUser{})
+	xt.Equal(t, s.Type, "object")
+	xt.Equal(t, s.Required, []string{"name"})
+	// Friends 递归引用了 testUser
+	xt.Equal(t, s.Properties["Friends"].Items.Ref, "#/$defs/testUser")
+	xt.NotNil(t, s.Defs["testUser"])
+
+	s = openapi.JSONSchema(&testError{})
+	xt.Equal(t, s.Type, "object")
+	xt.Len(t, s.Properties, 2)
+	xt.Nil(t, s.Defs)
+
+	s = openapi.JSONSchema([]testError{})
+	xt.Equal(t, s.Items.Ref, "#/$defs/testError")
+	xt.Len(t, s.Defs, 1)
+}
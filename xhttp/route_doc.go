@@ -0,0 +1,67 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttp
+
+// RouteDoc 路由的文档信息，用于生成 OpenAPI 等接口文档（如 xhttp/openapi 包）
+type RouteDoc struct {
+	// Summary 接口的简要说明
+	Summary string
+
+	// Description 接口的详细说明
+	Description string
+
+	// Tags 接口的分组标签
+	Tags []string
+
+	// Deprecated 接口是否已经废弃
+	Deprecated bool
+
+	// Request 请求参数的类型，如 UserRequest{} 或者 (*UserRequest)(nil)，
+	// 结构体字段的 tag 同 Binder，如 path、query、header、cookie、form、json
+	Request any
+
+	// Response 成功时（状态码 200）的响应数据类型，如 UserResponse{}
+	Response any
+
+	// Responses 其他状态码的响应数据类型，如 {404: ErrorResponse{}}，值为 nil 表示没有响应内容
+	Responses map[int]any
+}
+
+// HasRouteDoc 可以提供文档信息的 http.Handler（如 JSONHandler 创建的 TypedHandler），
+// 注册路由时，会自动作为路由的文档信息
+type HasRouteDoc interface {
+	RouteDoc() RouteDoc
+}
+
+// Describe 给路由添加文档信息，同一个 pattern 注册的多个路由（如 "GET,POST /user"）共享同一个文档信息。
+// 若 doc 中的 Request、Response、Responses 为空，会保留已有的（如 HasRouteDoc 提供的）值。
+//
+//	router.Get("/user/{id:UINT}", handler).Describe(xhttp.RouteDoc{Summary: "查询用户", Response: User{}})
+func (ri RouteInfo) Describe(doc RouteDoc) RouteInfo {
+	if ri.doc == nil {
+		return ri
+	}
+	if old := ri.doc.Load(); old != nil {
+		if doc.Request == nil {
+			doc.Request = old.Request
+		}
+		if doc.Response == nil {
+			doc.Response = old.Response
+		}
+		if doc.Responses == nil {
+			doc.Responses = old.Responses
+		}
+	}
+	ri.doc.Store(&doc)
+	return ri
+}
+
+// Doc 读取路由的文档信息，若没有，返回 nil
+func (ri RouteInfo) Doc() *RouteDoc {
+	if ri.doc == nil {
+		return nil
+	}
+	return ri.doc.Load()
+}
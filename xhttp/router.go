@@ -196,6 +196,12 @@ func (r *Router) register(pattern string, handler http.Handler, mds ...Middlewar
 		xlog.Int("Routes.cnt", len(routes)),
 	)
 
+	doc := &atomic.Pointer[RouteDoc]{}
+	if hd, ok := handler.(HasRouteDoc); ok {
+		rd := hd.RouteDoc()
+		doc.Store(&rd)
+	}
+
 	handler = r.wrap(handler, mds...)
 
 	result := make([]RouteInfo, 0, len(routes))
@@ -208,6 +214,7 @@ func (r *Router) register(pattern string, handler http.Handler, mds ...Middlewar
 			MetaID:         route.Meta.ID,
 			MetaOther:      route.Meta.Other,
 			metaPathValues: route.Meta.PathValues,
+			doc:            doc,
 		}
 		route.Info = info
 		r.subRoute = append(r.subRoute, route)
@@ -296,10 +303,29 @@ func (r *Router) Prefix(prefix string, mds ...MiddlewareFunc) *Router {
 	if r.notFoundRaw != nil {
 		g.NotFound(r.notFoundRaw)
 	}
-	r.MustHandle(prefix+"*", g)
+	infos := r.mustRegister(prefix+"*", g)
+	for _, sub := range r.subRoute[len(r.subRoute)-len(infos):] {
+		info := sub.Info.(RouteInfo)
+		info.group = g
+		sub.Info = info
+	}
 	return g
 }
 
+// Routes 返回所有已注册的路由信息（按照注册的顺序），使用 Prefix 创建的分组会展开为分组内的路由
+func (r *Router) Routes() []RouteInfo {
+	result := make([]RouteInfo, 0, len(r.subRoute))
+	for _, sub := range r.subRoute {
+		info := sub.Info.(RouteInfo)
+		if info.group != nil {
+			result = append(result, info.group.Routes()...)
+			continue
+		}
+		result = append(result, info)
+	}
+	return result
+}
+
 // Head  注册 HEAD 请求路由，pattern 支持格式 (Method\s+)?(Path)(\s+meta|Meta)
 func (r *Router) Head(pattern string, handler http.Handler, mds ...MiddlewareFunc) RouteInfo {
 	return r.handleMethod(http.MethodHead, pattern, handler, mds...)
@@ -387,6 +413,9 @@ type RouteInfo struct {
 	MetaOther map[string]string
 
 	metaPathValues map[string]string
+
+	doc   *atomic.Pointer[RouteDoc] // 同一次注册的多个路由共享
+	group *Router                   // 使用 Prefix 创建的分组路由
 }
 
 func (ri RouteInfo) Exists() bool {
@@ -288,3 +288,31 @@ func TestRouter_PrefixPrefix(t *testing.T) {
 		defer resp.Body.Close()
 	})
 }
+
+func TestRouter_Routes(t *testing.T) {
+	router := NewRouter()
+	router.GetFunc("/index", func(w http.ResponseWriter, r *http.Request) {})
+	api := router.Prefix("/api/")
+	info := api.PostFunc("/user/{id:UINT} meta|id=user_update", func(w http.ResponseWriter, r *http.Request) {
+		xt.Equal(t, ReadRouteInfo(r.Context()).Doc().Summary, "update user")
+	}).Describe(RouteDoc{Summary: "update user"})
+	xt.Equal(t, info.Doc().Summary, "update user")
+	router.MustHandle("GET,PUT /other", http.NotFoundHandler())
+
+	routes := router.Routes()
+	var got []string
+	for _, ri := range routes {
+		got = append(got, ri.Method+" "+ri.Pattern)
+	}
+	xt.Equal(t, got, []string{"GET /index", "POST /api/user/{id:UINT}", "GET /other", "PUT /other"})
+	xt.Equal(t, routes[1].MetaID, "user_update")
+	xt.Equal(t, routes[1].Doc().Summary, "update user")
+	xt.Nil(t, routes[0].Doc())
+
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+	resp, err := ts.Client().Post(ts.URL+"/api/user/1", "", nil)
+	xt.NoError(t, err)
+	defer resp.Body.Close()
+	xt.Equal(t, resp.StatusCode, http.StatusOK)
+}
@@ -0,0 +1,273 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package sse
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var _ http.Handler = (*Broker)(nil)
+
+// Broker 管理 SSE 客户端的连接，并将发布的事件推送给订阅了对应 topic 的客户端。
+//
+// Broker 实现了 http.Handler，可以直接注册到路由上，如：
+//
+//	b := &sse.Broker{History: sse.NewMemoryHistory(100)}
+//	router.Get("/events/{topic}", b)
+//	b.Publish(ctx, "news", sse.Event{Data: "hello"})
+//
+// 每个客户端有独立的发送缓冲区，若缓冲区满（客户端接收太慢），连接会被断开，
+// 客户端重连时，若配置了 History，会依据请求头 Last-Event-ID 补发错过的事件。
+type Broker struct {
+	// Buffer 每个客户端的缓冲区大小，可选，默认为 64
+	Buffer int
+
+	// Heartbeat 心跳间隔，会发送注释行以保持连接，可选，默认为 15 秒，为负数时不发送
+	Heartbeat time.Duration
+
+	// Retry 可选，连接建立后，发送给客户端的重连间隔
+	Retry time.Duration
+
+	// History 可选，事件历史存储，用于补发事件
+	History History
+
+	// Topics 可选，读取客户端订阅的 topic，默认读取路由参数 topic，
+	// 若没有，读取 query 参数 topic（多个使用逗号连接），若也没有，则订阅空字符串 topic
+	Topics func(r *http.Request) []string
+
+	mux     sync.RWMutex
+	clients map[string]map[*brokerClient]struct{}
+	seq     atomic.Int64
+	closed  chan struct{}
+	once    sync.Once
+}
+
+type brokerClient struct {
+	ch      chan Event
+	dropped chan struct{}
+	once    sync.Once
+}
+
+func (c *brokerClient) drop() {
+	c.once.Do(func() {
+		close(c.dropped)
+	})
+}
+
+func (b *Broker) init() {
+	b.once.Do(func() {
+		b.clients = make(map[string]map[*brokerClient]struct{})
+		b.closed = make(chan struct{})
+	})
+}
+
+func (b *Broker) getBuffer() int {
+	if b.Buffer > 0 {
+		return b.Buffer
+	}
+	return 64
+}
+
+func (b *Broker) getHeartbeat() time.Duration {
+	if b.Heartbeat == 0 {
+		return 15 * time.Second
+	}
+	return b.Heartbeat
+}
+
+func (b *Broker) topics(r *http.Request) []string {
+	if b.Topics != nil {
+		return b.Topics(r)
+	}
+	if topic := r.PathValue("topic"); topic != "" {
+		return []string{topic}
+	}
+	var result []string
+	for _, topic := range strings.Split(r.URL.Query().Get("topic"), ",") {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			result = append(result, topic)
+		}
+	}
+	if len(result) == 0 {
+		return []string{""}
+	}
+	return result
+}
+
+// nextID 生成递增的事件 ID，以纳秒时间戳为基础，多个进程之间也大致有序
+func (b *Broker) nextID() string {
+	for {
+		last := b.seq.Load()
+		id := max(last+1, time.Now().UnixNano())
+		if b.seq.CompareAndSwap(last, id) {
+			return strconv.FormatInt(id, 10)
+		}
+	}
+}
+
+// Publish 发布事件，若事件的 ID 为空，会自动生成，返回实际发布的事件
+func (b *Broker) Publish(ctx context.Context, topic string, e Event) (Event, error) {
+	b.init()
+	if e.ID == "" {
+		e.ID = b.nextID()
+	}
+	if b.History != nil {
+		if err := b.History.Add(ctx, topic, e); err != nil {
+			return e, err
+		}
+	}
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+	for c := range b.clients[topic] {
+		select {
+		case c.ch <- e:
+		default:
+			// 缓冲区满，断开连接，客户端重连后再补发
+			c.drop()
+		}
+	}
+	return e, nil
+}
+
+// ClientCount 返回订阅了 topic 的客户端数量
+func (b *Broker) ClientCount(topic string) int {
+	b.init()
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+	return len(b.clients[topic])
+}
+
+// Close 断开所有的客户端连接，之后新的连接也会立即断开
+func (b *Broker) Close() {
+	b.init()
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	select {
+	case <-b.closed:
+	default:
+		close(b.closed)
+	}
+}
+
+func (b *Broker) subscribe(topics []string) *brokerClient {
+	c := &brokerClient{
+		ch:      make(chan Event, b.getBuffer()),
+		dropped: make(chan struct{}),
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	for _, topic := range topics {
+		if b.clients[topic] == nil {
+			b.clients[topic] = make(map[*brokerClient]struct{})
+		}
+		b.clients[topic][c] = struct{}{}
+	}
+	return c
+}
+
+func (b *Broker) unsubscribe(topics []string, c *brokerClient) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	for _, topic := range topics {
+		delete(b.clients[topic], c)
+		if len(b.clients[topic]) == 0 {
+			delete(b.clients, topic)
+		}
+	}
+}
+
+var errBrokerClosed = errors.New("sse broker closed")
+
+func (b *Broker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.init()
+	select {
+	case <-b.closed:
+		http.Error(w, errBrokerClosed.Error(), http.StatusServiceUnavailable)
+		return
+	default:
+	}
+	rc := http.NewResponseController(w)
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if b.Retry > 0 {
+		_, _ = Event{Retry: int(b.Retry.Milliseconds())}.WriteTo(w)
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	topics := b.topics(r)
+	// 先订阅再补发，避免补发期间发布的事件丢失
+	c := b.subscribe(topics)
+	defer b.unsubscribe(topics, c)
+
+	sent := make(map[string]bool)
+	if lastID := lastEventID(r); lastID != "" && b.History != nil {
+		for _, topic := range topics {
+			events, err := b.History.Since(r.Context(), topic, lastID)
+			if err != nil {
+				continue
+			}
+			for _, e := range events {
+				if _, err = e.WriteTo(w); err != nil {
+					return
+				}
+				sent[e.ID] = true
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+
+	var heartbeat <-chan time.Time
+	if hb := b.getHeartbeat(); hb > 0 {
+		tk := time.NewTicker(hb)
+		defer tk.Stop()
+		heartbeat = tk.C
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-b.closed:
+			return
+		case <-c.dropped:
+			return
+		case <-heartbeat:
+			if _, err := (Event{Comment: "ping"}).WriteTo(w); err != nil {
+				return
+			}
+		case e := <-c.ch:
+			if len(sent) > 0 && sent[e.ID] {
+				delete(sent, e.ID)
+				continue
+			}
+			if _, err := e.WriteTo(w); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func lastEventID(r *http.Request) string {
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		return id
+	}
+	return r.URL.Query().Get("lastEventId")
+}
@@ -0,0 +1,95 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package sse
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/store/xkv"
+	"github.com/xanygo/anygo/xt"
+)
+
+func TestBroker(t *testing.T) {
+	b := &Broker{
+		History:   NewMemoryHistory(10),
+		Retry:     time.Second,
+		Heartbeat: 20 * time.Millisecond,
+	}
+	ts := httptest.NewServer(b)
+	defer ts.Close()
+	ctx := context.Background()
+
+	e1, err := b.Publish(ctx, "news", Event{Data: "e1"})
+	xt.NoError(t, err)
+	xt.NotEmpty(t, e1.ID)
+	_, err = b.Publish(ctx, "news", Event{Data: "e2"})
+	xt.NoError(t, err)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"?topic=news,other", nil)
+	req.Header.Set("Last-Event-ID", e1.ID)
+	resp, err := http.DefaultClient.Do(req)
+	xt.NoError(t, err)
+	defer resp.Body.Close()
+	xt.Equal(t, resp.Header.Get("Content-Type"), "text/event-stream")
+	rd := bufio.NewReader(resp.Body)
+
+	ev, err := ReadEvent(rd)
+	xt.NoError(t, err)
+	xt.Equal(t, ev.Retry, 1000)
+
+	// 补发 e1 之后的事件
+	ev, err = ReadEvent(rd)
+	xt.NoError(t, err)
+	xt.Equal(t, ev.Data, "e2")
+
+	for b.ClientCount("other") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	_, err = b.Publish(ctx, "other", Event{Event: "msg", Data: "e3"})
+	xt.NoError(t, err)
+	for {
+		ev, err = ReadEvent(rd)
+		xt.NoError(t, err)
+		if ev.Comment == "ping" {
+			continue
+		}
+		xt.Equal(t, ev.Event, "msg")
+		xt.Equal(t, ev.Data, "e3")
+		break
+	}
+
+	b.Close()
+	for b.ClientCount("news") > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	w := httptest.NewRecorder()
+	b.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	xt.Equal(t, w.Code, http.StatusServiceUnavailable)
+}
+
+func TestKVHistory(t *testing.T) {
+	h := &KVHistory{DB: xkv.NewMemoryStore(), Size: 2}
+	ctx := context.Background()
+	for _, id := range []string{"1", "2", "3"} {
+		xt.NoError(t, h.Add(ctx, "t", Event{ID: id, Data: id}))
+	}
+	events, err := h.Since(ctx, "t", "2")
+	xt.NoError(t, err)
+	xt.Equal(t, events, []Event{{ID: "3", Data: "3"}})
+
+	// ID 1 已经被淘汰，返回 ID 更大的
+	events, err = h.Since(ctx, "t", "1")
+	xt.NoError(t, err)
+	xt.Len(t, events, 2)
+
+	events, err = h.Since(ctx, "t", "x")
+	xt.NoError(t, err)
+	xt.Empty(t, events)
+}
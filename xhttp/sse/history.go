@@ -0,0 +1,142 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+
+	"github.com/xanygo/anygo/ds/xslice"
+	"github.com/xanygo/anygo/store/xkv"
+)
+
+// History 存储已发布的事件，用于客户端重连时，依据 Last-Event-ID 补发错过的事件
+type History interface {
+	// Add 添加事件
+	Add(ctx context.Context, topic string, e Event) error
+
+	// Since 返回 ID 为 lastID 的事件之后的所有事件，按照发布的顺序
+	Since(ctx context.Context, topic string, lastID string) ([]Event, error)
+}
+
+// eventsSince 返回 events 中 lastID 之后的事件。
+// 若 lastID 不存在（如已被淘汰），并且是数字，则返回 ID 比它大的事件
+func eventsSince(events []Event, lastID string) []Event {
+	for i, e := range events {
+		if e.ID == lastID {
+			return events[i+1:]
+		}
+	}
+	last, err := strconv.ParseInt(lastID, 10, 64)
+	if err != nil {
+		return nil
+	}
+	for i, e := range events {
+		if id, err := strconv.ParseInt(e.ID, 10, 64); err == nil && id > last {
+			return events[i:]
+		}
+	}
+	return nil
+}
+
+var _ History = (*MemoryHistory)(nil)
+
+// NewMemoryHistory 创建进程内的事件历史，每个 topic 最多保留 size 个事件
+func NewMemoryHistory(size int) *MemoryHistory {
+	return &MemoryHistory{
+		size:   max(size, 1),
+		topics: make(map[string]*xslice.Ring[Event]),
+	}
+}
+
+// MemoryHistory 使用 Ring 存储每个 topic 最近的事件
+type MemoryHistory struct {
+	size   int
+	mux    sync.RWMutex
+	topics map[string]*xslice.Ring[Event]
+}
+
+func (m *MemoryHistory) Add(ctx context.Context, topic string, e Event) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	ring := m.topics[topic]
+	if ring == nil {
+		ring = xslice.NewRing[Event](m.size)
+		m.topics[topic] = ring
+	}
+	ring.Push(e)
+	return nil
+}
+
+func (m *MemoryHistory) Since(ctx context.Context, topic string, lastID string) ([]Event, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+	ring := m.topics[topic]
+	if ring == nil {
+		return nil, nil
+	}
+	return eventsSince(ring.Values(), lastID), nil
+}
+
+var _ History = (*KVHistory)(nil)
+
+// KVHistory 使用 xkv 的 List 存储事件历史，可用于多个进程共享
+type KVHistory struct {
+	DB        xkv.StringStorage // 必填
+	Size      int               // 可选，每个 topic 最多保留的事件数，默认为 100
+	KeyPrefix string            // 可选，默认为 "sse|"
+}
+
+func (k *KVHistory) getSize() int {
+	if k.Size > 0 {
+		return k.Size
+	}
+	return 100
+}
+
+func (k *KVHistory) key(topic string) string {
+	if k.KeyPrefix == "" {
+		return "sse|" + topic
+	}
+	return k.KeyPrefix + topic
+}
+
+func (k *KVHistory) Add(ctx context.Context, topic string, e Event) error {
+	bf, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	list := k.DB.List(k.key(topic))
+	n, err := list.RPush(ctx, string(bf))
+	if err != nil {
+		return err
+	}
+	if over := int(n) - k.getSize(); over > 0 {
+		_, err = list.LPopN(ctx, over)
+	}
+	return err
+}
+
+func (k *KVHistory) Since(ctx context.Context, topic string, lastID string) ([]Event, error) {
+	var events []Event
+	var errDecode error
+	err := k.DB.List(k.key(topic)).LRange(ctx, func(val string) bool {
+		var e Event
+		if errDecode = json.Unmarshal([]byte(val), &e); errDecode != nil {
+			return false
+		}
+		events = append(events, e)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if errDecode != nil {
+		return nil, errDecode
+	}
+	return eventsSince(events, lastID), nil
+}
@@ -0,0 +1,310 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/xanygo/anygo/xcodec"
+	"github.com/xanygo/anygo/xerror"
+	"github.com/xanygo/anygo/xlog"
+	"github.com/xanygo/anygo/xvalidator"
+)
+
+// DefaultCodecs TypedHandler 默认支持的响应编码格式，依据请求头 Accept 选择，第一个为默认值
+var DefaultCodecs = []xcodec.Codec{xcodec.JSON, xcodec.XML, xcodec.Form}
+
+// JSONHandler 创建类型化的 http.Handler：
+//  1. 使用 Bind 将请求数据绑定到 Req 上，并校验（详见 Binder）
+//  2. 调用 fn
+//  3. 依据请求头 Accept 选择编码格式（默认为 JSON），输出 Resp 或者错误信息（ErrorBody），
+//     错误对应的状态码见 ErrorStatus。Resp 为 nil 时，状态码为 204
+//
+// 使用 Router 注册时，Req 和 Resp 会作为路由的文档信息（RouteDoc），如
+//
+//	router.Post("/user", xhttp.JSONHandler(svc.CreateUser))
+func JSONHandler[Req any, Resp any](fn func(ctx context.Context, req *Req) (*Resp, error)) *TypedHandler[Req, Resp] {
+	return &TypedHandler[Req, Resp]{
+		Func: fn,
+	}
+}
+
+var (
+	_ http.Handler = (*TypedHandler[struct{}, struct{}])(nil)
+	_ HasRouteDoc  = (*TypedHandler[struct{}, struct{}])(nil)
+)
+
+// TypedHandler 类型化的 http.Handler，详见 JSONHandler
+type TypedHandler[Req any, Resp any] struct {
+	// Func 必填，业务逻辑
+	Func func(ctx context.Context, req *Req) (*Resp, error)
+
+	// Codecs 可选，支持的响应编码格式，默认为 DefaultCodecs
+	Codecs []xcodec.Codec
+
+	// OnError 可选，自定义错误的输出，默认使用 WriteError
+	OnError func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+func (th *TypedHandler[Req, Resp]) getCodecs() []xcodec.Codec {
+	if len(th.Codecs) > 0 {
+		return th.Codecs
+	}
+	return DefaultCodecs
+}
+
+func (th *TypedHandler[Req, Resp]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req := new(Req)
+	if err := Bind(r, req); err != nil {
+		if !xerror.IsInvalidParam(err) {
+			// 如 JSON 格式错误、不支持的 Content-Type
+			err = fmt.Errorf("%w: %w", xerror.InvalidParam, err)
+		}
+		th.writeError(w, r, err)
+		return
+	}
+	resp, err := th.Func(r.Context(), req)
+	if err != nil {
+		th.writeError(w, r, err)
+		return
+	}
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeEncoded(w, r, th.getCodecs(), http.StatusOK, resp)
+}
+
+func (th *TypedHandler[Req, Resp]) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	if th.OnError != nil {
+		th.OnError(w, r, err)
+		return
+	}
+	WriteError(w, r, err, th.getCodecs()...)
+}
+
+// RouteDoc 实现 HasRouteDoc 接口，返回请求、响应的类型
+func (th *TypedHandler[Req, Resp]) RouteDoc() RouteDoc {
+	return RouteDoc{
+		Request:  new(Req),
+		Response: new(Resp),
+		Responses: map[int]any{
+			http.StatusBadRequest:          ErrorBody{},
+			http.StatusInternalServerError: ErrorBody{},
+		},
+	}
+}
+
+// ErrorBody 错误响应的内容，字段名和 Error 输出的 JSON 一致
+type ErrorBody struct {
+	LogID string `json:"LogID" xml:"LogID"`
+
+	// Code 错误码，error 有错误码（xerror.HasErrCode）时为其错误码，否则为 HTTP 状态码
+	Code int64 `json:"Code" xml:"Code"`
+
+	Msg string `json:"Msg" xml:"Msg"`
+
+	// Fields 参数绑定、校验失败时，每个字段的错误信息
+	Fields []FieldMessage `json:"Fields,omitempty" xml:"Fields>Field,omitempty"`
+}
+
+type FieldMessage struct {
+	Field string `json:"Field" xml:"Field,attr"`
+	Msg   string `json:"Msg" xml:",chardata"`
+}
+
+// NewErrorBody 使用 err 创建错误响应的内容，status 为 5xx 时，不会输出 err 的详细信息
+func NewErrorBody(ctx context.Context, status int, err error) *ErrorBody {
+	eb := &ErrorBody{
+		LogID: xlog.FindLogID(ctx),
+		Code:  int64(status),
+	}
+	if code, ok := xerror.ErrCode2(err); ok && code != 0 {
+		eb.Code = code
+	}
+	if status >= http.StatusInternalServerError {
+		eb.Msg = http.StatusText(status)
+		return eb
+	}
+	eb.Msg = err.Error()
+
+	var be *BindError
+	if errors.As(err, &be) {
+		for _, f := range be.Fields {
+			eb.Fields = append(eb.Fields, FieldMessage{Field: f.Field, Msg: f.Err.Error()})
+		}
+	}
+	var ve xvalidator.Errors
+	if errors.As(err, &ve) {
+		for _, f := range ve {
+			eb.Fields = append(eb.Fields, FieldMessage{Field: f.Field, Msg: f.Message()})
+		}
+	}
+	return eb
+}
+
+// ErrorStatus 返回 error 对应的 HTTP 状态码：
+//
+//	nil:                                            200
+//	*xerror.StatusError:                            其 Code（若是有效的状态码）
+//	xerror.InvalidParam（包括 BindError、校验失败）:     400
+//	xerror.NotFound、fs.ErrNotExist:                 404
+//	xerror.AlreadyExist、xerror.DuplicateKey、fs.ErrExist: 409
+//	context.DeadlineExceeded:                       504
+//	其他:                                            500
+func ErrorStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	var se *xerror.StatusError
+	if errors.As(err, &se) && se.Code >= 400 && se.Code <= 599 {
+		return int(se.Code)
+	}
+	switch {
+	case xerror.IsInvalidParam(err):
+		return http.StatusBadRequest
+	case errors.Is(err, xerror.NotFound), errors.Is(err, fs.ErrNotExist):
+		return http.StatusNotFound
+	case errors.Is(err, xerror.AlreadyExist), errors.Is(err, xerror.DuplicateKey), errors.Is(err, fs.ErrExist):
+		return http.StatusConflict
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	code, _ := xerror.ErrCode2(err)
+	switch code {
+	case xerror.CodeInvalidParam:
+		return http.StatusBadRequest
+	case xerror.CodeNotFound:
+		return http.StatusNotFound
+	case xerror.CodeAlreadyExist, xerror.CodeDuplicateKey:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// WriteError 输出错误信息（ErrorBody），状态码见 ErrorStatus，依据请求头 Accept 从 codecs 中选择编码格式，
+// codecs 为空时使用 DefaultCodecs
+func WriteError(w http.ResponseWriter, r *http.Request, err error, codecs ...xcodec.Codec) {
+	status := ErrorStatus(err)
+	ctx := r.Context()
+	if xlog.IsContext(ctx) {
+		xlog.AddAttr(ctx,
+			xlog.Int("ErrCode", status),
+			xlog.ErrorAttr("Error", err),
+		)
+	}
+	if len(codecs) == 0 {
+		codecs = DefaultCodecs
+	}
+	writeEncoded(w, r, codecs, status, NewErrorBody(ctx, status, err))
+}
+
+// writeEncoded 依据请求头 Accept 选择编码格式输出 data，若选中的编码格式不支持 data 的类型（如 Form 只支持 map），
+// 会依次尝试其他可接受的编码格式，最后使用 codecs[0]
+func writeEncoded(w http.ResponseWriter, r *http.Request, codecs []xcodec.Codec, status int, data any) {
+	var bf []byte
+	var ct string
+	var err error
+	for _, c := range append(negotiateCodecs(r.Header.Get("Accept"), codecs), codecs[0]) {
+		bf, err = c.Encode(data)
+		if err == nil {
+			ct, _ = xcodec.ContentType(c)
+			break
+		}
+	}
+	if err != nil {
+		TextError(w, r, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if ct != "" {
+		if !strings.Contains(ct, "charset") && !strings.HasPrefix(ct, "application/x-www-form-urlencoded") {
+			ct += "; charset=utf-8"
+		}
+		w.Header().Set("Content-Type", ct)
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(bf)
+}
+
+// negotiateCodecs 依据请求头 Accept（支持 q 值和 */*、application/* 这样的通配符）， 返回可接受的编码格式，
+// 按照优先级从高到低排序
+func negotiateCodecs(accept string, codecs []xcodec.Codec) []xcodec.Codec {
+	if accept == "" {
+		return nil
+	}
+	type item struct {
+		codec xcodec.Codec
+		q     float64
+		index int
+	}
+	var items []item
+	for index, c := range codecs {
+		ct, err := xcodec.ContentType(c)
+		if err != nil {
+			continue
+		}
+		if q := acceptQuality(accept, ct); q > 0 {
+			items = append(items, item{codec: c, q: q, index: index})
+		}
+	}
+	slices.SortStableFunc(items, func(a, b item) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		default:
+			return a.index - b.index
+		}
+	})
+	result := make([]xcodec.Codec, len(items))
+	for i, it := range items {
+		result[i] = it.codec
+	}
+	return result
+}
+
+// acceptQuality 返回 contentType 在请求头 Accept 中的 q 值，越具体的媒体类型优先级越高
+func acceptQuality(accept string, contentType string) float64 {
+	mt, _, _ := mime.ParseMediaType(contentType)
+	major, _, _ := strings.Cut(mt, "/")
+	q, level := 0.0, 0
+	for _, part := range strings.Split(accept, ",") {
+		am, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		var l int
+		switch am {
+		case mt:
+			l = 3
+		case major + "/*":
+			l = 2
+		case "*/*":
+			l = 1
+		default:
+			continue
+		}
+		if l < level {
+			continue
+		}
+		level = l
+		q = 1
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+	}
+	return q
+}
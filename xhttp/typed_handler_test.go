@@ -0,0 +1,152 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/xanygo/anygo/xerror"
+	"github.com/xanygo/anygo/xhttp"
+	"github.com/xanygo/anygo/xt"
+)
+
+type typedReq struct {
+	ID   int64  `path:"id"`
+	Name string `json:"name" xml:"name" validator:"required"`
+}
+
+type typedResp struct {
+	ID   int64  `json:"id" xml:"id"`
+	Name string `json:"name" xml:"name"`
+}
+
+func TestJSONHandler(t *testing.T) {
+	router := xhttp.NewRouter()
+	info := router.Post("/user/{id:UINT}", xhttp.JSONHandler(func(ctx context.Context, req *typedReq) (*typedResp, error) {
+		switch req.Name {
+		case "notfound":
+			return nil, fmt.Errorf("user %d: %w", req.ID, xerror.NotFound)
+		case "forbidden":
+			return nil, xerror.NewStatusError(http.StatusForbidden)
+		case "fail":
+			return nil, errors.New("db password is 123")
+		case "empty":
+			return nil, nil
+		}
+		return &typedResp{ID: req.ID, Name: req.Name}, nil
+	}))
+	xt.Equal(t, info.Doc().Request, any(&typedReq{}))
+	xt.Equal(t, info.Doc().Response, any(&typedResp{}))
+	info.Describe(xhttp.RouteDoc{Summary: "update user"})
+	xt.Equal(t, info.Doc().Summary, "update user")
+	xt.Equal(t, info.Doc().Request, any(&typedReq{}))
+
+	call := func(t *testing.T, body string, contentType string, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/user/12", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	decode := func(t *testing.T, w *httptest.ResponseRecorder) *xhttp.ErrorBody {
+		eb := &xhttp.ErrorBody{}
+		xt.NoError(t, json.Unmarshal(w.Body.Bytes(), eb))
+		return eb
+	}
+
+	t.Run("success", func(t *testing.T) {
+		w := call(t, `{"name":"hello"}`, "application/json", "")
+		xt.Equal(t, w.Code, http.StatusOK)
+		xt.Equal(t, w.Header().Get("Content-Type"), "application/json; charset=utf-8")
+		xt.Equal(t, strings.TrimSpace(w.Body.String()), `{"id":12,"name":"hello"}`)
+	})
+
+	t.Run("xml", func(t *testing.T) {
+		w := call(t, `<typedReq><name>hello</name></typedReq>`, "application/xml", "text/html, application/xml;q=0.9, */*;q=0.1")
+		xt.Equal(t, w.Code, http.StatusOK)
+		xt.Equal(t, w.Header().Get("Content-Type"), "application/xml; charset=utf-8")
+		var got typedResp
+		xt.NoError(t, xml.Unmarshal(w.Body.Bytes(), &got))
+		xt.Equal(t, got, typedResp{ID: 12, Name: "hello"})
+	})
+
+	t.Run("form fallback", func(t *testing.T) {
+		// Form 不支持结构体，使用默认的 JSON
+		w := call(t, `{"name":"hello"}`, "application/json", "application/x-www-form-urlencoded")
+		xt.Equal(t, w.Code, http.StatusOK)
+		xt.Equal(t, w.Header().Get("Content-Type"), "application/json; charset=utf-8")
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		w := call(t, `{}`, "application/json", "")
+		xt.Equal(t, w.Code, http.StatusBadRequest)
+		eb := decode(t, w)
+		xt.Len(t, eb.Fields, 1)
+		xt.Equal(t, eb.Fields[0].Field, "Name")
+	})
+
+	t.Run("bad json", func(t *testing.T) {
+		w := call(t, `{`, "application/json", "")
+		xt.Equal(t, w.Code, http.StatusBadRequest)
+		xt.Equal(t, decode(t, w).Code, xerror.CodeInvalidParam)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		w := call(t, `{"name":"notfound"}`, "application/json", "")
+		xt.Equal(t, w.Code, http.StatusNotFound)
+		eb := decode(t, w)
+		xt.Equal(t, eb.Code, xerror.CodeNotFound)
+		xt.Equal(t, eb.Msg, "user 12: not found (errno:1000) ")
+	})
+
+	t.Run("status", func(t *testing.T) {
+		w := call(t, `{"name":"forbidden"}`, "application/json", "")
+		xt.Equal(t, w.Code, http.StatusForbidden)
+		xt.Equal(t, decode(t, w).Code, http.StatusForbidden)
+	})
+
+	t.Run("internal", func(t *testing.T) {
+		w := call(t, `{"name":"fail"}`, "application/json", "")
+		xt.Equal(t, w.Code, http.StatusInternalServerError)
+		xt.Equal(t, decode(t, w).Msg, "Internal Server Error")
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		w := call(t, `{"name":"empty"}`, "application/json", "")
+		xt.Equal(t, w.Code, http.StatusNoContent)
+		xt.Empty(t, w.Body.String())
+	})
+}
+
+func TestErrorStatus(t *testing.T) {
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{err: nil, want: http.StatusOK},
+		{err: xerror.InvalidParam, want: http.StatusBadRequest},
+		{err: fmt.Errorf("open: %w", fs.ErrNotExist), want: http.StatusNotFound},
+		{err: xerror.WithCode(errors.New("dup"), xerror.CodeDuplicateKey), want: http.StatusConflict},
+		{err: xerror.NewStatusError(http.StatusTooManyRequests), want: http.StatusTooManyRequests},
+		{err: xerror.NewStatusError(1), want: http.StatusInternalServerError},
+		{err: context.DeadlineExceeded, want: http.StatusGatewayTimeout},
+		{err: errors.New("other"), want: http.StatusInternalServerError},
+	}
+	for _, c := range cases {
+		xt.Equal(t, xhttp.ErrorStatus(c.err), c.want)
+	}
+}
@@ -0,0 +1,320 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xcookiejar
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xanygo/anygo/store/xkv"
+)
+
+var _ http.CookieJar = (*Jar)(nil)
+
+// New 创建 Cookie Jar，store 可选，用于持久化存储有过期时间的 Cookie（会话 Cookie 只在内存中）。
+//
+// 同一个 store 可以被多个进程共享，但每个 Jar 只在首次使用时从 store 加载一次数据，
+// 之后的变化只会写入 store，不会再读取。
+//
+// 注意：未使用公共后缀列表（Public Suffix List），只拒绝了 Domain 为顶级域名（如 "com"）的 Cookie，
+// 所以不建议用于访问不受信任的站点。
+func New(store xkv.Hash[string]) *Jar {
+	return &Jar{
+		store:   store,
+		entries: make(map[string]*Entry),
+	}
+}
+
+// Jar 实现了 http.CookieJar，并发安全
+type Jar struct {
+	store xkv.Hash[string]
+
+	mux     sync.Mutex
+	entries map[string]*Entry // key 为 Entry.key()
+	loaded  bool
+	seq     uint64
+}
+
+// Entry 一个 Cookie 条目
+type Entry struct {
+	Name     string
+	Value    string
+	Domain   string // 小写，没有前导的 "."
+	Path     string
+	HostOnly bool // 为 true 时，只发送给和 Domain 完全相同的主机
+	Secure   bool
+	HttpOnly bool
+	SameSite http.SameSite
+	Expires  time.Time // 为零值时是会话 Cookie
+	Created  time.Time
+
+	seq uint64 // 同一时间创建的，按照设置的顺序排序
+}
+
+func (e *Entry) key() string {
+	return e.Domain + ";" + e.Path + ";" + e.Name
+}
+
+func (e *Entry) persistent() bool {
+	return !e.Expires.IsZero()
+}
+
+func (e *Entry) expired(now time.Time) bool {
+	return e.persistent() && !e.Expires.After(now)
+}
+
+// SetCookies 保存 u 的响应中的 Cookie
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	host, ok := canonicalHost(u)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	defPath := defaultPath(u.Path)
+
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	j.loadLocked()
+	for _, c := range cookies {
+		e, ok := newEntry(c, host, defPath, now)
+		if !ok {
+			continue
+		}
+		key := e.key()
+		if e.expired(now) {
+			// Max-Age<=0 或 Expires 为过去的时间：删除
+			if _, has := j.entries[key]; has {
+				delete(j.entries, key)
+				j.storeDelete(key)
+			}
+			continue
+		}
+		if old, has := j.entries[key]; has {
+			e.Created = old.Created
+			e.seq = old.seq
+		} else {
+			j.seq++
+			e.seq = j.seq
+		}
+		j.entries[key] = e
+		if e.persistent() {
+			j.storeSet(key, e)
+		}
+	}
+}
+
+// Cookies 返回发送给 u 的 Cookie，按照 RFC 6265 的要求，Path 更长的排在前面，同样长度的先创建的排在前面
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	host, ok := canonicalHost(u)
+	if !ok {
+		return nil
+	}
+	https := u.Scheme == "https" || u.Scheme == "wss"
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	now := time.Now()
+
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	j.loadLocked()
+	var selected []*Entry
+	for key, e := range j.entries {
+		if e.expired(now) {
+			delete(j.entries, key)
+			j.storeDelete(key)
+			continue
+		}
+		if e.Secure && !https {
+			continue
+		}
+		if !e.domainMatch(host) || !pathMatch(path, e.Path) {
+			continue
+		}
+		selected = append(selected, e)
+	}
+	slices.SortFunc(selected, func(a, b *Entry) int {
+		if d := len(b.Path) - len(a.Path); d != 0 {
+			return d
+		}
+		if c := a.Created.Compare(b.Created); c != 0 {
+			return c
+		}
+		if a.seq != b.seq {
+			return cmp.Compare(a.seq, b.seq)
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	cookies := make([]*http.Cookie, 0, len(selected))
+	for _, e := range selected {
+		cookies = append(cookies, &http.Cookie{Name: e.Name, Value: e.Value})
+	}
+	return cookies
+}
+
+// Entries 返回所有未过期的 Cookie
+func (j *Jar) Entries() []Entry {
+	now := time.Now()
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	j.loadLocked()
+	result := make([]Entry, 0, len(j.entries))
+	for _, e := range j.entries {
+		if !e.expired(now) {
+			result = append(result, *e)
+		}
+	}
+	slices.SortFunc(result, func(a, b Entry) int {
+		return strings.Compare(a.key(), b.key())
+	})
+	return result
+}
+
+// Clear 删除所有的 Cookie（包括 store 中的）
+func (j *Jar) Clear() {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	j.loadLocked()
+	for key, e := range j.entries {
+		if e.persistent() {
+			j.storeDelete(key)
+		}
+	}
+	clear(j.entries)
+}
+
+// loadLocked 首次使用时，从 store 中加载数据
+func (j *Jar) loadLocked() {
+	if j.loaded || j.store == nil {
+		return
+	}
+	j.loaded = true
+	now := time.Now()
+	_ = j.store.HRange(context.Background(), func(field string, value string) bool {
+		e := &Entry{}
+		if err := json.Unmarshal([]byte(value), e); err != nil || e.expired(now) || e.key() != field {
+			return true
+		}
+		j.entries[field] = e
+		return true
+	})
+}
+
+func (j *Jar) storeSet(key string, e *Entry) {
+	if j.store == nil {
+		return
+	}
+	bf, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	_ = j.store.HSet(context.Background(), key, string(bf))
+}
+
+func (j *Jar) storeDelete(key string) {
+	if j.store == nil {
+		return
+	}
+	_ = j.store.HDel(context.Background(), key)
+}
+
+func newEntry(c *http.Cookie, host string, defPath string, now time.Time) (*Entry, bool) {
+	if c.Name == "" {
+		return nil, false
+	}
+	e := &Entry{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+		SameSite: c.SameSite,
+		Created:  now,
+	}
+	if e.Path == "" || e.Path[0] != '/' {
+		e.Path = defPath
+	}
+
+	domain := strings.TrimPrefix(strings.ToLower(c.Domain), ".")
+	switch {
+	case domain == "":
+		e.Domain = host
+		e.HostOnly = true
+	case domain == host:
+		e.Domain = domain
+	case isIP(host) || !strings.Contains(domain, ".") || !strings.HasSuffix(host, "."+domain):
+		// IP 地址不能设置 Domain 属性；不能设置为顶级域名；只能设置为当前主机或者其父域名
+		return nil, false
+	default:
+		e.Domain = domain
+	}
+
+	switch {
+	case c.MaxAge < 0:
+		e.Expires = time.Unix(1, 0)
+	case c.MaxAge > 0:
+		e.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+	case !c.Expires.IsZero():
+		e.Expires = c.Expires
+		if !e.Expires.After(now) {
+			e.Expires = time.Unix(1, 0)
+		}
+	}
+	return e, true
+}
+
+func (e *Entry) domainMatch(host string) bool {
+	if e.Domain == host {
+		return true
+	}
+	return !e.HostOnly && !isIP(host) && strings.HasSuffix(host, "."+e.Domain)
+}
+
+// pathMatch RFC 6265 5.1.4
+func pathMatch(reqPath string, cookiePath string) bool {
+	if reqPath == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(reqPath, cookiePath) {
+		return false
+	}
+	return cookiePath[len(cookiePath)-1] == '/' || reqPath[len(cookiePath)] == '/'
+}
+
+// defaultPath RFC 6265 5.1.4
+func defaultPath(path string) string {
+	if path == "" || path[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndex(path, "/")
+	if i == 0 {
+		return "/"
+	}
+	return path[:i]
+}
+
+func canonicalHost(u *url.URL) (string, bool) {
+	if u == nil {
+		return "", false
+	}
+	host := u.Hostname()
+	if host == "" {
+		return "", false
+	}
+	return strings.TrimSuffix(strings.ToLower(host), "."), true
+}
+
+func isIP(host string) bool {
+	return net.ParseIP(host) != nil
+}
@@ -0,0 +1,81 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xcookiejar_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/store/xkv"
+	"github.com/xanygo/anygo/xhttp/xcookiejar"
+	"github.com/xanygo/anygo/xt"
+)
+
+func mustURL(t *testing.T, s string) *url.URL {
+	u, err := url.Parse(s)
+	xt.NoError(t, err)
+	return u
+}
+
+func cookieString(cs []*http.Cookie) string {
+	var s string
+	for i, c := range cs {
+		if i > 0 {
+			s += "; "
+		}
+		s += c.String()
+	}
+	return s
+}
+
+func TestJar(t *testing.T) {
+	jar := xcookiejar.New(nil)
+	jar.SetCookies(mustURL(t, "http://www.example.com/a/b"), []*http.Cookie{
+		{Name: "host", Value: "1"},
+		{Name: "domain", Value: "2", Domain: ".example.com", Path: "/"},
+		{Name: "secure", Value: "3", Secure: true, Path: "/"},
+		{Name: "path", Value: "4", Path: "/a/b"},
+		{Name: "tld", Value: "5", Domain: "com"},
+		{Name: "other", Value: "6", Domain: "other.com"},
+	})
+
+	xt.Equal(t, cookieString(jar.Cookies(mustURL(t, "http://www.example.com/a/b/c"))), "path=4; host=1; domain=2")
+	xt.Equal(t, cookieString(jar.Cookies(mustURL(t, "https://www.example.com/"))), "domain=2; secure=3")
+	xt.Equal(t, cookieString(jar.Cookies(mustURL(t, "http://api.example.com/a"))), "domain=2")
+	xt.Empty(t, jar.Cookies(mustURL(t, "http://example.org/")))
+	xt.Len(t, jar.Entries(), 4)
+
+	// 删除
+	jar.SetCookies(mustURL(t, "http://www.example.com/"), []*http.Cookie{
+		{Name: "domain", Domain: "example.com", Path: "/", MaxAge: -1},
+	})
+	xt.Equal(t, cookieString(jar.Cookies(mustURL(t, "http://www.example.com/a/x"))), "host=1")
+
+	jar.Clear()
+	xt.Empty(t, jar.Entries())
+}
+
+func TestJar_store(t *testing.T) {
+	store := xkv.NewMemoryStore().Hash("cookies")
+	u := mustURL(t, "http://127.0.0.1:8080/")
+
+	j1 := xcookiejar.New(store)
+	j1.SetCookies(u, []*http.Cookie{
+		{Name: "session", Value: "s"},
+		{Name: "persist", Value: "p", MaxAge: 3600},
+		{Name: "expired", Value: "e", Expires: time.Now().Add(-time.Hour)},
+		{Name: "ip", Value: "x", Domain: "0.1"},
+	})
+	xt.Equal(t, cookieString(j1.Cookies(u)), "session=s; persist=p")
+
+	// 只有持久化的 Cookie 会被保存
+	j2 := xcookiejar.New(store)
+	xt.Equal(t, cookieString(j2.Cookies(u)), "persist=p")
+
+	j2.Clear()
+	xt.Empty(t, xcookiejar.New(store).Entries())
+}
@@ -0,0 +1,283 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhandler
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/xanygo/anygo/ds/xctx"
+	"github.com/xanygo/anygo/xhttp"
+	"github.com/xanygo/anygo/xhttp/xjwt"
+	"github.com/xanygo/anygo/xlog"
+)
+
+// 认证相关的中间件：JWTAuth、BasicAuth、APIKeyAuth。
+//
+// 认证通过后，可以使用 AuthUser 读取当前的用户。
+// 若期望接口不需要认证，可以在注册的时候同时注册 meta 信息予以标记，具体如下:
+// router.Get("/health  meta|auth=no", handler)
+
+var ctxKeyAuthUser = xctx.NewKey()
+
+// AuthUser 读取认证中间件注入的当前用户：
+// BasicAuth 为用户名，APIKeyAuth 为 key 对应的名称，JWTAuth 为 sub 声明
+func AuthUser(ctx context.Context) string {
+	user, _ := ctx.Value(ctxKeyAuthUser).(string)
+	return user
+}
+
+func withAuthUser(r *http.Request, user string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), ctxKeyAuthUser, user))
+}
+
+func skipAuth(r *http.Request) bool {
+	value, ok := xhttp.ReadRouteInfo(r.Context()).GetMeta("auth")
+	return ok && value == "no"
+}
+
+func authFail(w http.ResponseWriter, r *http.Request, onError http.Handler, challenge string) {
+	if onError != nil {
+		onError.ServeHTTP(w, r)
+		return
+	}
+	if challenge != "" {
+		w.Header().Set("WWW-Authenticate", challenge)
+	}
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
+
+// JWTAuth 校验请求中的 JWT，校验通过后，可以使用 JWTFromContext 读取 token
+type JWTAuth struct {
+	// Verifier 必填
+	Verifier *xjwt.Verifier `json:"-" yaml:"-"`
+
+	// TokenLookup 读取 token 的位置，多个使用逗号连接，可选，默认为 header:Authorization。支持：
+	//  header:{Name}: 请求头，若是 Authorization，值需要是 "Bearer {token}" 格式
+	//  query:{Name}: query 参数
+	//  cookie:{Name}: cookie
+	TokenLookup string `json:"TokenLookup" yaml:"TokenLookup"`
+
+	// Optional 为 true 时，没有 token 的请求也会放行（token 无效的仍然会拒绝）
+	Optional bool `json:"Optional" yaml:"Optional"`
+
+	// OnError 可选，校验失败时的回调，默认返回 401
+	OnError http.Handler `json:"-" yaml:"-"`
+
+	xlog.WithLogger `json:"-" yaml:"-"`
+}
+
+func (ja *JWTAuth) getTokenLookup() []string {
+	if ja.TokenLookup == "" {
+		return []string{"header:Authorization"}
+	}
+	var result []string
+	for _, item := range strings.Split(ja.TokenLookup, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func (ja *JWTAuth) Next(handler http.Handler) http.Handler {
+	lookups := ja.getTokenLookup()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if skipAuth(r) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		raw := lookupToken(r, lookups)
+		if raw == "" {
+			if ja.Optional {
+				handler.ServeHTTP(w, r)
+				return
+			}
+			authFail(w, r, ja.OnError, `Bearer`)
+			return
+		}
+		token, err := ja.Verifier.Verify(r.Context(), raw)
+		if err != nil {
+			ja.AutoLogger().Warn(r.Context(), "JWT verify failed", xlog.ErrorAttr("error", err))
+			authFail(w, r, ja.OnError, `Bearer error="invalid_token"`)
+			return
+		}
+		r = withAuthUser(r, token.Claims.Subject())
+		r = r.WithContext(context.WithValue(r.Context(), ctxKeyJWT, token))
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func lookupToken(r *http.Request, lookups []string) string {
+	for _, lookup := range lookups {
+		from, name, _ := strings.Cut(lookup, ":")
+		var value string
+		switch from {
+		case "header":
+			value = r.Header.Get(name)
+			if strings.EqualFold(name, "Authorization") {
+				scheme, token, ok := strings.Cut(value, " ")
+				if !ok || !strings.EqualFold(scheme, "Bearer") {
+					continue
+				}
+				value = strings.TrimSpace(token)
+			}
+		case "query":
+			value = r.URL.Query().Get(name)
+		case "cookie":
+			if c, err := r.Cookie(name); err == nil {
+				value = c.Value
+			}
+		}
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+var ctxKeyJWT = xctx.NewKey()
+
+// JWTFromContext 读取 JWTAuth 校验通过的 token
+func JWTFromContext(ctx context.Context) *xjwt.Token {
+	token, _ := ctx.Value(ctxKeyJWT).(*xjwt.Token)
+	return token
+}
+
+// BasicAuth HTTP Basic 认证
+//
+// 可通过 xcfg 加载配置，如：
+//
+//	Realm: admin
+//	Users:
+//	  admin: "{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=" # htpasswd -nbs admin password
+type BasicAuth struct {
+	// Realm 可选，默认为 Restricted
+	Realm string `json:"Realm" yaml:"Realm"`
+
+	// Users 用户名和密码，密码支持的格式见 CheckPassword，可以使用 LoadHtpasswd 从 htpasswd 文件中读取
+	Users map[string]string `json:"Users" yaml:"Users"`
+
+	// Validate 可选，自定义的校验方法，优先级高于 Users
+	Validate func(ctx context.Context, user string, password string) bool `json:"-" yaml:"-"`
+
+	// OnError 可选，校验失败时的回调，默认返回 401
+	OnError http.Handler `json:"-" yaml:"-"`
+}
+
+func (ba *BasicAuth) getRealm() string {
+	if ba.Realm != "" {
+		return ba.Realm
+	}
+	return "Restricted"
+}
+
+func (ba *BasicAuth) Next(handler http.Handler) http.Handler {
+	challenge := `Basic realm=` + strconv.Quote(ba.getRealm()) + `, charset="UTF-8"`
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if skipAuth(r) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		user, password, ok := r.BasicAuth()
+		if !ok || !ba.check(r.Context(), user, password) {
+			authFail(w, r, ba.OnError, challenge)
+			return
+		}
+		handler.ServeHTTP(w, withAuthUser(r, user))
+	})
+}
+
+func (ba *BasicAuth) check(ctx context.Context, user string, password string) bool {
+	if ba.Validate != nil {
+		return ba.Validate(ctx, user, password)
+	}
+	hashed, ok := ba.Users[user]
+	if !ok {
+		// 用户不存在时，同样执行一次校验，减少通过耗时差异判断用户是否存在的可能
+		CheckPassword("{SHA}", password)
+		return false
+	}
+	return CheckPassword(hashed, password)
+}
+
+// APIKeyAuth 校验请求头或者 query 参数中的 API Key
+//
+// 可通过 xcfg 加载配置，如：
+//
+//	Header: X-API-Key
+//	Keys:
+//	  key-xxx: app1
+type APIKeyAuth struct {
+	// Header 读取 key 的请求头，可选，默认为 X-API-Key
+	Header string `json:"Header" yaml:"Header"`
+
+	// Query 读取 key 的 query 参数，可选，为空时不读取
+	Query string `json:"Query" yaml:"Query"`
+
+	// Keys 允许的 key 和其名称（可以使用 AuthUser 读取）
+	Keys map[string]string `json:"Keys" yaml:"Keys"`
+
+	// Validate 可选，自定义的校验方法，优先级高于 Keys，返回 key 的名称和是否有效
+	Validate func(ctx context.Context, key string) (string, bool) `json:"-" yaml:"-"`
+
+	// OnError 可选，校验失败时的回调，默认返回 401
+	OnError http.Handler `json:"-" yaml:"-"`
+}
+
+func (ak *APIKeyAuth) getHeader() string {
+	if ak.Header != "" {
+		return ak.Header
+	}
+	return "X-API-Key"
+}
+
+func (ak *APIKeyAuth) Next(handler http.Handler) http.Handler {
+	keys := make(map[[sha256.Size]byte]string, len(ak.Keys))
+	for k, name := range ak.Keys {
+		keys[sha256.Sum256([]byte(k))] = name
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if skipAuth(r) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		key := r.Header.Get(ak.getHeader())
+		if key == "" && ak.Query != "" {
+			key = r.URL.Query().Get(ak.Query)
+		}
+		var name string
+		var ok bool
+		if key != "" {
+			if ak.Validate != nil {
+				name, ok = ak.Validate(r.Context(), key)
+			} else {
+				name, ok = matchAPIKey(keys, key)
+			}
+		}
+		if !ok {
+			authFail(w, r, ak.OnError, "")
+			return
+		}
+		handler.ServeHTTP(w, withAuthUser(r, name))
+	})
+}
+
+// matchAPIKey 使用 key 的 sha256 值和所有的 key 做常量时间的比较
+func matchAPIKey(keys map[[sha256.Size]byte]string, key string) (string, bool) {
+	sum := sha256.Sum256([]byte(key))
+	var name string
+	var found bool
+	for k, v := range keys {
+		if subtle.ConstantTimeCompare(k[:], sum[:]) == 1 {
+			name, found = v, true
+		}
+	}
+	return name, found
+}
@@ -0,0 +1,139 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhandler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/xhttp/xjwt"
+	"github.com/xanygo/anygo/xt"
+)
+
+var userHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	_, _ = w.Write([]byte(AuthUser(r.Context())))
+})
+
+func TestCheckPassword(t *testing.T) {
+	cases := []struct {
+		hashed string
+		want   bool
+	}{
+		{hashed: "Hello world!", want: true},
+		{hashed: "{SHA}00hq6RNueFa8QiEjhep5cJRHWAI=", want: true},
+		{hashed: "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5", want: true},
+		{hashed: "$5$rounds=10000$saltstringsaltst$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA", want: true},
+		{hashed: "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1", want: true},
+		{hashed: "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc6", want: false},
+		{hashed: "{SHA}abc", want: false},
+		{hashed: "$apr1$abc$def", want: false},
+	}
+	for _, c := range cases {
+		xt.Equal(t, CheckPassword(c.hashed, "Hello world!"), c.want)
+	}
+	// 不支持的格式，不能当做明文比较
+	for _, hashed := range []string{"$1$abc$xyz", "{SSHA}abc", "$y$abc$xyz", "$argon2id$v=19$abc"} {
+		xt.False(t, CheckPassword(hashed, hashed))
+	}
+
+	users, err := ParseHtpasswd([]byte("# comment\nu1:{SHA}00hq6RNueFa8QiEjhep5cJRHWAI=\n\nu2:plain\n"))
+	xt.NoError(t, err)
+	xt.Equal(t, users, map[string]string{"u1": "{SHA}00hq6RNueFa8QiEjhep5cJRHWAI=", "u2": "plain"})
+	_, err = ParseHtpasswd([]byte("bad"))
+	xt.Error(t, err)
+	_, err = ParseHtpasswd([]byte("u1:$apr1$abc$def\n"))
+	xt.ErrorContains(t, err, "unsupported password format")
+}
+
+func TestBasicAuth(t *testing.T) {
+	h := (&BasicAuth{Users: map[string]string{"u1": "{SHA}00hq6RNueFa8QiEjhep5cJRHWAI="}}).Next(userHandler)
+	call := func(user, pass string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if user != "" {
+			req.SetBasicAuth(user, pass)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	w := call("", "")
+	xt.Equal(t, w.Code, http.StatusUnauthorized)
+	xt.Equal(t, w.Header().Get("WWW-Authenticate"), `Basic realm="Restricted", charset="UTF-8"`)
+	xt.Equal(t, call("u1", "bad").Code, http.StatusUnauthorized)
+	xt.Equal(t, call("u2", "Hello world!").Code, http.StatusUnauthorized)
+	w = call("u1", "Hello world!")
+	xt.Equal(t, w.Code, http.StatusOK)
+	xt.Equal(t, w.Body.String(), "u1")
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	h := (&APIKeyAuth{Query: "api_key", Keys: map[string]string{"k1": "app1", "k2": "app2"}}).Next(userHandler)
+	call := func(target string, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	xt.Equal(t, call("/", "").Code, http.StatusUnauthorized)
+	xt.Equal(t, call("/", "k3").Code, http.StatusUnauthorized)
+	xt.Equal(t, call("/", "k2").Body.String(), "app2")
+	xt.Equal(t, call("/?api_key=k1", "").Body.String(), "app1")
+}
+
+func TestJWTAuth(t *testing.T) {
+	secret := []byte("secret")
+	signer := &xjwt.Signer{Method: xjwt.HS256, Key: secret}
+	ja := &JWTAuth{
+		Verifier:    &xjwt.Verifier{Key: secret},
+		TokenLookup: "header:Authorization,cookie:jwt",
+	}
+	var token *xjwt.Token
+	h := ja.Next(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = JWTFromContext(r.Context())
+		userHandler.ServeHTTP(w, r)
+	}))
+	valid, err := signer.Sign(xjwt.Claims{"sub": "u1", "exp": time.Now().Add(time.Hour).Unix()})
+	xt.NoError(t, err)
+	expired, err := signer.Sign(xjwt.Claims{"sub": "u1", "exp": time.Now().Add(-time.Hour).Unix()})
+	xt.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	xt.Equal(t, w.Code, http.StatusUnauthorized)
+	xt.Equal(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+expired)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	xt.Equal(t, w.Code, http.StatusUnauthorized)
+	xt.Equal(t, w.Header().Get("WWW-Authenticate"), `Bearer error="invalid_token"`)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "bearer "+valid)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	xt.Equal(t, w.Code, http.StatusOK)
+	xt.Equal(t, w.Body.String(), "u1")
+	xt.Equal(t, token.Raw, valid)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "jwt", Value: valid})
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	xt.Equal(t, w.Code, http.StatusOK)
+
+	ja.Optional = true
+	w = httptest.NewRecorder()
+	h = ja.Next(userHandler)
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	xt.Equal(t, w.Code, http.StatusOK)
+	xt.Equal(t, w.Body.String(), "")
+}
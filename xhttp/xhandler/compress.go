@@ -0,0 +1,368 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhandler
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/xanygo/anygo/ds/xsync"
+	"github.com/xanygo/anygo/xhttp"
+)
+
+// DefaultCompressTypes Compress 默认会压缩的 Content-Type
+var DefaultCompressTypes = []string{
+	"text/*",
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"application/xhtml+xml",
+	"application/rss+xml",
+	"application/atom+xml",
+	"application/x-javascript",
+	"application/wasm",
+	"image/svg+xml",
+}
+
+// Compress 对响应内容使用 gzip 或者 deflate 压缩，依据请求头 Accept-Encoding（支持 q 值）选择压缩算法。
+//
+// 以下情况不会压缩：
+//  1. 请求是 HEAD 请求，或者是 Upgrade 请求（如 websocket）
+//  2. 响应已经设置了 Content-Encoding，或者有 Content-Range
+//  3. 响应的 Content-Type 不在 ContentTypes 中
+//  4. 响应内容小于 MinSize（若在达到 MinSize 之前调用了 Flush，则只要 Content-Type 满足条件就会压缩）
+//  5. 路由的 meta 信息里，存在 compress=no 属性
+//
+// 压缩之后，会删除 Content-Length，并将强 ETag 转换为弱 ETag（W/"xxx"）
+type Compress struct {
+	// Level 压缩级别，可选，默认为 gzip.DefaultCompression
+	Level int `json:"Level" yaml:"Level"`
+
+	// MinSize 最小压缩的字节数，可选，默认为 1024
+	MinSize int `json:"MinSize" yaml:"MinSize"`
+
+	// ContentTypes 需要压缩的 Content-Type，可以使用 "text/*" 这样的通配符，可选，默认为 DefaultCompressTypes
+	ContentTypes []string `json:"ContentTypes" yaml:"ContentTypes"`
+
+	once        sync.Once
+	gzipPool    *xsync.Pool[*gzip.Writer]
+	deflatePool *xsync.Pool[*zlib.Writer]
+}
+
+func (c *Compress) init() {
+	level := c.Level
+	if level == 0 || level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		level = gzip.DefaultCompression
+	}
+	c.gzipPool = xsync.NewPool(func() *gzip.Writer {
+		w, _ := gzip.NewWriterLevel(io.Discard, level)
+		return w
+	})
+	c.deflatePool = xsync.NewPool(func() *zlib.Writer {
+		w, _ := zlib.NewWriterLevel(io.Discard, level)
+		return w
+	})
+}
+
+func (c *Compress) getMinSize() int {
+	if c.MinSize > 0 {
+		return c.MinSize
+	}
+	return 1024
+}
+
+func (c *Compress) Next(handler http.Handler) http.Handler {
+	c.once.Do(c.init)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead || r.Header.Get("Upgrade") != "" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		routeInfo := xhttp.ReadRouteInfo(r.Context())
+		if value, ok := routeInfo.GetMeta("compress"); ok && value == "no" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		encoding := NegotiateEncoding(r.Header.Get("Accept-Encoding"), "gzip", "deflate")
+		if encoding == "" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressWriter{
+			w:        w,
+			c:        c,
+			encoding: encoding,
+		}
+		defer cw.close()
+		handler.ServeHTTP(cw, r)
+	})
+}
+
+func (c *Compress) typeAllowed(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	types := c.ContentTypes
+	if len(types) == 0 {
+		types = DefaultCompressTypes
+	}
+	for _, t := range types {
+		if t == mt {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(t, "*"); ok && strings.HasPrefix(mt, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// NegotiateEncoding 依据请求头 Accept-Encoding 的值，从 supported 中选择 q 值最大的编码，
+// q 值相同时，supported 中靠前的优先，若没有可接受的编码，返回空字符串。
+//
+// 如 NegotiateEncoding("deflate;q=0.5, gzip", "gzip", "deflate") 返回 "gzip"
+func NegotiateEncoding(acceptEncoding string, supported ...string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+	qs := make(map[string]float64)
+	wildcard := -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(p), "=")
+			if ok && strings.EqualFold(strings.TrimSpace(k), "q") {
+				if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					q = f
+				}
+			}
+		}
+		if name == "*" {
+			wildcard = q
+		} else {
+			qs[name] = q
+		}
+	}
+	var best string
+	var bestQ float64
+	for _, name := range supported {
+		q, ok := qs[name]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+var (
+	_ http.ResponseWriter         = (*compressWriter)(nil)
+	_ http.Flusher                = (*compressWriter)(nil)
+	_ http.Hijacker               = (*compressWriter)(nil)
+	_ xhttp.WrappedResponseWriter = (*compressWriter)(nil)
+)
+
+type compressWriter struct {
+	w        http.ResponseWriter
+	c        *Compress
+	encoding string
+
+	statusCode int
+	buf        []byte
+
+	decided  bool // 是否已经决定了是否压缩（已经发送了响应头）
+	hijacked bool
+	enc      io.WriteCloser
+}
+
+func (cw *compressWriter) Header() http.Header {
+	return cw.w.Header()
+}
+
+func (cw *compressWriter) WriteHeader(statusCode int) {
+	if cw.decided {
+		return
+	}
+	if statusCode < http.StatusOK {
+		// 1xx 的响应，如 103 Early Hints
+		cw.w.WriteHeader(statusCode)
+		return
+	}
+	cw.statusCode = statusCode
+	if statusCode == http.StatusNoContent || statusCode == http.StatusNotModified {
+		cw.decide(false)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.decided {
+		if !cw.canCompress() {
+			cw.decide(false)
+		} else if len(cw.buf)+len(p) < cw.c.getMinSize() {
+			cw.buf = append(cw.buf, p...)
+			return len(p), nil
+		} else {
+			cw.buf = append(cw.buf, p...)
+			cw.decide(true)
+			return len(p), cw.flushBuf()
+		}
+	}
+	if cw.enc != nil {
+		return cw.enc.Write(p)
+	}
+	return cw.w.Write(p)
+}
+
+// canCompress 在还未决定是否压缩前，判断响应是否可以压缩
+func (cw *compressWriter) canCompress() bool {
+	h := cw.w.Header()
+	if h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" {
+		return false
+	}
+	if cw.statusCode == http.StatusPartialContent {
+		return false
+	}
+	if cl := h.Get("Content-Length"); cl != "" {
+		if n, err := strconv.Atoi(cl); err == nil && n < cw.c.getMinSize() {
+			return false
+		}
+	}
+	ct := h.Get("Content-Type")
+	if ct == "" {
+		// 和 http.ResponseWriter 的行为一致，依据内容探测 Content-Type
+		return true
+	}
+	return cw.c.typeAllowed(ct)
+}
+
+// decide 决定是否压缩，并发送响应头
+func (cw *compressWriter) decide(compress bool) {
+	if cw.decided {
+		return
+	}
+	cw.decided = true
+	h := cw.w.Header()
+	if h.Get("Content-Type") == "" && len(cw.buf) > 0 && h.Get("Content-Encoding") == "" {
+		h.Set("Content-Type", http.DetectContentType(cw.buf))
+		if compress {
+			compress = cw.c.typeAllowed(h.Get("Content-Type"))
+		}
+	}
+	if compress || cw.c.typeAllowed(h.Get("Content-Type")) {
+		addVary(h, "Accept-Encoding")
+	}
+	if compress {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", cw.encoding)
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+		cw.enc = cw.newEncoder()
+	}
+	if cw.statusCode != 0 {
+		cw.w.WriteHeader(cw.statusCode)
+	}
+	if !compress {
+		_ = cw.flushBuf()
+	}
+}
+
+func (cw *compressWriter) newEncoder() io.WriteCloser {
+	if cw.encoding == "deflate" {
+		zw := cw.c.deflatePool.Get()
+		zw.Reset(cw.w)
+		return zw
+	}
+	gw := cw.c.gzipPool.Get()
+	gw.Reset(cw.w)
+	return gw
+}
+
+func (cw *compressWriter) flushBuf() error {
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	buf := cw.buf
+	cw.buf = nil
+	var err error
+	if cw.enc != nil {
+		_, err = cw.enc.Write(buf)
+	} else {
+		_, err = cw.w.Write(buf)
+	}
+	return err
+}
+
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		cw.decide(cw.canCompress())
+		_ = cw.flushBuf()
+	}
+	if f, ok := cw.enc.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	_ = http.NewResponseController(cw.w).Flush()
+}
+
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(cw.w).Hijack()
+	if err == nil {
+		cw.hijacked = true
+	}
+	return conn, rw, err
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.w
+}
+
+func (cw *compressWriter) close() {
+	if cw.hijacked {
+		return
+	}
+	if !cw.decided {
+		cw.decide(len(cw.buf) >= cw.c.getMinSize() && cw.canCompress())
+	}
+	if cw.enc == nil {
+		return
+	}
+	_ = cw.enc.Close()
+	switch enc := cw.enc.(type) {
+	case *gzip.Writer:
+		enc.Reset(io.Discard)
+		cw.c.gzipPool.Put(enc)
+	case *zlib.Writer:
+		enc.Reset(io.Discard)
+		cw.c.deflatePool.Put(enc)
+	}
+	cw.enc = nil
+}
+
+func addVary(h http.Header, value string) {
+	for _, v := range h.Values("Vary") {
+		for _, item := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(item), value) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", value)
+}
@@ -0,0 +1,142 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhandler
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/xanygo/anygo/xhttp"
+	"github.com/xanygo/anygo/xt"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	cases := []struct {
+		accept string
+		want   string
+	}{
+		{accept: "", want: ""},
+		{accept: "gzip", want: "gzip"},
+		{accept: "deflate", want: "deflate"},
+		{accept: "gzip, deflate, br", want: "gzip"},
+		{accept: "deflate;q=1.0, gzip;q=0.5", want: "deflate"},
+		{accept: "gzip;q=0, deflate", want: "deflate"},
+		{accept: "*", want: "gzip"},
+		{accept: "*;q=0.5, gzip;q=0", want: "deflate"},
+		{accept: "br, identity", want: ""},
+	}
+	for _, c := range cases {
+		xt.Equal(t, NegotiateEncoding(c.accept, "gzip", "deflate"), c.want)
+	}
+}
+
+func TestCompress(t *testing.T) {
+	body := strings.Repeat("hello world ", 200)
+	c := &Compress{}
+	h := c.Next(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", r.URL.Query().Get("ct"))
+		w.Header().Set("ETag", `"abc"`)
+		w.Header().Set("Content-Length", "2400")
+		_, _ = io.WriteString(w, body[:len(body)/2])
+		_, _ = io.WriteString(w, body[len(body)/2:])
+	}))
+
+	t.Run("gzip", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?ct=text/plain", nil)
+		req.Header.Set("Accept-Encoding", "gzip, deflate")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		xt.Equal(t, w.Header().Get("Content-Encoding"), "gzip")
+		xt.Equal(t, w.Header().Get("Content-Length"), "")
+		xt.Equal(t, w.Header().Get("ETag"), `W/"abc"`)
+		xt.Equal(t, w.Header().Get("Vary"), "Accept-Encoding")
+		xt.Less(t, w.Body.Len(), len(body))
+		zr, err := gzip.NewReader(w.Body)
+		xt.NoError(t, err)
+		got, err := io.ReadAll(zr)
+		xt.NoError(t, err)
+		xt.Equal(t, string(got), body)
+	})
+
+	t.Run("deflate", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?ct=application/json", nil)
+		req.Header.Set("Accept-Encoding", "gzip;q=0.1, deflate")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		xt.Equal(t, w.Header().Get("Content-Encoding"), "deflate")
+		zr, err := zlib.NewReader(w.Body)
+		xt.NoError(t, err)
+		got, err := io.ReadAll(zr)
+		xt.NoError(t, err)
+		xt.Equal(t, string(got), body)
+	})
+
+	t.Run("not accept", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?ct=text/plain", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		xt.Equal(t, w.Header().Get("Content-Encoding"), "")
+		xt.Equal(t, w.Header().Get("ETag"), `"abc"`)
+		xt.Equal(t, w.Body.String(), body)
+	})
+
+	t.Run("type not allowed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?ct=image/png", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		xt.Equal(t, w.Header().Get("Content-Encoding"), "")
+		xt.Equal(t, w.Header().Get("Vary"), "")
+		xt.Equal(t, w.Header().Get("Content-Length"), "2400")
+		xt.Equal(t, w.Body.String(), body)
+	})
+}
+
+func TestCompress_small(t *testing.T) {
+	h := (&Compress{}).Next(okHandler)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	xt.Equal(t, w.Header().Get("Content-Encoding"), "")
+	xt.Equal(t, w.Header().Get("Content-Type"), "text/plain; charset=utf-8")
+	xt.Equal(t, w.Header().Get("Vary"), "Accept-Encoding")
+	xt.Equal(t, w.Body.String(), "ok")
+
+	h = (&Compress{}).Next(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	xt.Equal(t, w.Code, http.StatusNoContent)
+	xt.Equal(t, w.Header().Get("Content-Encoding"), "")
+}
+
+func TestCompress_flush(t *testing.T) {
+	h := (&Compress{}).Next(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		sw := &xhttp.StatusWriter{W: w}
+		sw.WriteHeader(http.StatusAccepted)
+		_, _ = io.WriteString(sw, "data: 1\n\n")
+		_ = http.NewResponseController(sw).Flush()
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	xt.Equal(t, w.Code, http.StatusAccepted)
+	xt.True(t, w.Flushed)
+	xt.Equal(t, w.Header().Get("Content-Encoding"), "gzip")
+	zr, err := gzip.NewReader(w.Body)
+	xt.NoError(t, err)
+	got, err := io.ReadAll(zr)
+	xt.NoError(t, err)
+	xt.Equal(t, string(got), "data: 1\n\n")
+}
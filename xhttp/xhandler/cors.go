@@ -0,0 +1,169 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhandler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/xanygo/anygo/ds/xtype"
+)
+
+var defaultCORSMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// CORS 跨域资源共享（Cross-Origin Resource Sharing）中间件，
+// 处理预检请求（OPTIONS），并给跨域请求添加 Access-Control-* 响应头。
+//
+// 可通过 xcfg 加载配置，如：
+//
+//	AllowOrigins: ["https://example.com", "https://*.example.com"]
+//	AllowCredentials: true
+//	MaxAge: 10m
+type CORS struct {
+	// AllowOrigins 允许的来源，如 "https://example.com"，支持通配符，如 "https://*.example.com"，
+	// 为 "*" 时允许所有来源
+	AllowOrigins []string `json:"AllowOrigins" yaml:"AllowOrigins"`
+
+	// AllowOriginFunc 可选，自定义判断来源是否允许，优先于 AllowOrigins
+	AllowOriginFunc func(origin string, r *http.Request) bool `json:"-" yaml:"-"`
+
+	// AllowMethods 预检请求中允许的方法，可选，默认为 GET、HEAD、POST、PUT、PATCH、DELETE、OPTIONS
+	AllowMethods []string `json:"AllowMethods" yaml:"AllowMethods"`
+
+	// AllowHeaders 预检请求中允许的请求头，可选，为空时允许预检请求中 Access-Control-Request-Headers 的所有请求头
+	AllowHeaders []string `json:"AllowHeaders" yaml:"AllowHeaders"`
+
+	// ExposeHeaders 允许浏览器读取的响应头，可选
+	ExposeHeaders []string `json:"ExposeHeaders" yaml:"ExposeHeaders"`
+
+	// AllowCredentials 是否允许携带 Cookie 等凭证
+	AllowCredentials bool `json:"AllowCredentials" yaml:"AllowCredentials"`
+
+	// MaxAge 预检请求结果的缓存时长，可选，为 0 时不发送 Access-Control-Max-Age
+	MaxAge xtype.Duration `json:"MaxAge" yaml:"MaxAge"`
+}
+
+func (c *CORS) Next(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		if preflight {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+		}
+		if !c.originAllowed(origin, r) {
+			if preflight {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+			handler.ServeHTTP(w, r)
+			return
+		}
+		if c.allowAny() && !c.AllowCredentials {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if c.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+		if !preflight {
+			if len(c.ExposeHeaders) > 0 {
+				h.Set("Access-Control-Expose-Headers", strings.Join(c.ExposeHeaders, ", "))
+			}
+			handler.ServeHTTP(w, r)
+			return
+		}
+		c.preflight(w, r)
+	})
+}
+
+func (c *CORS) preflight(w http.ResponseWriter, r *http.Request) {
+	h := w.Header()
+	method := r.Header.Get("Access-Control-Request-Method")
+	methods := c.AllowMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	if !containsFold(methods, method) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	h.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+
+	if reqHeaders := r.Header.Get("Access-Control-Request-Headers"); reqHeaders != "" {
+		if len(c.AllowHeaders) == 0 {
+			h.Set("Access-Control-Allow-Headers", reqHeaders)
+		} else {
+			for _, name := range strings.Split(reqHeaders, ",") {
+				if !containsFold(c.AllowHeaders, strings.TrimSpace(name)) {
+					http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+					return
+				}
+			}
+			h.Set("Access-Control-Allow-Headers", strings.Join(c.AllowHeaders, ", "))
+		}
+	}
+	if c.MaxAge > 0 {
+		h.Set("Access-Control-Max-Age", strconv.FormatInt(int64(c.MaxAge.Duration().Seconds()), 10))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *CORS) allowAny() bool {
+	for _, o := range c.AllowOrigins {
+		if o == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CORS) originAllowed(origin string, r *http.Request) bool {
+	if c.AllowOriginFunc != nil {
+		return c.AllowOriginFunc(origin, r)
+	}
+	for _, pattern := range c.AllowOrigins {
+		if matchOrigin(pattern, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchOrigin 判断来源是否匹配规则，规则中可以使用一个 * 通配符，如 "https://*.example.com"
+func matchOrigin(pattern string, origin string) bool {
+	if pattern == "*" || strings.EqualFold(pattern, origin) {
+		return true
+	}
+	prefix, suffix, ok := strings.Cut(pattern, "*")
+	if !ok {
+		return false
+	}
+	origin = strings.ToLower(origin)
+	prefix, suffix = strings.ToLower(prefix), strings.ToLower(suffix)
+	return len(origin) > len(prefix)+len(suffix) &&
+		strings.HasPrefix(origin, prefix) &&
+		strings.HasSuffix(origin, suffix)
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,281 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhandler
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+
+	"github.com/xanygo/anygo/ds/xctx"
+	"github.com/xanygo/anygo/store/xsession"
+	"github.com/xanygo/anygo/xhttp"
+	"github.com/xanygo/anygo/xlog"
+)
+
+// CSRF token 的存储方式
+const (
+	// CSRFDoubleSubmit 双重提交：token 存储在 Cookie 中，提交时请求头或者表单中的 token 需要和 Cookie 中的一致
+	CSRFDoubleSubmit = "double-submit"
+
+	// CSRFSession 同步器 token：token 存储在 xsession.Session 中，需要在 xsession.HTTPHandler 之后使用
+	CSRFSession = "session"
+)
+
+// CSRF 跨站请求伪造（Cross-Site Request Forgery）防护中间件。
+//
+// 对于 GET、HEAD、OPTIONS、TRACE 请求，会确保已生成 token，并可使用 CSRFToken、CSRFTemplateFuncs 读取；
+// 对于其他请求，会校验请求头（默认 X-CSRF-Token）或者表单字段（默认 csrf_token）中的 token。
+//
+// 若期望接口不校验 CSRF token，可以在注册的时候同时注册 meta 信息予以标记，具体如下:
+// router.Post("/api/callback  meta|csrf=no", handler)
+//
+// 可通过 xcfg 加载配置，如：
+//
+//	Mode: session
+//	HeaderName: X-CSRF-Token
+type CSRF struct {
+	// Mode token 的存储方式，可选，默认为 CSRFDoubleSubmit，可选值 CSRFSession
+	Mode string `json:"Mode" yaml:"Mode"`
+
+	// CookieName 双重提交模式下，存储 token 的 Cookie 名称，可选，默认为 csrf_token
+	CookieName string `json:"CookieName" yaml:"CookieName"`
+
+	// CookieSecure 双重提交模式下，Cookie 是否只在 HTTPS 下发送
+	CookieSecure bool `json:"CookieSecure" yaml:"CookieSecure"`
+
+	// SessionKey Session 模式下，存储 token 的 key，可选，默认为 csrf_token
+	SessionKey string `json:"SessionKey" yaml:"SessionKey"`
+
+	// HeaderName 提交 token 的请求头名称，可选，默认为 X-CSRF-Token
+	HeaderName string `json:"HeaderName" yaml:"HeaderName"`
+
+	// FormField 提交 token 的表单字段名称，可选，默认为 csrf_token
+	FormField string `json:"FormField" yaml:"FormField"`
+
+	// Skip 可选，判断当前请求是否跳过校验
+	Skip func(r *http.Request) bool `json:"-" yaml:"-"`
+
+	// OnError 可选，校验失败时的回调，默认返回 403
+	OnError http.Handler `json:"-" yaml:"-"`
+
+	xlog.WithLogger `json:"-" yaml:"-"`
+}
+
+func (c *CSRF) getCookieName() string {
+	if c.CookieName != "" {
+		return c.CookieName
+	}
+	return "csrf_token"
+}
+
+func (c *CSRF) getSessionKey() string {
+	if c.SessionKey != "" {
+		return c.SessionKey
+	}
+	return "csrf_token"
+}
+
+func (c *CSRF) getHeaderName() string {
+	if c.HeaderName != "" {
+		return c.HeaderName
+	}
+	return "X-CSRF-Token"
+}
+
+func (c *CSRF) getFormField() string {
+	if c.FormField != "" {
+		return c.FormField
+	}
+	return "csrf_token"
+}
+
+func (c *CSRF) Next(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c.Skip != nil && c.Skip(r) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		routeInfo := xhttp.ReadRouteInfo(r.Context())
+		if value, ok := routeInfo.GetMeta("csrf"); ok && value == "no" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		token, err := c.loadToken(r)
+		if err != nil {
+			c.AutoLogger().Warn(r.Context(), "CSRF load token failed", xlog.ErrorAttr("error", err))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		if !isSafeMethod(r.Method) {
+			if token == "" || !c.verify(r, token) {
+				c.AutoLogger().Warn(r.Context(), "CSRF token invalid",
+					xlog.String("method", r.Method),
+					xlog.String("path", r.URL.Path),
+				)
+				c.fail(w, r)
+				return
+			}
+		}
+		if token == "" {
+			token = newCSRFToken()
+			if err = c.saveToken(w, r, token); err != nil {
+				c.AutoLogger().Warn(r.Context(), "CSRF save token failed", xlog.ErrorAttr("error", err))
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+		}
+		ctx := context.WithValue(r.Context(), ctxKeyCSRF, &csrfValue{token: token, field: c.getFormField()})
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (c *CSRF) fail(w http.ResponseWriter, r *http.Request) {
+	if c.OnError != nil {
+		c.OnError.ServeHTTP(w, r)
+		return
+	}
+	http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+}
+
+func (c *CSRF) loadToken(r *http.Request) (string, error) {
+	if c.Mode == CSRFSession {
+		ss := xsession.FromContext(r.Context())
+		if ss == nil {
+			return "", errNoSession
+		}
+		token, _ := ss.Get(r.Context(), c.getSessionKey())
+		return token, nil
+	}
+	cookie, err := r.Cookie(c.getCookieName())
+	if err != nil {
+		return "", nil
+	}
+	return cookie.Value, nil
+}
+
+func (c *CSRF) saveToken(w http.ResponseWriter, r *http.Request, token string) error {
+	if c.Mode == CSRFSession {
+		ss := xsession.FromContext(r.Context())
+		if ss == nil {
+			return errNoSession
+		}
+		if err := ss.Set(r.Context(), c.getSessionKey(), token); err != nil {
+			return err
+		}
+		return ss.Save(r.Context())
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     c.getCookieName(),
+		Value:    token,
+		Path:     "/",
+		Secure:   c.CookieSecure,
+		SameSite: http.SameSiteLaxMode,
+		// 双重提交模式下，页面的 JS 需要读取 Cookie 中的 token，所以不能设置 HttpOnly
+		HttpOnly: false,
+	})
+	return nil
+}
+
+// verify 校验请求头或者表单中提交的 token
+func (c *CSRF) verify(r *http.Request, token string) bool {
+	got := r.Header.Get(c.getHeaderName())
+	if got == "" {
+		got = c.formToken(r)
+	}
+	return got != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+// formToken 读取表单中的 token，读取后请求的 Body 仍然可以被后续的 Handler 读取
+func (c *CSRF) formToken(r *http.Request) string {
+	if r.Body == nil {
+		return ""
+	}
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mt {
+	case "application/x-www-form-urlencoded":
+		body, err := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			return ""
+		}
+		values, _ := url.ParseQuery(string(body))
+		return values.Get(c.getFormField())
+	case "multipart/form-data":
+		if r.MultipartForm == nil {
+			if err := r.ParseMultipartForm(xhttp.DefaultMaxMemory); err != nil {
+				return ""
+			}
+		}
+		if vs := r.MultipartForm.Value[c.getFormField()]; len(vs) > 0 {
+			return vs[0]
+		}
+	}
+	return ""
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
+
+func newCSRFToken() string {
+	bf := make([]byte, 32)
+	_, _ = rand.Read(bf)
+	return base64.RawURLEncoding.EncodeToString(bf)
+}
+
+var errNoSession = errors.New("no session in context, should use xsession.HTTPHandler first")
+
+var ctxKeyCSRF = xctx.NewKey()
+
+type csrfValue struct {
+	token string
+	field string
+}
+
+// CSRFToken 读取当前请求的 CSRF token，需要在 CSRF 中间件之后使用
+func CSRFToken(ctx context.Context) string {
+	v, _ := ctx.Value(ctxKeyCSRF).(*csrfValue)
+	if v == nil {
+		return ""
+	}
+	return v.token
+}
+
+// CSRFTemplateFuncs 返回用于 html/template 的模版函数：
+//
+//	csrf_token: 返回 token，如 <meta name="csrf-token" content="{{ csrf_token }}">
+//	csrf_field: 返回隐藏的表单字段，如 <form method="post">{{ csrf_field }}</form>
+func CSRFTemplateFuncs(ctx context.Context) template.FuncMap {
+	v, _ := ctx.Value(ctxKeyCSRF).(*csrfValue)
+	if v == nil {
+		v = &csrfValue{}
+	}
+	return template.FuncMap{
+		"csrf_token": func() string {
+			return v.token
+		},
+		"csrf_field": func() template.HTML {
+			if v.token == "" {
+				return ""
+			}
+			return template.HTML(`<input type="hidden" name="` + template.HTMLEscapeString(v.field) +
+				`" value="` + template.HTMLEscapeString(v.token) + `">`)
+		},
+	}
+}
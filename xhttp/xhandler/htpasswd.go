@@ -0,0 +1,223 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhandler
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadHtpasswd 读取 htpasswd 文件，返回用户名和密码的对应关系，可用于 BasicAuth.Users
+func LoadHtpasswd(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseHtpasswd(data)
+}
+
+// ParseHtpasswd 解析 htpasswd 格式的内容，每行一个 "用户名:密码"，# 开头的为注释。
+//
+// 支持的密码格式见 CheckPassword，有不支持的格式（如 bcrypt、Apache MD5）时返回错误
+func ParseHtpasswd(data []byte) (map[string]string, error) {
+	users := make(map[string]string)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	var no int
+	for sc.Scan() {
+		no++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		user, pass, ok := strings.Cut(line, ":")
+		if !ok || user == "" {
+			return nil, fmt.Errorf("htpasswd line %d: invalid format", no)
+		}
+		if hashScheme(pass) == "" {
+			return nil, fmt.Errorf("htpasswd line %d: unsupported password format of user %q", no, user)
+		}
+		users[user] = pass
+	}
+	return users, sc.Err()
+}
+
+// CheckPassword 校验密码，hashed 支持以下格式（不支持 bcrypt 和 Apache MD5）：
+//
+//	{SHA}base64(sha1(password))：htpasswd -s 生成
+//	$5$salt$hash：SHA-256 crypt，可以带 rounds=N$
+//	$6$salt$hash：SHA-512 crypt，可以带 rounds=N$
+//	不以 $ 或 { 开头：明文，htpasswd -p 生成
+//
+// 其他以 $ 或 { 开头的格式（如 $apr1$、$2y$、{SSHA}）不支持，始终返回 false
+func CheckPassword(hashed string, password string) bool {
+	var expect string
+	switch hashScheme(hashed) {
+	case "{SHA}":
+		sum := sha1.Sum([]byte(password))
+		expect = "{SHA}" + base64.StdEncoding.EncodeToString(sum[:])
+	case "$5$":
+		expect = shaCrypt(sha256.New, "$5$", hashed, password)
+	case "$6$":
+		expect = shaCrypt(sha512.New, "$6$", hashed, password)
+	case "plain":
+		expect = password
+	default:
+		return false
+	}
+	return expect != "" && subtle.ConstantTimeCompare([]byte(expect), []byte(hashed)) == 1
+}
+
+// hashScheme 返回密码的格式：{SHA}、$5$、$6$ 或者 plain（明文），不支持的格式返回空字符串
+func hashScheme(hashed string) string {
+	switch {
+	case strings.HasPrefix(hashed, "{SHA}"):
+		return "{SHA}"
+	case strings.HasPrefix(hashed, "$5$"):
+		return "$5$"
+	case strings.HasPrefix(hashed, "$6$"):
+		return "$6$"
+	case strings.HasPrefix(hashed, "$"), strings.HasPrefix(hashed, "{"):
+		return ""
+	default:
+		return "plain"
+	}
+}
+
+const shaCryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// SHA-crypt 输出时字节的排列顺序
+var (
+	sha256CryptOrder = [][3]int{
+		{0, 10, 20}, {21, 1, 11}, {12, 22, 2}, {3, 13, 23}, {24, 4, 14},
+		{15, 25, 5}, {6, 16, 26}, {27, 7, 17}, {18, 28, 8}, {9, 19, 29},
+	}
+	sha512CryptOrder = [][3]int{
+		{0, 21, 42}, {22, 43, 1}, {44, 2, 23}, {3, 24, 45}, {25, 46, 4},
+		{47, 5, 26}, {6, 27, 48}, {28, 49, 7}, {50, 8, 29}, {9, 30, 51},
+		{31, 52, 10}, {53, 11, 32}, {12, 33, 54}, {34, 55, 13}, {56, 14, 35},
+		{15, 36, 57}, {37, 58, 16}, {59, 17, 38}, {18, 39, 60}, {40, 61, 19},
+		{62, 20, 41},
+	}
+)
+
+// shaCrypt 使用 setting（$5$[rounds=N$]salt[$hash]）中的参数计算密码的 SHA-crypt 值，
+// 算法见 https://www.akkadia.org/drepper/SHA-crypt.txt ，参数无效时返回空字符串
+func shaCrypt(newHash func() hash.Hash, prefix string, setting string, password string) string {
+	rest := strings.TrimPrefix(setting, prefix)
+	rounds := 5000
+	customRounds := false
+	if v, after, ok := strings.Cut(rest, "$"); ok && strings.HasPrefix(v, "rounds=") {
+		n, err := strconv.Atoi(strings.TrimPrefix(v, "rounds="))
+		if err != nil {
+			return ""
+		}
+		rounds = min(max(n, 1000), 999999999)
+		customRounds = true
+		rest = after
+	}
+	salt, _, _ := strings.Cut(rest, "$")
+	if len(salt) > 16 {
+		salt = salt[:16]
+	}
+	pw, sa := []byte(password), []byte(salt)
+
+	h := newHash()
+	h.Write(pw)
+	h.Write(sa)
+	h.Write(pw)
+	b := h.Sum(nil)
+	size := len(b)
+
+	h.Reset()
+	h.Write(pw)
+	h.Write(sa)
+	for i := len(pw); i > 0; i -= size {
+		h.Write(b[:min(i, size)])
+	}
+	for i := len(pw); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			h.Write(b)
+		} else {
+			h.Write(pw)
+		}
+	}
+	a := h.Sum(nil)
+
+	h.Reset()
+	for range len(pw) {
+		h.Write(pw)
+	}
+	dp := h.Sum(nil)
+	pSeq := make([]byte, 0, len(pw))
+	for i := len(pw); i > 0; i -= size {
+		pSeq = append(pSeq, dp[:min(i, size)]...)
+	}
+
+	h.Reset()
+	for range 16 + int(a[0]) {
+		h.Write(sa)
+	}
+	ds := h.Sum(nil)
+	sSeq := ds[:len(sa)]
+
+	c := a
+	for i := 0; i < rounds; i++ {
+		h.Reset()
+		if i&1 != 0 {
+			h.Write(pSeq)
+		} else {
+			h.Write(c)
+		}
+		if i%3 != 0 {
+			h.Write(sSeq)
+		}
+		if i%7 != 0 {
+			h.Write(pSeq)
+		}
+		if i&1 != 0 {
+			h.Write(c)
+		} else {
+			h.Write(pSeq)
+		}
+		c = h.Sum(nil)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	if customRounds {
+		sb.WriteString("rounds=" + strconv.Itoa(rounds) + "$")
+	}
+	sb.WriteString(salt)
+	sb.WriteByte('$')
+	encode := func(b2, b1, b0 byte, n int) {
+		w := uint(b2)<<16 | uint(b1)<<8 | uint(b0)
+		for range n {
+			sb.WriteByte(shaCryptAlphabet[w&0x3f])
+			w >>= 6
+		}
+	}
+	if size == sha256.Size {
+		for _, o := range sha256CryptOrder {
+			encode(c[o[0]], c[o[1]], c[o[2]], 4)
+		}
+		encode(0, c[31], c[30], 3)
+	} else {
+		for _, o := range sha512CryptOrder {
+			encode(c[o[0]], c[o[1]], c[o[2]], 4)
+		}
+		encode(0, 0, c[63], 2)
+	}
+	return sb.String()
+}
@@ -0,0 +1,94 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhandler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xtype"
+)
+
+// RequestLimit 限制请求 Body 的大小，以及读取请求、写响应的超时时间
+//
+// 可通过 xcfg 加载配置，如：
+//
+//	MaxBodySize: 10MB
+//	ReadTimeout: 30s
+type RequestLimit struct {
+	// MaxBodySize 请求 Body 的最大字节数，可选，为 0 时不限制。
+	// 若请求的 Content-Length 超出此值，直接返回 413，
+	// 否则读取 Body 超出此值时，读取会返回 *http.MaxBytesError
+	MaxBodySize xtype.ByteCount `json:"MaxBodySize" yaml:"MaxBodySize"`
+
+	// ReadTimeout 读取请求 Body 的超时时间，可选，为 0 时不限制
+	ReadTimeout xtype.Duration `json:"ReadTimeout" yaml:"ReadTimeout"`
+
+	// WriteTimeout 写响应的超时时间，可选，为 0 时不限制
+	WriteTimeout xtype.Duration `json:"WriteTimeout" yaml:"WriteTimeout"`
+}
+
+func (rl *RequestLimit) Next(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rl.MaxBodySize > 0 {
+			limit := int64(rl.MaxBodySize)
+			if r.ContentLength > limit {
+				w.Header().Set("Connection", "close")
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+			if r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
+			}
+		}
+		if rl.ReadTimeout > 0 || rl.WriteTimeout > 0 {
+			rc := http.NewResponseController(w)
+			now := time.Now()
+			if rl.ReadTimeout > 0 {
+				_ = rc.SetReadDeadline(now.Add(rl.ReadTimeout.Duration()))
+			}
+			if rl.WriteTimeout > 0 {
+				_ = rc.SetWriteDeadline(now.Add(rl.WriteTimeout.Duration()))
+			}
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Security 组合多个安全相关的中间件，为 nil 的不生效，执行的顺序为：
+// RequestLimit -> SecureHeaders -> CORS -> CSRF
+//
+// 可通过 xcfg 加载配置，如：
+//
+//	Limit:
+//	  MaxBodySize: 10MB
+//	Headers:
+//	  HSTSMaxAge: 8760h
+//	CORS:
+//	  AllowOrigins: ["https://*.example.com"]
+//	CSRF:
+//	  Mode: double-submit
+type Security struct {
+	Limit   *RequestLimit  `json:"Limit"   yaml:"Limit"`
+	Headers *SecureHeaders `json:"Headers" yaml:"Headers"`
+	CORS    *CORS          `json:"CORS"    yaml:"CORS"`
+	CSRF    *CSRF          `json:"CSRF"    yaml:"CSRF"`
+}
+
+func (s *Security) Next(handler http.Handler) http.Handler {
+	if s.CSRF != nil {
+		handler = s.CSRF.Next(handler)
+	}
+	if s.CORS != nil {
+		handler = s.CORS.Next(handler)
+	}
+	if s.Headers != nil {
+		handler = s.Headers.Next(handler)
+	}
+	if s.Limit != nil {
+		handler = s.Limit.Next(handler)
+	}
+	return handler
+}
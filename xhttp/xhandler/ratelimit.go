@@ -0,0 +1,133 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhandler
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xlimiter"
+	"github.com/xanygo/anygo/xhttp"
+	"github.com/xanygo/anygo/xlog"
+)
+
+// RateLimit 按照客户端或者其他 key 限制请求速率，超出限制时返回 429，
+// 并设置 Retry-After 响应头。所有的响应都会设置 RateLimit-Limit、RateLimit-Remaining、RateLimit-Reset 响应头。
+//
+// 若期望接口不限流，可以在注册的时候同时注册 meta 信息予以标记，具体如下:
+// router.Get("/health  meta|ratelimit=no", handler)
+//
+// 限流器出错时（如 Redis 不可用），请求会被放行
+type RateLimit struct {
+	// Limiter 限流器，必填
+	Limiter *xlimiter.Limiter `json:"-" yaml:"-"`
+
+	// KeyBy 限流的 key 的组成，多个使用逗号连接，可选，默认为 ip。支持：
+	//  ip: 客户端 IP，使用 xhttp.ClientIP 读取
+	//  header:{Name}: 请求头，如 header:X-API-Key
+	//  route: 路由的 Pattern，未使用 xhttp.Router 时为请求的 Path
+	//  method: 请求方法
+	// 如 "route,ip" 表示每个客户端 IP 访问每个路由的速率分别限制
+	KeyBy string `json:"KeyBy" yaml:"KeyBy"`
+
+	// KeyFunc 可选，自定义的 key 的生成方法，优先级高于 KeyBy，
+	// 返回空字符串时不限流
+	KeyFunc func(r *http.Request) string `json:"-" yaml:"-"`
+
+	// OnLimited 可选，超出限制时的回调，默认返回 429
+	OnLimited http.Handler `json:"-" yaml:"-"`
+
+	xlog.WithLogger `json:"-" yaml:"-"`
+}
+
+func (rl *RateLimit) getKeyBy() []string {
+	if rl.KeyBy == "" {
+		return []string{"ip"}
+	}
+	var result []string
+	for _, item := range strings.Split(rl.KeyBy, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func (rl *RateLimit) Next(handler http.Handler) http.Handler {
+	keyBy := rl.getKeyBy()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		routeInfo := xhttp.ReadRouteInfo(r.Context())
+		if value, ok := routeInfo.GetMeta("ratelimit"); ok && value == "no" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		var key string
+		if rl.KeyFunc != nil {
+			key = rl.KeyFunc(r)
+		} else {
+			key = rateLimitKey(r, keyBy)
+		}
+		if key == "" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		ret, err := rl.Limiter.Allow(r.Context(), key)
+		if err != nil {
+			rl.AutoLogger().Warn(r.Context(), "RateLimit failed", xlog.String("key", key), xlog.ErrorAttr("error", err))
+			handler.ServeHTTP(w, r)
+			return
+		}
+		h := w.Header()
+		h.Set("RateLimit-Limit", strconv.FormatInt(ret.Limit, 10))
+		h.Set("RateLimit-Remaining", strconv.FormatInt(ret.Remaining, 10))
+		h.Set("RateLimit-Reset", ceilSeconds(ret.ResetAfter))
+		if ret.Allowed {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		h.Set("Retry-After", ceilSeconds(ret.RetryAfter))
+		if rl.OnLimited != nil {
+			rl.OnLimited.ServeHTTP(w, r)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+	})
+}
+
+func rateLimitKey(r *http.Request, keyBy []string) string {
+	parts := make([]string, 0, len(keyBy))
+	for _, by := range keyBy {
+		var value string
+		switch by {
+		case "ip":
+			value = xhttp.ClientIP(r)
+		case "route":
+			value = xhttp.ReadRouteInfo(r.Context()).Pattern
+			if value == "" {
+				value = r.URL.Path
+			}
+		case "method":
+			value = r.Method
+		default:
+			if name, ok := strings.CutPrefix(by, "header:"); ok {
+				value = r.Header.Get(name)
+			}
+		}
+		if value == "" {
+			// 值为空的请求（如未传递请求头）共享同一个配额
+			value = "-"
+		}
+		parts = append(parts, value)
+	}
+	return strings.Join(parts, "|")
+}
+
+// ceilSeconds 向上取整的秒数
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
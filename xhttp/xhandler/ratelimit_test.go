@@ -0,0 +1,73 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhandler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xlimiter"
+	"github.com/xanygo/anygo/ds/xtype"
+	"github.com/xanygo/anygo/xt"
+)
+
+func TestRateLimit(t *testing.T) {
+	rl := &RateLimit{
+		Limiter: &xlimiter.Limiter{
+			Algorithm: &xlimiter.GCRA{Rate: xlimiter.PerMinute(2)},
+		},
+		KeyBy: "header:X-API-Key,ip",
+	}
+	h := rl.Next(okHandler)
+	call := func(apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	w := call("k1")
+	xt.Equal(t, w.Code, http.StatusOK)
+	xt.Equal(t, w.Header().Get("RateLimit-Limit"), "2")
+	xt.Equal(t, w.Header().Get("RateLimit-Remaining"), "1")
+	xt.Equal(t, w.Header().Get("RateLimit-Reset"), "30")
+
+	xt.Equal(t, call("k1").Code, http.StatusOK)
+	w = call("k1")
+	xt.Equal(t, w.Code, http.StatusTooManyRequests)
+	xt.Equal(t, w.Header().Get("Retry-After"), "30")
+	xt.Equal(t, w.Header().Get("RateLimit-Remaining"), "0")
+
+	xt.Equal(t, call("k2").Code, http.StatusOK)
+
+	// 未传递请求头的共享同一个配额
+	xt.Equal(t, call("").Code, http.StatusOK)
+	xt.Equal(t, call("").Code, http.StatusOK)
+	xt.Equal(t, call("").Code, http.StatusTooManyRequests)
+}
+
+func TestRateLimit_keyFunc(t *testing.T) {
+	rl := &RateLimit{
+		Limiter: &xlimiter.Limiter{
+			Algorithm: &xlimiter.SlidingLog{Rate: xlimiter.Rate{Limit: 1, Period: xtype.Duration(time.Minute)}},
+		},
+		KeyFunc: func(r *http.Request) string {
+			return r.URL.Query().Get("user")
+		},
+	}
+	h := rl.Next(okHandler)
+	call := func(target string) int {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w.Code
+	}
+	xt.Equal(t, call("/?user=a"), http.StatusOK)
+	xt.Equal(t, call("/?user=a"), http.StatusTooManyRequests)
+	// 返回空 key 的不限流
+	xt.Equal(t, call("/"), http.StatusOK)
+	xt.Equal(t, call("/"), http.StatusOK)
+}
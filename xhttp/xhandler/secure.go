@@ -0,0 +1,258 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhandler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/xanygo/anygo/ds/xctx"
+	"github.com/xanygo/anygo/ds/xtype"
+	"github.com/xanygo/anygo/xhttp/trustheader"
+)
+
+// SecureHeaders 给所有响应添加安全相关的响应头。
+//
+// 字符串类型的字段，为空时使用默认值，为 "-" 时不发送此响应头。
+//
+// 可通过 xcfg 加载配置，如：
+//
+//	HSTSMaxAge: 8760h
+//	HSTSIncludeSubdomains: true
+//	FrameOptions: DENY
+//	ContentSecurityPolicy: "default-src 'self'; img-src 'self' data:"
+//	CSPNonce: true
+type SecureHeaders struct {
+	// HSTSMaxAge Strict-Transport-Security 的 max-age，为 0 时不发送。
+	// 只有 HTTPS 请求（包括可信的 X-Forwarded-Proto 请求头为 https 的）才会发送
+	HSTSMaxAge xtype.Duration `json:"HSTSMaxAge" yaml:"HSTSMaxAge"`
+
+	// HSTSIncludeSubdomains 是否添加 includeSubDomains
+	HSTSIncludeSubdomains bool `json:"HSTSIncludeSubdomains" yaml:"HSTSIncludeSubdomains"`
+
+	// HSTSPreload 是否添加 preload
+	HSTSPreload bool `json:"HSTSPreload" yaml:"HSTSPreload"`
+
+	// FrameOptions X-Frame-Options，默认为 SAMEORIGIN
+	FrameOptions string `json:"FrameOptions" yaml:"FrameOptions"`
+
+	// ContentTypeOptions X-Content-Type-Options，默认为 nosniff
+	ContentTypeOptions string `json:"ContentTypeOptions" yaml:"ContentTypeOptions"`
+
+	// ReferrerPolicy Referrer-Policy，默认为 strict-origin-when-cross-origin
+	ReferrerPolicy string `json:"ReferrerPolicy" yaml:"ReferrerPolicy"`
+
+	// CrossOriginOpenerPolicy Cross-Origin-Opener-Policy，默认为 same-origin
+	CrossOriginOpenerPolicy string `json:"CrossOriginOpenerPolicy" yaml:"CrossOriginOpenerPolicy"`
+
+	// PermissionsPolicy Permissions-Policy，可选，默认不发送
+	PermissionsPolicy string `json:"PermissionsPolicy" yaml:"PermissionsPolicy"`
+
+	// ContentSecurityPolicy Content-Security-Policy，可选，默认不发送。
+	// 可以使用 CSP 构建，如 NewCSP().DefaultSrc(CSPSelf).String()
+	ContentSecurityPolicy string `json:"ContentSecurityPolicy" yaml:"ContentSecurityPolicy"`
+
+	// CSPReportOnly 为 true 时使用 Content-Security-Policy-Report-Only 响应头
+	CSPReportOnly bool `json:"CSPReportOnly" yaml:"CSPReportOnly"`
+
+	// CSPNonce 是否给每个请求生成随机的 nonce，并添加到 CSP 的 script-src 和 style-src 中。
+	// 在页面中可以使用 CSPNonceFromContext 读取
+	CSPNonce bool `json:"CSPNonce" yaml:"CSPNonce"`
+}
+
+func (s *SecureHeaders) Next(handler http.Handler) http.Handler {
+	static := s.staticHeaders()
+	hsts := s.hstsValue()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		for _, kv := range static {
+			h.Set(kv[0], kv[1])
+		}
+		if hsts != "" && isHTTPS(r) {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		if s.ContentSecurityPolicy != "" {
+			policy := s.ContentSecurityPolicy
+			if s.CSPNonce {
+				nonce := newNonce()
+				policy = addCSPNonce(policy, nonce)
+				r = r.WithContext(context.WithValue(r.Context(), ctxKeyCSPNonce, nonce))
+			}
+			name := "Content-Security-Policy"
+			if s.CSPReportOnly {
+				name = "Content-Security-Policy-Report-Only"
+			}
+			h.Set(name, policy)
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func (s *SecureHeaders) staticHeaders() [][2]string {
+	var result [][2]string
+	add := func(key string, value string, def string) {
+		if value == "" {
+			value = def
+		}
+		if value == "" || value == "-" {
+			return
+		}
+		result = append(result, [2]string{key, value})
+	}
+	add("X-Frame-Options", s.FrameOptions, "SAMEORIGIN")
+	add("X-Content-Type-Options", s.ContentTypeOptions, "nosniff")
+	add("Referrer-Policy", s.ReferrerPolicy, "strict-origin-when-cross-origin")
+	add("Cross-Origin-Opener-Policy", s.CrossOriginOpenerPolicy, "same-origin")
+	add("Permissions-Policy", s.PermissionsPolicy, "")
+	return result
+}
+
+func (s *SecureHeaders) hstsValue() string {
+	if s.HSTSMaxAge <= 0 {
+		return ""
+	}
+	value := "max-age=" + strconv.FormatInt(int64(s.HSTSMaxAge.Duration().Seconds()), 10)
+	if s.HSTSIncludeSubdomains {
+		value += "; includeSubDomains"
+	}
+	if s.HSTSPreload {
+		value += "; preload"
+	}
+	return value
+}
+
+func isHTTPS(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	proto, ok := trustheader.Get(r.Header, "X-Forwarded-Proto")
+	return ok && strings.EqualFold(proto, "https")
+}
+
+var ctxKeyCSPNonce = xctx.NewKey()
+
+// CSPNonceFromContext 读取 SecureHeaders 给当前请求生成的 CSP nonce，
+// 可以在页面中这样使用：<script nonce="{{ .Nonce }}">
+func CSPNonceFromContext(ctx context.Context) string {
+	nonce, _ := ctx.Value(ctxKeyCSPNonce).(string)
+	return nonce
+}
+
+func newNonce() string {
+	bf := make([]byte, 16)
+	_, _ = rand.Read(bf)
+	return base64.StdEncoding.EncodeToString(bf)
+}
+
+// addCSPNonce 将 nonce 添加到 script-src 和 style-src 指令中，若没有这两个指令，则添加到 default-src
+func addCSPNonce(policy string, nonce string) string {
+	value := "'nonce-" + nonce + "'"
+	parts := strings.Split(policy, ";")
+	var added bool
+	for i, part := range parts {
+		name, _, _ := strings.Cut(strings.TrimSpace(part), " ")
+		if name == "script-src" || name == "style-src" {
+			parts[i] = strings.TrimSpace(part) + " " + value
+			added = true
+		}
+	}
+	if !added {
+		for i, part := range parts {
+			name, _, _ := strings.Cut(strings.TrimSpace(part), " ")
+			if name == "default-src" {
+				parts[i] = strings.TrimSpace(part) + " " + value
+			}
+		}
+	}
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// CSP 内容安全策略（Content-Security-Policy）的构建器，指令按照添加的顺序输出
+//
+//	policy := NewCSP().DefaultSrc(CSPSelf).ImgSrc(CSPSelf, "data:").FrameAncestors(CSPNone).String()
+type CSP struct {
+	names  []string
+	values map[string][]string
+}
+
+// CSP 中常用的值
+const (
+	CSPSelf          = "'self'"
+	CSPNone          = "'none'"
+	CSPUnsafeInline  = "'unsafe-inline'"
+	CSPUnsafeEval    = "'unsafe-eval'"
+	CSPStrictDynamic = "'strict-dynamic'"
+)
+
+func NewCSP() *CSP {
+	return &CSP{
+		values: make(map[string][]string),
+	}
+}
+
+// Add 添加指令，若指令已存在，则追加值
+func (c *CSP) Add(directive string, values ...string) *CSP {
+	if _, ok := c.values[directive]; !ok {
+		c.names = append(c.names, directive)
+	}
+	c.values[directive] = append(c.values[directive], values...)
+	return c
+}
+
+func (c *CSP) DefaultSrc(values ...string) *CSP {
+	return c.Add("default-src", values...)
+}
+
+func (c *CSP) ScriptSrc(values ...string) *CSP {
+	return c.Add("script-src", values...)
+}
+
+func (c *CSP) StyleSrc(values ...string) *CSP {
+	return c.Add("style-src", values...)
+}
+
+func (c *CSP) ImgSrc(values ...string) *CSP {
+	return c.Add("img-src", values...)
+}
+
+func (c *CSP) ConnectSrc(values ...string) *CSP {
+	return c.Add("connect-src", values...)
+}
+
+func (c *CSP) FontSrc(values ...string) *CSP {
+	return c.Add("font-src", values...)
+}
+
+func (c *CSP) FrameAncestors(values ...string) *CSP {
+	return c.Add("frame-ancestors", values...)
+}
+
+func (c *CSP) ReportURI(uri string) *CSP {
+	return c.Add("report-uri", uri)
+}
+
+func (c *CSP) UpgradeInsecureRequests() *CSP {
+	return c.Add("upgrade-insecure-requests")
+}
+
+func (c *CSP) String() string {
+	parts := make([]string, 0, len(c.names))
+	for _, name := range c.names {
+		values := c.values[name]
+		if len(values) == 0 {
+			parts = append(parts, name)
+		} else {
+			parts = append(parts, name+" "+strings.Join(values, " "))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,231 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhandler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xtype"
+	"github.com/xanygo/anygo/store/xsession"
+	"github.com/xanygo/anygo/xt"
+)
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	_, _ = w.Write([]byte("ok"))
+})
+
+func TestCORS(t *testing.T) {
+	c := &CORS{
+		AllowOrigins:     []string{"https://example.com", "https://*.example.org"},
+		AllowHeaders:     []string{"Content-Type", "X-Token"},
+		ExposeHeaders:    []string{"X-Request-Id"},
+		AllowCredentials: true,
+		MaxAge:           xtype.Duration(10 * time.Minute),
+	}
+	h := c.Next(okHandler)
+
+	t.Run("simple", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Origin", "https://a.example.org")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		xt.Equal(t, w.Body.String(), "ok")
+		xt.Equal(t, w.Header().Get("Access-Control-Allow-Origin"), "https://a.example.org")
+		xt.Equal(t, w.Header().Get("Access-Control-Allow-Credentials"), "true")
+		xt.Equal(t, w.Header().Get("Access-Control-Expose-Headers"), "X-Request-Id")
+		xt.Equal(t, w.Header().Get("Vary"), "Origin")
+	})
+
+	t.Run("not allowed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Origin", "https://example.org")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		xt.Equal(t, w.Body.String(), "ok")
+		xt.Equal(t, w.Header().Get("Access-Control-Allow-Origin"), "")
+	})
+
+	t.Run("preflight", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/", nil)
+		req.Header.Set("Origin", "https://example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPut)
+		req.Header.Set("Access-Control-Request-Headers", "x-token")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		xt.Equal(t, w.Code, http.StatusNoContent)
+		xt.Equal(t, w.Body.String(), "")
+		xt.Equal(t, w.Header().Get("Access-Control-Allow-Headers"), "Content-Type, X-Token")
+		xt.Equal(t, w.Header().Get("Access-Control-Max-Age"), "600")
+		xt.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "PUT")
+	})
+
+	t.Run("preflight bad header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/", nil)
+		req.Header.Set("Origin", "https://example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "X-Other")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		xt.Equal(t, w.Code, http.StatusForbidden)
+	})
+
+	t.Run("any", func(t *testing.T) {
+		h2 := (&CORS{AllowOrigins: []string{"*"}}).Next(okHandler)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Origin", "https://abc.com")
+		w := httptest.NewRecorder()
+		h2.ServeHTTP(w, req)
+		xt.Equal(t, w.Header().Get("Access-Control-Allow-Origin"), "*")
+	})
+}
+
+func TestCSRF(t *testing.T) {
+	c := &CSRF{}
+	var gotToken string
+	var page string
+	h := c.Next(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotToken = CSRFToken(r.Context())
+		tpl := template.Must(template.New("").Funcs(CSRFTemplateFuncs(r.Context())).Parse(`{{ csrf_field }}`))
+		bf := &bytes.Buffer{}
+		_ = tpl.Execute(bf, nil)
+		page = bf.String()
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	xt.Equal(t, w.Code, http.StatusOK)
+	xt.NotEmpty(t, gotToken)
+	xt.Contains(t, page, `name="csrf_token" value="`+gotToken+`"`)
+	cookies := w.Result().Cookies()
+	xt.Len(t, cookies, 1)
+	xt.Equal(t, cookies[0].Value, gotToken)
+
+	post := func(body string, header string, withCookie bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if header != "" {
+			req.Header.Set("X-CSRF-Token", header)
+		}
+		if withCookie {
+			req.AddCookie(cookies[0])
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	xt.Equal(t, post("a=1", "", true).Code, http.StatusForbidden)
+	xt.Equal(t, post("a=1", "bad", true).Code, http.StatusForbidden)
+	xt.Equal(t, post("a=1", gotToken, false).Code, http.StatusForbidden)
+	xt.Equal(t, post("a=1", gotToken, true).Code, http.StatusOK)
+
+	// 表单提交的 token，Body 在后续仍然可以读取
+	w = post("a=1&csrf_token="+gotToken, "", true)
+	xt.Equal(t, w.Code, http.StatusOK)
+	xt.Equal(t, w.Body.String(), "a=1&csrf_token="+gotToken)
+}
+
+func TestCSRF_session(t *testing.T) {
+	c := &CSRF{Mode: CSRFSession}
+	xt.Empty(t, CSRFToken(context.Background()))
+
+	w := httptest.NewRecorder()
+	c.Next(okHandler).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	xt.Equal(t, w.Code, http.StatusInternalServerError)
+
+	store := xsession.NewMemoryStore(10, time.Minute)
+	ss := &xsession.HTTPHandler{
+		NewStorage: func(http.ResponseWriter, *http.Request) xsession.Storage {
+			return store
+		},
+	}
+	var token string
+	h := ss.Next(c.Next(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = CSRFToken(r.Context())
+	})))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	xt.Equal(t, w.Code, http.StatusOK)
+	xt.NotEmpty(t, token)
+	sid := w.Result().Cookies()[0]
+
+	post := func(token string) int {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.AddCookie(sid)
+		req.Header.Set("X-CSRF-Token", token)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+	xt.Equal(t, post("bad"), http.StatusForbidden)
+	xt.Equal(t, post(token), http.StatusOK)
+}
+
+func TestSecureHeaders(t *testing.T) {
+	s := &SecureHeaders{
+		HSTSMaxAge:            xtype.Duration(time.Hour),
+		HSTSIncludeSubdomains: true,
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "-",
+		ContentSecurityPolicy: NewCSP().DefaultSrc(CSPSelf).ScriptSrc(CSPSelf).ImgSrc(CSPSelf, "data:").String(),
+		CSPNonce:              true,
+	}
+	var nonce string
+	h := s.Next(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce = CSPNonceFromContext(r.Context())
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	xt.Equal(t, w.Header().Get("X-Frame-Options"), "DENY")
+	xt.Equal(t, w.Header().Get("X-Content-Type-Options"), "nosniff")
+	xt.Equal(t, w.Header().Get("Referrer-Policy"), "")
+	xt.Equal(t, w.Header().Get("Strict-Transport-Security"), "")
+	xt.NotEmpty(t, nonce)
+	xt.Equal(t, w.Header().Get("Content-Security-Policy"),
+		"default-src 'self'; script-src 'self' 'nonce-"+nonce+"'; img-src 'self' data:")
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "https://example.com/", nil))
+	xt.Equal(t, w.Header().Get("Strict-Transport-Security"), "max-age=3600; includeSubDomains")
+}
+
+func TestRequestLimit(t *testing.T) {
+	rl := &RequestLimit{MaxBodySize: 4, ReadTimeout: xtype.Duration(time.Second)}
+	h := rl.Next(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.ReadAll(r.Body)
+		var me *http.MaxBytesError
+		if errors.As(err, &me) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("abc")))
+	xt.Equal(t, w.Body.String(), "ok")
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("abcdef")))
+	xt.Equal(t, w.Code, http.StatusRequestEntityTooLarge)
+
+	// 未知长度的 Body
+	req := httptest.NewRequest(http.MethodPost, "/", io.NopCloser(strings.NewReader("abcdef")))
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	xt.Equal(t, w.Code, http.StatusRequestEntityTooLarge)
+}
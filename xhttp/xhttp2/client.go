@@ -0,0 +1,1159 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttp2
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/xanygo/anygo/xnet"
+)
+
+// ClientPreface 客户端在连接建立后，首先发送的连接序言
+const ClientPreface = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+
+const (
+	streamRecvWindow  = 4 << 20  // 本端每个流的接收窗口
+	connRecvWindow    = 16 << 20 // 本端连接级别的接收窗口
+	maxHeaderListSize = 10 << 20 // 本端允许的响应头的最大大小
+
+	// defaultMaxStreams 收到对端的 SETTINGS 之前，允许的最大并发流数
+	defaultMaxStreams = 100
+
+	maxStreamID = 1<<31 - 1
+)
+
+var (
+	// ErrClientConnClosed 连接已关闭
+	ErrClientConnClosed = errors.New("http2: client connection closed")
+
+	// ErrClientConnUnusable 连接不能再创建新的流（如已收到 GOAWAY，或者流 ID 已用完）
+	ErrClientConnUnusable = errors.New("http2: client connection is not usable for new requests")
+
+	errBodyClosed = errors.New("http2: read on closed response body")
+	errStreamDone = errors.New("http2: stream closed")
+)
+
+var _ http.RoundTripper = (*ClientConn)(nil)
+
+// ClientConn HTTP/2 客户端连接，在一个网络连接上并发的发送多个请求（多路复用）：
+//  1. 支持 HPACK 头部压缩、流量控制、SETTINGS、PING 以及 GOAWAY
+//  2. 不支持服务端推送（会发送 SETTINGS_ENABLE_PUSH=0），不支持 CONNECT 方法
+//  3. 请求的 :scheme 依据连接确定，TLS 连接为 https，否则为 http（即 h2c）
+//
+// 可以直接作为 http.Client 的 Transport 使用（所有的请求都会发送到这一个连接）
+type ClientConn struct {
+	conn   net.Conn
+	scheme string
+	br     *bufio.Reader
+
+	wmu  sync.Mutex // 保证帧完整的写入，以及 HEADERS 按照流 ID 的顺序发送
+	bw   *bufio.Writer
+	henc *hpackEncoder
+	hbuf []byte
+
+	hdec *hpackDecoder // 只在 readLoop 中使用
+
+	mu           sync.Mutex
+	streams      map[uint32]*clientStream
+	nextStreamID uint32
+	changed      chan struct{} // 窗口、流的数量等状态变化时，会被关闭并重建，用于唤醒等待者
+
+	sendWindow  int64 // 连接级别的发送窗口
+	recvWindow  int64 // 连接级别的剩余接收窗口
+	recvUnacked int64 // 已被读取，但还未使用 WINDOW_UPDATE 告知对端的字节数
+
+	peerMaxFrameSize  uint32
+	peerMaxStreams    uint32
+	peerInitialWindow int64
+
+	goAway *GoAwayError
+	pings  map[[8]byte]chan struct{}
+
+	err       error // 连接关闭的原因
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewClientConn 在已建立的连接 conn 上创建 HTTP/2 客户端连接，会发送连接序言和 SETTINGS，并开始读取对端的帧。
+//
+// conn 可以是：
+//  1. 通过 ALPN 协商了 h2 的 TLS 连接（*tls.Conn 或者最外层是 *tls.Conn 的 *xnet.ConnNode）
+//  2. 明文连接，使用 prior-knowledge 的方式，即 h2c
+func NewClientConn(conn net.Conn) (*ClientConn, error) {
+	scheme := "http"
+	if st, ok := tlsState(conn); ok {
+		if st.NegotiatedProtocol != "h2" {
+			return nil, fmt.Errorf("http2: unexpected ALPN protocol %q, want \"h2\"", st.NegotiatedProtocol)
+		}
+		scheme = "https"
+	}
+	cc := &ClientConn{
+		conn:              conn,
+		scheme:            scheme,
+		br:                bufio.NewReader(conn),
+		bw:                bufio.NewWriter(conn),
+		henc:              newHpackEncoder(),
+		hdec:              newHpackDecoder(maxHeaderListSize),
+		streams:           make(map[uint32]*clientStream),
+		nextStreamID:      1,
+		changed:           make(chan struct{}),
+		sendWindow:        defaultInitialWindowSize,
+		recvWindow:        connRecvWindow,
+		peerMaxFrameSize:  defaultMaxFrameSize,
+		peerMaxStreams:    defaultMaxStreams,
+		peerInitialWindow: defaultInitialWindowSize,
+		pings:             make(map[[8]byte]chan struct{}),
+		done:              make(chan struct{}),
+	}
+	buf := []byte(ClientPreface)
+	buf = appendFrame(buf, frameSettings, 0, 0, encodeSettings(
+		setting{id: settingEnablePush, val: 0},
+		setting{id: settingInitialWindowSize, val: streamRecvWindow},
+		setting{id: settingMaxHeaderListSize, val: maxHeaderListSize},
+	))
+	buf = appendFrame(buf, frameWindowUpdate, 0, 0, encodeUint32(connRecvWindow-defaultInitialWindowSize))
+	if err := cc.write(buf); err != nil {
+		return nil, err
+	}
+	go cc.readLoop()
+	return cc, nil
+}
+
+func tlsState(conn net.Conn) (tls.ConnectionState, bool) {
+	if node, ok := conn.(*xnet.ConnNode); ok {
+		conn = node.Outer()
+	}
+	if tc, ok := conn.(*tls.Conn); ok {
+		return tc.ConnectionState(), true
+	}
+	return tls.ConnectionState{}, false
+}
+
+// Done 连接关闭后，返回的 chan 会被关闭
+func (cc *ClientConn) Done() <-chan struct{} {
+	return cc.done
+}
+
+// Err 连接关闭的原因，连接正常时返回 nil
+func (cc *ClientConn) Err() error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.err
+}
+
+// CanTakeNewRequest 是否可以发送新的请求（当前活跃的流的数量未达到上限时才会返回 true）
+func (cc *ClientConn) CanTakeNewRequest() bool {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.usableLocked() == nil && uint32(len(cc.streams)) < cc.peerMaxStreams
+}
+
+// MaxConcurrentStreams 对端允许的最大并发流数（SETTINGS_MAX_CONCURRENT_STREAMS）
+func (cc *ClientConn) MaxConcurrentStreams() int {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return int(cc.peerMaxStreams)
+}
+
+// ActiveStreams 当前活跃的流的数量
+func (cc *ClientConn) ActiveStreams() int {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return len(cc.streams)
+}
+
+func (cc *ClientConn) usableLocked() error {
+	if cc.err != nil {
+		return cc.err
+	}
+	if cc.goAway != nil {
+		return cc.goAway
+	}
+	if cc.nextStreamID > maxStreamID {
+		return ErrClientConnUnusable
+	}
+	return nil
+}
+
+// notifyLocked 唤醒所有的等待者
+func (cc *ClientConn) notifyLocked() {
+	close(cc.changed)
+	cc.changed = make(chan struct{})
+}
+
+// Close 发送 GOAWAY 并关闭连接，正在处理中的请求都会失败
+func (cc *ClientConn) Close() error {
+	cc.writeGoAway(ErrCodeNo, "")
+	cc.closeWithErr(ErrClientConnClosed)
+	return nil
+}
+
+func (cc *ClientConn) closeWithErr(err error) {
+	cc.closeOnce.Do(func() {
+		cc.mu.Lock()
+		cc.err = err
+		streams := make([]*clientStream, 0, len(cc.streams))
+		for _, cs := range cc.streams {
+			streams = append(streams, cs)
+		}
+		close(cc.done)
+		cc.notifyLocked()
+		cc.mu.Unlock()
+
+		_ = cc.conn.Close()
+		for _, cs := range streams {
+			cs.abort(err, 0)
+		}
+	})
+}
+
+// write 写入已编码的帧
+func (cc *ClientConn) write(buf []byte) error {
+	cc.wmu.Lock()
+	defer cc.wmu.Unlock()
+	return cc.writeLocked(buf)
+}
+
+func (cc *ClientConn) writeLocked(buf []byte) error {
+	_, err := cc.bw.Write(buf)
+	if err == nil {
+		err = cc.bw.Flush()
+	}
+	if err != nil {
+		cc.closeWithErr(err)
+	}
+	return err
+}
+
+func (cc *ClientConn) writeRSTStream(id uint32, code ErrCode) {
+	_ = cc.write(appendFrame(nil, frameRSTStream, 0, id, encodeUint32(uint32(code))))
+}
+
+func (cc *ClientConn) writeGoAway(code ErrCode, debug string) {
+	// 客户端不处理对端创建的流，所以 Last-Stream-ID 总是 0
+	payload := binary.BigEndian.AppendUint32(nil, 0)
+	payload = binary.BigEndian.AppendUint32(payload, uint32(code))
+	payload = append(payload, debug...)
+	_ = cc.write(appendFrame(nil, frameGoAway, 0, 0, payload))
+}
+
+// Ping 发送 PING 并等待对端的响应，可用于检查连接是否可用
+func (cc *ClientConn) Ping(ctx context.Context) error {
+	var data [8]byte
+	_, _ = rand.Read(data[:])
+	ch := make(chan struct{})
+	cc.mu.Lock()
+	if cc.err != nil {
+		cc.mu.Unlock()
+		return cc.err
+	}
+	cc.pings[data] = ch
+	cc.mu.Unlock()
+	defer func() {
+		cc.mu.Lock()
+		delete(cc.pings, data)
+		cc.mu.Unlock()
+	}()
+	if err := cc.write(appendFrame(nil, framePing, 0, 0, data[:])); err != nil {
+		return err
+	}
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-cc.done:
+		return cc.Err()
+	}
+}
+
+// RoundTrip 发送请求并等待响应头，请求的 ctx 结束时，流会被取消（包括读取响应的 Body）
+func (cc *ClientConn) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	cs, err := cc.newStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return cs.awaitResponse(ctx)
+}
+
+// newStream 创建流并发送请求头，若有 Body，会在独立的 goroutine 中发送
+func (cc *ClientConn) newStream(ctx context.Context, req *http.Request) (*clientStream, error) {
+	if req.Method == http.MethodConnect {
+		return nil, errors.New("http2: CONNECT method is not supported")
+	}
+	fields, err := cc.requestFields(req)
+	if err != nil {
+		return nil, err
+	}
+	body := req.Body
+	if body == http.NoBody {
+		body = nil
+	}
+	for {
+		if err = cc.waitStreamSlot(ctx); err != nil {
+			if body != nil {
+				_ = body.Close()
+			}
+			return nil, err
+		}
+		cs, ok, err := cc.tryNewStream(req, fields, body == nil)
+		if err != nil {
+			if body != nil {
+				_ = body.Close()
+			}
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if body != nil {
+			go cs.writeBody(body)
+		}
+		return cs, nil
+	}
+}
+
+// waitStreamSlot 等待直到活跃的流的数量小于对端的限制
+func (cc *ClientConn) waitStreamSlot(ctx context.Context) error {
+	for {
+		cc.mu.Lock()
+		if err := cc.usableLocked(); err != nil {
+			cc.mu.Unlock()
+			return err
+		}
+		if uint32(len(cc.streams)) < cc.peerMaxStreams {
+			cc.mu.Unlock()
+			return nil
+		}
+		ch := cc.changed
+		cc.mu.Unlock()
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+}
+
+func (cc *ClientConn) tryNewStream(req *http.Request, fields []headerField, endStream bool) (*clientStream, bool, error) {
+	cc.wmu.Lock()
+	defer cc.wmu.Unlock()
+
+	cc.mu.Lock()
+	if err := cc.usableLocked(); err != nil {
+		cc.mu.Unlock()
+		return nil, false, err
+	}
+	if uint32(len(cc.streams)) >= cc.peerMaxStreams {
+		cc.mu.Unlock()
+		return nil, false, nil
+	}
+	cs := &clientStream{
+		cc:         cc,
+		id:         cc.nextStreamID,
+		req:        req,
+		sendWindow: cc.peerInitialWindow,
+		recvWindow: streamRecvWindow,
+		sendEnded:  endStream,
+		respCh:     make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	cs.cond = sync.NewCond(&cs.bmu)
+	cc.nextStreamID += 2
+	cc.streams[cs.id] = cs
+	maxFrameSize := cc.peerMaxFrameSize
+	cc.mu.Unlock()
+
+	cc.hbuf = cc.henc.encode(cc.hbuf[:0], fields)
+	block := cc.hbuf
+	var buf []byte
+	first := true
+	for first || len(block) > 0 {
+		chunk := block[:min(len(block), int(maxFrameSize))]
+		block = block[len(chunk):]
+		var flags uint8
+		if len(block) == 0 {
+			flags |= flagEndHeaders
+		}
+		typ := frameContinuation
+		if first {
+			typ = frameHeaders
+			if endStream {
+				flags |= flagEndStream
+			}
+		}
+		buf = appendFrame(buf, typ, flags, cs.id, chunk)
+		first = false
+	}
+	if err := cc.writeLocked(buf); err != nil {
+		return nil, false, err
+	}
+	return cs, true, nil
+}
+
+// 不能在 HTTP/2 中使用的连接相关的头（RFC 9113 8.2.2）
+var connectionHeaders = map[string]bool{
+	"connection":        true,
+	"proxy-connection":  true,
+	"keep-alive":        true,
+	"transfer-encoding": true,
+	"upgrade":           true,
+	"host":              true,
+}
+
+// 值不能加入 HPACK 动态表的头
+var sensitiveHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+}
+
+func (cc *ClientConn) requestFields(req *http.Request) ([]headerField, error) {
+	if req.URL == nil {
+		return nil, errors.New("http2: nil Request.URL")
+	}
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	if host == "" {
+		return nil, errors.New("http2: no Host in request URL")
+	}
+	path := req.URL.RequestURI()
+	if path == "" {
+		path = "/"
+	}
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	fields := make([]headerField, 0, len(req.Header)+5)
+	fields = append(fields,
+		headerField{name: ":authority", value: host},
+		headerField{name: ":method", value: method},
+		headerField{name: ":path", value: path},
+		headerField{name: ":scheme", value: cc.scheme},
+	)
+	var hasLength bool
+	for k, vs := range req.Header {
+		name := strings.ToLower(k)
+		if connectionHeaders[name] {
+			continue
+		}
+		if name == "content-length" {
+			hasLength = true
+		}
+		for _, v := range vs {
+			if strings.ContainsAny(v, "\r\n\x00") {
+				return nil, fmt.Errorf("http2: invalid value for header %q", k)
+			}
+			if name == "te" && v != "trailers" {
+				continue
+			}
+			fields = append(fields, headerField{name: name, value: v, sensitive: sensitiveHeaders[name]})
+		}
+	}
+	if !hasLength {
+		switch {
+		case req.ContentLength > 0:
+			fields = append(fields, headerField{name: "content-length", value: strconv.FormatInt(req.ContentLength, 10)})
+		case (req.Body == nil || req.Body == http.NoBody) && (method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch):
+			fields = append(fields, headerField{name: "content-length", value: "0"})
+		}
+	}
+	return fields, nil
+}
+
+func (cc *ClientConn) readLoop() {
+	err := cc.doReadLoop()
+	var ce *connError
+	if errors.As(err, &ce) {
+		cc.writeGoAway(ce.code, ce.msg)
+	}
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	cc.closeWithErr(err)
+}
+
+func (cc *ClientConn) doReadLoop() error {
+	for {
+		f, err := readFrame(cc.br, defaultMaxFrameSize)
+		if err != nil {
+			return err
+		}
+		if err = cc.processFrame(f); err != nil {
+			return err
+		}
+	}
+}
+
+func (cc *ClientConn) processFrame(f *frame) error {
+	switch f.typ {
+	case frameData:
+		return cc.processData(f)
+	case frameHeaders:
+		return cc.processHeaders(f)
+	case frameRSTStream:
+		return cc.processRSTStream(f)
+	case frameSettings:
+		return cc.processSettings(f)
+	case framePushPromise:
+		return &connError{code: ErrCodeProtocol, msg: "received PUSH_PROMISE with push disabled"}
+	case framePing:
+		return cc.processPing(f)
+	case frameGoAway:
+		return cc.processGoAway(f)
+	case frameWindowUpdate:
+		return cc.processWindowUpdate(f)
+	case frameContinuation:
+		return &connError{code: ErrCodeProtocol, msg: "unexpected CONTINUATION"}
+	default:
+		// PRIORITY 以及未知类型的帧，忽略
+		return nil
+	}
+}
+
+func (cc *ClientConn) streamByID(id uint32) *clientStream {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.streams[id]
+}
+
+func (cc *ClientConn) processData(f *frame) error {
+	if f.streamID == 0 {
+		return &connError{code: ErrCodeProtocol, msg: "DATA on stream 0"}
+	}
+	data, err := f.trimPadding()
+	if err != nil {
+		return err
+	}
+	size := int64(len(f.payload))
+	cc.mu.Lock()
+	if size > cc.recvWindow {
+		cc.mu.Unlock()
+		return &connError{code: ErrCodeFlowControl, msg: "connection receive window exceeded"}
+	}
+	cc.recvWindow -= size
+	cs := cc.streams[f.streamID]
+	if cs == nil || cs.recvEnded {
+		cc.mu.Unlock()
+		// 流已经结束（如已被取消），直接归还连接级别的窗口
+		cc.returnRecvWindow(nil, size)
+		return nil
+	}
+	if size > cs.recvWindow {
+		cc.mu.Unlock()
+		cs.abort(&StreamError{StreamID: cs.id, Code: ErrCodeFlowControl}, ErrCodeFlowControl)
+		cc.returnRecvWindow(nil, size)
+		return nil
+	}
+	cs.recvWindow -= size
+	cc.mu.Unlock()
+
+	// 填充的部分，直接归还窗口
+	if padding := size - int64(len(data)); padding > 0 {
+		cc.returnRecvWindow(cs, padding)
+	}
+	cs.pushData(data)
+	if f.has(flagEndStream) {
+		cs.endRecv(nil)
+	}
+	return nil
+}
+
+// returnRecvWindow 数据被读取（消费）后，归还接收窗口，累计达到窗口的一半时，发送 WINDOW_UPDATE
+func (cc *ClientConn) returnRecvWindow(cs *clientStream, n int64) {
+	var buf []byte
+	cc.mu.Lock()
+	cc.recvUnacked += n
+	if cc.recvUnacked >= connRecvWindow/2 {
+		buf = appendFrame(buf, frameWindowUpdate, 0, 0, encodeUint32(uint32(cc.recvUnacked)))
+		cc.recvWindow += cc.recvUnacked
+		cc.recvUnacked = 0
+	}
+	if cs != nil && !cs.recvEnded && !cs.closed {
+		cs.recvUnacked += n
+		if cs.recvUnacked >= streamRecvWindow/2 {
+			buf = appendFrame(buf, frameWindowUpdate, 0, cs.id, encodeUint32(uint32(cs.recvUnacked)))
+			cs.recvWindow += cs.recvUnacked
+			cs.recvUnacked = 0
+		}
+	}
+	cc.mu.Unlock()
+	if len(buf) > 0 {
+		_ = cc.write(buf)
+	}
+}
+
+func (cc *ClientConn) processHeaders(f *frame) error {
+	if f.streamID == 0 {
+		return &connError{code: ErrCodeProtocol, msg: "HEADERS on stream 0"}
+	}
+	block, err := f.trimPadding()
+	if err != nil {
+		return err
+	}
+	if f.has(flagPriority) {
+		if len(block) < 5 {
+			return &connError{code: ErrCodeProtocol, msg: "invalid HEADERS priority"}
+		}
+		block = block[5:]
+	}
+	// 读取后续的 CONTINUATION，它们必须是连续的
+	endHeaders := f.has(flagEndHeaders)
+	if !endHeaders {
+		block = append([]byte(nil), block...)
+	}
+	for !endHeaders {
+		cf, err := readFrame(cc.br, defaultMaxFrameSize)
+		if err != nil {
+			return err
+		}
+		if cf.typ != frameContinuation || cf.streamID != f.streamID {
+			return &connError{code: ErrCodeProtocol, msg: "expected CONTINUATION, got " + cf.String()}
+		}
+		block = append(block, cf.payload...)
+		if len(block) > maxHeaderListSize {
+			return &connError{code: ErrCodeProtocol, msg: "header block too large"}
+		}
+		endHeaders = cf.has(flagEndHeaders)
+	}
+
+	// 即使流已经不存在，也需要解码，以保持 HPACK 动态表的状态一致
+	fields, err := cc.hdec.decode(block)
+	if err != nil {
+		if errors.Is(err, errHeaderListTooLarge) {
+			if cs := cc.streamByID(f.streamID); cs != nil {
+				cs.abort(err, ErrCodeCancel)
+			}
+			return nil
+		}
+		return &connError{code: ErrCodeCompression, msg: err.Error()}
+	}
+	cs := cc.streamByID(f.streamID)
+	if cs == nil {
+		return nil
+	}
+	return cs.processHeaders(fields, f.has(flagEndStream))
+}
+
+func (cc *ClientConn) processRSTStream(f *frame) error {
+	if f.streamID == 0 || len(f.payload) != 4 {
+		return &connError{code: ErrCodeProtocol, msg: "invalid RST_STREAM"}
+	}
+	if cs := cc.streamByID(f.streamID); cs != nil {
+		code := ErrCode(binary.BigEndian.Uint32(f.payload))
+		cs.abort(&StreamError{StreamID: f.streamID, Code: code}, 0)
+	}
+	return nil
+}
+
+func (cc *ClientConn) processSettings(f *frame) error {
+	if f.streamID != 0 {
+		return &connError{code: ErrCodeProtocol, msg: "SETTINGS on non-zero stream"}
+	}
+	if f.has(flagAck) {
+		if len(f.payload) != 0 {
+			return &connError{code: ErrCodeFrameSize, msg: "SETTINGS ACK with payload"}
+		}
+		return nil
+	}
+	ss, err := parseSettings(f.payload)
+	if err != nil {
+		return err
+	}
+	// 先获取写锁，以保证 HPACK 表大小的变化和 ACK 的发送顺序
+	cc.wmu.Lock()
+	defer cc.wmu.Unlock()
+	cc.mu.Lock()
+	for _, s := range ss {
+		switch s.id {
+		case settingHeaderTableSize:
+			cc.henc.setMaxSize(s.val)
+		case settingMaxConcurrentStreams:
+			cc.peerMaxStreams = s.val
+		case settingInitialWindowSize:
+			if s.val > maxWindowSize {
+				cc.mu.Unlock()
+				return &connError{code: ErrCodeFlowControl, msg: "invalid SETTINGS_INITIAL_WINDOW_SIZE"}
+			}
+			delta := int64(s.val) - cc.peerInitialWindow
+			cc.peerInitialWindow = int64(s.val)
+			for _, cs := range cc.streams {
+				cs.sendWindow += delta
+			}
+		case settingMaxFrameSize:
+			if s.val < defaultMaxFrameSize || s.val > maxFrameSizeLimit {
+				cc.mu.Unlock()
+				return &connError{code: ErrCodeProtocol, msg: "invalid SETTINGS_MAX_FRAME_SIZE"}
+			}
+			cc.peerMaxFrameSize = s.val
+		}
+	}
+	cc.notifyLocked()
+	cc.mu.Unlock()
+	return cc.writeLocked(appendFrame(nil, frameSettings, flagAck, 0, nil))
+}
+
+func (cc *ClientConn) processPing(f *frame) error {
+	if f.streamID != 0 || len(f.payload) != 8 {
+		return &connError{code: ErrCodeProtocol, msg: "invalid PING"}
+	}
+	if !f.has(flagAck) {
+		return cc.write(appendFrame(nil, framePing, flagAck, 0, f.payload))
+	}
+	var data [8]byte
+	copy(data[:], f.payload)
+	cc.mu.Lock()
+	if ch := cc.pings[data]; ch != nil {
+		close(ch)
+		delete(cc.pings, data)
+	}
+	cc.mu.Unlock()
+	return nil
+}
+
+func (cc *ClientConn) processGoAway(f *frame) error {
+	if f.streamID != 0 || len(f.payload) < 8 {
+		return &connError{code: ErrCodeProtocol, msg: "invalid GOAWAY"}
+	}
+	ga := &GoAwayError{
+		LastStreamID: binary.BigEndian.Uint32(f.payload) & maxStreamID,
+		Code:         ErrCode(binary.BigEndian.Uint32(f.payload[4:])),
+		Debug:        string(f.payload[8:]),
+	}
+	cc.mu.Lock()
+	cc.goAway = ga
+	var refused []*clientStream
+	for id, cs := range cc.streams {
+		if id > ga.LastStreamID {
+			refused = append(refused, cs)
+		}
+	}
+	cc.notifyLocked()
+	cc.mu.Unlock()
+	// 这些流没有被对端处理
+	for _, cs := range refused {
+		cs.abort(ga, 0)
+	}
+	return nil
+}
+
+func (cc *ClientConn) processWindowUpdate(f *frame) error {
+	if len(f.payload) != 4 {
+		return &connError{code: ErrCodeFrameSize, msg: "invalid WINDOW_UPDATE"}
+	}
+	incr := int64(binary.BigEndian.Uint32(f.payload) & maxWindowSize)
+	cc.mu.Lock()
+	if f.streamID == 0 {
+		if incr == 0 || cc.sendWindow+incr > maxWindowSize {
+			cc.mu.Unlock()
+			return &connError{code: ErrCodeFlowControl, msg: "invalid connection WINDOW_UPDATE"}
+		}
+		cc.sendWindow += incr
+		cc.notifyLocked()
+		cc.mu.Unlock()
+		return nil
+	}
+	cs := cc.streams[f.streamID]
+	if cs == nil {
+		cc.mu.Unlock()
+		return nil
+	}
+	if incr == 0 || cs.sendWindow+incr > maxWindowSize {
+		cc.mu.Unlock()
+		cs.abort(&StreamError{StreamID: cs.id, Code: ErrCodeFlowControl}, ErrCodeFlowControl)
+		return nil
+	}
+	cs.sendWindow += incr
+	cc.notifyLocked()
+	cc.mu.Unlock()
+	return nil
+}
+
+// clientStream 一个请求对应的流
+type clientStream struct {
+	cc  *ClientConn
+	id  uint32
+	req *http.Request
+
+	// 以下字段使用 cc.mu 保护
+	sendWindow  int64
+	recvWindow  int64
+	recvUnacked int64
+	sendEnded   bool // 已发送 END_STREAM
+	recvEnded   bool // 已收到 END_STREAM
+	closed      bool // 已从 cc.streams 中移除
+
+	respOnce sync.Once
+	respCh   chan struct{} // 收到响应头或者流被终止后关闭
+	resp     *http.Response
+	respErr  error
+
+	// 以下字段使用 bmu 保护
+	bmu        sync.Mutex
+	cond       *sync.Cond
+	buf        []byte
+	bodyErr    error // 读取完 buf 后返回的错误，正常结束时为 io.EOF
+	bodyClosed bool
+	stopCtx    func() bool
+
+	done chan struct{} // 流结束后关闭
+}
+
+// closeLocked 将流从连接中移除，需要持有 cc.mu
+func (cs *clientStream) closeLocked() {
+	if cs.closed {
+		return
+	}
+	cs.closed = true
+	delete(cs.cc.streams, cs.id)
+	close(cs.done)
+	cs.cc.notifyLocked()
+}
+
+// abort 终止流，code 不为 0 时，会向对端发送 RST_STREAM
+func (cs *clientStream) abort(err error, code ErrCode) {
+	cc := cs.cc
+	cc.mu.Lock()
+	alreadyClosed := cs.closed
+	cs.closeLocked()
+	cc.mu.Unlock()
+
+	cs.failResponse(err)
+	cs.bmu.Lock()
+	if cs.bodyErr == nil {
+		cs.bodyErr = err
+	}
+	cs.cond.Broadcast()
+	cs.bmu.Unlock()
+
+	if code != 0 && !alreadyClosed {
+		cc.writeRSTStream(cs.id, code)
+	}
+}
+
+// failResponse 还未收到响应头时，让 awaitResponse 返回 err
+func (cs *clientStream) failResponse(err error) {
+	cs.respOnce.Do(func() {
+		cs.respErr = err
+		close(cs.respCh)
+	})
+}
+
+// awaitResponse 等待响应头，ctx 结束时会取消流，响应的 Body 在 ctx 结束后也会读取失败
+func (cs *clientStream) awaitResponse(ctx context.Context) (*http.Response, error) {
+	select {
+	case <-cs.respCh:
+	case <-ctx.Done():
+		err := context.Cause(ctx)
+		cs.abort(err, ErrCodeCancel)
+		return nil, err
+	}
+	if cs.respErr != nil {
+		return nil, cs.respErr
+	}
+	if cs.resp.Body != http.NoBody {
+		stop := context.AfterFunc(ctx, func() {
+			cs.abort(context.Cause(ctx), ErrCodeCancel)
+		})
+		cs.bmu.Lock()
+		cs.stopCtx = stop
+		cs.bmu.Unlock()
+	}
+	return cs.resp, nil
+}
+
+func (cs *clientStream) processHeaders(fields []headerField, endStream bool) error {
+	cs.cc.mu.Lock()
+	gotResp := cs.resp != nil
+	cs.cc.mu.Unlock()
+	if gotResp {
+		// 响应的 Trailer
+		if !endStream {
+			cs.abort(&StreamError{StreamID: cs.id, Code: ErrCodeProtocol}, ErrCodeProtocol)
+			return nil
+		}
+		cs.bmu.Lock()
+		if cs.resp.Trailer == nil {
+			cs.resp.Trailer = make(http.Header)
+		}
+		for _, f := range fields {
+			if !strings.HasPrefix(f.name, ":") {
+				cs.resp.Trailer.Add(http.CanonicalHeaderKey(f.name), f.value)
+			}
+		}
+		cs.bmu.Unlock()
+		cs.endRecv(nil)
+		return nil
+	}
+
+	resp, err := cs.newResponse(fields)
+	if err != nil {
+		cs.abort(err, ErrCodeProtocol)
+		return nil
+	}
+	if resp.StatusCode < 200 {
+		// 1xx 的响应，忽略，继续等待最终的响应
+		if endStream {
+			cs.abort(&StreamError{StreamID: cs.id, Code: ErrCodeProtocol}, ErrCodeProtocol)
+		}
+		return nil
+	}
+	if endStream {
+		resp.Body = http.NoBody
+		if resp.ContentLength < 0 {
+			resp.ContentLength = 0
+		}
+	} else {
+		resp.Body = &streamBody{cs: cs}
+	}
+	cs.cc.mu.Lock()
+	// 在 cc.mu 中赋值，让 processHeaders 可以判断是否已收到响应头
+	cs.resp = resp
+	cs.cc.mu.Unlock()
+	cs.respOnce.Do(func() {
+		close(cs.respCh)
+	})
+	if endStream {
+		cs.endRecv(nil)
+	}
+	return nil
+}
+
+func (cs *clientStream) newResponse(fields []headerField) (*http.Response, error) {
+	resp := &http.Response{
+		Proto:         "HTTP/2.0",
+		ProtoMajor:    2,
+		Header:        make(http.Header, len(fields)),
+		ContentLength: -1,
+		Request:       cs.req,
+	}
+	var status string
+	for _, f := range fields {
+		if f.name == ":status" {
+			status = f.value
+			continue
+		}
+		if strings.HasPrefix(f.name, ":") {
+			return nil, fmt.Errorf("http2: invalid response pseudo header %q", f.name)
+		}
+		resp.Header.Add(http.CanonicalHeaderKey(f.name), f.value)
+	}
+	code, err := strconv.Atoi(status)
+	if err != nil || len(status) != 3 {
+		return nil, fmt.Errorf("http2: invalid response :status %q", status)
+	}
+	resp.StatusCode = code
+	resp.Status = status + " " + http.StatusText(code)
+	if cl := resp.Header.Get("Content-Length"); cl != "" {
+		if n, err := strconv.ParseInt(cl, 10, 64); err == nil && n >= 0 {
+			resp.ContentLength = n
+		}
+	}
+	for _, v := range resp.Header.Values("Trailer") {
+		for name := range strings.SplitSeq(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				if resp.Trailer == nil {
+					resp.Trailer = make(http.Header)
+				}
+				resp.Trailer[http.CanonicalHeaderKey(name)] = nil
+			}
+		}
+	}
+	return resp, nil
+}
+
+func (cs *clientStream) pushData(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	cs.bmu.Lock()
+	if cs.bodyClosed {
+		cs.bmu.Unlock()
+		cs.cc.returnRecvWindow(cs, int64(len(data)))
+		return
+	}
+	cs.buf = append(cs.buf, data...)
+	cs.cond.Broadcast()
+	cs.bmu.Unlock()
+}
+
+// endRecv 收到 END_STREAM
+func (cs *clientStream) endRecv(err error) {
+	if err == nil {
+		err = io.EOF
+	}
+	cc := cs.cc
+	cc.mu.Lock()
+	cs.recvEnded = true
+	if cs.sendEnded {
+		cs.closeLocked()
+	}
+	cc.mu.Unlock()
+
+	cs.failResponse(&StreamError{StreamID: cs.id, Code: ErrCodeProtocol})
+	cs.bmu.Lock()
+	if cs.bodyErr == nil {
+		cs.bodyErr = err
+	}
+	cs.cond.Broadcast()
+	cs.bmu.Unlock()
+}
+
+// endSend 已发送 END_STREAM
+func (cs *clientStream) endSend() {
+	cc := cs.cc
+	cc.mu.Lock()
+	cs.sendEnded = true
+	if cs.recvEnded {
+		cs.closeLocked()
+	}
+	cc.mu.Unlock()
+}
+
+func (cs *clientStream) writeBody(body io.ReadCloser) {
+	defer body.Close()
+	buf := make([]byte, defaultMaxFrameSize)
+	for {
+		n, rerr := body.Read(buf)
+		data := buf[:n]
+		for len(data) > 0 {
+			allowed, err := cs.awaitSendWindow(len(data))
+			if err != nil {
+				return
+			}
+			if err = cs.cc.write(appendFrame(nil, frameData, 0, cs.id, data[:allowed])); err != nil {
+				return
+			}
+			data = data[allowed:]
+		}
+		if errors.Is(rerr, io.EOF) {
+			if cs.isDone() {
+				return
+			}
+			if err := cs.cc.write(appendFrame(nil, frameData, flagEndStream, cs.id, nil)); err == nil {
+				cs.endSend()
+			}
+			return
+		}
+		if rerr != nil {
+			cs.abort(fmt.Errorf("http2: read request body: %w", rerr), ErrCodeCancel)
+			return
+		}
+	}
+}
+
+func (cs *clientStream) isDone() bool {
+	select {
+	case <-cs.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// awaitSendWindow 等待可用的发送窗口，返回本次可以发送的字节数（不超过 n）
+func (cs *clientStream) awaitSendWindow(n int) (int, error) {
+	cc := cs.cc
+	for {
+		cc.mu.Lock()
+		if cs.closed {
+			cc.mu.Unlock()
+			return 0, errStreamDone
+		}
+		if cc.err != nil {
+			err := cc.err
+			cc.mu.Unlock()
+			return 0, err
+		}
+		allowed := min(int64(n), cs.sendWindow, cc.sendWindow, int64(cc.peerMaxFrameSize))
+		if allowed > 0 {
+			cs.sendWindow -= allowed
+			cc.sendWindow -= allowed
+			cc.mu.Unlock()
+			return int(allowed), nil
+		}
+		ch := cc.changed
+		cc.mu.Unlock()
+		select {
+		case <-ch:
+		case <-cs.done:
+		}
+	}
+}
+
+var _ io.ReadCloser = (*streamBody)(nil)
+
+// streamBody 响应的 Body
+type streamBody struct {
+	cs *clientStream
+}
+
+func (b *streamBody) Read(p []byte) (int, error) {
+	cs := b.cs
+	cs.bmu.Lock()
+	for len(cs.buf) == 0 && cs.bodyErr == nil && !cs.bodyClosed {
+		cs.cond.Wait()
+	}
+	if cs.bodyClosed {
+		cs.bmu.Unlock()
+		return 0, errBodyClosed
+	}
+	if len(cs.buf) == 0 {
+		err := cs.bodyErr
+		cs.bmu.Unlock()
+		return 0, err
+	}
+	n := copy(p, cs.buf)
+	cs.buf = cs.buf[n:]
+	if len(cs.buf) == 0 {
+		cs.buf = nil
+	}
+	cs.bmu.Unlock()
+	cs.cc.returnRecvWindow(cs, int64(n))
+	return n, nil
+}
+
+// Close 关闭 Body，若响应还未读取完，会发送 RST_STREAM 取消流
+func (b *streamBody) Close() error {
+	cs := b.cs
+	cs.bmu.Lock()
+	if cs.bodyClosed {
+		cs.bmu.Unlock()
+		return nil
+	}
+	cs.bodyClosed = true
+	unread := int64(len(cs.buf))
+	cs.buf = nil
+	finished := cs.bodyErr != nil
+	stop := cs.stopCtx
+	cs.cond.Broadcast()
+	cs.bmu.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+	if unread > 0 {
+		cs.cc.returnRecvWindow(cs, unread)
+	}
+	if !finished {
+		cs.abort(errBodyClosed, ErrCodeCancel)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttp2_test
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/xhttp/xhttp2"
+	"github.com/xanygo/anygo/xt"
+)
+
+func newH2CServer(t *testing.T, h http.Handler) *httptest.Server {
+	ts := httptest.NewUnstartedServer(h)
+	ts.Config.Protocols = &http.Protocols{}
+	ts.Config.Protocols.SetUnencryptedHTTP2(true)
+	ts.Start()
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func echoHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Proto", r.Proto)
+	w.Header().Set("X-Path", r.URL.RequestURI())
+	w.Header().Set("Trailer", "X-Size")
+	n, _ := io.Copy(w, r.Body)
+	w.Header().Set("X-Size", fmt.Sprint(n))
+}
+
+func testClientConn(t *testing.T, cc *xhttp2.ClientConn, baseURL string) {
+	t.Run("concurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := range 50 {
+			wg.Go(func() {
+				req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, fmt.Sprintf("%s/get?id=%d", baseURL, i), nil)
+				resp, err := cc.RoundTrip(req)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				defer resp.Body.Close()
+				xt.Equal(t, resp.ProtoMajor, 2)
+				xt.Equal(t, resp.StatusCode, http.StatusOK)
+				xt.Equal(t, resp.Header.Get("X-Proto"), "HTTP/2.0")
+				xt.Equal(t, resp.Header.Get("X-Path"), fmt.Sprintf("/get?id=%d", i))
+			})
+		}
+		wg.Wait()
+		xt.Equal(t, cc.ActiveStreams(), 0)
+	})
+
+	t.Run("post large body", func(t *testing.T) {
+		// 超过默认的流控窗口（64KB）
+		data := bytes.Repeat([]byte("0123456789"), 100_000)
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, baseURL+"/post", bytes.NewReader(data))
+		resp, err := cc.RoundTrip(req)
+		xt.NoError(t, err)
+		defer resp.Body.Close()
+		got, err := io.ReadAll(resp.Body)
+		xt.NoError(t, err)
+		xt.Equal(t, len(got), len(data))
+		xt.True(t, bytes.Equal(got, data))
+		xt.Equal(t, resp.Trailer.Get("X-Size"), fmt.Sprint(len(data)))
+	})
+
+	t.Run("ping", func(t *testing.T) {
+		xt.NoError(t, cc.Ping(t.Context()))
+	})
+
+	t.Run("cancel body", func(t *testing.T) {
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodPost, baseURL+"/post", strings.NewReader(strings.Repeat("a", 1<<20)))
+		resp, err := cc.RoundTrip(req)
+		xt.NoError(t, err)
+		buf := make([]byte, 10)
+		_, err = io.ReadFull(resp.Body, buf)
+		xt.NoError(t, err)
+		xt.NoError(t, resp.Body.Close())
+		_, err = resp.Body.Read(buf)
+		xt.Error(t, err)
+		xt.True(t, cc.CanTakeNewRequest())
+	})
+}
+
+func TestClientConn_h2c(t *testing.T) {
+	ts := newH2CServer(t, http.HandlerFunc(echoHandler))
+	conn, err := net.Dial("tcp", ts.Listener.Addr().String())
+	xt.NoError(t, err)
+	cc, err := xhttp2.NewClientConn(conn)
+	xt.NoError(t, err)
+
+	testClientConn(t, cc, ts.URL)
+
+	xt.NoError(t, cc.Close())
+	select {
+	case <-cc.Done():
+	case <-time.After(time.Second):
+		t.Fatal("ClientConn not closed")
+	}
+	xt.False(t, cc.CanTakeNewRequest())
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, ts.URL, nil)
+	_, err = cc.RoundTrip(req)
+	xt.ErrorIs(t, err, xhttp2.ErrClientConnClosed)
+}
+
+func TestClientConn_tls(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(echoHandler))
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	conn, err := tls.Dial("tcp", ts.Listener.Addr().String(), &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{"h2"},
+	})
+	xt.NoError(t, err)
+	cc, err := xhttp2.NewClientConn(conn)
+	xt.NoError(t, err)
+	defer cc.Close()
+	testClientConn(t, cc, ts.URL)
+
+	// 未协商 h2 的 TLS 连接
+	conn2, err := tls.Dial("tcp", ts.Listener.Addr().String(), &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{"http/1.1"},
+	})
+	xt.NoError(t, err)
+	defer conn2.Close()
+	_, err = xhttp2.NewClientConn(conn2)
+	xt.Error(t, err)
+}
+
+func TestClientConn_goAway(t *testing.T) {
+	ts := newH2CServer(t, http.HandlerFunc(echoHandler))
+	conn, err := net.Dial("tcp", ts.Listener.Addr().String())
+	xt.NoError(t, err)
+	cc, err := xhttp2.NewClientConn(conn)
+	xt.NoError(t, err)
+	defer cc.Close()
+
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, ts.URL, nil)
+	resp, err := cc.RoundTrip(req)
+	xt.NoError(t, err)
+	resp.Body.Close()
+
+	// 服务端关闭时，会发送 GOAWAY
+	ts.Config.Shutdown(t.Context())
+	select {
+	case <-cc.Done():
+	case <-time.After(3 * time.Second):
+		t.Fatal("ClientConn not closed after GOAWAY")
+	}
+	xt.False(t, cc.CanTakeNewRequest())
+}
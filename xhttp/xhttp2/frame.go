@@ -0,0 +1,246 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttp2
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// 帧的类型（RFC 9113 第 6 节）
+type frameType uint8
+
+const (
+	frameData         frameType = 0x0
+	frameHeaders      frameType = 0x1
+	framePriority     frameType = 0x2
+	frameRSTStream    frameType = 0x3
+	frameSettings     frameType = 0x4
+	framePushPromise  frameType = 0x5
+	framePing         frameType = 0x6
+	frameGoAway       frameType = 0x7
+	frameWindowUpdate frameType = 0x8
+	frameContinuation frameType = 0x9
+)
+
+var frameNames = map[frameType]string{
+	frameData:         "DATA",
+	frameHeaders:      "HEADERS",
+	framePriority:     "PRIORITY",
+	frameRSTStream:    "RST_STREAM",
+	frameSettings:     "SETTINGS",
+	framePushPromise:  "PUSH_PROMISE",
+	framePing:         "PING",
+	frameGoAway:       "GOAWAY",
+	frameWindowUpdate: "WINDOW_UPDATE",
+	frameContinuation: "CONTINUATION",
+}
+
+func (t frameType) String() string {
+	if name, ok := frameNames[t]; ok {
+		return name
+	}
+	return "UNKNOWN_FRAME_TYPE_" + strconv.Itoa(int(t))
+}
+
+// 帧的标志位
+const (
+	flagEndStream  uint8 = 0x1  // DATA、HEADERS
+	flagAck        uint8 = 0x1  // SETTINGS、PING
+	flagEndHeaders uint8 = 0x4  // HEADERS、CONTINUATION
+	flagPadded     uint8 = 0x8  // DATA、HEADERS
+	flagPriority   uint8 = 0x20 // HEADERS
+)
+
+// SETTINGS 的参数
+type settingID uint16
+
+const (
+	settingHeaderTableSize      settingID = 0x1
+	settingEnablePush           settingID = 0x2
+	settingMaxConcurrentStreams settingID = 0x3
+	settingInitialWindowSize    settingID = 0x4
+	settingMaxFrameSize         settingID = 0x5
+	settingMaxHeaderListSize    settingID = 0x6
+)
+
+type setting struct {
+	id  settingID
+	val uint32
+}
+
+const (
+	frameHeaderLen = 9
+
+	defaultHeaderTableSize   = 4096
+	defaultInitialWindowSize = 65535
+	defaultMaxFrameSize      = 16384
+	maxFrameSizeLimit        = 1<<24 - 1
+	maxWindowSize            = 1<<31 - 1
+)
+
+// ErrCode RST_STREAM 和 GOAWAY 帧中的错误码（RFC 9113 第 7 节）
+type ErrCode uint32
+
+const (
+	ErrCodeNo                 ErrCode = 0x0
+	ErrCodeProtocol           ErrCode = 0x1
+	ErrCodeInternal           ErrCode = 0x2
+	ErrCodeFlowControl        ErrCode = 0x3
+	ErrCodeSettingsTimeout    ErrCode = 0x4
+	ErrCodeStreamClosed       ErrCode = 0x5
+	ErrCodeFrameSize          ErrCode = 0x6
+	ErrCodeRefusedStream      ErrCode = 0x7
+	ErrCodeCancel             ErrCode = 0x8
+	ErrCodeCompression        ErrCode = 0x9
+	ErrCodeConnect            ErrCode = 0xa
+	ErrCodeEnhanceYourCalm    ErrCode = 0xb
+	ErrCodeInadequateSecurity ErrCode = 0xc
+	ErrCodeHTTP11Required     ErrCode = 0xd
+)
+
+var errCodeNames = map[ErrCode]string{
+	ErrCodeNo:                 "NO_ERROR",
+	ErrCodeProtocol:           "PROTOCOL_ERROR",
+	ErrCodeInternal:           "INTERNAL_ERROR",
+	ErrCodeFlowControl:        "FLOW_CONTROL_ERROR",
+	ErrCodeSettingsTimeout:    "SETTINGS_TIMEOUT",
+	ErrCodeStreamClosed:       "STREAM_CLOSED",
+	ErrCodeFrameSize:          "FRAME_SIZE_ERROR",
+	ErrCodeRefusedStream:      "REFUSED_STREAM",
+	ErrCodeCancel:             "CANCEL",
+	ErrCodeCompression:        "COMPRESSION_ERROR",
+	ErrCodeConnect:            "CONNECT_ERROR",
+	ErrCodeEnhanceYourCalm:    "ENHANCE_YOUR_CALM",
+	ErrCodeInadequateSecurity: "INADEQUATE_SECURITY",
+	ErrCodeHTTP11Required:     "HTTP_1_1_REQUIRED",
+}
+
+func (e ErrCode) String() string {
+	if name, ok := errCodeNames[e]; ok {
+		return name
+	}
+	return "UNKNOWN_ERR_CODE_" + strconv.FormatUint(uint64(e), 16)
+}
+
+// StreamError 流级别的错误，如收到对端的 RST_STREAM
+type StreamError struct {
+	StreamID uint32
+	Code     ErrCode
+}
+
+func (e *StreamError) Error() string {
+	return fmt.Sprintf("http2: stream %d reset: %s", e.StreamID, e.Code)
+}
+
+// GoAwayError 对端发送了 GOAWAY，ID 大于 LastStreamID 的流没有被处理，可以安全的重试
+type GoAwayError struct {
+	LastStreamID uint32
+	Code         ErrCode
+	Debug        string
+}
+
+func (e *GoAwayError) Error() string {
+	return fmt.Sprintf("http2: server sent GOAWAY, LastStreamID=%d, Code=%s, Debug=%q", e.LastStreamID, e.Code, e.Debug)
+}
+
+// connError 连接级别的错误，需要发送 GOAWAY 后关闭连接
+type connError struct {
+	code ErrCode
+	msg  string
+}
+
+func (e *connError) Error() string {
+	return fmt.Sprintf("http2: connection error: %s: %s", e.code, e.msg)
+}
+
+var errHeaderListTooLarge = errors.New("http2: header list too large")
+
+type frame struct {
+	typ      frameType
+	flags    uint8
+	streamID uint32
+	payload  []byte
+}
+
+func (f *frame) has(flag uint8) bool {
+	return f.flags&flag != 0
+}
+
+func (f *frame) String() string {
+	return fmt.Sprintf("%s flags=0x%x stream=%d len=%d", f.typ, f.flags, f.streamID, len(f.payload))
+}
+
+// readFrame 读取一个帧，maxSize 为本端允许的最大帧长度（SETTINGS_MAX_FRAME_SIZE）
+func readFrame(r io.Reader, maxSize uint32) (*frame, error) {
+	var head [frameHeaderLen]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return nil, err
+	}
+	length := uint32(head[0])<<16 | uint32(head[1])<<8 | uint32(head[2])
+	if length > maxSize {
+		return nil, &connError{code: ErrCodeFrameSize, msg: fmt.Sprintf("frame length %d exceeds %d", length, maxSize)}
+	}
+	f := &frame{
+		typ:      frameType(head[3]),
+		flags:    head[4],
+		streamID: binary.BigEndian.Uint32(head[5:]) & (1<<31 - 1),
+		payload:  make([]byte, length),
+	}
+	if _, err := io.ReadFull(r, f.payload); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// appendFrame 将帧编码后追加到 dst
+func appendFrame(dst []byte, typ frameType, flags uint8, streamID uint32, payload []byte) []byte {
+	n := len(payload)
+	dst = append(dst, byte(n>>16), byte(n>>8), byte(n), byte(typ), flags)
+	dst = binary.BigEndian.AppendUint32(dst, streamID&(1<<31-1))
+	return append(dst, payload...)
+}
+
+// trimPadding 去掉 DATA 和 HEADERS 帧的填充
+func (f *frame) trimPadding() ([]byte, error) {
+	p := f.payload
+	if !f.has(flagPadded) {
+		return p, nil
+	}
+	if len(p) == 0 || int(p[0]) >= len(p) {
+		return nil, &connError{code: ErrCodeProtocol, msg: "invalid padding in " + f.typ.String()}
+	}
+	return p[1 : len(p)-int(p[0])], nil
+}
+
+func parseSettings(p []byte) ([]setting, error) {
+	if len(p)%6 != 0 {
+		return nil, &connError{code: ErrCodeFrameSize, msg: "invalid SETTINGS length"}
+	}
+	ss := make([]setting, 0, len(p)/6)
+	for i := 0; i < len(p); i += 6 {
+		ss = append(ss, setting{
+			id:  settingID(binary.BigEndian.Uint16(p[i:])),
+			val: binary.BigEndian.Uint32(p[i+2:]),
+		})
+	}
+	return ss, nil
+}
+
+func encodeSettings(ss ...setting) []byte {
+	p := make([]byte, 0, len(ss)*6)
+	for _, s := range ss {
+		p = binary.BigEndian.AppendUint16(p, uint16(s.id))
+		p = binary.BigEndian.AppendUint32(p, s.val)
+	}
+	return p
+}
+
+func encodeUint32(v uint32) []byte {
+	return binary.BigEndian.AppendUint32(nil, v)
+}
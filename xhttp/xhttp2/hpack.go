@@ -0,0 +1,481 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttp2
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// HPACK 头部压缩（RFC 7541）
+
+// headerField 头部字段，sensitive 为 true 时，不会加入动态表（使用 Never Indexed 方式编码）
+type headerField struct {
+	name      string
+	value     string
+	sensitive bool
+}
+
+// size 在动态表中占用的大小
+func (f headerField) size() uint32 {
+	return uint32(len(f.name) + len(f.value) + 32)
+}
+
+var staticTable = [...]headerField{
+	{name: ":authority"},
+	{name: ":method", value: "GET"},
+	{name: ":method", value: "POST"},
+	{name: ":path", value: "/"},
+	{name: ":path", value: "/index.html"},
+	{name: ":scheme", value: "http"},
+	{name: ":scheme", value: "https"},
+	{name: ":status", value: "200"},
+	{name: ":status", value: "204"},
+	{name: ":status", value: "206"},
+	{name: ":status", value: "304"},
+	{name: ":status", value: "400"},
+	{name: ":status", value: "404"},
+	{name: ":status", value: "500"},
+	{name: "accept-charset"},
+	{name: "accept-encoding", value: "gzip, deflate"},
+	{name: "accept-language"},
+	{name: "accept-ranges"},
+	{name: "accept"},
+	{name: "access-control-allow-origin"},
+	{name: "age"},
+	{name: "allow"},
+	{name: "authorization"},
+	{name: "cache-control"},
+	{name: "content-disposition"},
+	{name: "content-encoding"},
+	{name: "content-language"},
+	{name: "content-length"},
+	{name: "content-location"},
+	{name: "content-range"},
+	{name: "content-type"},
+	{name: "cookie"},
+	{name: "date"},
+	{name: "etag"},
+	{name: "expect"},
+	{name: "expires"},
+	{name: "from"},
+	{name: "host"},
+	{name: "if-match"},
+	{name: "if-modified-since"},
+	{name: "if-none-match"},
+	{name: "if-range"},
+	{name: "if-unmodified-since"},
+	{name: "last-modified"},
+	{name: "link"},
+	{name: "location"},
+	{name: "max-forwards"},
+	{name: "proxy-authenticate"},
+	{name: "proxy-authorization"},
+	{name: "range"},
+	{name: "referer"},
+	{name: "refresh"},
+	{name: "retry-after"},
+	{name: "server"},
+	{name: "set-cookie"},
+	{name: "strict-transport-security"},
+	{name: "transfer-encoding"},
+	{name: "user-agent"},
+	{name: "vary"},
+	{name: "via"},
+	{name: "www-authenticate"},
+}
+
+var errHpackInvalid = errors.New("hpack: invalid header block")
+
+// dynamicTable 动态表，ents[0] 是最新加入的元素（即 index = len(staticTable) + 1）
+type dynamicTable struct {
+	ents    []headerField
+	size    uint32
+	maxSize uint32
+}
+
+func (t *dynamicTable) add(f headerField) {
+	t.ents = append(t.ents, headerField{})
+	copy(t.ents[1:], t.ents)
+	t.ents[0] = f
+	t.size += f.size()
+	t.evict()
+}
+
+func (t *dynamicTable) setMaxSize(n uint32) {
+	t.maxSize = n
+	t.evict()
+}
+
+func (t *dynamicTable) evict() {
+	for t.size > t.maxSize && len(t.ents) > 0 {
+		last := len(t.ents) - 1
+		t.size -= t.ents[last].size()
+		t.ents[last] = headerField{}
+		t.ents = t.ents[:last]
+	}
+}
+
+// get 读取 index（从 1 开始，包括静态表）对应的字段
+func (t *dynamicTable) get(index uint64) (headerField, bool) {
+	if index == 0 {
+		return headerField{}, false
+	}
+	if index <= uint64(len(staticTable)) {
+		return staticTable[index-1], true
+	}
+	index -= uint64(len(staticTable)) + 1
+	if index >= uint64(len(t.ents)) {
+		return headerField{}, false
+	}
+	return t.ents[index], true
+}
+
+// search 查找字段，返回 index 以及 value 是否也匹配，找不到时 index 为 0
+func (t *dynamicTable) search(f headerField) (index uint64, valueMatch bool) {
+	for i, sf := range staticTable {
+		if sf.name != f.name {
+			continue
+		}
+		if index == 0 {
+			index = uint64(i + 1)
+		}
+		if !f.sensitive && sf.value == f.value {
+			return uint64(i + 1), true
+		}
+	}
+	for i, df := range t.ents {
+		if df.name != f.name {
+			continue
+		}
+		if index == 0 {
+			index = uint64(len(staticTable) + i + 1)
+		}
+		if !f.sensitive && df.value == f.value {
+			return uint64(len(staticTable) + i + 1), true
+		}
+	}
+	return index, false
+}
+
+// hpackEncoder 头部编码器，同一个连接上，所有的头部都需要按照发送的顺序依次编码
+type hpackEncoder struct {
+	table dynamicTable
+
+	// minSize 自上次编码后，设置过的最小的表大小，用于在下一个头部块的开头发送 Dynamic Table Size Update
+	minSize   uint32
+	sizeDirty bool
+}
+
+func newHpackEncoder() *hpackEncoder {
+	return &hpackEncoder{
+		table: dynamicTable{maxSize: defaultHeaderTableSize},
+	}
+}
+
+// setMaxSize 对端 SETTINGS_HEADER_TABLE_SIZE 变化后调用，编码器最多使用 defaultHeaderTableSize
+func (e *hpackEncoder) setMaxSize(n uint32) {
+	n = min(n, defaultHeaderTableSize)
+	if n == e.table.maxSize {
+		return
+	}
+	if !e.sizeDirty || n < e.minSize {
+		e.minSize = n
+	}
+	e.sizeDirty = true
+	e.table.setMaxSize(n)
+}
+
+func (e *hpackEncoder) encode(dst []byte, fields []headerField) []byte {
+	if e.sizeDirty {
+		if e.minSize < e.table.maxSize {
+			dst = appendInt(dst, 5, 0x20, uint64(e.minSize))
+		}
+		dst = appendInt(dst, 5, 0x20, uint64(e.table.maxSize))
+		e.sizeDirty = false
+	}
+	for _, f := range fields {
+		index, valueMatch := e.table.search(f)
+		switch {
+		case valueMatch:
+			// Indexed Header Field
+			dst = appendInt(dst, 7, 0x80, index)
+			continue
+		case f.sensitive:
+			// Literal Header Field Never Indexed
+			dst = appendInt(dst, 4, 0x10, index)
+		case f.size() > e.table.maxSize:
+			// 比整个表都大，没必要加入动态表：Literal Header Field without Indexing
+			dst = appendInt(dst, 4, 0x00, index)
+		default:
+			// Literal Header Field with Incremental Indexing
+			dst = appendInt(dst, 6, 0x40, index)
+			e.table.add(f)
+		}
+		if index == 0 {
+			dst = appendString(dst, f.name)
+		}
+		dst = appendString(dst, f.value)
+	}
+	return dst
+}
+
+// appendInt 使用 n 位前缀编码整数，first 是第一个字节中前缀之外的标志位
+func appendInt(dst []byte, n uint8, first byte, v uint64) []byte {
+	limit := uint64(1)<<n - 1
+	if v < limit {
+		return append(dst, first|byte(v))
+	}
+	dst = append(dst, first|byte(limit))
+	v -= limit
+	for v >= 128 {
+		dst = append(dst, byte(v&0x7f)|0x80)
+		v >>= 7
+	}
+	return append(dst, byte(v))
+}
+
+// appendString 编码字符串，若 Huffman 编码后更短，则使用 Huffman 编码
+func appendString(dst []byte, s string) []byte {
+	if hl := huffmanEncodedLen(s); hl < uint64(len(s)) {
+		dst = appendInt(dst, 7, 0x80, hl)
+		return huffmanEncode(dst, s)
+	}
+	dst = appendInt(dst, 7, 0, uint64(len(s)))
+	return append(dst, s...)
+}
+
+// hpackDecoder 头部解码器
+type hpackDecoder struct {
+	table dynamicTable
+
+	// maxTableSize 本端通过 SETTINGS_HEADER_TABLE_SIZE 允许的最大表大小
+	maxTableSize uint32
+
+	// maxListSize 解码后头部的最大大小（同 SETTINGS_MAX_HEADER_LIST_SIZE 的计算方式）
+	maxListSize uint32
+}
+
+func newHpackDecoder(maxListSize uint32) *hpackDecoder {
+	return &hpackDecoder{
+		table:        dynamicTable{maxSize: defaultHeaderTableSize},
+		maxTableSize: defaultHeaderTableSize,
+		maxListSize:  maxListSize,
+	}
+}
+
+// decode 解码一个完整的头部块（HEADERS 以及其后的 CONTINUATION 的内容）。
+// 头部列表超过 maxListSize 时，依然会解码完整个块以保持动态表的状态一致，
+// 超出后的字段会被丢弃，最后返回 errHeaderListTooLarge
+func (d *hpackDecoder) decode(block []byte) ([]headerField, error) {
+	var fields []headerField
+	var listSize uint32
+	var count int
+	for len(block) > 0 {
+		b := block[0]
+		var f headerField
+		var err error
+		switch {
+		case b&0x80 != 0:
+			// Indexed Header Field
+			var index uint64
+			if index, block, err = readInt(block, 7); err != nil {
+				return nil, err
+			}
+			var ok bool
+			if f, ok = d.table.get(index); !ok {
+				return nil, fmt.Errorf("%w: invalid index %d", errHpackInvalid, index)
+			}
+		case b&0xc0 == 0x40:
+			// Literal Header Field with Incremental Indexing
+			if f, block, err = d.readLiteral(block, 6); err != nil {
+				return nil, err
+			}
+			d.table.add(f)
+		case b&0xe0 == 0x20:
+			// Dynamic Table Size Update
+			if count > 0 {
+				return nil, fmt.Errorf("%w: table size update after header field", errHpackInvalid)
+			}
+			var size uint64
+			if size, block, err = readInt(block, 5); err != nil {
+				return nil, err
+			}
+			if size > uint64(d.maxTableSize) {
+				return nil, fmt.Errorf("%w: table size %d exceeds %d", errHpackInvalid, size, d.maxTableSize)
+			}
+			d.table.setMaxSize(uint32(size))
+			continue
+		default:
+			// Literal Header Field without Indexing（0000） 和 Never Indexed（0001）
+			if f, block, err = d.readLiteral(block, 4); err != nil {
+				return nil, err
+			}
+			f.sensitive = b&0x10 != 0
+		}
+		count++
+		listSize += f.size()
+		if d.maxListSize > 0 && listSize > d.maxListSize {
+			fields = nil
+			continue
+		}
+		fields = append(fields, f)
+	}
+	if d.maxListSize > 0 && listSize > d.maxListSize {
+		return nil, errHeaderListTooLarge
+	}
+	return fields, nil
+}
+
+func (d *hpackDecoder) readLiteral(block []byte, n uint8) (f headerField, rest []byte, err error) {
+	var index uint64
+	if index, rest, err = readInt(block, n); err != nil {
+		return f, nil, err
+	}
+	if index == 0 {
+		if f.name, rest, err = readString(rest); err != nil {
+			return f, nil, err
+		}
+	} else {
+		nf, ok := d.table.get(index)
+		if !ok {
+			return f, nil, fmt.Errorf("%w: invalid index %d", errHpackInvalid, index)
+		}
+		f.name = nf.name
+	}
+	if f.value, rest, err = readString(rest); err != nil {
+		return f, nil, err
+	}
+	return f, rest, nil
+}
+
+func readInt(buf []byte, n uint8) (uint64, []byte, error) {
+	if len(buf) == 0 {
+		return 0, nil, errHpackInvalid
+	}
+	limit := uint64(1)<<n - 1
+	v := uint64(buf[0]) & limit
+	buf = buf[1:]
+	if v < limit {
+		return v, buf, nil
+	}
+	var m uint
+	for len(buf) > 0 {
+		b := buf[0]
+		buf = buf[1:]
+		v += uint64(b&0x7f) << m
+		if b&0x80 == 0 {
+			return v, buf, nil
+		}
+		m += 7
+		if m >= 63 {
+			return 0, nil, fmt.Errorf("%w: integer overflow", errHpackInvalid)
+		}
+	}
+	return 0, nil, errHpackInvalid
+}
+
+func readString(buf []byte) (string, []byte, error) {
+	if len(buf) == 0 {
+		return "", nil, errHpackInvalid
+	}
+	huffman := buf[0]&0x80 != 0
+	length, buf, err := readInt(buf, 7)
+	if err != nil {
+		return "", nil, err
+	}
+	if uint64(len(buf)) < length {
+		return "", nil, errHpackInvalid
+	}
+	data := buf[:length]
+	buf = buf[length:]
+	if !huffman {
+		return string(data), buf, nil
+	}
+	s, err := huffmanDecode(data)
+	return s, buf, err
+}
+
+func huffmanEncodedLen(s string) uint64 {
+	var n uint64
+	for i := 0; i < len(s); i++ {
+		n += uint64(huffmanCodes[s[i]][1])
+	}
+	return (n + 7) / 8
+}
+
+func huffmanEncode(dst []byte, s string) []byte {
+	var acc uint64 // 待输出的 bit
+	var bits uint  // acc 中有效的 bit 数
+	for i := 0; i < len(s); i++ {
+		code := huffmanCodes[s[i]]
+		acc = acc<<code[1] | uint64(code[0])
+		bits += uint(code[1])
+		for bits >= 8 {
+			bits -= 8
+			dst = append(dst, byte(acc>>bits))
+		}
+	}
+	if bits > 0 {
+		// 使用 EOS 的前缀（全为 1）填充
+		dst = append(dst, byte(acc<<(8-bits))|byte(0xff>>bits))
+	}
+	return dst
+}
+
+// huffmanNode Huffman 解码树的节点，叶子节点的 children 都为 nil
+type huffmanNode struct {
+	children [2]*huffmanNode
+	sym      byte
+}
+
+var huffmanRoot = sync.OnceValue(func() *huffmanNode {
+	root := &huffmanNode{}
+	for sym, code := range huffmanCodes {
+		cur := root
+		for i := int(code[1]) - 1; i >= 0; i-- {
+			bit := (code[0] >> i) & 1
+			if cur.children[bit] == nil {
+				cur.children[bit] = &huffmanNode{}
+			}
+			cur = cur.children[bit]
+		}
+		cur.sym = byte(sym)
+	}
+	return root
+})
+
+var errHuffman = errors.New("hpack: invalid huffman-encoded data")
+
+func huffmanDecode(data []byte) (string, error) {
+	root := huffmanRoot()
+	buf := make([]byte, 0, len(data)*8/5)
+	cur := root
+	var depth int     // 当前节点的深度（即未完成的编码的 bit 数）
+	var allOne = true // 未完成的编码的 bit 是否都为 1
+	for _, b := range data {
+		for i := 7; i >= 0; i-- {
+			bit := (b >> i) & 1
+			cur = cur.children[bit]
+			if cur == nil {
+				return "", errHuffman
+			}
+			depth++
+			allOne = allOne && bit == 1
+			if cur.children[0] == nil && cur.children[1] == nil {
+				buf = append(buf, cur.sym)
+				cur = root
+				depth = 0
+				allOne = true
+			}
+		}
+	}
+	// 结尾的填充必须是 EOS 的前缀（全为 1），并且不能超过 7 bit
+	if depth > 7 || !allOne {
+		return "", errHuffman
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,122 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttp2
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/xanygo/anygo/xt"
+)
+
+// 测试数据来自 RFC 7541 附录 C.4：使用 Huffman 编码的请求
+func TestHpack(t *testing.T) {
+	requests := []struct {
+		fields []headerField
+		hex    string
+	}{
+		{
+			fields: []headerField{
+				{name: ":method", value: "GET"},
+				{name: ":scheme", value: "http"},
+				{name: ":path", value: "/"},
+				{name: ":authority", value: "www.example.com"},
+			},
+			hex: "828684418cf1e3c2e5f23a6ba0ab90f4ff",
+		},
+		{
+			fields: []headerField{
+				{name: ":method", value: "GET"},
+				{name: ":scheme", value: "http"},
+				{name: ":path", value: "/"},
+				{name: ":authority", value: "www.example.com"},
+				{name: "cache-control", value: "no-cache"},
+			},
+			hex: "828684be5886a8eb10649cbf",
+		},
+		{
+			fields: []headerField{
+				{name: ":method", value: "GET"},
+				{name: ":scheme", value: "https"},
+				{name: ":path", value: "/index.html"},
+				{name: ":authority", value: "www.example.com"},
+				{name: "custom-key", value: "custom-value"},
+			},
+			hex: "828785bf408825a849e95ba97d7f8925a849e95bb8e8b4bf",
+		},
+	}
+	enc := newHpackEncoder()
+	dec := newHpackDecoder(0)
+	for _, r := range requests {
+		got := enc.encode(nil, r.fields)
+		xt.Equal(t, hex.EncodeToString(got), r.hex)
+
+		fields, err := dec.decode(got)
+		xt.NoError(t, err)
+		xt.Equal(t, fields, r.fields)
+	}
+	xt.Equal(t, dec.table.size, uint32(164))
+
+	t.Run("sensitive", func(t *testing.T) {
+		f := headerField{name: "authorization", value: "secret", sensitive: true}
+		block := enc.encode(nil, []headerField{f})
+		fields, err := newHpackDecoder(0).decode(block)
+		xt.NoError(t, err)
+		xt.Equal(t, fields, []headerField{f})
+	})
+
+	t.Run("table size update", func(t *testing.T) {
+		e := newHpackEncoder()
+		d := newHpackDecoder(0)
+		e.setMaxSize(0)
+		fs := []headerField{{name: "x-a", value: "1"}}
+		fields, err := d.decode(e.encode(nil, fs))
+		xt.NoError(t, err)
+		xt.Equal(t, fields, fs)
+		xt.Equal(t, d.table.maxSize, uint32(0))
+		xt.Empty(t, d.table.ents)
+	})
+
+	t.Run("list too large", func(t *testing.T) {
+		fs := []headerField{{name: "x-a", value: strings.Repeat("a", 100)}}
+		_, err := newHpackDecoder(64).decode(newHpackEncoder().encode(nil, fs))
+		xt.ErrorIs(t, err, errHeaderListTooLarge)
+
+		// 超出限制后，剩余的字段依然需要写入动态表，后续的头部块才能正确解码
+		e := newHpackEncoder()
+		d := newHpackDecoder(128)
+		fs = []headerField{
+			{name: "x-a", value: strings.Repeat("a", 100)},
+			{name: "x-b", value: "b"},
+			{name: "x-c", value: "c"},
+		}
+		_, err = d.decode(e.encode(nil, fs))
+		xt.ErrorIs(t, err, errHeaderListTooLarge)
+		fields, err := d.decode(e.encode(nil, fs[1:]))
+		xt.NoError(t, err)
+		xt.Equal(t, fields, fs[1:])
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := newHpackDecoder(0).decode([]byte{0xff, 0xff})
+		xt.Error(t, err)
+		_, err = newHpackDecoder(0).decode([]byte{0xbe})
+		xt.ErrorIs(t, err, errHpackInvalid)
+	})
+}
+
+func TestHuffman(t *testing.T) {
+	for _, s := range []string{"", "a", "www.example.com", "no-cache", "Mon, 21 Oct 2013 20:13:21 GMT", "\x00\xff测试"} {
+		enc := huffmanEncode(nil, s)
+		xt.Equal(t, uint64(len(enc)), huffmanEncodedLen(s))
+		got, err := huffmanDecode(enc)
+		xt.NoError(t, err)
+		xt.Equal(t, got, s)
+	}
+	// 超过 7 位的填充
+	_, err := huffmanDecode([]byte{0xff, 0xff, 0xff, 0xff})
+	xt.ErrorIs(t, err, errHuffman)
+}
@@ -0,0 +1,569 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttp2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xmeta"
+	"github.com/xanygo/anygo/ds/xpool"
+	"github.com/xanygo/anygo/ds/xsync"
+	"github.com/xanygo/anygo/xnet"
+	"github.com/xanygo/anygo/xnet/xdial"
+)
+
+// Protocol 协议名称，在 service 配置中使用 "Protocol": "HTTP2" 后：
+//  1. 使用 HTTP/2 的连接池（同一个地址的多个请求共享同一个连接），配置的 ConnPool.Name 会被忽略
+//  2. 若配置了 TLS，会通过 ALPN 协商 h2，否则使用 prior-knowledge 的 h2c
+const Protocol = "HTTP2"
+
+func init() {
+	xdial.RegisterGroupPool(Protocol, NewGroupPool)
+	xdial.RegisterALPN(Protocol, "h2")
+}
+
+var errNotStream = errors.New("http2: Conn does not support Read/Write, use Send and Response")
+
+// NewGroupPool 创建 HTTP/2 的连接池，每个地址对应一组连接：
+//  1. 借出的是 *Conn，同一个连接可以同时借出多次（多路复用），直到达到对端的 SETTINGS_MAX_CONCURRENT_STREAMS
+//  2. 已有的连接都不可用时，创建新的连接，连接总数不超过 MaxOpen（<=0 时不限制）
+//  3. 空闲超过 MaxIdleTime 或者 创建超过 MaxLifeTime 的连接，在不再使用后关闭
+func NewGroupPool(opt *xpool.Option, cc xdial.Connector) xdial.GroupPool {
+	opt = opt.Normalization()
+	g := &groupPool{
+		option:    opt,
+		connector: cc,
+		pools:     make(map[string]*pool),
+		stop:      make(chan struct{}),
+	}
+	go g.cleanLoop()
+	return g
+}
+
+var _ xdial.GroupPool = (*groupPool)(nil)
+
+type groupPool struct {
+	option    *xpool.Option
+	connector xdial.Connector
+
+	mux    sync.Mutex
+	pools  map[string]*pool
+	closed bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func (g *groupPool) getPool(addr xnet.AddrNode) (*pool, error) {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	if g.closed {
+		return nil, xpool.ErrClosed
+	}
+	key := addr.Key()
+	p := g.pools[key]
+	if p == nil {
+		p = &pool{
+			group:    g,
+			addr:     addr,
+			changed:  make(chan struct{}),
+			lastUsed: time.Now(),
+		}
+		g.pools[key] = p
+	}
+	return p, nil
+}
+
+func (g *groupPool) Get(ctx context.Context, key xnet.AddrNode) (xpool.Entry[io.ReadWriteCloser], error) {
+	p, err := g.getPool(key)
+	if err != nil {
+		return nil, err
+	}
+	return p.Get(ctx)
+}
+
+func (g *groupPool) GetIdle(ctx context.Context, key xnet.AddrNode) (xpool.Entry[io.ReadWriteCloser], error) {
+	return nil, nil
+}
+
+func (g *groupPool) Put(e xpool.Entry[io.ReadWriteCloser], err error) {
+	if c, ok := e.Raw().(*Conn); ok && c != nil {
+		c.pc.pool.Put(e, err)
+	}
+}
+
+func (g *groupPool) Close() error {
+	g.stopOnce.Do(func() {
+		close(g.stop)
+	})
+	g.mux.Lock()
+	g.closed = true
+	pools := g.pools
+	g.pools = map[string]*pool{}
+	g.mux.Unlock()
+	for _, p := range pools {
+		_ = p.Close()
+	}
+	return nil
+}
+
+func (g *groupPool) Stats() xpool.Stats {
+	var s xpool.Stats
+	g.Range(func(key string, p xpool.Pool[io.ReadWriteCloser]) bool {
+		s = s.Add(p.Stats())
+		return true
+	})
+	return s
+}
+
+func (g *groupPool) GroupStats() map[string]xpool.Stats {
+	result := make(map[string]xpool.Stats)
+	g.Range(func(key string, p xpool.Pool[io.ReadWriteCloser]) bool {
+		result[key] = p.Stats()
+		return true
+	})
+	return result
+}
+
+func (g *groupPool) Range(fn func(key string, p xpool.Pool[io.ReadWriteCloser]) bool) {
+	g.mux.Lock()
+	pools := make(map[string]*pool, len(g.pools))
+	for k, p := range g.pools {
+		pools[k] = p
+	}
+	g.mux.Unlock()
+	for k, p := range pools {
+		if !fn(k, p) {
+			return
+		}
+	}
+}
+
+// cleanLoop 定期关闭过期的连接，以及长时间未使用的子连接池
+func (g *groupPool) cleanLoop() {
+	ticker := time.NewTicker(max(time.Second, min(g.option.MaxIdleTime, g.option.MaxPoolIdleTime)/2))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-g.stop:
+			return
+		case <-ticker.C:
+		}
+		g.mux.Lock()
+		var idle []*pool
+		for k, p := range g.pools {
+			if p.idleTooLong() {
+				delete(g.pools, k)
+				idle = append(idle, p)
+			}
+		}
+		pools := make([]*pool, 0, len(g.pools))
+		for _, p := range g.pools {
+			pools = append(pools, p)
+		}
+		g.mux.Unlock()
+		for _, p := range idle {
+			_ = p.Close()
+		}
+		for _, p := range pools {
+			p.clean()
+		}
+	}
+}
+
+var _ xpool.Pool[io.ReadWriteCloser] = (*pool)(nil)
+
+// pool 一个地址对应的连接池
+type pool struct {
+	group *groupPool
+	addr  xnet.AddrNode
+
+	mux      sync.Mutex
+	conns    []*pooledConn
+	dialing  bool
+	changed  chan struct{} // 连接的状态变化（如被归还、创建完成）时，会被关闭并重建
+	closed   bool
+	lastUsed time.Time
+
+	wait              int
+	waitCount         int64
+	waitDuration      time.Duration
+	maxIdleTimeClosed int64
+	maxLifeTimeClosed int64
+}
+
+// pooledConn 连接池中的一个连接
+type pooledConn struct {
+	pool      *pool
+	cc        *ClientConn
+	conn      net.Conn
+	createdAt time.Time
+	lastUsed  time.Time
+	inUse     int
+}
+
+func (pc *pooledConn) available() bool {
+	return pc.inUse < pc.cc.MaxConcurrentStreams() && pc.cc.CanTakeNewRequest()
+}
+
+func (p *pool) notifyLocked() {
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+func (p *pool) Get(ctx context.Context) (xpool.Entry[io.ReadWriteCloser], error) {
+	var waitStart time.Time
+	for {
+		p.mux.Lock()
+		if p.closed {
+			p.mux.Unlock()
+			return nil, xpool.ErrClosed
+		}
+		now := time.Now()
+		p.lastUsed = now
+		closing := p.cleanLocked(now)
+		if pc := p.pickLocked(); pc != nil {
+			pc.inUse++
+			pc.lastUsed = now
+			p.waitDoneLocked(waitStart)
+			p.mux.Unlock()
+			closeConns(closing)
+			return p.newEntry(pc), nil
+		}
+		maxOpen := p.group.option.MaxOpen
+		if !p.dialing && (maxOpen <= 0 || len(p.conns) < maxOpen) {
+			p.dialing = true
+			p.waitDoneLocked(waitStart)
+			p.mux.Unlock()
+			closeConns(closing)
+			return p.dialAndGet(ctx)
+		}
+		if waitStart.IsZero() {
+			waitStart = now
+			p.waitCount++
+			p.wait++
+		}
+		ch := p.changed
+		p.mux.Unlock()
+		closeConns(closing)
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			p.mux.Lock()
+			p.waitDoneLocked(waitStart)
+			p.mux.Unlock()
+			return nil, context.Cause(ctx)
+		}
+	}
+}
+
+func (p *pool) waitDoneLocked(waitStart time.Time) {
+	if waitStart.IsZero() {
+		return
+	}
+	p.wait--
+	p.waitDuration += time.Since(waitStart)
+}
+
+func (p *pool) dialAndGet(ctx context.Context) (xpool.Entry[io.ReadWriteCloser], error) {
+	pc, err := p.dial(ctx)
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.dialing = false
+	p.notifyLocked()
+	if err != nil {
+		return nil, err
+	}
+	if p.closed {
+		_ = pc.cc.Close()
+		return nil, xpool.ErrClosed
+	}
+	pc.inUse++
+	p.conns = append(p.conns, pc)
+	return p.newEntry(pc), nil
+}
+
+func (p *pool) dial(ctx context.Context) (*pooledConn, error) {
+	ctx = xpool.ContextWithOption(ctx, p.group.option)
+	rw, err := xdial.Connect(ctx, p.group.connector, p.addr, nil)
+	if err != nil {
+		return nil, err
+	}
+	conn, ok := rw.(net.Conn)
+	if !ok {
+		_ = rw.Close()
+		return nil, fmt.Errorf("http2: connection is %T, not net.Conn", rw)
+	}
+	cc, err := NewClientConn(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	now := time.Now()
+	return &pooledConn{
+		pool:      p,
+		cc:        cc,
+		conn:      conn,
+		createdAt: now,
+		lastUsed:  now,
+	}, nil
+}
+
+// pickLocked 选择一个可用的连接，优先使用已创建的连接，以让请求尽可能的集中在少数连接上
+func (p *pool) pickLocked() *pooledConn {
+	lifeTime := p.group.option.MaxLifeTime
+	for _, pc := range p.conns {
+		if lifeTime > 0 && time.Since(pc.createdAt) > lifeTime {
+			continue
+		}
+		if pc.available() {
+			return pc
+		}
+	}
+	return nil
+}
+
+// cleanLocked 从连接池中移除不可用、空闲超时、存活超时的连接，返回需要关闭的连接
+func (p *pool) cleanLocked(now time.Time) []*pooledConn {
+	opt := p.group.option
+	var closing []*pooledConn
+	p.conns = slices.DeleteFunc(p.conns, func(pc *pooledConn) bool {
+		if pc.cc.Err() != nil {
+			return pc.inUse == 0
+		}
+		if pc.inUse > 0 {
+			return false
+		}
+		var remove bool
+		switch {
+		case opt.MaxLifeTime > 0 && now.Sub(pc.createdAt) > opt.MaxLifeTime:
+			p.maxLifeTimeClosed++
+			remove = true
+		case opt.MaxIdleTime > 0 && now.Sub(pc.lastUsed) > opt.MaxIdleTime:
+			p.maxIdleTimeClosed++
+			remove = true
+		case !pc.cc.CanTakeNewRequest() && pc.cc.ActiveStreams() == 0:
+			// 如已收到 GOAWAY
+			remove = true
+		}
+		if remove {
+			closing = append(closing, pc)
+		}
+		return remove
+	})
+	return closing
+}
+
+func (p *pool) clean() {
+	p.mux.Lock()
+	closing := p.cleanLocked(time.Now())
+	p.mux.Unlock()
+	closeConns(closing)
+}
+
+func closeConns(conns []*pooledConn) {
+	for _, pc := range conns {
+		_ = pc.cc.Close()
+	}
+}
+
+func (p *pool) idleTooLong() bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.wait > 0 || p.dialing {
+		return false
+	}
+	for _, pc := range p.conns {
+		if pc.inUse > 0 {
+			return false
+		}
+	}
+	return time.Since(p.lastUsed) > p.group.option.MaxPoolIdleTime
+}
+
+func (p *pool) newEntry(pc *pooledConn) xpool.Entry[io.ReadWriteCloser] {
+	e := xpool.NewOpenEntry[io.ReadWriteCloser](&Conn{pc: pc}, p)
+	e.UpdateUsing()
+	return e
+}
+
+func (p *pool) GetIdle(ctx context.Context) (xpool.Entry[io.ReadWriteCloser], error) {
+	return nil, nil
+}
+
+func (p *pool) Put(e xpool.Entry[io.ReadWriteCloser], err error) {
+	c, ok := e.Raw().(*Conn)
+	if !ok || c == nil {
+		return
+	}
+	c.closeStream()
+	pc := c.pc
+	if errors.Is(err, xpool.ErrBadEntry) {
+		_ = pc.cc.Close()
+	}
+	p.mux.Lock()
+	pc.inUse--
+	pc.lastUsed = time.Now()
+	p.notifyLocked()
+	p.mux.Unlock()
+}
+
+func (p *pool) Close() error {
+	p.mux.Lock()
+	p.closed = true
+	conns := p.conns
+	p.conns = nil
+	p.notifyLocked()
+	p.mux.Unlock()
+	closeConns(conns)
+	return nil
+}
+
+func (p *pool) Stats() xpool.Stats {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	opt := p.group.option
+	s := xpool.Stats{
+		Open:              !p.closed,
+		MaxOpen:           opt.MaxOpen,
+		MaxLifeTime:       opt.MaxLifeTime,
+		MaxIdleTime:       opt.MaxIdleTime,
+		NumOpen:           len(p.conns),
+		Wait:              p.wait,
+		WaitCount:         p.waitCount,
+		WaitDuration:      p.waitDuration,
+		MaxIdleTimeClosed: p.maxIdleTimeClosed,
+		MaxLifeTimeClosed: p.maxLifeTimeClosed,
+	}
+	for _, pc := range p.conns {
+		if pc.inUse > 0 {
+			s.InUse++
+		} else {
+			s.Idle++
+		}
+	}
+	return s
+}
+
+var _ io.ReadWriteCloser = (*Conn)(nil)
+var _ xpool.Recycler = (*Conn)(nil)
+var _ xmeta.Setter = (*Conn)(nil)
+var _ xmeta.Getter = (*Conn)(nil)
+
+// Conn 从 HTTP/2 连接池中借出的连接，每次借出都是一个新的 Conn，用于发送一个请求：
+// 先使用 Send 发送请求，再使用 Response 读取响应。
+//
+// 多个 Conn 共享同一个 ClientConn（即同一个网络连接），调用 Close 会归还到连接池，不会关闭网络连接。
+type Conn struct {
+	pc *pooledConn
+
+	mux       sync.Mutex
+	stream    *clientStream
+	onRecycle xsync.OnceRead[func()]
+	err       xsync.Value[error]
+}
+
+// Addr 连接的地址
+func (c *Conn) Addr() xnet.AddrNode {
+	return c.pc.pool.addr
+}
+
+// ClientConn 底层的 HTTP/2 连接
+func (c *Conn) ClientConn() *ClientConn {
+	return c.pc.cc
+}
+
+// Send 创建流并发送请求，请求的 Body 会在后台继续发送，ctx 只用于等待可用的流以及发送请求头
+func (c *Conn) Send(ctx context.Context, req *http.Request) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.stream != nil {
+		return errors.New("http2: Conn already has an active request")
+	}
+	cs, err := c.pc.cc.newStream(ctx, req)
+	if err != nil {
+		c.err.Store(err)
+		return err
+	}
+	c.stream = cs
+	return nil
+}
+
+// Response 等待 Send 发送的请求的响应头，ctx 结束时，流会被取消，响应的 Body 也会读取失败
+func (c *Conn) Response(ctx context.Context) (*http.Response, error) {
+	c.mux.Lock()
+	cs := c.stream
+	c.mux.Unlock()
+	if cs == nil {
+		return nil, errors.New("http2: no request sent")
+	}
+	resp, err := cs.awaitResponse(ctx)
+	if err != nil {
+		c.err.Store(err)
+	}
+	return resp, err
+}
+
+// closeStream 归还时，若响应还未读取完，取消流
+func (c *Conn) closeStream() {
+	c.mux.Lock()
+	cs := c.stream
+	c.stream = nil
+	c.mux.Unlock()
+	if cs == nil {
+		return
+	}
+	select {
+	case <-cs.done:
+	default:
+		cs.abort(errStreamDone, ErrCodeCancel)
+	}
+}
+
+func (c *Conn) Read(p []byte) (int, error) {
+	return 0, errNotStream
+}
+
+func (c *Conn) Write(p []byte) (int, error) {
+	return 0, errNotStream
+}
+
+// Close 归还到连接池
+func (c *Conn) Close() error {
+	if recycle, ok := c.onRecycle.Read(); ok && recycle != nil {
+		recycle()
+		return nil
+	}
+	c.closeStream()
+	return nil
+}
+
+func (c *Conn) OnRecycle(fn func()) {
+	c.onRecycle.Store(sync.OnceFunc(fn))
+}
+
+// Err 连接不可用时（如已关闭、收到 GOAWAY），返回原因
+func (c *Conn) Err() error {
+	return c.pc.cc.Err()
+}
+
+func (c *Conn) SetMeta(key any, val any) {
+	xmeta.TrySet(c.pc.conn, key, val)
+}
+
+func (c *Conn) GetMeta(key any) (any, bool) {
+	if g, ok := c.pc.conn.(xmeta.Getter); ok {
+		return g.GetMeta(key)
+	}
+	return nil, false
+}
@@ -0,0 +1,103 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttp2_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/xanygo/anygo/ds/xoption"
+	"github.com/xanygo/anygo/xhttp/xhttp2"
+	"github.com/xanygo/anygo/xhttp/xhttpc"
+	"github.com/xanygo/anygo/xnet/xservice"
+	"github.com/xanygo/anygo/xt"
+)
+
+func TestGroupPool(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Remote", r.RemoteAddr)
+		_, _ = io.Copy(w, r.Body)
+	})
+	h2c := newH2CServer(t, handler)
+
+	h2 := httptest.NewUnstartedServer(handler)
+	h2.EnableHTTP2 = true
+	h2.StartTLS()
+	defer h2.Close()
+
+	tests := []struct {
+		name string
+		ts   *httptest.Server
+		tls  *xoption.TLSConfig
+	}{
+		{name: "h2c", ts: h2c},
+		{name: "h2", ts: h2, tls: &xoption.TLSConfig{SkipVerify: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.ts.URL)
+			xt.NoError(t, err)
+			cfg := &xservice.Config{
+				Name:     "h2-" + tt.name,
+				Protocol: xhttp2.Protocol,
+				TLS:      tt.tls,
+				DownStream: xservice.DownStreamPart{
+					Address: []string{u.Host},
+				},
+			}
+			srv, err := cfg.Parser("test")
+			xt.NoError(t, err)
+			xt.NoError(t, srv.Start(t.Context()))
+			defer srv.Stop(t.Context())
+
+			var mux sync.Mutex
+			remotes := map[string]int{}
+			var wg sync.WaitGroup
+			for i := range 20 {
+				wg.Go(func() {
+					resp := &http.Response{}
+					body := map[string]int{"id": i}
+					err := xhttpc.PostJSON(t.Context(), srv, "/post", body, xhttpc.FetchResponse(resp))
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					got, _ := io.ReadAll(resp.Body)
+					if resp.ProtoMajor != 2 || string(got) != fmt.Sprintf(`{"id":%d}`, i) {
+						t.Errorf("unexpected response: %s %q", resp.Proto, got)
+					}
+					mux.Lock()
+					remotes[resp.Header.Get("X-Remote")]++
+					mux.Unlock()
+				})
+			}
+			wg.Wait()
+			// 所有的请求都使用同一个连接
+			xt.Len(t, remotes, 1)
+
+			stats := srv.GroupPool().Stats()
+			xt.Equal(t, stats.NumOpen, 1)
+			xt.Equal(t, stats.InUse, 0)
+
+			// 流式读取时，Body 关闭前一直占用连接
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, "/stream", strings.NewReader("hello stream"))
+			xt.NoError(t, err)
+			sr, err := xhttpc.OpenStream(t.Context(), srv, req)
+			xt.NoError(t, err)
+			xt.Equal(t, srv.GroupPool().Stats().InUse, 1)
+			got, err := io.ReadAll(sr.Body)
+			xt.NoError(t, err)
+			xt.Equal(t, string(got), "hello stream")
+			xt.NoError(t, sr.Body.Close())
+			xt.Equal(t, srv.GroupPool().Stats().InUse, 0)
+		})
+	}
+}
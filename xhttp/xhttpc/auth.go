@@ -0,0 +1,190 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttpc
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/xanygo/anygo/ds/xoption"
+	"github.com/xanygo/anygo/xnet/xrpc"
+	"github.com/xanygo/anygo/xnet/xservice"
+)
+
+// authorization 返回 Basic 和 Bearer 认证的 Authorization Header 的值，其他类型返回空字符串
+func authorization(auth *xservice.HTTPAuthPart) string {
+	if auth == nil {
+		return ""
+	}
+	switch auth.Type {
+	case xservice.AuthBasic:
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(auth.Username+":"+auth.Password))
+	case xservice.AuthBearer:
+		return "Bearer " + auth.Token
+	default:
+		return ""
+	}
+}
+
+// digestRequest 收到 401 响应后，若配置了 Digest 认证，依据 WWW-Authenticate 创建带有认证信息的请求（RFC 7616）
+func digestRequest(req xrpc.Request, sent *http.Request, rr *http.Response, opt xoption.Reader, st hopState) (*NativeRequest, error) {
+	auth := optAuth(opt)
+	if auth == nil || auth.Type != xservice.AuthDigest || st.noAuth || st.digest {
+		return nil, nil
+	}
+	var ch digestChallenge
+	var found bool
+	for _, v := range rr.Header.Values("Www-Authenticate") {
+		if ch, found = parseDigestChallenge(v); found {
+			break
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+	next := baseRequest(sent, opt, true)
+	if next == nil {
+		return nil, nil
+	}
+	value, err := ch.authorize(auth, next.Method, next.URL.RequestURI())
+	if err != nil {
+		return nil, err
+	}
+	next.Header.Set("Authorization", value)
+	st.digest = true
+	return newHopRequest(req, next, st), nil
+}
+
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       []string
+	userhash  bool
+}
+
+// parseDigestChallenge 解析 WWW-Authenticate: Digest realm="x", nonce="y", qop="auth", algorithm=SHA-256
+func parseDigestChallenge(value string) (digestChallenge, bool) {
+	var ch digestChallenge
+	scheme, params, _ := strings.Cut(strings.TrimSpace(value), " ")
+	if !strings.EqualFold(scheme, "Digest") {
+		return ch, false
+	}
+	for len(params) > 0 {
+		var key, val string
+		key, params, _ = strings.Cut(params, "=")
+		key = strings.ToLower(strings.Trim(key, " \t,"))
+		params = strings.TrimLeft(params, " \t")
+		if strings.HasPrefix(params, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(params) && params[i] != '"'; i++ {
+				if params[i] == '\\' && i+1 < len(params) {
+					i++
+				}
+				b.WriteByte(params[i])
+			}
+			val = b.String()
+			params = params[min(i+1, len(params)):]
+		} else {
+			val, params, _ = strings.Cut(params, ",")
+			val = strings.TrimSpace(val)
+		}
+		params = strings.TrimLeft(params, " \t,")
+		switch key {
+		case "realm":
+			ch.realm = val
+		case "nonce":
+			ch.nonce = val
+		case "opaque":
+			ch.opaque = val
+		case "algorithm":
+			ch.algorithm = val
+		case "qop":
+			for q := range strings.SplitSeq(val, ",") {
+				ch.qop = append(ch.qop, strings.TrimSpace(q))
+			}
+		case "userhash":
+			ch.userhash = strings.EqualFold(val, "true")
+		}
+	}
+	return ch, ch.nonce != ""
+}
+
+func (ch digestChallenge) authorize(auth *xservice.HTTPAuthPart, method string, uri string) (string, error) {
+	var newHash func() hash.Hash
+	algorithm := strings.ToUpper(ch.algorithm)
+	switch strings.TrimSuffix(algorithm, "-SESS") {
+	case "", "MD5":
+		newHash = md5.New
+	case "SHA-256":
+		newHash = sha256.New
+	default:
+		return "", fmt.Errorf("unsupported digest algorithm %q", ch.algorithm)
+	}
+	h := func(s string) string {
+		hh := newHash()
+		hh.Write([]byte(s))
+		return hex.EncodeToString(hh.Sum(nil))
+	}
+
+	cnonce := rand.Text()
+	const nc = "00000001"
+	ha1 := h(auth.Username + ":" + ch.realm + ":" + auth.Password)
+	if strings.HasSuffix(algorithm, "-SESS") {
+		ha1 = h(ha1 + ":" + ch.nonce + ":" + cnonce)
+	}
+	ha2 := h(method + ":" + uri)
+
+	var qop string
+	if slices.Contains(ch.qop, "auth") {
+		qop = "auth"
+	} else if len(ch.qop) > 0 {
+		return "", fmt.Errorf("unsupported digest qop %q", ch.qop)
+	}
+	var response string
+	if qop == "" {
+		response = h(ha1 + ":" + ch.nonce + ":" + ha2)
+	} else {
+		response = h(ha1 + ":" + ch.nonce + ":" + nc + ":" + cnonce + ":" + qop + ":" + ha2)
+	}
+
+	username := auth.Username
+	if ch.userhash {
+		username = h(auth.Username + ":" + ch.realm)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `Digest username=%s, realm=%s, nonce=%s, uri=%s, response=%s`,
+		quoteString(username), quoteString(ch.realm), quoteString(ch.nonce), quoteString(uri), quoteString(response))
+	if ch.algorithm != "" {
+		fmt.Fprintf(&b, `, algorithm=%s`, ch.algorithm)
+	}
+	if ch.opaque != "" {
+		fmt.Fprintf(&b, `, opaque=%s`, quoteString(ch.opaque))
+	}
+	if qop != "" {
+		fmt.Fprintf(&b, `, qop=%s, nc=%s, cnonce=%s`, qop, nc, quoteString(cnonce))
+	}
+	if ch.userhash {
+		b.WriteString(", userhash=true")
+	}
+	return b.String(), nil
+}
+
+var quoteReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// quoteString 转换为 RFC 9110 的 quoted-string
+func quoteString(s string) string {
+	return `"` + quoteReplacer.Replace(s) + `"`
+}
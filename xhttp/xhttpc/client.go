@@ -34,6 +34,9 @@ func (in InvokeFunc) Invoke(ctx context.Context, service any, req *http.Request,
 	return in(ctx, service, req, handler, opts...)
 }
 
+// Invoke 发送请求，使用 handler 处理响应。
+//
+// 若启用了重定向（ OptRedirect ）或者 Digest 认证（ OptAuth ），会自动发送后续的请求，handler 只处理最终的响应
 func Invoke(ctx context.Context, service any, req *http.Request, handler HandlerFunc, opts ...xrpc.Option) error {
 	hr := &NativeRequest{
 		Request: req,
@@ -41,7 +44,17 @@ func Invoke(ctx context.Context, service any, req *http.Request, handler Handler
 	resp := &Response{
 		Handler: handler,
 	}
-	return xrpc.Invoke(ctx, service, hr, resp, opts...)
+	return invoke(ctx, service, hr, resp, opts)
+}
+
+func invoke(ctx context.Context, service any, hr *NativeRequest, resp *Response, opts []xrpc.Option) error {
+	for {
+		err := xrpc.Invoke(ctx, service, hr, resp, opts...)
+		if err != nil || resp.next == nil {
+			return err
+		}
+		hr = resp.next
+	}
 }
 
 func Get(ctx context.Context, service any, url string, handler HandlerFunc, opts ...xrpc.Option) error {
@@ -71,6 +84,10 @@ func GetAsJSON[T any](ctx context.Context, service any, url string, opts ...xrpc
 }
 
 func InvokeWithCodec(ctx context.Context, service any, method string, url string, body any, ec xcodec.Encoder, handler HandlerFunc, opts ...xrpc.Option) error {
+	return invokeWithCodec(ctx, InvokeFunc(Invoke), service, method, url, body, ec, handler, opts...)
+}
+
+func invokeWithCodec(ctx context.Context, inv Invoker, service any, method string, url string, body any, ec xcodec.Encoder, handler HandlerFunc, opts ...xrpc.Option) error {
 	contentType, err := xcodec.ContentType(ec)
 	if err != nil {
 		return err
@@ -91,7 +108,7 @@ func InvokeWithCodec(ctx context.Context, service any, method string, url string
 		}
 	}
 	req.Header.Set("Content-Type", contentType)
-	return Invoke(ctx, service, req, handler, opts...)
+	return inv.Invoke(ctx, service, req, handler, opts...)
 }
 
 func Post(ctx context.Context, service any, url string, ct string, body io.Reader, handler HandlerFunc, opts ...xrpc.Option) error {
@@ -132,7 +149,7 @@ type Executor interface {
 // ExecutorToInvoker 将 Executor 转换为 Invoker
 //
 // 使用场景：
-// 默认的 Invoker 是不支持 302 跳转的，在使用 CachedClient 的时候，会有诸多限制，所以若需要跳转，则可以这样：
+// 默认的 Invoker 不会跟随 302 跳转（需要使用 OptRedirect 启用），若需要使用 http.Client 的跳转逻辑，则可以这样：
 //
 //	 hc := &http.Client{
 //	    Transport: &xhttpc.Client{},
@@ -156,11 +173,12 @@ var _ http.RoundTripper = (*Client)(nil)
 
 // Client 实现了 RoundTripper 的 HTTP Client
 //
-// 该 Client 所有方法都只会发送一次请求，不会处理更高层协议细节，例如重定向、认证或 Cookie。
-// 若需要处理重定向，可以结合 http.Client 使用
+// 默认只会发送一次请求，不会处理重定向、认证或 Cookie，可以通过 Opts 或者 service 的配置启用，
+// 如 OptRedirect、OptCookieJar、OptAuth。也可以结合 http.Client 使用
 type Client struct {
 	Service any           // 可选，当为空时，会使用 Dummy
 	Opts    []xrpc.Option // 可选，额外的 RPC Client 参数
+	Cache   *HTTPCache    // 可选，遵循 HTTP 缓存语义的缓存
 }
 
 func (c *Client) getService() any {
@@ -170,42 +188,63 @@ func (c *Client) getService() any {
 	return c.Service
 }
 
+func (c *Client) getInvoker() Invoker {
+	if c.Cache == nil {
+		return InvokeFunc(Invoke)
+	}
+	return c.Cache
+}
+
+// RoundTrip 发送请求，未配置 Cache 时，响应的 Body 是流式读取的（见 OpenStream），调用方必须关闭 Body
 func (c *Client) RoundTrip(req *http.Request) (*http.Response, error) {
+	if c.Cache == nil {
+		return OpenStream(req.Context(), c.getService(), req, c.Opts...)
+	}
+	return c.do(req)
+}
+
+// do 发送请求，响应的 Body 会被完整读取到内存中
+func (c *Client) do(req *http.Request) (*http.Response, error) {
 	r := &http.Response{}
 	handler := FetchResponse(r)
-	err := Invoke(req.Context(), c.getService(), req, handler, c.Opts...)
+	err := c.getInvoker().Invoke(req.Context(), c.getService(), req, handler, c.Opts...)
 	return r, err
 }
 
 func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
-	r := &http.Response{}
-	handler := FetchResponse(r)
-	err := Get(ctx, c.getService(), url, handler, c.Opts...)
-	return r, err
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(req)
 }
 
 func (c *Client) Post(ctx context.Context, url string, contentType string, body io.Reader) (*http.Response, error) {
-	r := &http.Response{}
-	handler := FetchResponse(r)
-	err := Post(ctx, c.getService(), url, contentType, body, handler, c.Opts...)
-	return r, err
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return c.do(req)
 }
 
 func (c *Client) PostForm(ctx context.Context, url string, data url.Values) (*http.Response, error) {
 	r := &http.Response{}
 	handler := FetchResponse(r)
-	err := PostForm(ctx, c.getService(), url, data, handler, c.Opts...)
+	err := invokeWithCodec(ctx, c.getInvoker(), c.getService(), http.MethodPost, url, data, xcodec.Form, handler, c.Opts...)
 	return r, err
 }
 
 func (c *Client) PostJSON(ctx context.Context, url string, data any) (*http.Response, error) {
 	r := &http.Response{}
 	handler := FetchResponse(r)
-	err := PostJSON(ctx, c.getService(), url, data, handler, c.Opts...)
+	err := invokeWithCodec(ctx, c.getInvoker(), c.getService(), http.MethodPost, url, data, xcodec.JSON, handler, c.Opts...)
 	return r, err
 }
 
 // CachedClient 带有缓存的 HTTP Client,只会成功获取 response 的才会被缓存
+//
+// 缓存时间是固定的，不考虑响应的 Cache-Control 等 Header，若需要遵循 HTTP 缓存语义，请使用 HTTPCache
 type CachedClient struct {
 	Cache   xcache.Cache[string, *StoredResponse] // 必填，缓存对象
 	Request *http.Request                         // 必填，请求
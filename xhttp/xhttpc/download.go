@@ -0,0 +1,292 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttpc
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/xanygo/anygo/xerror"
+	"github.com/xanygo/anygo/xnet/xrpc"
+	"github.com/xanygo/anygo/xnet/xservice"
+)
+
+// ErrChecksumMismatch 下载的文件和 Download.Checksum 不一致
+var ErrChecksumMismatch = errors.New("xhttpc: checksum mismatch")
+
+// Download 使用 Range 请求断点续传下载文件，响应的 Body 是流式读取的，适合下载大文件
+type Download struct {
+	Service any         // 可选，为空时使用 Dummy
+	URL     string      // 必填，下载地址，使用 service 时可以只有 Path，如 /files/a.zip
+	Header  http.Header // 可选，额外的请求 Header
+
+	// File 必填，保存的文件路径。下载过程中写入 File + ".part"，下载完成并校验通过后，重命名为 File。
+	// 服务端文件的 ETag 或 Last-Modified 保存在 File + ".part.meta" 中，
+	// 若 ".part" 文件已存在（如上次下载中断），会使用 Range 和 If-Range 从其末尾继续下载，
+	// 若没有 ".part.meta" 文件（无法确认服务端的文件是否已变化），会从头开始下载
+	File string
+
+	// Checksum 可选，文件的校验和，格式为 "算法:十六进制值"，如 "sha256:e3b0c442...",
+	// 支持 md5、sha1、sha256、sha512。校验失败时，会删除下载的文件，并返回 ErrChecksumMismatch
+	Checksum string
+
+	// Retry 可选，下载中断后，继续下载的次数，默认为 3，小于 0 时不重试
+	Retry int
+
+	Opts []xrpc.Option // 可选
+}
+
+func (d *Download) getService() any {
+	if d.Service == nil {
+		return xservice.GetDummyService()
+	}
+	return d.Service
+}
+
+func (d *Download) getRetry() int {
+	if d.Retry == 0 {
+		return 3
+	}
+	return max(d.Retry, 0)
+}
+
+// Do 下载文件
+func (d *Download) Do(ctx context.Context) error {
+	newHash, sum, err := parseChecksum(d.Checksum)
+	if err != nil {
+		return err
+	}
+	part := d.File + ".part"
+	f, err := os.OpenFile(part, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	st := &downloadState{total: -1, metaFile: part + ".meta"}
+	st.loadValidator()
+	for attempt := 0; ; attempt++ {
+		err = d.fetch(ctx, f, st)
+		if err == nil {
+			break
+		}
+		var te xerror.TemporaryFailure
+		if attempt >= d.getRetry() || ctx.Err() != nil || (errors.As(err, &te) && !te.Temporary()) {
+			return err
+		}
+	}
+
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if st.total >= 0 && size != st.total {
+		return fmt.Errorf("xhttpc: downloaded %d bytes, expected %d", size, st.total)
+	}
+	if newHash != nil {
+		h := newHash()
+		if _, err = f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err = io.Copy(h, f); err != nil {
+			return err
+		}
+		if got := hex.EncodeToString(h.Sum(nil)); got != sum {
+			_ = f.Close()
+			_ = os.Remove(part)
+			_ = os.Remove(st.metaFile)
+			return fmt.Errorf("%w: got %s, expected %s", ErrChecksumMismatch, got, sum)
+		}
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(part, d.File); err != nil {
+		return err
+	}
+	_ = os.Remove(st.metaFile)
+	return nil
+}
+
+// downloadState 多次请求之间共享的状态
+type downloadState struct {
+	validator string // 首次响应的 ETag 或 Last-Modified，续传时用于 If-Range
+	total     int64  // 文件的总长度，-1 表示未知
+	metaFile  string // 保存 validator 的文件，用于在新的进程中续传
+}
+
+func (st *downloadState) loadValidator() {
+	bf, err := os.ReadFile(st.metaFile)
+	if err == nil {
+		st.validator = strings.TrimSpace(string(bf))
+	}
+}
+
+// setValidator 更新 validator 并保存到 metaFile，value 为空时删除 metaFile
+func (st *downloadState) setValidator(value string) error {
+	st.validator = value
+	if value == "" {
+		if err := os.Remove(st.metaFile); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(st.metaFile, []byte(value), 0644)
+}
+
+// fetch 从文件的末尾开始下载
+func (d *Download) fetch(ctx context.Context, f *os.File, st *downloadState) error {
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.URL, nil)
+	if err != nil {
+		return xerror.WithTemporary(err, false)
+	}
+	for k, vs := range d.Header {
+		req.Header[k] = vs
+	}
+	if offset > 0 && st.validator == "" {
+		// 无法确认已下载的内容和服务端的文件是否一致，从头开始下载
+		if err = truncate(f); err != nil {
+			return err
+		}
+		offset = 0
+	}
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+		// 文件已经变化时，服务端会返回完整的内容
+		req.Header.Set("If-Range", st.validator)
+	}
+	resp, err := OpenStream(ctx, d.getService(), req, d.Opts...)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err = truncate(f); err != nil {
+			return err
+		}
+		st.total = resp.ContentLength
+	case http.StatusPartialContent:
+		start, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			// 和请求的范围不一致，从头开始下载
+			if err = truncate(f); err != nil {
+				return err
+			}
+			return fmt.Errorf("xhttpc: unexpected Content-Range %q", resp.Header.Get("Content-Range"))
+		}
+		st.total = total
+	case http.StatusRequestedRangeNotSatisfiable:
+		// 可能已经下载完成
+		_, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if ok && total == offset {
+			st.total = total
+			return nil
+		}
+		if err = truncate(f); err != nil {
+			return err
+		}
+		return xerror.NewStatusError(int64(resp.StatusCode))
+	default:
+		return xerror.WithTemporary(xerror.NewStatusError(int64(resp.StatusCode)), retryableStatus(resp.StatusCode))
+	}
+	if resp.StatusCode == http.StatusOK || st.validator == "" {
+		// 完整的内容，文件可能已经变化，需要使用新的 validator
+		if err = st.setValidator(rangeValidator(resp.Header)); err != nil {
+			return err
+		}
+	}
+	_, err = io.Copy(f, resp.Body)
+	return err
+}
+
+func truncate(f *os.File) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	_, err := f.Seek(0, io.SeekStart)
+	return err
+}
+
+// rangeValidator 用于 If-Range 的值：强 ETag，或者 Last-Modified
+func rangeValidator(h http.Header) string {
+	if v := h.Get("ETag"); v != "" && !strings.HasPrefix(v, "W/") {
+		return v
+	}
+	return h.Get("Last-Modified")
+}
+
+// parseContentRange 解析 "bytes 100-199/1000" 和 "bytes */1000"，total 未知（"*"）时为 -1
+func parseContentRange(value string) (start int64, total int64, ok bool) {
+	rest, found := strings.CutPrefix(value, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	rng, size, found := strings.Cut(rest, "/")
+	if !found {
+		return 0, 0, false
+	}
+	total = -1
+	if size != "*" {
+		n, err := strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		total = n
+	}
+	if rng == "*" {
+		return 0, total, true
+	}
+	first, _, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, total, true
+}
+
+func parseChecksum(checksum string) (func() hash.Hash, string, error) {
+	if checksum == "" {
+		return nil, "", nil
+	}
+	algo, sum, found := strings.Cut(checksum, ":")
+	if !found || sum == "" {
+		return nil, "", fmt.Errorf("xhttpc: invalid checksum %q", checksum)
+	}
+	var newHash func() hash.Hash
+	switch strings.ToLower(algo) {
+	case "md5":
+		newHash = md5.New
+	case "sha1":
+		newHash = sha1.New
+	case "sha256":
+		newHash = sha256.New
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return nil, "", fmt.Errorf("xhttpc: unsupported checksum algorithm %q", algo)
+	}
+	return newHash, strings.ToLower(sum), nil
+}
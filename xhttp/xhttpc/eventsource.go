@@ -0,0 +1,188 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttpc
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/xanygo/anygo/xerror"
+	"github.com/xanygo/anygo/xhttp/sse"
+	"github.com/xanygo/anygo/xnet/xrpc"
+	"github.com/xanygo/anygo/xnet/xservice"
+)
+
+// EventSource Server-Sent Events 客户端，连接断开时会自动重连，
+// 重连时会携带请求头 Last-Event-ID，重连的间隔可以被服务端的 retry 字段修改。
+//
+// 如读取 LLM 的流式接口：
+//
+//	es := &xhttpc.EventSource{
+//		NewRequest: func(ctx context.Context) (*http.Request, error) {
+//			return http.NewRequestWithContext(ctx, http.MethodPost, api, bytes.NewReader(body))
+//		},
+//		Opts: []xrpc.Option{xrpc.OptReadTimeout(5 * time.Minute)},
+//	}
+//	for ev, err := range es.Events(ctx) {
+//		...
+//	}
+//
+// 注意：读取响应的总时长受 ReadTimeout 限制，对于长连接，需要设置足够长的 ReadTimeout，超时后会自动重连
+type EventSource struct {
+	// Service 可选，当为空时，会使用 Dummy
+	Service any
+
+	// URL 使用 GET 请求的地址，和 NewRequest 二选一
+	URL string
+
+	// NewRequest 可选，创建请求，每次连接（包括重连）都会调用，优先级高于 URL
+	NewRequest func(ctx context.Context) (*http.Request, error)
+
+	// Opts 可选，额外的 RPC Client 参数
+	Opts []xrpc.Option
+
+	// Retry 可选，重连的间隔，默认为 3 秒，服务端发送的 retry 字段会覆盖此值
+	Retry time.Duration
+
+	// MaxRetries 可选，没有读取到新的事件时，连续重连的最大次数，默认为 3，为负数时不重连
+	MaxRetries int
+
+	// ReconnectOnEOF 服务端正常关闭连接后，是否重连（浏览器 EventSource 的行为）。
+	// 默认为 false，适用于 LLM 等在服务端发送完成后即关闭连接的流式接口
+	ReconnectOnEOF bool
+}
+
+func (es *EventSource) getService() any {
+	if es.Service == nil {
+		return xservice.GetDummyService()
+	}
+	return es.Service
+}
+
+func (es *EventSource) getMaxRetries() int {
+	if es.MaxRetries == 0 {
+		return 3
+	}
+	return es.MaxRetries
+}
+
+// ErrEventSourceClosed 服务端返回 204 状态码，表示不要再重连
+var ErrEventSourceClosed = errors.New("eventsource closed by server")
+
+func (es *EventSource) newRequest(ctx context.Context, lastID string) (*http.Request, error) {
+	var req *http.Request
+	var err error
+	if es.NewRequest != nil {
+		req, err = es.NewRequest(ctx)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, es.URL, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	return req, nil
+}
+
+// Events 连接并以迭代器的方式读取事件（不包括只有注释的事件，如心跳）。
+//
+// 读取失败并且不再重连时，会返回一次 error，之后迭代结束；服务端正常关闭连接并且不重连时，迭代直接结束
+func (es *EventSource) Events(ctx context.Context) iter.Seq2[sse.Event, error] {
+	return func(yield func(sse.Event, error) bool) {
+		retry := es.Retry
+		if retry <= 0 {
+			retry = 3 * time.Second
+		}
+		var lastID string
+		var failures int
+		for {
+			var stopped bool
+			var received bool
+			// 开始读取事件后的错误不返回给 xrpc，避免其使用原始的请求（没有 Last-Event-ID）重试
+			var streamErr error
+			handler := func(ctx context.Context, resp *http.Response) error {
+				defer func() {
+					if !stopped {
+						_ = resp.Body.Close()
+					}
+				}()
+				if resp.StatusCode == http.StatusNoContent {
+					streamErr = ErrEventSourceClosed
+					return nil
+				}
+				if resp.StatusCode != http.StatusOK {
+					return xerror.NewStatusError(int64(resp.StatusCode))
+				}
+				if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+					return errors.New("invalid Content-Type: " + ct)
+				}
+				rd := bufio.NewReader(resp.Body)
+				for {
+					ev, err := sse.ReadEvent(rd)
+					if err != nil {
+						streamErr = err
+						return nil
+					}
+					if ev.Retry > 0 {
+						retry = time.Duration(ev.Retry) * time.Millisecond
+					}
+					if ev.ID != "" {
+						lastID = ev.ID
+					}
+					if ev.ID == "" && ev.Event == "" && ev.Data == "" {
+						continue
+					}
+					received = true
+					if !yield(ev, nil) {
+						stopped = true
+						return errAbortBody
+					}
+				}
+			}
+			req, err := es.newRequest(ctx, lastID)
+			if err == nil {
+				err = Invoke(ctx, es.getService(), req, handler, es.Opts...)
+			}
+			if err == nil {
+				err = streamErr
+			}
+			if stopped {
+				return
+			}
+			if errors.Is(err, io.EOF) && !es.ReconnectOnEOF {
+				return
+			}
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			if received {
+				failures = 0
+			} else if err != nil {
+				failures++
+			}
+			if err != nil && (errors.Is(err, ErrEventSourceClosed) || ctx.Err() != nil ||
+				es.getMaxRetries() < 0 || failures > es.getMaxRetries()) {
+				yield(sse.Event{}, err)
+				return
+			}
+			select {
+			case <-ctx.Done():
+				yield(sse.Event{}, ctx.Err())
+				return
+			case <-time.After(retry):
+			}
+		}
+	}
+}
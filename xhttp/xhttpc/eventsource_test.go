@@ -0,0 +1,94 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttpc_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/xhttp/sse"
+	"github.com/xanygo/anygo/xhttp/xhttpc"
+	"github.com/xanygo/anygo/xnet/xservice"
+	"github.com/xanygo/anygo/xt"
+)
+
+func TestEventSource(t *testing.T) {
+	var connects atomic.Int32
+	var lastIDs []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := connects.Add(1)
+		lastIDs = append(lastIDs, r.Header.Get("Last-Event-ID"))
+		if n == 3 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = sse.Event{Retry: 10, Comment: "hello"}.WriteTo(w)
+		for i := 1; i <= 2; i++ {
+			id := strconv.Itoa(int(n)*10 + i)
+			_, _ = sse.Event{ID: id, Data: "data-" + id}.WriteTo(w)
+		}
+	}))
+	defer ts.Close()
+
+	t.Run("reconnect", func(t *testing.T) {
+		es := &xhttpc.EventSource{
+			Service:        xservice.Dummy,
+			URL:            ts.URL,
+			ReconnectOnEOF: true,
+		}
+		var got []string
+		var lastErr error
+		for ev, err := range es.Events(context.Background()) {
+			if err != nil {
+				lastErr = err
+				break
+			}
+			got = append(got, ev.Data)
+		}
+		xt.ErrorIs(t, lastErr, xhttpc.ErrEventSourceClosed)
+		xt.Equal(t, got, []string{"data-11", "data-12", "data-21", "data-22"})
+		xt.Equal(t, lastIDs, []string{"", "12", "22"})
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		connects.Store(0)
+		es := &xhttpc.EventSource{URL: ts.URL}
+		var got []string
+		for ev, err := range es.Events(context.Background()) {
+			xt.NoError(t, err)
+			got = append(got, ev.ID)
+		}
+		xt.Equal(t, got, []string{"11", "12"})
+		xt.Equal(t, connects.Load(), int32(1))
+	})
+}
+
+func TestEventSource_broker(t *testing.T) {
+	b := &sse.Broker{}
+	ts := httptest.NewServer(b)
+	defer ts.Close()
+	defer b.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	go func() {
+		for b.ClientCount("") == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		_, _ = b.Publish(ctx, "", sse.Event{Data: "hello"})
+	}()
+	es := &xhttpc.EventSource{URL: ts.URL}
+	for ev, err := range es.Events(ctx) {
+		xt.NoError(t, err)
+		xt.Equal(t, ev.Data, "hello")
+		break
+	}
+}
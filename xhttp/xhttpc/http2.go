@@ -0,0 +1,97 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xoption"
+	"github.com/xanygo/anygo/xhttp/xhttp2"
+	"github.com/xanygo/anygo/xio"
+	"github.com/xanygo/anygo/xnet/xrpc"
+)
+
+// sendHTTP2 通过 HTTP/2 连接发送请求，Body 会在后台继续发送
+func sendHTTP2(ctx context.Context, hc *xhttp2.Conn, req *http.Request, opt xoption.Reader) error {
+	ctx, cancel := context.WithTimeout(ctx, xoption.WriteTimeout(opt))
+	defer cancel()
+	if err := hc.Send(ctx, req); err != nil {
+		return fmt.Errorf("http2.Send: %w", err)
+	}
+	return nil
+}
+
+// loadHTTP2 读取 HTTP/2 连接的响应，读超时时间包含 Handler 读取 Body 的时间，和 HTTP/1.x 的读超时一致
+func (resp *Response) loadHTTP2(ctx context.Context, req xrpc.Request, hc *xhttp2.Conn, opt xoption.Reader) error {
+	if resp.Stream {
+		return resp.loadHTTP2Stream(ctx, req, hc, opt)
+	}
+	ctx, cancel := context.WithTimeout(ctx, xoption.ReadTimeout(opt))
+	defer cancel()
+
+	rr, err := hc.Response(ctx)
+	if err != nil {
+		return fmt.Errorf("http2.Response %w", err)
+	}
+	rr.Body = xio.LimitReaderCloser(rr.Body, xoption.MaxResponseSize(opt))
+	resp.decompress(rr)
+	resp.resp = rr
+	if skip, err := resp.intercept(req, rr, opt); skip || err != nil {
+		_ = rr.Body.Close()
+		return err
+	}
+	err = resp.Handler(ctx, rr)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, errAbortBody) {
+		// 关闭 Body 会取消流（RST_STREAM），连接可以继续被其他请求使用
+		_ = rr.Body.Close()
+		return nil
+	}
+	return fmt.Errorf("resp.Handler %w", err)
+}
+
+// loadHTTP2Stream 流式读取 HTTP/2 的响应，流的生命周期和 Body 一致，每次读取的超时时间为 ReadTimeout
+func (resp *Response) loadHTTP2Stream(ctx context.Context, req xrpc.Request, hc *xhttp2.Conn, opt xoption.Reader) error {
+	timeout := xoption.ReadTimeout(opt)
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	timer := time.AfterFunc(timeout, cancel)
+	rr, err := hc.Response(ctx)
+	timer.Stop()
+	if err != nil {
+		cancel()
+		return fmt.Errorf("http2.Response %w", err)
+	}
+	rr.Body = xio.LimitReaderCloser(rr.Body, xoption.MaxResponseSize(opt))
+	resp.decompress(rr)
+	resp.resp = rr
+	if skip, err := resp.intercept(req, rr, opt); skip || err != nil {
+		_ = rr.Body.Close()
+		cancel()
+		return err
+	}
+	sb := &streamBody{
+		timeout: timeout,
+		reuse:   true,
+		setDeadline: func(t time.Time) {
+			if t.IsZero() {
+				timer.Stop()
+			} else {
+				timer.Reset(time.Until(t))
+			}
+		},
+		abort:   cancel,
+		release: cancel,
+	}
+	if err = resp.handleStream(ctx, rr, hc, sb); err != nil {
+		return fmt.Errorf("resp.Handler %w", err)
+	}
+	return nil
+}
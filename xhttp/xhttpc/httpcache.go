@@ -0,0 +1,516 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xhash"
+	"github.com/xanygo/anygo/safely"
+	"github.com/xanygo/anygo/store/xcache"
+	"github.com/xanygo/anygo/xnet/xrpc"
+	"github.com/xanygo/anygo/xnet/xservice"
+)
+
+var _ Invoker = (*HTTPCache)(nil)
+
+// HTTPCache 遵循 HTTP 缓存语义（RFC 9111）的缓存层，实现了 Invoker，可以直接使用，也可以设置为 Client.Cache。
+//
+// 和 CachedClient 使用固定的缓存时间不同，HTTPCache 依据响应的 Cache-Control、Expires 等 Header 决定是否缓存以及缓存多久：
+//  1. 只缓存 GET 请求的响应，POST、PUT、DELETE 等请求成功后，会删除此地址的缓存
+//  2. 支持 max-age、s-maxage、no-store、no-cache、private、public、must-revalidate 等指令，
+//     以及 RFC 5861 的 stale-while-revalidate 和 stale-if-error
+//  3. 缓存过期后，若有 ETag 或 Last-Modified，会使用 If-None-Match、If-Modified-Since 重新验证，
+//     收到 304 响应时，更新缓存并使用缓存的内容
+//  4. 依据响应的 Vary Header，为请求的不同 Header 值分别缓存
+//
+// 缓存命中时，handler 收到的响应会带上 Age Header。
+type HTTPCache struct {
+	// Cache 必填，用于存储响应，可以是任意的 xcache.Cache 实现，如内存、文件、Redis 缓存
+	Cache xcache.Cache[string, *StoredResponse]
+
+	// Shared 可选，是否为共享缓存（如网关、代理服务），为 true 时，
+	// 不缓存 Cache-Control: private 的响应，优先使用 s-maxage，
+	// 并且请求有 Authorization Header 时，只缓存明确允许的响应（public、s-maxage、must-revalidate）
+	Shared bool
+
+	// MaxTTL 可选，响应在 Cache 中保存的最长时间，默认为 24 小时。
+	// 有 ETag 或 Last-Modified 的响应，过期后仍会保存到 MaxTTL，以便发送条件请求重新验证
+	MaxTTL time.Duration
+
+	// KeyPrefix 可选，缓存 key 的前缀
+	KeyPrefix string
+
+	// Invoker 可选，用于发送请求，默认为 Invoke
+	Invoker Invoker
+}
+
+func (c *HTTPCache) getMaxTTL() time.Duration {
+	if c.MaxTTL > 0 {
+		return c.MaxTTL
+	}
+	return 24 * time.Hour
+}
+
+func (c *HTTPCache) getInvoker() Invoker {
+	if c.Invoker == nil {
+		return InvokeFunc(Invoke)
+	}
+	return c.Invoker
+}
+
+func (c *HTTPCache) Invoke(ctx context.Context, service any, req *http.Request, handler HandlerFunc, opts ...xrpc.Option) error {
+	key := c.cacheKey(service, req)
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return c.getInvoker().Invoke(ctx, service, req, handler, opts...)
+	default:
+		return c.invalidate(ctx, service, req, key, handler, opts)
+	}
+
+	reqCC := requestCacheControl(req.Header)
+	if reqCC.has("no-store") || isConditional(req.Header) {
+		return c.getInvoker().Invoke(ctx, service, req, handler, opts...)
+	}
+
+	stored := c.load(ctx, key, req)
+	if stored != nil {
+		f := newFreshness(stored, c.Shared, time.Now())
+		if f.usable(reqCC) {
+			return handler(ctx, stored.httpResponse(req, f.age))
+		}
+		if f.staleWhileRevalidate(reqCC) {
+			c.revalidateAsync(ctx, service, req, key, stored, opts)
+			return handler(ctx, stored.httpResponse(req, f.age))
+		}
+	}
+	if reqCC.has("only-if-cached") {
+		sr := &StoredResponse{StatusCode: http.StatusGatewayTimeout}
+		return handler(ctx, sr.httpResponse(req, 0))
+	}
+	return c.fetch(ctx, service, req, key, stored, reqCC, handler, opts)
+}
+
+// DeleteCache 删除此请求地址的缓存
+func (c *HTTPCache) DeleteCache(ctx context.Context, service any, req *http.Request) error {
+	return c.Cache.Delete(ctx, c.cacheKey(service, req))
+}
+
+// invalidate 发送不安全的请求（如 POST），若响应成功，删除此地址的缓存（RFC 9111 4.4）
+func (c *HTTPCache) invalidate(ctx context.Context, service any, req *http.Request, key string, handler HandlerFunc, opts []xrpc.Option) error {
+	var code int
+	err := c.getInvoker().Invoke(ctx, service, req, func(ctx context.Context, resp *http.Response) error {
+		code = resp.StatusCode
+		return handler(ctx, resp)
+	}, opts...)
+	if code >= 200 && code < 400 {
+		_ = c.Cache.Delete(ctx, key)
+	}
+	return err
+}
+
+// fetch 发送请求，若有缓存（已过期），会发送条件请求进行验证
+func (c *HTTPCache) fetch(ctx context.Context, service any, req *http.Request, key string, stored *StoredResponse,
+	reqCC cacheControl, handler HandlerFunc, opts []xrpc.Option) error {
+	sent := req
+	if stored != nil {
+		sent = conditionalRequest(ctx, req, stored.Header)
+	}
+	var handled bool
+	start := time.Now()
+	err := c.getInvoker().Invoke(ctx, service, sent, func(ctx context.Context, resp *http.Response) error {
+		handled = true
+		if stored != nil {
+			if resp.StatusCode == http.StatusNotModified {
+				drainBody(resp)
+				sr := stored.refresh(resp.Header, start)
+				c.store(ctx, key, req, sr)
+				return handler(ctx, sr.httpResponse(req, newFreshness(sr, c.Shared, time.Now()).age))
+			}
+			if retryableStatus(resp.StatusCode) {
+				if f := newFreshness(stored, c.Shared, time.Now()); f.staleIfError(reqCC) {
+					drainBody(resp)
+					return handler(ctx, stored.httpResponse(req, f.age))
+				}
+			}
+		}
+		if !c.storable(req, resp) {
+			return handler(ctx, resp)
+		}
+		sr := &StoredResponse{
+			CreateAt: start.Unix(),
+		}
+		if err := TeeReader(sr)(ctx, resp); err != nil {
+			return err
+		}
+		sr.Cost = time.Since(start)
+		c.store(ctx, key, req, sr)
+		return handler(ctx, resp)
+	}, opts...)
+	if err != nil && !handled && stored != nil {
+		// 网络错误等未收到响应的情况
+		if f := newFreshness(stored, c.Shared, time.Now()); f.staleIfError(reqCC) {
+			return handler(ctx, stored.httpResponse(req, f.age))
+		}
+	}
+	return err
+}
+
+var revalidating sync.Map
+
+// revalidateAsync 异步更新缓存（stale-while-revalidate），同一个缓存同时只会有一个更新的请求
+func (c *HTTPCache) revalidateAsync(ctx context.Context, service any, req *http.Request, key string, stored *StoredResponse, opts []xrpc.Option) {
+	if _, loaded := revalidating.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	req = req.Clone(ctx)
+	go safely.RunVoid(func() {
+		defer revalidating.Delete(key)
+		handler := func(ctx context.Context, resp *http.Response) error {
+			drainBody(resp)
+			return nil
+		}
+		_ = c.fetch(ctx, service, req, key, stored, nil, handler, opts)
+	})
+}
+
+func (c *HTTPCache) cacheKey(service any, req *http.Request) string {
+	var name string
+	switch sv := service.(type) {
+	case string:
+		name = sv
+	case xservice.Service:
+		name = sv.Name()
+	}
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	return c.KeyPrefix + "httpcache|" + xhash.Md5(name+"|"+host+"|"+req.URL.String())
+}
+
+// load 读取缓存，若缓存的是 Vary 索引，则依据请求的 Header 读取对应的响应
+func (c *HTTPCache) load(ctx context.Context, key string, req *http.Request) *StoredResponse {
+	sr, err := c.Cache.Get(ctx, key)
+	if err != nil || sr == nil {
+		return nil
+	}
+	if sr.StatusCode != 0 {
+		return sr
+	}
+	sr, err = c.Cache.Get(ctx, varyKey(key, varyNames(sr.Header), req.Header))
+	if err != nil || sr == nil || sr.StatusCode == 0 {
+		return nil
+	}
+	return sr
+}
+
+// storable 响应是否可以存储（RFC 9111 3）
+func (c *HTTPCache) storable(req *http.Request, resp *http.Response) bool {
+	cc := parseCacheControl(resp.Header)
+	switch {
+	case cc.has("no-store"),
+		c.Shared && cc.has("private"),
+		c.Shared && req.Header.Get("Authorization") != "" &&
+			!cc.has("public") && !cc.has("s-maxage") && !cc.has("must-revalidate"),
+		slices.Contains(varyNames(resp.Header), "*"):
+		return false
+	}
+	if cc.has("max-age") || cc.has("public") || resp.Header.Get("Expires") != "" || (c.Shared && cc.has("s-maxage")) {
+		return resp.StatusCode != http.StatusPartialContent && resp.StatusCode != http.StatusNotModified
+	}
+	return heuristicStatus[resp.StatusCode]
+}
+
+// store 存储响应，有 Vary 时，在 key 上存储 Vary 索引，响应存储在 varyKey 上
+func (c *HTTPCache) store(ctx context.Context, key string, req *http.Request, sr *StoredResponse) {
+	f := newFreshness(sr, c.Shared, time.Now())
+	ttl := f.lifetime - f.age
+	if v, ok := f.cc.seconds("stale-while-revalidate"); ok {
+		ttl = max(ttl, f.lifetime-f.age+v)
+	}
+	if v, ok := f.cc.seconds("stale-if-error"); ok {
+		ttl = max(ttl, f.lifetime-f.age+v)
+	}
+	if hasValidator(sr.Header) {
+		ttl = c.getMaxTTL()
+	}
+	ttl = min(ttl, c.getMaxTTL())
+	if ttl <= 0 {
+		return
+	}
+	sr.FromCache = true
+	names := varyNames(sr.Header)
+	if len(names) == 0 {
+		_ = c.Cache.Set(ctx, key, sr, ttl)
+		return
+	}
+	index := &StoredResponse{
+		CreateAt: sr.CreateAt,
+		Header:   http.Header{"Vary": names},
+	}
+	_ = c.Cache.Set(ctx, key, index, c.getMaxTTL())
+	_ = c.Cache.Set(ctx, varyKey(key, names, req.Header), sr, ttl)
+}
+
+// heuristicStatus 可以使用启发式新鲜期的状态码（RFC 9110 15.1）
+var heuristicStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusPermanentRedirect:    true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// freshness 缓存的响应的新鲜度（RFC 9111 4.2）
+type freshness struct {
+	age      time.Duration // 当前的 Age
+	lifetime time.Duration // 新鲜期
+	cc       cacheControl  // 响应的 Cache-Control
+	shared   bool
+}
+
+func newFreshness(sr *StoredResponse, shared bool, now time.Time) freshness {
+	h := sr.Header
+	responseTime := sr.CreateTime()
+	date, err := http.ParseTime(h.Get("Date"))
+	if err != nil {
+		date = responseTime
+	}
+	age := max(responseTime.Sub(date), 0)
+	if v, err := strconv.ParseInt(h.Get("Age"), 10, 64); err == nil && v > 0 {
+		age = max(age, time.Duration(v)*time.Second)
+	}
+	f := freshness{
+		age:    age + max(now.Sub(responseTime), 0),
+		cc:     parseCacheControl(h),
+		shared: shared,
+	}
+	if v, ok := f.cc.seconds("s-maxage"); ok && shared {
+		f.lifetime = v
+	} else if v, ok = f.cc.seconds("max-age"); ok {
+		f.lifetime = v
+	} else if ev := h.Get("Expires"); ev != "" {
+		// 无效的 Expires（如 "0"）表示已过期
+		if exp, err := http.ParseTime(ev); err == nil {
+			f.lifetime = exp.Sub(date)
+		}
+	} else if lm, err := http.ParseTime(h.Get("Last-Modified")); err == nil && heuristicStatus[sr.StatusCode] {
+		// 启发式新鲜期：Last-Modified 至今的 10%
+		f.lifetime = max(date.Sub(lm)/10, 0)
+	}
+	return f
+}
+
+func (f freshness) staleness() time.Duration {
+	return f.age - f.lifetime
+}
+
+func (f freshness) mustRevalidate() bool {
+	return f.cc.has("must-revalidate") || (f.shared && f.cc.has("proxy-revalidate"))
+}
+
+// usable 是否可以直接使用缓存而不用验证：缓存是新鲜的，或者请求使用 max-stale 允许使用过期的缓存
+func (f freshness) usable(reqCC cacheControl) bool {
+	if f.cc.has("no-cache") || reqCC.has("no-cache") {
+		return false
+	}
+	if v, ok := reqCC.seconds("max-age"); ok && f.age > v {
+		return false
+	}
+	remain := f.lifetime - f.age
+	if v, ok := reqCC.seconds("min-fresh"); ok {
+		remain -= v
+	}
+	if remain > 0 {
+		return true
+	}
+	if f.mustRevalidate() || !reqCC.has("max-stale") {
+		return false
+	}
+	v, ok := reqCC.seconds("max-stale")
+	// max-stale 没有值，表示可以接受任意时长的过期
+	return !ok || f.staleness() <= v
+}
+
+// staleWhileRevalidate 是否可以先使用过期的缓存，同时异步更新（RFC 5861 3）
+func (f freshness) staleWhileRevalidate(reqCC cacheControl) bool {
+	if f.cc.has("no-cache") || f.mustRevalidate() || reqCC.has("no-cache") || reqCC.has("max-age") {
+		return false
+	}
+	v, ok := f.cc.seconds("stale-while-revalidate")
+	return ok && f.staleness() <= v
+}
+
+// staleIfError 请求失败（网络错误或者 500、502、503、504）时，是否可以使用过期的缓存（RFC 5861 4），
+// 请求和响应的 Cache-Control 都可以有 stale-if-error
+func (f freshness) staleIfError(reqCC cacheControl) bool {
+	if f.cc.has("no-cache") || f.mustRevalidate() {
+		return false
+	}
+	v1, ok1 := f.cc.seconds("stale-if-error")
+	v2, ok2 := reqCC.seconds("stale-if-error")
+	if !ok1 && !ok2 {
+		return false
+	}
+	return f.staleness() <= max(v1, v2)
+}
+
+// cacheControl 解析后的 Cache-Control，key 为小写的指令名
+type cacheControl map[string]string
+
+func parseCacheControl(h http.Header) cacheControl {
+	cc := cacheControl{}
+	for _, line := range h.Values("Cache-Control") {
+		for part := range strings.SplitSeq(line, ",") {
+			k, v, _ := strings.Cut(part, "=")
+			k = strings.ToLower(strings.TrimSpace(k))
+			if k != "" {
+				cc[k] = strings.Trim(strings.TrimSpace(v), `"`)
+			}
+		}
+	}
+	return cc
+}
+
+// requestCacheControl 解析请求的 Cache-Control，没有 Cache-Control 时，Pragma: no-cache 等同于 no-cache
+func requestCacheControl(h http.Header) cacheControl {
+	cc := parseCacheControl(h)
+	if len(h.Values("Cache-Control")) == 0 && strings.EqualFold(h.Get("Pragma"), "no-cache") {
+		cc["no-cache"] = ""
+	}
+	return cc
+}
+
+func (cc cacheControl) has(name string) bool {
+	_, ok := cc[name]
+	return ok
+}
+
+// seconds 读取 delta-seconds 类型的指令值，如 max-age=60
+func (cc cacheControl) seconds(name string) (time.Duration, bool) {
+	v, ok := cc[name]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// varyNames 读取 Vary 中的 Header 名称，已规范化、去重和排序
+func varyNames(h http.Header) []string {
+	var names []string
+	for _, line := range h.Values("Vary") {
+		for name := range strings.SplitSeq(line, ",") {
+			name = strings.TrimSpace(name)
+			if name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+func varyKey(key string, names []string, h http.Header) string {
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteString(":")
+		b.WriteString(strings.Join(h.Values(name), ","))
+		b.WriteString("\n")
+	}
+	return key + "|" + xhash.Md5(b.String())
+}
+
+func hasValidator(h http.Header) bool {
+	return h.Get("ETag") != "" || h.Get("Last-Modified") != ""
+}
+
+// isConditional 请求是否已有条件 Header（由调用方自己处理），这类请求不使用缓存
+func isConditional(h http.Header) bool {
+	for _, name := range []string{"If-None-Match", "If-Modified-Since", "If-Match", "If-Unmodified-Since", "If-Range", "Range"} {
+		if h.Get(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionalRequest 使用缓存的 ETag 和 Last-Modified 创建验证缓存的请求（RFC 9111 4.3.1）
+func conditionalRequest(ctx context.Context, req *http.Request, stored http.Header) *http.Request {
+	if !hasValidator(stored) {
+		return req
+	}
+	next := req.Clone(ctx)
+	if v := stored.Get("ETag"); v != "" {
+		next.Header.Set("If-None-Match", v)
+	}
+	if v := stored.Get("Last-Modified"); v != "" {
+		next.Header.Set("If-Modified-Since", v)
+	}
+	return next
+}
+
+// refresh 使用 304 响应的 Header 更新缓存的响应（RFC 9111 4.3.4）
+func (sr *StoredResponse) refresh(h http.Header, created time.Time) *StoredResponse {
+	nsr := *sr
+	nsr.Header = sr.Header.Clone()
+	nsr.Header.Del("Age")
+	for k, vs := range h {
+		switch k {
+		case "Content-Length", "Content-Encoding", "Transfer-Encoding", "Content-Range":
+			continue
+		}
+		nsr.Header[k] = vs
+	}
+	nsr.CreateAt = created.Unix()
+	return &nsr
+}
+
+// httpResponse 使用缓存的响应创建 http.Response
+func (sr *StoredResponse) httpResponse(req *http.Request, age time.Duration) *http.Response {
+	h := sr.Header.Clone()
+	if h == nil {
+		h = http.Header{}
+	}
+	h.Set("Age", strconv.FormatInt(int64(age/time.Second), 10))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", sr.StatusCode, http.StatusText(sr.StatusCode)),
+		StatusCode:    sr.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          io.NopCloser(bytes.NewReader(sr.Body)),
+		ContentLength: int64(len(sr.Body)),
+		Request:       req,
+	}
+}
+
+func drainBody(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
@@ -0,0 +1,220 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttpc_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/store/xcache"
+	"github.com/xanygo/anygo/xhttp/xhttpc"
+	"github.com/xanygo/anygo/xnet/xservice"
+	"github.com/xanygo/anygo/xt"
+)
+
+func TestHTTPCache(t *testing.T) {
+	xservice.DefaultRegistry().Register(xservice.DefaultDummyService())
+
+	var hits atomic.Int64
+	var failing atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/max-age", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		fmt.Fprintf(w, "v%d", hits.Add(1))
+	})
+	mux.HandleFunc("/no-store", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store, max-age=60")
+		fmt.Fprintf(w, "v%d", hits.Add(1))
+	})
+	mux.HandleFunc("/private", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "private, max-age=60")
+		fmt.Fprintf(w, "v%d", hits.Add(1))
+	})
+	mux.HandleFunc("/etag", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("ETag", `"e1"`)
+		w.Header().Set("X-Hits", fmt.Sprint(hits.Load()))
+		if r.Header.Get("If-None-Match") == `"e1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, "etag body")
+	})
+	mux.HandleFunc("/vary", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Language")
+		fmt.Fprintf(w, "%s-%d", r.Header.Get("Accept-Language"), hits.Add(1))
+	})
+	mux.HandleFunc("/stale-if-error", func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Cache-Control", "max-age=0, stale-if-error=60")
+		fmt.Fprintf(w, "v%d", hits.Add(1))
+	})
+	mux.HandleFunc("/swr", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=0, stale-while-revalidate=60")
+		fmt.Fprintf(w, "v%d", hits.Add(1))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	newCache := func(shared bool) *xhttpc.HTTPCache {
+		return &xhttpc.HTTPCache{
+			Cache:  xcache.NewLRU[string, *xhttpc.StoredResponse](100),
+			Shared: shared,
+		}
+	}
+	get := func(t *testing.T, hc *xhttpc.HTTPCache, path string, header ...string) (*http.Response, string) {
+		t.Helper()
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, ts.URL+path, nil)
+		xt.NoError(t, err)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		resp := &http.Response{}
+		err = hc.Invoke(t.Context(), xservice.Dummy, req, xhttpc.FetchResponse(resp))
+		xt.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	t.Run("max-age", func(t *testing.T) {
+		hits.Store(0)
+		hc := newCache(false)
+		resp, body := get(t, hc, "/max-age")
+		xt.Equal(t, body, "v1")
+		xt.Empty(t, resp.Header.Get("Age"))
+
+		resp, body = get(t, hc, "/max-age")
+		xt.Equal(t, body, "v1")
+		xt.Equal(t, resp.StatusCode, http.StatusOK)
+		xt.Equal(t, resp.Header.Get("Age"), "0")
+		xt.Equal(t, hits.Load(), int64(1))
+
+		_, body = get(t, hc, "/max-age", "Cache-Control", "no-cache")
+		xt.Equal(t, body, "v2")
+		_, body = get(t, hc, "/max-age", "Cache-Control", "no-store")
+		xt.Equal(t, body, "v3")
+		_, body = get(t, hc, "/max-age")
+		xt.Equal(t, body, "v2")
+	})
+
+	t.Run("no-store", func(t *testing.T) {
+		hits.Store(0)
+		hc := newCache(false)
+		_, body := get(t, hc, "/no-store")
+		xt.Equal(t, body, "v1")
+		_, body = get(t, hc, "/no-store")
+		xt.Equal(t, body, "v2")
+	})
+
+	t.Run("private", func(t *testing.T) {
+		hits.Store(0)
+		hc := newCache(false)
+		get(t, hc, "/private")
+		_, body := get(t, hc, "/private")
+		xt.Equal(t, body, "v1")
+
+		shared := newCache(true)
+		get(t, shared, "/private")
+		_, body = get(t, shared, "/private")
+		xt.Equal(t, body, "v3")
+	})
+
+	t.Run("revalidate", func(t *testing.T) {
+		hits.Store(0)
+		hc := newCache(false)
+		resp, body := get(t, hc, "/etag")
+		xt.Equal(t, body, "etag body")
+		xt.Equal(t, resp.Header.Get("X-Hits"), "1")
+
+		resp, body = get(t, hc, "/etag")
+		xt.Equal(t, resp.StatusCode, http.StatusOK)
+		xt.Equal(t, body, "etag body")
+		// 使用 304 响应的 Header 更新了缓存
+		xt.Equal(t, resp.Header.Get("X-Hits"), "2")
+		xt.Equal(t, hits.Load(), int64(2))
+
+		// 调用方自己发送的条件请求，不使用缓存
+		resp, _ = get(t, hc, "/etag", "If-None-Match", `"e1"`)
+		xt.Equal(t, resp.StatusCode, http.StatusNotModified)
+	})
+
+	t.Run("vary", func(t *testing.T) {
+		hits.Store(0)
+		hc := newCache(false)
+		_, body := get(t, hc, "/vary", "Accept-Language", "en")
+		xt.Equal(t, body, "en-1")
+		_, body = get(t, hc, "/vary", "Accept-Language", "zh")
+		xt.Equal(t, body, "zh-2")
+		_, body = get(t, hc, "/vary", "Accept-Language", "en")
+		xt.Equal(t, body, "en-1")
+		_, body = get(t, hc, "/vary", "Accept-Language", "zh")
+		xt.Equal(t, body, "zh-2")
+		xt.Equal(t, hits.Load(), int64(2))
+	})
+
+	t.Run("stale-if-error", func(t *testing.T) {
+		hits.Store(0)
+		hc := newCache(false)
+		_, body := get(t, hc, "/stale-if-error")
+		xt.Equal(t, body, "v1")
+		_, body = get(t, hc, "/stale-if-error")
+		xt.Equal(t, body, "v2")
+
+		failing.Store(true)
+		defer failing.Store(false)
+		resp, body := get(t, hc, "/stale-if-error")
+		xt.Equal(t, resp.StatusCode, http.StatusOK)
+		xt.Equal(t, body, "v2")
+	})
+
+	t.Run("stale-while-revalidate", func(t *testing.T) {
+		hits.Store(0)
+		hc := newCache(false)
+		_, body := get(t, hc, "/swr")
+		xt.Equal(t, body, "v1")
+		_, body = get(t, hc, "/swr")
+		xt.Equal(t, body, "v1")
+		for i := 0; i < 100 && hits.Load() < 2; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		xt.Equal(t, hits.Load(), int64(2))
+	})
+
+	t.Run("only-if-cached", func(t *testing.T) {
+		hc := newCache(false)
+		resp, _ := get(t, hc, "/max-age", "Cache-Control", "only-if-cached")
+		xt.Equal(t, resp.StatusCode, http.StatusGatewayTimeout)
+	})
+
+	t.Run("invalidate", func(t *testing.T) {
+		hits.Store(0)
+		hc := newCache(false)
+		cli := &xhttpc.Client{Cache: hc}
+		resp, err := cli.Get(t.Context(), ts.URL+"/max-age")
+		xt.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		xt.Equal(t, string(body), "v1")
+
+		_, err = cli.Post(t.Context(), ts.URL+"/max-age", "text/plain", strings.NewReader("x"))
+		xt.NoError(t, err)
+		xt.Equal(t, hits.Load(), int64(2))
+
+		resp, err = cli.Get(t.Context(), ts.URL+"/max-age")
+		xt.NoError(t, err)
+		body, _ = io.ReadAll(resp.Body)
+		xt.Equal(t, string(body), "v3")
+	})
+}
@@ -0,0 +1,64 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttpc
+
+import (
+	"net/http"
+
+	"github.com/xanygo/anygo/ds/xoption"
+	"github.com/xanygo/anygo/xnet/xrpc"
+	"github.com/xanygo/anygo/xnet/xservice"
+)
+
+var (
+	optKeyRedirect = xoption.NewKey("HTTP:Redirect")
+	optKeyAuth     = xoption.NewKey("HTTP:Auth")
+)
+
+// OptRedirect 自动跟随重定向，最多 maxHops 次，超过后返回 ErrTooManyRedirects，maxHops<=0 时不跟随。
+// 优先级高于 service 配置中的 HTTP.Redirect。
+//
+// 只对使用 Invoke 系列方法（包括 Client）发送的请求生效：
+//  1. 301、302 的 POST 请求和 303 的非 HEAD 请求，会改为 GET 请求，并且不再发送 Body
+//  2. 307、308 保持请求方法和 Body 不变，若请求有 Body，但是没有 GetBody，则不跟随
+//  3. 跳转到其他主机（不是原主机或其子域名）时，不再发送 Authorization、Cookie 等敏感 Header，也不再自动添加身份认证信息
+func OptRedirect(maxHops int) xrpc.Option {
+	return xrpc.OptOptionSetter(func(o xoption.Option) {
+		o.Set(optKeyRedirect, maxHops)
+	})
+}
+
+func optRedirect(opt xoption.Reader) int {
+	if n, ok := xoption.GetAs[int](opt, optKeyRedirect); ok {
+		return n
+	}
+	return xservice.OptHTTP(opt).Redirect
+}
+
+// OptCookieJar 设置使用的 Cookie Jar，优先级高于 service 配置中的 HTTP.CookieJar。
+//
+// 发送请求时，会添加 Jar 中的 Cookie，收到响应后，会保存响应中的 Cookie。
+// 可以使用 xcookiejar.New 创建一个可持久化的 Jar。
+func OptCookieJar(jar http.CookieJar) xrpc.Option {
+	return xrpc.OptOptionSetter(func(o xoption.Option) {
+		xservice.SetOptCookieJar(o, jar)
+	})
+}
+
+// OptAuth 设置身份认证信息，优先级高于 service 配置中的 HTTP.Auth。
+//
+// 若请求已有 Authorization Header，则不会再添加。
+func OptAuth(auth *xservice.HTTPAuthPart) xrpc.Option {
+	return xrpc.OptOptionSetter(func(o xoption.Option) {
+		o.Set(optKeyAuth, auth)
+	})
+}
+
+func optAuth(opt xoption.Reader) *xservice.HTTPAuthPart {
+	if a, ok := xoption.GetAs[*xservice.HTTPAuthPart](opt, optKeyAuth); ok {
+		return a
+	}
+	return xservice.OptHTTP(opt).Auth
+}
@@ -0,0 +1,233 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttpc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/xanygo/anygo/ds/xmeta"
+	"github.com/xanygo/anygo/ds/xoption"
+	"github.com/xanygo/anygo/xnet/xrpc"
+	"github.com/xanygo/anygo/xnet/xservice"
+)
+
+// ErrTooManyRedirects 重定向次数超过了 OptRedirect 或者 service 配置中 HTTP.Redirect 的值
+var ErrTooManyRedirects = errors.New("xhttpc: too many redirects")
+
+// metaKeyRequest 在连接上保存最近一次发送的请求，以在读取响应时使用
+var metaKeyRequest = xmeta.NewKey("xhttpc:Request")
+
+// hopState 一次 Invoke 中，自动发送的后续请求（重定向、Digest 认证）的状态
+type hopState struct {
+	hops   int  // 已跟随的重定向次数
+	noAuth bool // 已跳转到其他主机，不再自动添加身份认证信息
+	digest bool // 已发送 Digest 认证信息
+}
+
+// prepareRequest 添加 Cookie 和身份认证信息
+func prepareRequest(req *http.Request, opt xoption.Reader, https bool, st hopState) {
+	if jar := xservice.OptCookieJar(opt); jar != nil {
+		for _, c := range jar.Cookies(cookieURL(req, https)) {
+			req.AddCookie(c)
+		}
+	}
+	if st.noAuth || req.Header.Get("Authorization") != "" {
+		return
+	}
+	if v := authorization(optAuth(opt)); v != "" {
+		req.Header.Set("Authorization", v)
+	}
+}
+
+// cookieURL 用于 Cookie Jar 的地址，使用 service 发送请求时，URL 中可能没有 Host
+func cookieURL(req *http.Request, https bool) *url.URL {
+	u := *req.URL
+	if u.Host == "" {
+		u.Host = req.Host
+	}
+	if https {
+		u.Scheme = "https"
+	} else {
+		u.Scheme = "http"
+	}
+	return &u
+}
+
+func isHTTPS(req xrpc.Request, sent *http.Request) bool {
+	if r, ok := req.(*Request); ok {
+		return r.HTTPS
+	}
+	return sent.URL.Scheme == "https"
+}
+
+// intercept 在 Handler 之前处理响应：保存 Cookie，若需要发送后续的请求（重定向、Digest 认证），
+// 丢弃当前响应并返回 true
+func (resp *Response) intercept(req xrpc.Request, rr *http.Response, opt xoption.Reader) (bool, error) {
+	sent := rr.Request
+	if sent == nil {
+		return false, nil
+	}
+	https := isHTTPS(req, sent)
+	if jar := xservice.OptCookieJar(opt); jar != nil {
+		if cs := rr.Cookies(); len(cs) > 0 {
+			jar.SetCookies(cookieURL(sent, https), cs)
+		}
+	}
+	var st hopState
+	if nr, ok := req.(*NativeRequest); ok {
+		st = nr.hop
+	}
+	var next *NativeRequest
+	var err error
+	switch rr.StatusCode {
+	case http.StatusUnauthorized:
+		next, err = digestRequest(req, sent, rr, opt, st)
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		next, err = redirectRequest(req, sent, rr, opt, st, https)
+	}
+	if err != nil || next == nil {
+		return false, err
+	}
+	discardBody(rr)
+	resp.next = next
+	return true, nil
+}
+
+func redirectRequest(req xrpc.Request, sent *http.Request, rr *http.Response, opt xoption.Reader, st hopState, https bool) (*NativeRequest, error) {
+	maxHops := optRedirect(opt)
+	location := rr.Header.Get("Location")
+	if maxHops <= 0 || location == "" {
+		return nil, nil
+	}
+	if st.hops >= maxHops {
+		return nil, fmt.Errorf("%w: stopped after %d redirects", ErrTooManyRedirects, maxHops)
+	}
+	base := *sent.URL
+	if https && base.Host != "" {
+		// 如 Request 发送时，URL 的 Scheme 总是 http
+		base.Scheme = "https"
+	}
+	u, err := base.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Location %q: %w", location, err)
+	}
+
+	method := sent.Method
+	keepBody := true
+	switch rr.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound:
+		if method == http.MethodPost {
+			method = http.MethodGet
+			keepBody = false
+		}
+	case http.StatusSeeOther:
+		if method != http.MethodHead {
+			method = http.MethodGet
+		}
+		keepBody = false
+	}
+
+	next := baseRequest(sent, opt, keepBody)
+	if next == nil {
+		// 有 Body，但是不能再次读取
+		return nil, nil
+	}
+	next.Method = method
+
+	oldHost := sent.Host
+	if oldHost == "" {
+		oldHost = sent.URL.Host
+	}
+	if u.Host != "" && strings.EqualFold(u.Host, oldHost) && sent.URL.Host == "" {
+		// 跳转到同一个主机，并且原请求是通过 service 的地址列表发送的，继续使用 service 的地址
+		u.Scheme = ""
+		u.Host = ""
+	}
+	next.URL = u
+	if u.Host != "" && !strings.EqualFold(u.Host, oldHost) {
+		next.Host = ""
+	}
+
+	st.hops++
+	st.digest = false
+	if !sameOrSubDomain(hostname(oldHost), hostname(u.Host)) && u.Host != "" {
+		st.noAuth = true
+	}
+	if st.noAuth {
+		for _, h := range []string{"Authorization", "Www-Authenticate", "Cookie", "Cookie2", "Proxy-Authorization"} {
+			next.Header.Del(h)
+		}
+	}
+	return newHopRequest(req, next, st), nil
+}
+
+// baseRequest 使用已发送的请求创建后续请求，去掉自动添加的 Cookie 和身份认证信息（会在发送时重新添加）。
+// 若需要 Body，但是 Body 无法再次读取，返回 nil
+func baseRequest(sent *http.Request, opt xoption.Reader, keepBody bool) *http.Request {
+	next := sent.Clone(sent.Context())
+	hasBody := sent.Body != nil && sent.Body != http.NoBody
+	if keepBody && hasBody && sent.GetBody == nil {
+		return nil
+	}
+	next.Body = nil
+	if !keepBody {
+		next.GetBody = nil
+		next.ContentLength = 0
+		next.Header.Del("Content-Type")
+		next.Header.Del("Content-Length")
+	}
+	if xservice.OptCookieJar(opt) != nil {
+		next.Header.Del("Cookie")
+	}
+	if v := next.Header.Get("Authorization"); v != "" && v == authorization(optAuth(opt)) {
+		next.Header.Del("Authorization")
+	}
+	next.Header.Del("X-Retry-Count")
+	return next
+}
+
+func newHopRequest(req xrpc.Request, next *http.Request, st hopState) *NativeRequest {
+	nr := &NativeRequest{
+		Request: next,
+		hop:     st,
+	}
+	switch r := req.(type) {
+	case *NativeRequest:
+		nr.API = r.API
+		nr.Idempotency = r.Idempotency
+	case *Request:
+		nr.API = r.API
+		nr.Idempotency = r.Idempotency
+	}
+	return nr
+}
+
+func hostname(hostPort string) string {
+	if hostPort == "" {
+		return ""
+	}
+	u := url.URL{Host: hostPort}
+	return strings.ToLower(u.Hostname())
+}
+
+// sameOrSubDomain newHost 是否为 oldHost 或者其子域名
+func sameOrSubDomain(oldHost string, newHost string) bool {
+	if oldHost == newHost {
+		return true
+	}
+	return strings.HasSuffix(newHost, "."+oldHost)
+}
+
+// discardBody 读取少量剩余的 Body 后关闭，让连接可以被复用
+func discardBody(rr *http.Response) {
+	_, _ = io.CopyN(io.Discard, rr.Body, 4<<10)
+	_ = rr.Body.Close()
+}
@@ -0,0 +1,200 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttpc_test
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/xanygo/anygo/xhttp/xcookiejar"
+	"github.com/xanygo/anygo/xhttp/xhttpc"
+	"github.com/xanygo/anygo/xnet/xrpc"
+	"github.com/xanygo/anygo/xnet/xservice"
+	"github.com/xanygo/anygo/xt"
+)
+
+func echoRequest(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	fmt.Fprintf(w, "%s %s body=%q auth=%q cookie=%q", r.Method, r.URL.Path, body, r.Header.Get("Authorization"), r.Header.Get("Cookie"))
+}
+
+func TestRedirect(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(echoRequest))
+	defer other.Close()
+	// 使用 localhost 访问，和 127.0.0.1 不是同一个主机
+	otherURL := strings.Replace(other.URL, "127.0.0.1", "localhost", 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/r/{code}", func(w http.ResponseWriter, r *http.Request) {
+		var code int
+		fmt.Sscan(r.PathValue("code"), &code)
+		http.Redirect(w, r, "/target", code)
+	})
+	mux.HandleFunc("/target", echoRequest)
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/cross", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: "s1"})
+		http.Redirect(w, r, otherURL+"/target", http.StatusFound)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	postBody := func(t *testing.T, path string, body io.Reader, opts ...xrpc.Option) (*http.Response, string) {
+		resp := &http.Response{}
+		err := xhttpc.Post(t.Context(), xservice.Dummy, ts.URL+path, "text/plain", body, xhttpc.FetchResponse(resp), opts...)
+		xt.NoError(t, err)
+		got, _ := io.ReadAll(resp.Body)
+		return resp, string(got)
+	}
+	post := func(t *testing.T, path string, opts ...xrpc.Option) (*http.Response, string) {
+		return postBody(t, path, strings.NewReader("hello"), opts...)
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		resp, _ := post(t, "/r/302")
+		xt.Equal(t, resp.StatusCode, http.StatusFound)
+	})
+
+	t.Run("method", func(t *testing.T) {
+		_, body := post(t, "/r/302", xhttpc.OptRedirect(3))
+		xt.Equal(t, body, `GET /target body="" auth="" cookie=""`)
+		_, body = post(t, "/r/303", xhttpc.OptRedirect(3))
+		xt.Equal(t, body, `GET /target body="" auth="" cookie=""`)
+
+		_, body = post(t, "/r/307", xhttpc.OptRedirect(3))
+		xt.Equal(t, body, `POST /target body="hello" auth="" cookie=""`)
+
+		// 307、308 需要 GetBody 才能再次发送 Body
+		resp, _ := postBody(t, "/r/307", io.MultiReader(strings.NewReader("hello")), xhttpc.OptRedirect(3))
+		xt.Equal(t, resp.StatusCode, http.StatusTemporaryRedirect)
+
+		c := &xhttpc.Client{Opts: []xrpc.Option{xhttpc.OptRedirect(3)}}
+		resp, err := c.PostJSON(t.Context(), ts.URL+"/r/308", map[string]int{"a": 1})
+		xt.NoError(t, err)
+		body2, _ := io.ReadAll(resp.Body)
+		xt.Equal(t, string(body2), `POST /target body="{\"a\":1}" auth="" cookie=""`)
+		xt.Equal(t, resp.Request.URL.Path, "/target")
+	})
+
+	t.Run("too many", func(t *testing.T) {
+		resp := &http.Response{}
+		err := xhttpc.Get(t.Context(), xservice.Dummy, ts.URL+"/loop", xhttpc.FetchResponse(resp), xhttpc.OptRedirect(2))
+		xt.ErrorIs(t, err, xhttpc.ErrTooManyRedirects)
+	})
+
+	t.Run("cross host", func(t *testing.T) {
+		jar := xcookiejar.New(nil)
+		auth := &xservice.HTTPAuthPart{Type: xservice.AuthBearer, Token: "t1"}
+		_, body := post(t, "/cross", xhttpc.OptRedirect(3), xhttpc.OptAuth(auth), xhttpc.OptCookieJar(jar))
+		xt.Equal(t, body, `GET /target body="" auth="" cookie=""`)
+		xt.Len(t, jar.Entries(), 1)
+
+		// 同一个主机，会发送认证信息和 Cookie
+		_, body = post(t, "/r/303", xhttpc.OptRedirect(3), xhttpc.OptAuth(auth), xhttpc.OptCookieJar(jar))
+		xt.Equal(t, body, `GET /target body="" auth="Bearer t1" cookie="sid=s1"`)
+	})
+}
+
+func TestAuth(t *testing.T) {
+	const realm, nonce = "test", "n1"
+	digestOK := func(r *http.Request) bool {
+		v, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Digest ")
+		if !ok {
+			return false
+		}
+		params := map[string]string{}
+		for _, kv := range strings.Split(v, ", ") {
+			k, val, _ := strings.Cut(kv, "=")
+			params[k] = strings.Trim(val, `"`)
+		}
+		h := func(s string) string {
+			sum := md5.Sum([]byte(s))
+			return hex.EncodeToString(sum[:])
+		}
+		ha1 := h("user:" + realm + ":pass")
+		ha2 := h(r.Method + ":" + r.URL.RequestURI())
+		want := h(ha1 + ":" + nonce + ":" + params["nc"] + ":" + params["cnonce"] + ":auth:" + ha2)
+		return params["response"] == want && params["uri"] == r.URL.RequestURI() && params["opaque"] == "op"
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/digest" && !digestOK(r) {
+			w.Header().Set("WWW-Authenticate", `Digest realm="test", nonce="n1", qop="auth,auth-int", opaque="op"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "sid", Value: "s2", Path: "/"})
+		}
+		echoRequest(w, r)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	xt.NoError(t, err)
+	newService := func(t *testing.T, auth *xservice.HTTPAuthPart) xservice.Service {
+		cfg := &xservice.Config{
+			Name: "auth-test",
+			HTTP: &xservice.HTTPPart{
+				CookieJar: true,
+				Auth:      auth,
+			},
+			DownStream: xservice.DownStreamPart{
+				Address: []string{u.Host},
+			},
+		}
+		srv, err := cfg.Parser("")
+		xt.NoError(t, err)
+		xt.NoError(t, srv.Start(t.Context()))
+		t.Cleanup(func() {
+			_ = srv.Stop(t.Context())
+		})
+		return srv
+	}
+
+	get := func(t *testing.T, srv any, path string, opts ...xrpc.Option) string {
+		body, err := xhttpc.GetBody(t.Context(), srv, path, opts...)
+		xt.NoError(t, err)
+		return string(body)
+	}
+
+	t.Run("basic", func(t *testing.T) {
+		srv := newService(t, &xservice.HTTPAuthPart{Type: xservice.AuthBasic, Username: "user", Password: "pass"})
+		xt.Equal(t, get(t, srv, "/login"), `GET /login body="" auth="Basic dXNlcjpwYXNz" cookie=""`)
+		// 同一个 service 共享 Cookie
+		xt.Equal(t, get(t, srv, "/me"), `GET /me body="" auth="Basic dXNlcjpwYXNz" cookie="sid=s2"`)
+	})
+
+	t.Run("digest", func(t *testing.T) {
+		srv := newService(t, &xservice.HTTPAuthPart{Type: xservice.AuthDigest, Username: "user", Password: "pass"})
+		body := get(t, srv, "/digest?a=1")
+		xt.HasPrefix(t, body, `GET /digest body="" auth="Digest username=\"user\"`)
+
+		// 错误的密码
+		resp := &http.Response{}
+		opt := xhttpc.OptAuth(&xservice.HTTPAuthPart{Type: xservice.AuthDigest, Username: "user", Password: "bad"})
+		err := xhttpc.Get(t.Context(), srv, "/digest", xhttpc.FetchResponse(resp), opt)
+		xt.NoError(t, err)
+		xt.Equal(t, resp.StatusCode, http.StatusUnauthorized)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		cfg := &xservice.Config{
+			Name:       "auth-invalid",
+			HTTP:       &xservice.HTTPPart{Auth: &xservice.HTTPAuthPart{Type: "OAuth"}},
+			DownStream: xservice.DownStreamPart{Address: []string{u.Host}},
+		}
+		_, err := cfg.Parser("")
+		xt.Error(t, err)
+	})
+}
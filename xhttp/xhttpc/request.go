@@ -20,6 +20,7 @@ import (
 	"github.com/xanygo/anygo/ds/xoption"
 	"github.com/xanygo/anygo/ds/xsync"
 	"github.com/xanygo/anygo/ds/xtype"
+	"github.com/xanygo/anygo/xhttp/xhttp2"
 	"github.com/xanygo/anygo/xlog"
 	"github.com/xanygo/anygo/xnet"
 	"github.com/xanygo/anygo/xnet/xbalance"
@@ -54,6 +55,13 @@ type Request struct {
 	Header  http.Header                   // 请求的 header，可选
 	GetBody func() (io.ReadCloser, error) // 发送的 Body，可选
 
+	// Body 发送的 Body，可选，用于流式上传，GetBody 为空时才会使用。
+	// Body 只会被读取一次，所以请求不会重试，写超时（WriteTimeout）是每次写入的超时
+	Body io.Reader
+
+	// ContentLength Body 的长度，可选，为 0 时，若不能从 Body 的类型得知其长度，会使用 chunked 编码发送
+	ContentLength int64
+
 	// Idempotency 多次发送该请求，Server 端的结果是否幂等，可选
 	// 当不设置的时候，会依据 Method 判断
 	Idempotency xtype.TriState
@@ -65,6 +73,10 @@ func (r *Request) Idempotent() bool {
 	if r == nil {
 		return false
 	}
+	if r.GetBody == nil && r.Body != nil {
+		// Body 不能重复读取
+		return false
+	}
 	if r.Idempotency.NotNull() {
 		return r.Idempotency.IsTrue()
 	}
@@ -120,44 +132,66 @@ func (r *Request) GetMethod() string {
 }
 
 func (r *Request) WriteTo(ctx context.Context, w io.Writer, opt xoption.Reader) error {
+	if hc, ok := w.(*xhttp2.Conn); ok {
+		req, err := r.newRequest(ctx, opt, hc.Addr().HostPort)
+		if err != nil {
+			return err
+		}
+		return sendHTTP2(ctx, hc, req, opt)
+	}
 	node, ok := w.(*xnet.ConnNode)
 	if !ok {
 		return fmt.Errorf("writer is %T, not net.ConnNode", w)
 	}
-	api, err := r.getURL(opt, node.Addr.HostPort)
-	if err != nil {
-		return err
-	}
 
 	timeout := xoption.WriteTimeout(opt)
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
-	if err = node.SetDeadline(time.Now().Add(timeout)); err != nil {
+	if err := node.SetDeadline(time.Now().Add(timeout)); err != nil {
 		return err
 	}
 	defer node.SetDeadline(time.Time{})
 
-	req, err := http.NewRequestWithContext(ctx, r.getMethod(), api, nil)
+	req, err := r.newRequest(ctx, opt, node.Addr.HostPort)
 	if err != nil {
 		return err
 	}
+	err = writeHTTP1(node, req, opt)
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("request.Write: %w", err)
+}
+
+func (r *Request) newRequest(ctx context.Context, opt xoption.Reader, address string) (*http.Request, error) {
+	api, err := r.getURL(opt, address)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, r.getMethod(), api, nil)
+	if err != nil {
+		return nil, err
+	}
 	if r.GetBody != nil {
 		req.GetBody = r.GetBody
 		bd, err := r.GetBody()
 		if err != nil {
-			return err
+			return nil, err
 		}
 		req.Body = bd
+	} else if r.Body != nil {
+		req.Body = io.NopCloser(r.Body)
+		req.ContentLength = r.ContentLength
+		if req.ContentLength == 0 {
+			req.ContentLength = knownLength(r.Body)
+		}
 	}
 	if req.Host == xnet.Dummy {
 		req.Host = ""
 	}
 	setHeader(ctx, req, opt)
-	err = req.Write(node.Outer())
-	if err == nil {
-		return nil
-	}
-	return fmt.Errorf("request.Write: %w", err)
+	prepareRequest(req, opt, r.HTTPS, hopState{})
+	return req, nil
 }
 
 func (r *Request) getURL(so xoption.Reader, address string) (string, error) {
@@ -244,6 +278,8 @@ type NativeRequest struct {
 	// Idempotency 多次发送该请求，Server 端的结果是否幂等，可选
 	// 当不设置的时候，会依据 Method 判断
 	Idempotency xtype.TriState
+
+	hop hopState
 }
 
 func (h *NativeRequest) Protocol() string {
@@ -256,6 +292,10 @@ func (h *NativeRequest) Idempotent() bool {
 	if h == nil || h.Request == nil {
 		return false
 	}
+	if h.Request.GetBody == nil && hasBody(h.Request) {
+		// Body 不能重复读取
+		return false
+	}
 	if h.Idempotency.NotNull() {
 		return h.Idempotency.IsTrue()
 	}
@@ -283,6 +323,13 @@ func (h *NativeRequest) GetMethod() string {
 }
 
 func (h *NativeRequest) WriteTo(ctx context.Context, w io.Writer, opt xoption.Reader) error {
+	if hc, ok := w.(*xhttp2.Conn); ok {
+		req, err := h.newRequest(ctx, opt, hc.Addr())
+		if err != nil {
+			return err
+		}
+		return sendHTTP2(ctx, hc, req, opt)
+	}
 	node, ok := w.(*xnet.ConnNode)
 	if !ok {
 		return fmt.Errorf("writer is %T, not net.ConnNode", w)
@@ -296,26 +343,35 @@ func (h *NativeRequest) WriteTo(ctx context.Context, w io.Writer, opt xoption.Re
 	}
 	defer node.SetDeadline(time.Time{})
 
+	req, err := h.newRequest(ctx, opt, node.Addr)
+	if err != nil {
+		return err
+	}
+	err = writeHTTP1(node, req, opt)
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("nativeRequest.Write: %w", err)
+}
+
+func (h *NativeRequest) newRequest(ctx context.Context, opt xoption.Reader, addr xnet.AddrNode) (*http.Request, error) {
 	req := h.Request.Clone(ctx)
 	if req.Host == xnet.Dummy {
 		req.Host = ""
 	}
 	if req.Host == "" {
-		req.Host = node.Addr.Host()
+		req.Host = addr.Host()
 	}
 	if req.GetBody != nil {
 		bd, err := req.GetBody()
 		if err != nil {
-			return err
+			return nil, err
 		}
 		req.Body = bd
 	}
 	setHeader(ctx, req, opt)
-	err := req.Write(node.Outer())
-	if err == nil {
-		return nil
-	}
-	return fmt.Errorf("nativeRequest.Write: %w", err)
+	prepareRequest(req, opt, req.URL.Scheme == "https", h.hop)
+	return req, nil
 }
 
 func (h *NativeRequest) balancer(opt xoption.Reader) xbalance.Reader {
@@ -6,6 +6,7 @@ package xhttpc
 
 import (
 	"bufio"
+	"bytes"
 	"compress/gzip"
 	"context"
 	"errors"
@@ -15,20 +16,36 @@ import (
 	"strings"
 	"time"
 
+	"github.com/xanygo/anygo/ds/xmeta"
 	"github.com/xanygo/anygo/ds/xoption"
 	"github.com/xanygo/anygo/ds/xsync"
 	"github.com/xanygo/anygo/xerror"
+	"github.com/xanygo/anygo/xhttp/xhttp2"
 	"github.com/xanygo/anygo/xio"
 	"github.com/xanygo/anygo/xnet/xrpc"
 )
 
 var _ xrpc.Response = (*Response)(nil)
+var _ xrpc.Hedger = (*Response)(nil)
 
 type Response struct {
 	Handler HandlerFunc
 
+	// Stream 可选，为 true 时，Handler 返回后，响应的 Body 仍然可以继续读取，
+	// 读取期间连接一直被占用，直到 Body 被关闭，连接才会放回连接池，见 OpenStream
+	Stream bool
+
 	resp    *http.Response
 	readErr error
+
+	// next 需要继续发送的请求，如重定向
+	next *NativeRequest
+
+	// held 连接由流式的 Body 持有
+	held bool
+
+	// buffered 对冲请求的 Response，响应已经完整读取到内存中，还未交给 Handler 处理
+	buffered bool
 }
 
 func (resp *Response) String() string {
@@ -40,7 +57,13 @@ func (resp *Response) String() string {
 
 func (resp *Response) LoadFrom(ctx context.Context, req xrpc.Request, r io.Reader, opt xoption.Reader) error {
 	resp.resp = nil
-	resp.readErr = resp.doLoadFrom(ctx, req, r, opt)
+	resp.next = nil
+	resp.held = false
+	return resp.setReadErr(resp.doLoadFrom(ctx, req, r, opt))
+}
+
+func (resp *Response) setReadErr(err error) error {
+	resp.readErr = err
 	if resp.readErr == nil {
 		return nil
 	}
@@ -69,29 +92,85 @@ func retryableStatus(code int) bool {
 }
 
 func (resp *Response) doLoadFrom(ctx context.Context, req xrpc.Request, r io.Reader, opt xoption.Reader) error {
+	if hc, ok := r.(*xhttp2.Conn); ok {
+		return resp.loadHTTP2(ctx, req, hc, opt)
+	}
 	if ds, ok := r.(xio.ReadDeadlineSetter); ok {
 		timeout := xoption.ReadTimeout(opt)
 		if err := ds.SetReadDeadline(time.Now().Add(timeout)); err != nil {
 			return err
 		}
 		defer ds.SetReadDeadline(time.Time{})
+		// ctx 结束时，让读取立即失败，避免读取 SSE 这类长连接的 Body 时一直阻塞
+		stop := context.AfterFunc(ctx, func() {
+			_ = ds.SetReadDeadline(time.Now())
+		})
+		defer stop()
 	}
 
+	src := r
+	st, _ := xmeta.TryGet(r, metaKeyExpect).(*expectState)
+	if st != nil {
+		// 等待 100 Continue 时，可能已经读取了部分响应
+		src = st.br
+	}
 	maxSize := xoption.MaxResponseSize(opt)
-	bio := bufio.NewReader(io.LimitReader(r, maxSize))
-	rr, err := http.ReadResponse(bio, nil)
+	bio := bufio.NewReader(io.LimitReader(src, maxSize))
+	sent, _ := xmeta.TryGet(r, metaKeyRequest).(*http.Request)
+	rr, err := readResponse(bio, sent)
 	if err != nil {
 		return fmt.Errorf("http.ReadResponse %w", err)
 	}
 	resp.decompress(rr)
 	resp.resp = rr
+	// 服务端要求关闭连接，或者 Expect: 100-continue 的请求没有发送 Body，连接不能再复用
+	reuse := !rr.Close && (st == nil || !st.rejected)
+	if skip, err := resp.intercept(req, rr, opt); skip || err != nil {
+		_ = rr.Body.Close()
+		if !reuse {
+			discardConn(r)
+		}
+		return err
+	}
+	if resp.Stream {
+		sb := newDeadlineStream(r, xoption.ReadTimeout(opt), reuse)
+		if err = resp.handleStream(ctx, rr, r, sb); err != nil {
+			return fmt.Errorf("resp.Handler %w", err)
+		}
+		return nil
+	}
+	if !reuse {
+		defer discardConn(r)
+	}
 	err = resp.Handler(ctx, rr)
 	if err == nil {
 		return nil
 	}
+	if errors.Is(err, errAbortBody) {
+		// 不再读取剩余的 Body（如 SSE 这类不会结束的流），让读取立即失败，
+		// 这样 Body.Close 时不会一直阻塞，同时连接会因为读错误而不会被放回连接池
+		if ds, ok := r.(xio.ReadDeadlineSetter); ok {
+			_ = ds.SetReadDeadline(time.Now())
+		}
+		_ = rr.Body.Close()
+		return nil
+	}
 	return fmt.Errorf("resp.Handler %w", err)
 }
 
+// readResponse 读取最终的响应，忽略 1xx 的响应（如等待超时后才收到的 100 Continue）
+func readResponse(br *bufio.Reader, sent *http.Request) (*http.Response, error) {
+	for {
+		rr, err := http.ReadResponse(br, sent)
+		if err != nil {
+			return nil, err
+		}
+		if rr.StatusCode >= 200 || rr.StatusCode == http.StatusSwitchingProtocols {
+			return rr, nil
+		}
+	}
+}
+
 func (resp *Response) decompress(rr *http.Response) {
 	if strings.EqualFold(rr.Header.Get("Content-Encoding"), "gzip") {
 		rr.Body = &gzipReader{body: rr.Body}
@@ -109,6 +188,9 @@ type gzipReader struct {
 	closeOnce xsync.OnceDoErr
 }
 
+// errAbortBody Handler 返回此错误，表示放弃读取剩余的响应 Body，并且不复用连接
+var errAbortBody = errors.New("xhttpc: abort response body")
+
 var errReadOnClosedResBody = errors.New("xhttpc: read on closed response body")
 
 func (gz *gzipReader) Read(p []byte) (n int, err error) {
@@ -163,3 +245,43 @@ func (resp *Response) Response() *http.Response {
 func (resp *Response) Unwrap() any {
 	return resp.resp
 }
+
+// NewHedge 实现 xrpc.Hedger，对冲请求的响应会完整读取到内存中，最先成功的响应再交给 Handler 处理。
+// 流式读取时不支持对冲
+func (resp *Response) NewHedge() xrpc.Response {
+	if resp.Stream {
+		return nil
+	}
+	hr := &Response{}
+	hr.Handler = func(ctx context.Context, rr *http.Response) error {
+		body, err := io.ReadAll(rr.Body)
+		_ = rr.Body.Close()
+		if err != nil {
+			return err
+		}
+		rr.Body = io.NopCloser(bytes.NewReader(body))
+		hr.buffered = true
+		return nil
+	}
+	return hr
+}
+
+// AcceptHedge 实现 xrpc.Hedger，使用最先成功的对冲请求的响应
+func (resp *Response) AcceptHedge(ctx context.Context, winner xrpc.Response) error {
+	hr, ok := winner.(*Response)
+	if !ok {
+		return fmt.Errorf("xhttpc: invalid hedge response %T", winner)
+	}
+	resp.resp = hr.resp
+	resp.next = hr.next
+	resp.held = false
+	if !hr.buffered {
+		// 如重定向，Handler 不需要处理此响应
+		return resp.setReadErr(nil)
+	}
+	err := resp.Handler(ctx, resp.resp)
+	if err == nil || errors.Is(err, errAbortBody) {
+		return resp.setReadErr(nil)
+	}
+	return resp.setReadErr(fmt.Errorf("resp.Handler %w", err))
+}
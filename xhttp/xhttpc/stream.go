@@ -0,0 +1,335 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xmeta"
+	"github.com/xanygo/anygo/ds/xoption"
+	"github.com/xanygo/anygo/xio"
+	"github.com/xanygo/anygo/xnet"
+	"github.com/xanygo/anygo/xnet/xrpc"
+)
+
+// OpenStream 发送请求并返回响应，响应的 Body 可以增量读取（如下载大文件），不会一次性读取到内存中。
+//
+// 读取期间连接一直被占用，调用方必须关闭 Body，之后连接才会放回连接池（未读完就关闭时，连接不会被复用）。
+// 每次读取 Body 的超时时间为 ReadTimeout，响应的大小依然受 MaxResponseSize 的限制。
+func OpenStream(ctx context.Context, service any, req *http.Request, opts ...xrpc.Option) (*http.Response, error) {
+	var rr *http.Response
+	resp := &Response{
+		Stream: true,
+		Handler: func(ctx context.Context, resp *http.Response) error {
+			rr = resp
+			return nil
+		},
+	}
+	if err := invoke(ctx, service, &NativeRequest{Request: req}, resp, opts); err != nil {
+		return nil, err
+	}
+	return rr, nil
+}
+
+// streamBody 流式读取的响应 Body，Handler 返回后继续持有连接，直到 Close
+type streamBody struct {
+	body    io.ReadCloser
+	timeout time.Duration
+
+	setDeadline func(t time.Time) // 设置读超时，t 为零值时取消
+	abort       func()            // 放弃读取剩余的内容，连接不会被复用
+	release     func()            // 可选，Close 时调用
+
+	reuse bool // 读完后，连接是否可以复用
+	eof   atomic.Bool
+
+	mux    sync.Mutex
+	conn   io.Closer // 持有的连接，Close 时归还
+	closed bool
+	err    error
+}
+
+func (sb *streamBody) Read(p []byte) (int, error) {
+	sb.setDeadline(time.Now().Add(sb.timeout))
+	n, err := sb.body.Read(p)
+	sb.setDeadline(time.Time{})
+	if err == io.EOF {
+		sb.eof.Store(true)
+	}
+	return n, err
+}
+
+// hold Handler 返回后，由 Body 持有连接，若 Body 已经被关闭，返回 false
+func (sb *streamBody) hold(conn io.Closer) bool {
+	sb.mux.Lock()
+	defer sb.mux.Unlock()
+	if sb.closed {
+		return false
+	}
+	sb.conn = conn
+	return true
+}
+
+func (sb *streamBody) Close() error {
+	sb.mux.Lock()
+	defer sb.mux.Unlock()
+	if sb.closed {
+		return sb.err
+	}
+	sb.closed = true
+	if sb.eof.Load() && sb.reuse {
+		sb.err = sb.body.Close()
+	} else {
+		// 放弃剩余的内容，此时 Close 返回的读取错误可以忽略
+		sb.abort()
+		_ = sb.body.Close()
+	}
+	if sb.release != nil {
+		sb.release()
+	}
+	if sb.conn != nil {
+		_ = sb.conn.Close()
+	}
+	return sb.err
+}
+
+// handleStream 使用流式的 Body 调用 Handler，Handler 成功返回后，连接由 Body 持有
+func (resp *Response) handleStream(ctx context.Context, rr *http.Response, conn io.Reader, sb *streamBody) error {
+	sb.body = rr.Body
+	rr.Body = sb
+	err := resp.Handler(ctx, rr)
+	if err == nil {
+		if c, ok := conn.(io.Closer); ok && sb.hold(c) {
+			resp.held = true
+		}
+		return nil
+	}
+	_ = sb.Close()
+	if errors.Is(err, errAbortBody) {
+		return nil
+	}
+	return err
+}
+
+// newDeadlineStream HTTP/1.x 连接上的流式 Body
+func newDeadlineStream(r io.Reader, timeout time.Duration, reuse bool) *streamBody {
+	sb := &streamBody{
+		timeout:     timeout,
+		reuse:       reuse,
+		setDeadline: func(time.Time) {},
+		abort:       func() {},
+	}
+	if ds, ok := r.(xio.ReadDeadlineSetter); ok {
+		sb.setDeadline = func(t time.Time) {
+			_ = ds.SetReadDeadline(t)
+		}
+		sb.abort = func() {
+			_ = ds.SetReadDeadline(time.Now())
+		}
+	}
+	return sb
+}
+
+// HoldConn 实现 xrpc.ConnHolder，流式读取时，连接由 Body 持有
+func (resp *Response) HoldConn() bool {
+	return resp.held
+}
+
+var _ xrpc.ConnHolder = (*Response)(nil)
+
+// discardConn 让 HTTP/1.x 的连接不再被复用：读取失败时，错误会记录在连接上，连接归还时会被关闭
+func discardConn(r io.Reader) {
+	if ds, ok := r.(xio.ReadDeadlineSetter); ok {
+		_ = ds.SetReadDeadline(time.Now())
+		_, _ = r.Read(make([]byte, 1))
+	}
+}
+
+var (
+	optKeyExpectContinueTimeout = xoption.NewKey("HTTP:ExpectContinueTimeout")
+
+	// metaKeyExpect 在连接上保存 Expect: 100-continue 的状态，以在读取响应时使用
+	metaKeyExpect = xmeta.NewKey("xhttpc:Expect")
+
+	errExpectRejected = errors.New("xhttpc: server rejected Expect: 100-continue")
+)
+
+// OptExpectContinueTimeout 请求有 "Expect: 100-continue" Header 时，等待服务端响应 100 Continue 的最长时间，
+// 超时后会继续发送 Body，默认为 1 秒。
+//
+// 只对 HTTP/1.1 生效，HTTP/2 会直接发送 Body
+func OptExpectContinueTimeout(timeout time.Duration) xrpc.Option {
+	return xrpc.OptOptionSetter(func(o xoption.Option) {
+		o.Set(optKeyExpectContinueTimeout, timeout)
+	})
+}
+
+func optExpectContinueTimeout(opt xoption.Reader) time.Duration {
+	return xoption.GetAsDefault[time.Duration](opt, optKeyExpectContinueTimeout, time.Second)
+}
+
+// expectState 发送 Expect: 100-continue 请求时，读取 100 Continue 使用的 Reader，读取最终的响应时需要继续使用
+type expectState struct {
+	br       *bufio.Reader
+	rejected bool // 服务端直接返回了最终的响应，Body 没有发送
+}
+
+// writeHTTP1 在 HTTP/1.x 连接上发送请求。
+//
+// 请求的 Body 不能重复读取（没有 GetBody）时，写超时是每次写入的超时，而不是发送整个请求的超时，以支持流式上传。
+// 请求有 Expect: 100-continue 时，先发送 Header，等待服务端的 100 Continue 后再发送 Body，
+// 若服务端直接返回了最终的响应（如 417、401），则不再发送 Body，并且连接不会被复用
+func writeHTTP1(node *xnet.ConnNode, req *http.Request, opt xoption.Reader) error {
+	node.SetMeta(metaKeyRequest, req)
+	node.SetMeta(metaKeyExpect, nil)
+
+	var w io.Writer = node.Outer()
+	if hasBody(req) && req.GetBody == nil {
+		w = &deadlineWriter{conn: node.Outer(), timeout: xoption.WriteTimeout(opt)}
+	}
+	if !expectContinue(req) {
+		return req.Write(w)
+	}
+
+	st := &expectState{
+		br: bufio.NewReader(node),
+	}
+	node.SetMeta(metaKeyExpect, st)
+	cr := &continueReader{
+		body:    req.Body,
+		node:    node,
+		st:      st,
+		timeout: optExpectContinueTimeout(opt),
+	}
+	// 使用 bufio.Writer，Request.Write 会在发送 Body 前将 Header 发送出去
+	bw := bufio.NewWriter(w)
+	req.Body = cr
+	err := req.Write(bw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if st.rejected {
+		// 读取 Body 的错误会被 Request.Write 包装，不能使用 errors.Is 判断
+		return nil
+	}
+	return err
+}
+
+func hasBody(req *http.Request) bool {
+	return req.Body != nil && req.Body != http.NoBody
+}
+
+// knownLength 返回内存中的 Body 的长度，未知时返回 0
+func knownLength(r io.Reader) int64 {
+	switch v := r.(type) {
+	case *bytes.Buffer:
+		return int64(v.Len())
+	case *bytes.Reader:
+		return int64(v.Len())
+	case *strings.Reader:
+		return int64(v.Len())
+	default:
+		return 0
+	}
+}
+
+// expectContinue 是否需要等待 100 Continue。
+// 对于 GET 这类通常没有 Body 的请求，长度未知时，Request.Write 会先尝试读取 Body，所以不等待
+func expectContinue(req *http.Request) bool {
+	if !strings.EqualFold(req.Header.Get("Expect"), "100-continue") || !hasBody(req) {
+		return false
+	}
+	if req.ContentLength > 0 {
+		return true
+	}
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
+
+// continueReader 首次读取时，等待服务端的 100 Continue
+type continueReader struct {
+	body    io.ReadCloser
+	node    *xnet.ConnNode
+	st      *expectState
+	timeout time.Duration
+	waited  bool
+}
+
+func (cr *continueReader) Read(p []byte) (int, error) {
+	if !cr.waited {
+		cr.waited = true
+		if err := cr.wait(); err != nil {
+			return 0, err
+		}
+	}
+	return cr.body.Read(p)
+}
+
+func (cr *continueReader) Close() error {
+	return cr.body.Close()
+}
+
+func (cr *continueReader) wait() error {
+	if err := cr.node.SetReadDeadline(time.Now().Add(cr.timeout)); err != nil {
+		return err
+	}
+	defer cr.node.SetReadDeadline(time.Time{})
+	for {
+		line, err := cr.st.br.Peek(len("HTTP/1.1 100"))
+		if err != nil {
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				// 服务端没有响应 100 Continue，继续发送 Body
+				return nil
+			}
+			return err
+		}
+		code := string(line[len(line)-3:])
+		if code[0] != '1' {
+			cr.st.rejected = true
+			return errExpectRejected
+		}
+		// 读取 1xx 响应的 Header
+		for {
+			l, err := cr.st.br.ReadSlice('\n')
+			if err != nil {
+				return err
+			}
+			if len(l) <= 2 && strings.TrimSpace(string(l)) == "" {
+				break
+			}
+		}
+		if code == "100" {
+			return nil
+		}
+	}
+}
+
+// deadlineWriter 每次写入前更新写超时
+type deadlineWriter struct {
+	conn    net.Conn
+	timeout time.Duration
+}
+
+func (dw *deadlineWriter) Write(p []byte) (int, error) {
+	if err := dw.conn.SetWriteDeadline(time.Now().Add(dw.timeout)); err != nil {
+		return 0, err
+	}
+	return dw.conn.Write(p)
+}
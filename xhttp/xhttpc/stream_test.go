@@ -0,0 +1,337 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xhttpc_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/xhttp/xhttpc"
+	"github.com/xanygo/anygo/xnet/xdial"
+	"github.com/xanygo/anygo/xnet/xrpc"
+	"github.com/xanygo/anygo/xnet/xservice"
+	"github.com/xanygo/anygo/xt"
+)
+
+func TestOpenStream(t *testing.T) {
+	xservice.DefaultRegistry().Register(xservice.DefaultDummyService())
+
+	var conns atomic.Int64
+	next := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/chunks", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "part1;")
+		w.(http.Flusher).Flush()
+		<-next
+		fmt.Fprint(w, "part2")
+	})
+	mux.HandleFunc("/big", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bytes.Repeat([]byte("a"), 1<<20))
+	})
+	ts := httptest.NewUnstartedServer(mux)
+	ts.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	xt.NoError(t, err)
+	cfg := &xservice.Config{
+		Name: "stream",
+		DownStream: xservice.DownStreamPart{
+			Address: []string{u.Host},
+		},
+		ConnPool: &xservice.ConnPoolPart{
+			Name:    xdial.Long,
+			MaxIdle: 2,
+		},
+	}
+	srv, err := cfg.Parser("test")
+	xt.NoError(t, err)
+	xt.NoError(t, srv.Start(t.Context()))
+	defer srv.Stop(t.Context())
+
+	open := func(t *testing.T, path string) *http.Response {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, path, nil)
+		xt.NoError(t, err)
+		resp, err := xhttpc.OpenStream(t.Context(), srv, req)
+		xt.NoError(t, err)
+		return resp
+	}
+
+	t.Run("incremental", func(t *testing.T) {
+		resp := open(t, "/chunks")
+		buf := make([]byte, 6)
+		_, err := io.ReadFull(resp.Body, buf)
+		xt.NoError(t, err)
+		// 服务端还没有发送完，已经可以读取到前面的内容
+		xt.Equal(t, string(buf), "part1;")
+		next <- struct{}{}
+		rest, err := io.ReadAll(resp.Body)
+		xt.NoError(t, err)
+		xt.Equal(t, string(rest), "part2")
+		xt.NoError(t, resp.Body.Close())
+	})
+
+	t.Run("reuse after close", func(t *testing.T) {
+		before := conns.Load()
+		for range 3 {
+			resp := open(t, "/big")
+			n, err := io.Copy(io.Discard, resp.Body)
+			xt.NoError(t, err)
+			xt.Equal(t, n, int64(1<<20))
+			xt.NoError(t, resp.Body.Close())
+		}
+		xt.Equal(t, conns.Load()-before, int64(0))
+	})
+
+	t.Run("close early", func(t *testing.T) {
+		resp := open(t, "/big")
+		buf := make([]byte, 10)
+		_, err := io.ReadFull(resp.Body, buf)
+		xt.NoError(t, err)
+		xt.NoError(t, resp.Body.Close())
+
+		// 未读完的连接不会被复用
+		before := conns.Load()
+		resp = open(t, "/big")
+		_, err = io.Copy(io.Discard, resp.Body)
+		xt.NoError(t, err)
+		resp.Body.Close()
+		xt.Equal(t, conns.Load()-before, int64(1))
+	})
+
+	t.Run("client", func(t *testing.T) {
+		hc := &http.Client{Transport: &xhttpc.Client{Service: srv}}
+		resp, err := hc.Get(ts.URL + "/big")
+		xt.NoError(t, err)
+		defer resp.Body.Close()
+		n, err := io.Copy(io.Discard, resp.Body)
+		xt.NoError(t, err)
+		xt.Equal(t, n, int64(1<<20))
+	})
+}
+
+// onceReader 记录 Body 是否被读取
+type onceReader struct {
+	r    io.Reader
+	read atomic.Bool
+}
+
+func (o *onceReader) Read(p []byte) (int, error) {
+	o.read.Store(true)
+	return o.r.Read(p)
+}
+
+func TestStreamUpload(t *testing.T) {
+	xservice.DefaultRegistry().Register(xservice.DefaultDummyService())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "te=%v len=%d body=%s", r.TransferEncoding, r.ContentLength, body)
+	})
+	mux.HandleFunc("/reject", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, "no")
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	post := func(t *testing.T, path string, body io.Reader, header ...string) (int, string) {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, ts.URL+path, body)
+		xt.NoError(t, err)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		resp := &http.Response{}
+		err = xhttpc.Invoke(t.Context(), xservice.Dummy, req, xhttpc.FetchResponse(resp))
+		xt.NoError(t, err)
+		got, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(got)
+	}
+
+	t.Run("chunked", func(t *testing.T) {
+		pr, pw := io.Pipe()
+		go func() {
+			for i := range 3 {
+				fmt.Fprintf(pw, "c%d;", i)
+				time.Sleep(5 * time.Millisecond)
+			}
+			pw.Close()
+		}()
+		code, body := post(t, "/echo", pr)
+		xt.Equal(t, code, http.StatusOK)
+		xt.Equal(t, body, "te=[chunked] len=-1 body=c0;c1;c2;")
+	})
+
+	t.Run("request body", func(t *testing.T) {
+		req := &xhttpc.Request{
+			Method: http.MethodPut,
+			Path:   ts.URL + "/echo",
+			Body:   io.MultiReader(strings.NewReader("hello "), strings.NewReader("world")),
+		}
+		xt.False(t, req.Idempotent())
+		var got string
+		resp := &xhttpc.Response{
+			Handler: func(ctx context.Context, resp *http.Response) error {
+				bf, err := io.ReadAll(resp.Body)
+				got = string(bf)
+				return err
+			},
+		}
+		err := xrpc.Invoke(t.Context(), xservice.Dummy, req, resp)
+		xt.NoError(t, err)
+		xt.Equal(t, got, "te=[chunked] len=-1 body=hello world")
+	})
+
+	t.Run("expect continue", func(t *testing.T) {
+		body := &onceReader{r: strings.NewReader("payload")}
+		code, got := post(t, "/echo", io.MultiReader(body), "Expect", "100-continue")
+		xt.Equal(t, code, http.StatusOK)
+		xt.Equal(t, got, "te=[chunked] len=-1 body=payload")
+		xt.True(t, body.read.Load())
+	})
+
+	t.Run("expect rejected", func(t *testing.T) {
+		body := &onceReader{r: strings.NewReader("payload")}
+		code, got := post(t, "/reject", io.MultiReader(body), "Expect", "100-continue")
+		xt.Equal(t, code, http.StatusUnauthorized)
+		xt.Equal(t, got, "no")
+		xt.False(t, body.read.Load())
+
+		code, got = post(t, "/echo", strings.NewReader("again"))
+		xt.Equal(t, code, http.StatusOK)
+		xt.Equal(t, got, "te=[] len=5 body=again")
+	})
+}
+
+func TestDownload(t *testing.T) {
+	xservice.DefaultRegistry().Register(xservice.DefaultDummyService())
+
+	content := bytes.Repeat([]byte("0123456789"), 10000)
+	sum := sha256.Sum256(content)
+	checksum := "sha256:" + hex.EncodeToString(sum[:])
+	modTime := time.Now().Add(-time.Hour)
+
+	var requests atomic.Int64
+	var ranges []string
+	var ifRanges []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/file", func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		ifRanges = append(ifRanges, r.Header.Get("If-Range"))
+		w.Header().Set("ETag", `"v1"`)
+		if requests.Add(1) == 1 {
+			// 第一次请求，发送一半后中断连接
+			w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+			w.Write(content[:len(content)/2])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, r, "file", modTime, bytes.NewReader(content))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	dir := t.TempDir()
+
+	t.Run("resume", func(t *testing.T) {
+		d := &xhttpc.Download{
+			URL:      ts.URL + "/file",
+			File:     filepath.Join(dir, "a.txt"),
+			Checksum: checksum,
+		}
+		xt.NoError(t, d.Do(t.Context()))
+		got, err := os.ReadFile(d.File)
+		xt.NoError(t, err)
+		xt.Equal(t, len(got), len(content))
+		xt.True(t, bytes.Equal(got, content))
+		xt.Equal(t, ranges, []string{"", fmt.Sprintf("bytes=%d-", len(content)/2)})
+		xt.Equal(t, ifRanges, []string{"", `"v1"`})
+		_, err = os.Stat(d.File + ".part")
+		xt.True(t, os.IsNotExist(err))
+		_, err = os.Stat(d.File + ".part.meta")
+		xt.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("existing part", func(t *testing.T) {
+		garbage := bytes.Repeat([]byte("x"), 100)
+		cases := []struct {
+			name     string
+			part     []byte
+			meta     string
+			ranges   []string
+			ifRanges []string
+		}{
+			{name: "b.txt", part: content[:100], meta: `"v1"`, ranges: []string{"bytes=100-"}, ifRanges: []string{`"v1"`}},
+			// 文件已变化，服务端返回完整的内容
+			{name: "c.txt", part: garbage, meta: `"v0"`, ranges: []string{"bytes=100-"}, ifRanges: []string{`"v0"`}},
+			// 没有 meta 文件，从头开始下载
+			{name: "d.txt", part: garbage, ranges: []string{""}, ifRanges: []string{""}},
+		}
+		for _, tt := range cases {
+			t.Run(tt.name, func(t *testing.T) {
+				ranges, ifRanges = nil, nil
+				d := &xhttpc.Download{
+					URL:      ts.URL + "/file",
+					File:     filepath.Join(dir, tt.name),
+					Checksum: checksum,
+				}
+				xt.NoError(t, os.WriteFile(d.File+".part", tt.part, 0644))
+				if tt.meta != "" {
+					xt.NoError(t, os.WriteFile(d.File+".part.meta", []byte(tt.meta), 0644))
+				}
+				xt.NoError(t, d.Do(t.Context()))
+				got, err := os.ReadFile(d.File)
+				xt.NoError(t, err)
+				xt.True(t, bytes.Equal(got, content))
+				xt.Equal(t, ranges, tt.ranges)
+				xt.Equal(t, ifRanges, tt.ifRanges)
+			})
+		}
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		d := &xhttpc.Download{
+			URL:      ts.URL + "/file",
+			File:     filepath.Join(dir, "e.txt"),
+			Checksum: "md5:00000000000000000000000000000000",
+		}
+		err := d.Do(t.Context())
+		xt.True(t, errors.Is(err, xhttpc.ErrChecksumMismatch))
+		_, err = os.Stat(d.File)
+		xt.True(t, os.IsNotExist(err))
+		_, err = os.Stat(d.File + ".part")
+		xt.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		d := &xhttpc.Download{
+			URL:  ts.URL + "/missing",
+			File: filepath.Join(dir, "f.txt"),
+		}
+		err := d.Do(t.Context())
+		xt.Error(t, err)
+	})
+}
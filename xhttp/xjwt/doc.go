@@ -0,0 +1,20 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+// Package xjwt JSON Web Token（RFC 7519）的签发和校验。
+//
+// 支持的签名算法：HS256、HS384、HS512、RS256、ES256、EdDSA（Ed25519）。
+//
+// 签发：
+//
+//	s := &xjwt.Signer{Method: xjwt.HS256, Key: []byte("secret")}
+//	token, err := s.Sign(xjwt.Claims{"sub": "123", "exp": time.Now().Add(time.Hour).Unix()})
+//
+// 校验：
+//
+//	v := &xjwt.Verifier{Key: []byte("secret"), Issuer: "demo"}
+//	tk, err := v.Verify(ctx, token)
+//
+// 公钥也可以从 JWKS 中读取，见 JWKSLoader；使用 RequestSigner 可以给发出的 HTTP 请求签名
+package xjwt
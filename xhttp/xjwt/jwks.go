@@ -0,0 +1,254 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xjwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/xanygo/anygo/xhttp/xhttpc"
+	"github.com/xanygo/anygo/xnet/xservice"
+)
+
+// JWK JSON Web Key（RFC 7517）
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+
+	// RSA 公钥
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// EC、OKP 公钥
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+
+	// 对称密钥
+	K string `json:"k,omitempty"`
+}
+
+// PublicKey 将 JWK 转换为校验签名使用的公钥：*rsa.PublicKey、*ecdsa.PublicKey、ed25519.PublicKey、[]byte
+func (k *JWK) PublicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err1 := b64.DecodeString(k.N)
+		e, err2 := b64.DecodeString(k.E)
+		if err := errors.Join(err1, err2); err != nil {
+			return nil, fmt.Errorf("invalid RSA key %q: %w", k.Kid, err)
+		}
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() > 1<<31-1 || exp.Int64() < 2 {
+			return nil, fmt.Errorf("invalid RSA key %q: bad exponent", k.Kid)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported EC curve %q", k.Crv)
+		}
+		x, err1 := b64.DecodeString(k.X)
+		y, err2 := b64.DecodeString(k.Y)
+		if err := errors.Join(err1, err2); err != nil {
+			return nil, fmt.Errorf("invalid EC key %q: %w", k.Kid, err)
+		}
+		if len(x) != 32 || len(y) != 32 {
+			return nil, fmt.Errorf("invalid EC key %q: bad coordinate length", k.Kid)
+		}
+		// 使用未压缩的点格式，解析时会校验点是否在曲线上
+		point := make([]byte, 0, 65)
+		point = append(point, 4)
+		point = append(point, x...)
+		point = append(point, y...)
+		return ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve %q", k.Crv)
+		}
+		x, err := b64.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key %q", k.Kid)
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		secret, err := b64.DecodeString(k.K)
+		if err != nil || len(secret) == 0 {
+			return nil, fmt.Errorf("invalid oct key %q", k.Kid)
+		}
+		return secret, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// NewJWK 将公钥转换为 JWK，用于对外发布 JWKS，支持：*rsa.PublicKey、*ecdsa.PublicKey（P-256）、ed25519.PublicKey
+func NewJWK(kid string, key any) (JWK, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: kid,
+			Alg: RS256,
+			Use: "sig",
+			N:   b64.EncodeToString(k.N.Bytes()),
+			E:   b64.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		point, err := k.Bytes()
+		if err != nil || k.Curve != elliptic.P256() {
+			return JWK{}, ErrInvalidKeyType
+		}
+		return JWK{
+			Kty: "EC",
+			Kid: kid,
+			Alg: ES256,
+			Use: "sig",
+			Crv: "P-256",
+			X:   b64.EncodeToString(point[1:33]),
+			Y:   b64.EncodeToString(point[33:]),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: kid,
+			Alg: EdDSA,
+			Use: "sig",
+			Crv: "Ed25519",
+			X:   b64.EncodeToString(k),
+		}, nil
+	default:
+		return JWK{}, ErrInvalidKeyType
+	}
+}
+
+// JWKS JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// ParseJWKS 解析 JWKS，返回 kid 和公钥的对应关系，不支持的 key 会被忽略
+func ParseJWKS(data []byte) (StaticKeys, error) {
+	var set JWKS
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	keys := make(StaticKeys, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwks: no valid keys")
+	}
+	return keys, nil
+}
+
+var _ KeySet = (*JWKSLoader)(nil)
+
+// JWKSLoader 从文件或者 URL 加载 JWKS，并定期刷新
+//
+// 当 token 的 kid 在当前的 JWKS 中不存在时（如密钥轮换），也会重新加载，但两次加载的间隔不少于 1 分钟
+type JWKSLoader struct {
+	// File JWKS 文件路径，和 URL 二选一
+	File string
+
+	// URL JWKS 地址，如 https://example.com/.well-known/jwks.json，使用 xhttpc 读取
+	URL string
+
+	// Service 读取 URL 使用的 xservice，可选，默认为 xservice.Dummy
+	Service any
+
+	// Refresh 定期重新加载的间隔，可选，默认为 1 小时
+	Refresh time.Duration
+
+	mux    sync.Mutex
+	keys   StaticKeys
+	loaded time.Time
+}
+
+func (j *JWKSLoader) getRefresh() time.Duration {
+	if j.Refresh > 0 {
+		return j.Refresh
+	}
+	return time.Hour
+}
+
+func (j *JWKSLoader) getService() any {
+	if j.Service != nil {
+		return j.Service
+	}
+	return xservice.Dummy
+}
+
+func (j *JWKSLoader) LookupKey(ctx context.Context, kid string, alg string) (any, error) {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	since := time.Since(j.loaded)
+	if j.keys == nil || since > j.getRefresh() {
+		if err := j.load(ctx); err != nil && j.keys == nil {
+			return nil, err
+		}
+	}
+	if key, ok := j.keys[kid]; ok {
+		return key, nil
+	}
+	if since > time.Minute {
+		if err := j.load(ctx); err != nil {
+			return nil, err
+		}
+		if key, ok := j.keys[kid]; ok {
+			return key, nil
+		}
+	}
+	return nil, ErrKeyNotFound
+}
+
+// Load 立即重新加载
+func (j *JWKSLoader) Load(ctx context.Context) error {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	return j.load(ctx)
+}
+
+func (j *JWKSLoader) load(ctx context.Context) error {
+	// 加载失败时，也会更新加载时间，避免频繁加载
+	j.loaded = time.Now()
+	var data []byte
+	var err error
+	switch {
+	case j.File != "":
+		data, err = os.ReadFile(j.File)
+	case j.URL != "":
+		data, err = xhttpc.GetBody(ctx, j.getService(), j.URL)
+	default:
+		return errors.New("jwks: File and URL are both empty")
+	}
+	if err != nil {
+		return err
+	}
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return err
+	}
+	j.keys = keys
+	return nil
+}
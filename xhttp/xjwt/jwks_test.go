@@ -0,0 +1,112 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xjwt_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xanygo/anygo/xhttp/xjwt"
+	"github.com/xanygo/anygo/xt"
+)
+
+func TestJWKSLoader(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edPub, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	var set xjwt.JWKS
+	for kid, pub := range map[string]any{"rsa": &rsaKey.PublicKey, "ec": &ecKey.PublicKey, "ed": edPub} {
+		jwk, err := xjwt.NewJWK(kid, pub)
+		xt.NoError(t, err)
+		set.Keys = append(set.Keys, jwk)
+	}
+	data, err := json.Marshal(set)
+	xt.NoError(t, err)
+
+	signers := []*xjwt.Signer{
+		{Method: xjwt.RS256, Key: rsaKey, KeyID: "rsa"},
+		{Method: xjwt.ES256, Key: ecKey, KeyID: "ec"},
+		{Method: xjwt.EdDSA, Key: edKey, KeyID: "ed"},
+	}
+	check := func(t *testing.T, loader *xjwt.JWKSLoader) {
+		v := &xjwt.Verifier{Keys: loader}
+		for _, s := range signers {
+			token, err := s.Sign(xjwt.Claims{"sub": s.KeyID})
+			xt.NoError(t, err)
+			tk, err := v.Verify(context.Background(), token)
+			xt.NoError(t, err)
+			xt.Equal(t, tk.Claims.Subject(), s.KeyID)
+		}
+		token, _ := (&xjwt.Signer{Method: xjwt.EdDSA, Key: edKey, KeyID: "other"}).Sign(xjwt.Claims{})
+		_, err := v.Verify(context.Background(), token)
+		xt.ErrorIs(t, err, xjwt.ErrKeyNotFound)
+	}
+
+	t.Run("file", func(t *testing.T) {
+		fp := filepath.Join(t.TempDir(), "jwks.json")
+		xt.NoError(t, os.WriteFile(fp, data, 0644))
+		check(t, &xjwt.JWKSLoader{File: fp})
+	})
+
+	t.Run("url", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(data)
+		}))
+		defer ts.Close()
+		check(t, &xjwt.JWKSLoader{URL: ts.URL})
+	})
+}
+
+func TestRequestSigner(t *testing.T) {
+	secret := []byte("secret")
+	v := &xjwt.Verifier{Key: secret, Issuer: "svc-a", Audience: "svc-b", RequireExpires: true}
+	var sub string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("Authorization")[len("Bearer "):]
+		tk, err := v.Verify(r.Context(), token)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		sub = tk.Claims.Subject()
+	}))
+	defer ts.Close()
+
+	rs := &xjwt.RequestSigner{
+		Signer:   &xjwt.Signer{Method: xjwt.HS256, Key: secret},
+		Issuer:   "svc-a",
+		Audience: "svc-b",
+		Claims: func(req *http.Request) xjwt.Claims {
+			return xjwt.Claims{"sub": req.URL.Path}
+		},
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/a", nil)
+	resp, err := (&http.Client{Transport: rs.RoundTripper(nil)}).Do(req)
+	xt.NoError(t, err)
+	_ = resp.Body.Close()
+	xt.Equal(t, resp.StatusCode, http.StatusOK)
+	xt.Equal(t, sub, "/a")
+	xt.Empty(t, req.Header.Get("Authorization"))
+
+	req, _ = http.NewRequest(http.MethodGet, ts.URL+"/b", nil)
+	err = rs.Invoker(nil).Invoke(context.Background(), "dummy", req, func(ctx context.Context, resp *http.Response) error {
+		xt.Equal(t, resp.StatusCode, http.StatusOK)
+		return nil
+	})
+	xt.NoError(t, err)
+	xt.Equal(t, sub, "/b")
+}
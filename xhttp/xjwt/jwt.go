@@ -0,0 +1,190 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xjwt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	ErrTokenMalformed       = errors.New("jwt: token is malformed")
+	ErrSignatureInvalid     = errors.New("jwt: signature is invalid")
+	ErrAlgorithmNotAllowed  = errors.New("jwt: algorithm not allowed")
+	ErrKeyNotFound          = errors.New("jwt: key not found")
+	ErrInvalidKeyType       = errors.New("jwt: invalid key type")
+	ErrTokenExpired         = errors.New("jwt: token is expired")
+	ErrTokenNotValidYet     = errors.New("jwt: token is not valid yet")
+	ErrTokenUsedBeforeIssue = errors.New("jwt: token used before issued")
+	ErrTokenMissingExpires  = errors.New("jwt: token missing exp claim")
+	ErrInvalidIssuer        = errors.New("jwt: invalid issuer")
+	ErrInvalidAudience      = errors.New("jwt: invalid audience")
+)
+
+// Header JWT 的头部
+type Header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// Token 已解析的 JWT
+type Token struct {
+	Raw       string // 原始的 token
+	Header    Header
+	Claims    Claims
+	payload   []byte
+	signed    string // header.payload
+	signature []byte
+}
+
+// Decode 将 payload 解析到 obj 中，如自定义的 struct
+func (t *Token) Decode(obj any) error {
+	return json.Unmarshal(t.payload, obj)
+}
+
+// Claims JWT 的声明（payload），数字类型的值为 json.Number
+type Claims map[string]any
+
+func (c Claims) String(key string) string {
+	v, _ := c[key].(string)
+	return v
+}
+
+// Issuer 签发者，iss
+func (c Claims) Issuer() string {
+	return c.String("iss")
+}
+
+// Subject 主题（通常是用户 ID），sub
+func (c Claims) Subject() string {
+	return c.String("sub")
+}
+
+// ID token 的唯一 ID，jti
+func (c Claims) ID() string {
+	return c.String("jti")
+}
+
+// Audience 接收方，aud，可以是字符串或者字符串数组
+func (c Claims) Audience() []string {
+	switch v := c["aud"].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				result = append(result, str)
+			}
+		}
+		return result
+	case []string:
+		return v
+	default:
+		return nil
+	}
+}
+
+// ExpiresAt 过期时间，exp
+func (c Claims) ExpiresAt() (time.Time, bool) {
+	return c.Time("exp")
+}
+
+// NotBefore 生效时间，nbf
+func (c Claims) NotBefore() (time.Time, bool) {
+	return c.Time("nbf")
+}
+
+// IssuedAt 签发时间，iat
+func (c Claims) IssuedAt() (time.Time, bool) {
+	return c.Time("iat")
+}
+
+// Time 读取 NumericDate 类型的值（unix 时间戳，可以有小数），不存在或者不是 NumericDate 时返回 false
+func (c Claims) Time(key string) (time.Time, bool) {
+	t, ok, err := c.numericDate(key)
+	return t, ok && err == nil
+}
+
+// numericDate 读取 NumericDate 类型的值，不存在时返回 false，存在但不是 NumericDate（如字符串、null）时返回 ErrTokenMalformed
+func (c Claims) numericDate(key string) (time.Time, bool, error) {
+	val, has := c[key]
+	if !has {
+		return time.Time{}, false, nil
+	}
+	var f float64
+	switch v := val.(type) {
+	case json.Number:
+		n, err := v.Float64()
+		if err != nil {
+			return time.Time{}, true, fmt.Errorf("%w: invalid %s", ErrTokenMalformed, key)
+		}
+		f = n
+	case float64:
+		f = v
+	case int64:
+		f = float64(v)
+	case int:
+		f = float64(v)
+	default:
+		return time.Time{}, true, fmt.Errorf("%w: invalid %s", ErrTokenMalformed, key)
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*1e9)), true, nil
+}
+
+// NumericDate 将时间转换为 JWT 使用的 unix 时间戳，用于设置 exp、nbf、iat
+func NumericDate(t time.Time) int64 {
+	return t.Unix()
+}
+
+var b64 = base64.RawURLEncoding
+
+// Parse 解析 token，不会校验签名和 Claims，通常应使用 Verifier
+func Parse(token string) (*Token, error) {
+	hs, rest, ok1 := cutDot(token)
+	ps, ss, ok2 := cutDot(rest)
+	if !ok1 || !ok2 {
+		return nil, ErrTokenMalformed
+	}
+	tk := &Token{
+		Raw:    token,
+		signed: token[:len(hs)+1+len(ps)],
+	}
+	headerBytes, err := b64.DecodeString(hs)
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+	if err = json.Unmarshal(headerBytes, &tk.Header); err != nil {
+		return nil, ErrTokenMalformed
+	}
+	if tk.payload, err = b64.DecodeString(ps); err != nil {
+		return nil, ErrTokenMalformed
+	}
+	dec := json.NewDecoder(bytes.NewReader(tk.payload))
+	dec.UseNumber()
+	if err = dec.Decode(&tk.Claims); err != nil {
+		return nil, ErrTokenMalformed
+	}
+	if tk.signature, err = b64.DecodeString(ss); err != nil {
+		return nil, ErrTokenMalformed
+	}
+	return tk, nil
+}
+
+func cutDot(s string) (before string, after string, ok bool) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '.' {
+			return s[:i], s[i+1:], true
+		}
+	}
+	return s, "", false
+}
@@ -0,0 +1,128 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xjwt_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/xhttp/xjwt"
+	"github.com/xanygo/anygo/xt"
+)
+
+func TestSignVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	xt.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	xt.NoError(t, err)
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	xt.NoError(t, err)
+	secret := []byte("secret")
+
+	cases := []struct {
+		method string
+		key    any
+		pub    any
+	}{
+		{method: xjwt.HS256, key: secret, pub: secret},
+		{method: xjwt.HS384, key: secret, pub: secret},
+		{method: xjwt.HS512, key: secret, pub: secret},
+		{method: xjwt.RS256, key: rsaKey, pub: &rsaKey.PublicKey},
+		{method: xjwt.ES256, key: ecKey, pub: &ecKey.PublicKey},
+		{method: xjwt.EdDSA, key: edKey, pub: edPub},
+	}
+	ctx := context.Background()
+	exp := time.Now().Add(time.Hour).Unix()
+	for _, c := range cases {
+		t.Run(c.method, func(t *testing.T) {
+			s := &xjwt.Signer{Method: c.method, Key: c.key, KeyID: "k1"}
+			token, err := s.Sign(xjwt.Claims{"sub": "u1", "exp": exp, "aud": []string{"a", "b"}})
+			xt.NoError(t, err)
+
+			v := &xjwt.Verifier{Key: c.pub, Audience: "b", RequireExpires: true}
+			tk, err := v.Verify(ctx, token)
+			xt.NoError(t, err)
+			xt.Equal(t, tk.Header.Alg, c.method)
+			xt.Equal(t, tk.Header.Kid, "k1")
+			xt.Equal(t, tk.Claims.Subject(), "u1")
+			got, ok := tk.Claims.ExpiresAt()
+			xt.True(t, ok)
+			xt.Equal(t, got.Unix(), exp)
+
+			var obj struct {
+				Sub string `json:"sub"`
+			}
+			xt.NoError(t, tk.Decode(&obj))
+			xt.Equal(t, obj.Sub, "u1")
+
+			// 篡改 payload
+			parts := strings.Split(token, ".")
+			other, _ := s.Sign(xjwt.Claims{"sub": "u2", "exp": exp, "aud": "b"})
+			parts[1] = strings.Split(other, ".")[1]
+			_, err = v.Verify(ctx, strings.Join(parts, "."))
+			xt.ErrorIs(t, err, xjwt.ErrSignatureInvalid)
+		})
+	}
+
+	t.Run("alg confusion", func(t *testing.T) {
+		// 使用 HS256 签名的 token，不能被 RSA 公钥校验通过
+		s := &xjwt.Signer{Method: xjwt.HS256, Key: rsaKey.PublicKey.N.Bytes()}
+		token, err := s.Sign(xjwt.Claims{"sub": "u1"})
+		xt.NoError(t, err)
+		_, err = (&xjwt.Verifier{Key: &rsaKey.PublicKey}).Verify(ctx, token)
+		xt.ErrorIs(t, err, xjwt.ErrAlgorithmNotAllowed)
+
+		none := "eyJhbGciOiJub25lIn0.eyJzdWIiOiJ1MSJ9."
+		_, err = (&xjwt.Verifier{Key: secret, Methods: []string{"none", xjwt.HS256}}).Verify(ctx, none)
+		xt.ErrorIs(t, err, xjwt.ErrAlgorithmNotAllowed)
+	})
+
+	t.Run("bad key", func(t *testing.T) {
+		_, err := (&xjwt.Signer{Method: xjwt.RS256, Key: secret}).Sign(xjwt.Claims{})
+		xt.ErrorIs(t, err, xjwt.ErrInvalidKeyType)
+	})
+}
+
+func TestVerifier_claims(t *testing.T) {
+	s := &xjwt.Signer{Method: xjwt.HS256, Key: []byte("secret")}
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	v := &xjwt.Verifier{
+		Key:    []byte("secret"),
+		Issuer: "demo",
+		Leeway: time.Minute,
+		Now: func() time.Time {
+			return now
+		},
+	}
+	check := func(claims xjwt.Claims) error {
+		token, err := s.Sign(claims)
+		xt.NoError(t, err)
+		_, err = v.Verify(context.Background(), token)
+		return err
+	}
+	unix := func(d time.Duration) int64 {
+		return now.Add(d).Unix()
+	}
+	xt.NoError(t, check(xjwt.Claims{"iss": "demo", "exp": unix(time.Hour)}))
+	xt.NoError(t, check(xjwt.Claims{"iss": "demo", "exp": unix(-30 * time.Second)}))
+	xt.ErrorIs(t, check(xjwt.Claims{"iss": "demo", "exp": unix(-2 * time.Minute)}), xjwt.ErrTokenExpired)
+	xt.ErrorIs(t, check(xjwt.Claims{"iss": "demo", "nbf": unix(2 * time.Minute)}), xjwt.ErrTokenNotValidYet)
+	xt.ErrorIs(t, check(xjwt.Claims{"iss": "demo", "iat": unix(2 * time.Minute)}), xjwt.ErrTokenUsedBeforeIssue)
+	xt.ErrorIs(t, check(xjwt.Claims{"iss": "other"}), xjwt.ErrInvalidIssuer)
+	xt.ErrorIs(t, check(xjwt.Claims{"iss": "demo", "exp": "tomorrow"}), xjwt.ErrTokenMalformed)
+	xt.ErrorIs(t, check(xjwt.Claims{"iss": "demo", "exp": nil}), xjwt.ErrTokenMalformed)
+	xt.ErrorIs(t, check(xjwt.Claims{"iss": "demo", "nbf": "now"}), xjwt.ErrTokenMalformed)
+	xt.ErrorIs(t, check(xjwt.Claims{"iss": "demo", "iat": true}), xjwt.ErrTokenMalformed)
+
+	_, err := v.Verify(context.Background(), "abc.def")
+	xt.ErrorIs(t, err, xjwt.ErrTokenMalformed)
+}
@@ -0,0 +1,154 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xjwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"math/big"
+)
+
+// 支持的签名算法
+const (
+	HS256 = "HS256"
+	HS384 = "HS384"
+	HS512 = "HS512"
+	RS256 = "RS256"
+	ES256 = "ES256"
+	EdDSA = "EdDSA"
+)
+
+// sign 使用私钥（HS 算法为 []byte 类型的密钥）签名
+func sign(method string, data []byte, key any) ([]byte, error) {
+	switch method {
+	case HS256, HS384, HS512:
+		secret, ok := key.([]byte)
+		if !ok || len(secret) == 0 {
+			return nil, ErrInvalidKeyType
+		}
+		mac := hmac.New(hmacHash(method), secret)
+		mac.Write(data)
+		return mac.Sum(nil), nil
+	case RS256:
+		pk, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, ErrInvalidKeyType
+		}
+		sum := sha256.Sum256(data)
+		return rsa.SignPKCS1v15(nil, pk, crypto.SHA256, sum[:])
+	case ES256:
+		pk, ok := key.(*ecdsa.PrivateKey)
+		if !ok || pk.Curve != elliptic.P256() {
+			return nil, ErrInvalidKeyType
+		}
+		sum := sha256.Sum256(data)
+		r, s, err := ecdsa.Sign(rand.Reader, pk, sum[:])
+		if err != nil {
+			return nil, err
+		}
+		// 签名为 32 字节的 R 和 32 字节的 S 的拼接（RFC 7518 3.4）
+		out := make([]byte, 64)
+		r.FillBytes(out[:32])
+		s.FillBytes(out[32:])
+		return out, nil
+	case EdDSA:
+		pk, ok := key.(ed25519.PrivateKey)
+		if !ok || len(pk) != ed25519.PrivateKeySize {
+			return nil, ErrInvalidKeyType
+		}
+		return ed25519.Sign(pk, data), nil
+	default:
+		return nil, ErrAlgorithmNotAllowed
+	}
+}
+
+// verify 使用公钥（HS 算法为 []byte 类型的密钥）校验签名
+func verify(method string, data []byte, sig []byte, key any) error {
+	switch method {
+	case HS256, HS384, HS512:
+		secret, ok := key.([]byte)
+		if !ok || len(secret) == 0 {
+			return ErrInvalidKeyType
+		}
+		mac := hmac.New(hmacHash(method), secret)
+		mac.Write(data)
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return ErrSignatureInvalid
+		}
+		return nil
+	case RS256:
+		pk, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrInvalidKeyType
+		}
+		sum := sha256.Sum256(data)
+		if rsa.VerifyPKCS1v15(pk, crypto.SHA256, sum[:], sig) != nil {
+			return ErrSignatureInvalid
+		}
+		return nil
+	case ES256:
+		pk, ok := key.(*ecdsa.PublicKey)
+		if !ok || pk.Curve != elliptic.P256() {
+			return ErrInvalidKeyType
+		}
+		if len(sig) != 64 {
+			return ErrSignatureInvalid
+		}
+		sum := sha256.Sum256(data)
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(pk, sum[:], r, s) {
+			return ErrSignatureInvalid
+		}
+		return nil
+	case EdDSA:
+		pk, ok := key.(ed25519.PublicKey)
+		if !ok || len(pk) != ed25519.PublicKeySize {
+			return ErrInvalidKeyType
+		}
+		if !ed25519.Verify(pk, data, sig) {
+			return ErrSignatureInvalid
+		}
+		return nil
+	default:
+		return ErrAlgorithmNotAllowed
+	}
+}
+
+func hmacHash(method string) func() hash.Hash {
+	switch method {
+	case HS384:
+		return sha512.New384
+	case HS512:
+		return sha512.New
+	default:
+		return sha256.New
+	}
+}
+
+// methodsForKey 依据公钥的类型，返回可以使用的签名算法
+func methodsForKey(key any) []string {
+	switch k := key.(type) {
+	case []byte:
+		return []string{HS256, HS384, HS512}
+	case *rsa.PublicKey:
+		return []string{RS256}
+	case *ecdsa.PublicKey:
+		if k.Curve == elliptic.P256() {
+			return []string{ES256}
+		}
+	case ed25519.PublicKey:
+		return []string{EdDSA}
+	}
+	return nil
+}
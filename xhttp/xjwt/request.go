@@ -0,0 +1,124 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xjwt
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"maps"
+	"net/http"
+	"time"
+
+	"github.com/xanygo/anygo/xhttp/xhttpc"
+	"github.com/xanygo/anygo/xnet/xrpc"
+)
+
+// RequestSigner 给发出的 HTTP 请求签发 JWT，并设置请求头 Authorization: Bearer {token}，
+// 每个请求都会签发新的 token（包括 iat、exp、jti）。
+//
+// 可以直接调用 SignRequest，或者包装 xhttpc.Invoker、http.RoundTripper 使用：
+//
+//	rs := &xjwt.RequestSigner{Signer: signer, Issuer: "svc-a", Audience: "svc-b"}
+//	invoker := rs.Invoker(nil)
+//	err := invoker.Invoke(ctx, "svc-b", req, handler)
+type RequestSigner struct {
+	// Signer 必填
+	Signer *Signer
+
+	// Issuer 可选，iss
+	Issuer string
+
+	// Subject 可选，sub
+	Subject string
+
+	// Audience 可选，aud
+	Audience string
+
+	// TTL 可选，token 的有效期，默认为 5 分钟
+	TTL time.Duration
+
+	// Claims 可选，额外的声明
+	Claims func(req *http.Request) Claims
+}
+
+func (rs *RequestSigner) getTTL() time.Duration {
+	if rs.TTL > 0 {
+		return rs.TTL
+	}
+	return 5 * time.Minute
+}
+
+// Token 签发一个 token
+func (rs *RequestSigner) Token(req *http.Request) (string, error) {
+	claims := Claims{}
+	if rs.Claims != nil {
+		maps.Copy(claims, rs.Claims(req))
+	}
+	now := time.Now()
+	claims["iat"] = NumericDate(now)
+	claims["exp"] = NumericDate(now.Add(rs.getTTL()))
+	claims["jti"] = newID()
+	if rs.Issuer != "" {
+		claims["iss"] = rs.Issuer
+	}
+	if rs.Subject != "" {
+		claims["sub"] = rs.Subject
+	}
+	if rs.Audience != "" {
+		claims["aud"] = rs.Audience
+	}
+	return rs.Signer.Sign(claims)
+}
+
+// SignRequest 签发 token，并设置到请求头 Authorization 中
+func (rs *RequestSigner) SignRequest(req *http.Request) error {
+	token, err := rs.Token(req)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Invoker 包装 xhttpc.Invoker，发送请求前先签名，next 为 nil 时使用 xhttpc.Invoke
+func (rs *RequestSigner) Invoker(next xhttpc.Invoker) xhttpc.Invoker {
+	if next == nil {
+		next = xhttpc.InvokeFunc(xhttpc.Invoke)
+	}
+	return xhttpc.InvokeFunc(func(ctx context.Context, service any, req *http.Request, handler xhttpc.HandlerFunc, opts ...xrpc.Option) error {
+		if err := rs.SignRequest(req); err != nil {
+			return err
+		}
+		return next.Invoke(ctx, service, req, handler, opts...)
+	})
+}
+
+// RoundTripper 包装 http.RoundTripper，发送请求前先签名，next 为 nil 时使用 http.DefaultTransport
+func (rs *RequestSigner) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		// RoundTripper 不应修改传入的请求
+		req = req.Clone(req.Context())
+		if err := rs.SignRequest(req); err != nil {
+			return nil, err
+		}
+		return next.RoundTrip(req)
+	})
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func newID() string {
+	bf := make([]byte, 16)
+	_, _ = rand.Read(bf)
+	return hex.EncodeToString(bf)
+}
@@ -0,0 +1,47 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xjwt
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Signer 签发 JWT
+type Signer struct {
+	// Method 签名算法，必填，如 HS256
+	Method string
+
+	// Key 签名的密钥，必填，和 Method 对应的类型为：
+	//  HS256、HS384、HS512: []byte
+	//  RS256: *rsa.PrivateKey
+	//  ES256: *ecdsa.PrivateKey（P-256）
+	//  EdDSA: ed25519.PrivateKey
+	Key any
+
+	// KeyID 可选，会设置到 Header 的 kid 字段，校验方可以依据此值从 JWKS 中查找公钥
+	KeyID string
+}
+
+// Sign 签发 token，claims 可以是 Claims 或者其他可以被 JSON 序列化的对象（如自定义的 struct）
+func (s *Signer) Sign(claims any) (string, error) {
+	if s.Method == "" {
+		return "", errors.New("jwt: Signer.Method is empty")
+	}
+	header, err := json.Marshal(Header{Alg: s.Method, Typ: "JWT", Kid: s.KeyID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := b64.EncodeToString(header) + "." + b64.EncodeToString(payload)
+	sig, err := sign(s.Method, []byte(signed), s.Key)
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + b64.EncodeToString(sig), nil
+}
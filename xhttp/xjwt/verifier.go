@@ -0,0 +1,133 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xjwt
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// KeySet 依据 Header 中的 kid 和 alg 查找校验签名的公钥
+type KeySet interface {
+	LookupKey(ctx context.Context, kid string, alg string) (any, error)
+}
+
+// StaticKeys 静态的公钥集合，key 是 kid
+type StaticKeys map[string]any
+
+func (s StaticKeys) LookupKey(ctx context.Context, kid string, alg string) (any, error) {
+	if key, ok := s[kid]; ok {
+		return key, nil
+	}
+	return nil, ErrKeyNotFound
+}
+
+// Verifier 校验 JWT 的签名和声明
+type Verifier struct {
+	// Key 校验签名的公钥（HS 算法为 []byte 类型的密钥），和 Keys 二选一
+	//  HS256、HS384、HS512: []byte
+	//  RS256: *rsa.PublicKey
+	//  ES256: *ecdsa.PublicKey（P-256）
+	//  EdDSA: ed25519.PublicKey
+	Key any
+
+	// Keys 依据 kid 查找公钥，如 JWKSLoader，和 Key 二选一，Key 不为空时优先使用 Key
+	Keys KeySet
+
+	// Methods 可选，允许的签名算法，默认为和公钥类型匹配的算法。
+	// 签名算法 none 始终是不允许的
+	Methods []string
+
+	// Issuer 可选，不为空时，iss 需要和此值相等
+	Issuer string
+
+	// Audience 可选，不为空时，aud 需要包含此值
+	Audience string
+
+	// Leeway 可选，校验 exp、nbf、iat 时允许的时钟偏差
+	Leeway time.Duration
+
+	// RequireExpires 是否要求必须有 exp 声明
+	RequireExpires bool
+
+	// Now 可选，获取当前时间，默认为 time.Now
+	Now func() time.Time
+}
+
+func (v *Verifier) now() time.Time {
+	if v.Now != nil {
+		return v.Now()
+	}
+	return time.Now()
+}
+
+// Verify 解析并校验 token，校验签名和 exp、nbf、iat、iss、aud 声明
+func (v *Verifier) Verify(ctx context.Context, token string) (*Token, error) {
+	tk, err := Parse(token)
+	if err != nil {
+		return nil, err
+	}
+	key := v.Key
+	if key == nil {
+		if v.Keys == nil {
+			return nil, ErrKeyNotFound
+		}
+		key, err = v.Keys.LookupKey(ctx, tk.Header.Kid, tk.Header.Alg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	methods := v.Methods
+	if len(methods) == 0 {
+		methods = methodsForKey(key)
+	}
+	if tk.Header.Alg == "" || tk.Header.Alg == "none" || !slices.Contains(methods, tk.Header.Alg) {
+		return nil, ErrAlgorithmNotAllowed
+	}
+	if err = verify(tk.Header.Alg, []byte(tk.signed), tk.signature, key); err != nil {
+		return nil, err
+	}
+	if err = v.checkClaims(tk.Claims); err != nil {
+		return nil, err
+	}
+	return tk, nil
+}
+
+func (v *Verifier) checkClaims(c Claims) error {
+	now := v.now()
+	exp, ok, err := c.numericDate("exp")
+	if err != nil {
+		return err
+	}
+	if ok {
+		if !now.Before(exp.Add(v.Leeway)) {
+			return ErrTokenExpired
+		}
+	} else if v.RequireExpires {
+		return ErrTokenMissingExpires
+	}
+	nbf, ok, err := c.numericDate("nbf")
+	if err != nil {
+		return err
+	}
+	if ok && now.Add(v.Leeway).Before(nbf) {
+		return ErrTokenNotValidYet
+	}
+	iat, ok, err := c.numericDate("iat")
+	if err != nil {
+		return err
+	}
+	if ok && now.Add(v.Leeway).Before(iat) {
+		return ErrTokenUsedBeforeIssue
+	}
+	if v.Issuer != "" && c.Issuer() != v.Issuer {
+		return ErrInvalidIssuer
+	}
+	if v.Audience != "" && !slices.Contains(c.Audience(), v.Audience) {
+		return ErrInvalidAudience
+	}
+	return nil
+}
@@ -182,6 +182,13 @@ func (lw *LockedWriter[W]) Write(p []byte) (n int, err error) {
 	return lw.Writer.Write(p)
 }
 
+// Flush 若 Writer 支持 Flush，在锁内调用
+func (lw *LockedWriter[W]) Flush() error {
+	lw.mux.Lock()
+	defer lw.mux.Unlock()
+	return TryFlush(lw.Writer)
+}
+
 func (lw *LockedWriter[W]) WithLock(fn func(w W) error) error {
 	lw.mux.Lock()
 	defer lw.mux.Unlock()
@@ -86,11 +86,12 @@ func (w *Watcher) Start() error {
 	if w.started {
 		return errors.New("already started")
 	}
+	// errors 需要在 timer 启动之前创建，scan 会读取它
+	w.errors = make(chan error)
 	w.timer = &xpp.Interval{}
 	w.timer.Add(w.scan)
 	w.timer.Start(w.getInterval())
 	w.started = true
-	w.errors = make(chan error)
 	return nil
 }
 
@@ -85,6 +85,20 @@ func RegisterGroupPool(name string, new func(opt *xpool.Option, cc Connector) Gr
 	return true
 }
 
+// HasGroupPool 判断是否已注册名称为 name 的 GroupPool
+func HasGroupPool(name string) bool {
+	_, ok := groupPoolFactory[strings.ToUpper(name)]
+	return ok
+}
+
+var alpnProtos = map[string][]string{}
+
+// RegisterALPN 注册协议在 TLS 握手时，通过 ALPN 协商的协议列表（如 HTTP2 协议对应 "h2"），
+// 当 tls.Config 未配置 NextProtos 时，会使用协议 ( xoption.Protocol ) 对应的值
+func RegisterALPN(protocol string, protos ...string) {
+	alpnProtos[strings.ToUpper(protocol)] = protos
+}
+
 const (
 	Long  = "Long"
 	Short = "Short"
@@ -8,6 +8,7 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"strings"
 
 	"github.com/xanygo/anygo/ds/xmetric"
 	"github.com/xanygo/anygo/ds/xoption"
@@ -49,6 +50,9 @@ func tlsUpgrade(ctx context.Context, conn *xnet.ConnNode, opt xoption.Reader, ta
 	if tc.MinVersion == 0 {
 		tc.MinVersion = tls.VersionTLS12
 	}
+	if len(tc.NextProtos) == 0 && opt != nil {
+		tc.NextProtos = alpnProtos[strings.ToUpper(xoption.Protocol(opt))]
+	}
 	span.SetAttributes(
 		xmetric.AnyAttr("ServerName", tc.ServerName),
 		xmetric.AnyAttr("SkipVerify", tc.InsecureSkipVerify),
@@ -6,7 +6,6 @@ package xjsonrpc2
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -17,6 +16,7 @@ import (
 
 	"github.com/xanygo/anygo/ds/xoption"
 	"github.com/xanygo/anygo/safely"
+	"github.com/xanygo/anygo/xcodec"
 	"github.com/xanygo/anygo/xerror"
 	"github.com/xanygo/anygo/xio"
 	"github.com/xanygo/anygo/xnet/xrpc"
@@ -64,6 +64,10 @@ func (c *ClientRequest[P]) WriteTo(ctx context.Context, w io.Writer, opt xoption
 	if err != nil {
 		return err
 	}
+	framer, err := OptFramer(opt)
+	if err != nil {
+		return err
+	}
 
 	if ds, ok := w.(xio.WriteDeadlineSetter); ok {
 		timeout := xoption.WriteTimeout(opt)
@@ -72,9 +76,7 @@ func (c *ClientRequest[P]) WriteTo(ctx context.Context, w io.Writer, opt xoption
 		}
 		defer ds.SetWriteDeadline(time.Time{})
 	}
-
-	_, err = rr.WriteTo(w)
-	return err
+	return writeMessage(w, framer, rr)
 }
 
 var _ xrpc.Response = (*ClientResponse[any])(nil)
@@ -97,10 +99,21 @@ func (c *ClientResponse[P]) LoadFrom(_ context.Context, req xrpc.Request, r io.R
 		return nil
 	}
 
+	framer, err := OptFramer(opt)
+	if err != nil {
+		return err
+	}
+	if ds, ok := r.(xio.ReadDeadlineSetter); ok {
+		timeout := xoption.ReadTimeout(opt)
+		if err = ds.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+			return err
+		}
+		defer ds.SetReadDeadline(time.Time{})
+	}
+	bio := bufio.NewReader(io.LimitReader(r, xoption.MaxResponseSize(opt)))
 	var resp *Response
-	var err error
 	for {
-		resp, err = c.readOne(r, opt)
+		resp, err = readFramedResponse(bio, framer)
 		if err != nil {
 			return err
 		}
@@ -121,17 +134,12 @@ func (c *ClientResponse[P]) LoadFrom(_ context.Context, req xrpc.Request, r io.R
 	return resp.DecodeResult(&c.Result)
 }
 
-func (c *ClientResponse[P]) readOne(r io.Reader, opt xoption.Reader) (*Response, error) {
-	if ds, ok := r.(xio.ReadDeadlineSetter); ok {
-		timeout := xoption.ReadTimeout(opt)
-		if err := ds.SetReadDeadline(time.Now().Add(timeout)); err != nil {
-			return nil, err
-		}
-		defer ds.SetReadDeadline(time.Time{})
+func readFramedResponse(br *bufio.Reader, framer Framer) (*Response, error) {
+	bf, err := framer.ReadMessage(br)
+	if err != nil {
+		return nil, err
 	}
-	maxSize := xoption.MaxResponseSize(opt)
-	bio := bufio.NewReader(io.LimitReader(r, maxSize))
-	return ReadResponse(bio)
+	return parserResponse(bf)
 }
 
 func (c *ClientResponse[P]) ErrCode() int64 {
@@ -185,25 +193,25 @@ func (crs ClientRequests[P]) WriteTo(_ context.Context, w io.Writer, opt xoption
 	if len(crs) == 0 {
 		return errors.New("empty request")
 	}
-	bf := bytes.NewBuffer(nil)
-	bf.WriteString("[")
-	for index, cr := range crs {
+	framer, err := OptFramer(opt)
+	if err != nil {
+		return err
+	}
+	items := make([]envelope, 0, len(crs))
+	for _, cr := range crs {
 		rr := &Request{
 			ID:     cr.ID,
 			Method: cr.Method,
 		}
-		err := rr.WithParams(cr.Params)
-		if err == nil {
-			_, err = rr.WriteTo(bf)
-		}
-		if err != nil {
+		if err = rr.WithParams(cr.Params); err != nil {
 			return err
 		}
-		if index < len(crs)-1 {
-			bf.WriteString(",")
-		}
+		items = append(items, rr.envelope())
+	}
+	bf, err := xcodec.JSON.Encode(items)
+	if err != nil {
+		return err
 	}
-	bf.WriteString("]\n")
 
 	if ds, ok := w.(xio.WriteDeadlineSetter); ok {
 		timeout := xoption.WriteTimeout(opt)
@@ -212,9 +220,7 @@ func (crs ClientRequests[P]) WriteTo(_ context.Context, w io.Writer, opt xoption
 		}
 		defer ds.SetWriteDeadline(time.Time{})
 	}
-
-	_, err := w.Write(bf.Bytes())
-	return err
+	return framer.WriteMessage(w, bf)
 }
 
 func (crs ClientRequests[P]) NoReply() bool {
@@ -244,15 +250,23 @@ func (c *ClientResponses) LoadFrom(_ context.Context, req xrpc.Request, r io.Rea
 	if nr, ok := req.(noReply); ok && nr.NoReply() {
 		return nil
 	}
+	framer, err := OptFramer(opt)
+	if err != nil {
+		return err
+	}
 	if ds, ok := r.(xio.ReadDeadlineSetter); ok {
 		timeout := xoption.ReadTimeout(opt)
-		if err := ds.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		if err = ds.SetReadDeadline(time.Now().Add(timeout)); err != nil {
 			return err
 		}
 		defer ds.SetReadDeadline(time.Time{})
 	}
 	bio := bufio.NewReader(io.LimitReader(r, xoption.MaxResponseSize(opt)))
-	c.values, _, c.err = ReadResponses(bio)
+	bf, err := framer.ReadMessage(bio)
+	if err == nil {
+		c.values, _, err = parseResponses(bf)
+	}
+	c.err = err
 	return c.err
 }
 
@@ -282,15 +296,27 @@ func (c *ClientResponses) Unwrap() any {
 type Client struct {
 	Service    any
 	RPCOptions []xrpc.Option
+
+	// Framer 可选，分帧方式，为空时，使用 service 配置中的 Framing，默认为 NewlineFramer
+	Framer Framer
 }
 
 func (c *Client) Clone() *Client {
 	return &Client{
 		Service:    c.Service,
 		RPCOptions: slices.Clone(c.RPCOptions),
+		Framer:     c.Framer,
 	}
 }
 
+func (c *Client) rpcOptions() []xrpc.Option {
+	if c.Framer == nil {
+		return c.RPCOptions
+	}
+	opts := slices.Clone(c.RPCOptions)
+	return append(opts, RPCOptFramer(c.Framer))
+}
+
 // InvokeUnary 发送同步的一元请求
 //
 // 该方法不可以和 Stream 同时使用，否则收到的消息可能会出现混乱
@@ -301,7 +327,7 @@ func (c *Client) InvokeUnary(ctx context.Context, req *Request) (*Response, erro
 		Params: req.Params,
 	}
 	cres := &ClientResponse[json.RawMessage]{}
-	err := xrpc.Invoke(ctx, c.Service, creq, cres, c.RPCOptions...)
+	err := xrpc.Invoke(ctx, c.Service, creq, cres, c.rpcOptions()...)
 	if err != nil {
 		return nil, err
 	}
@@ -317,7 +343,7 @@ func (c *Client) SendNotify(ctx context.Context, req *Request) error {
 		Method: req.Method,
 		Params: req.Params,
 	}
-	return xrpc.Invoke(ctx, c.Service, creq, xrpc.NoResponse(), c.RPCOptions...)
+	return xrpc.Invoke(ctx, c.Service, creq, xrpc.NoResponse(), c.rpcOptions()...)
 }
 
 // Stream 采用 stream 模式交互，异步的发送请求，异步读取响应
@@ -329,7 +355,7 @@ func (c *Client) Stream(ctx context.Context, sender <-chan *Request) (receiver <
 	resp := &chatClientResponse{
 		ch: rec,
 	}
-	opts := slices.Clone(c.RPCOptions)
+	opts := slices.Clone(c.rpcOptions())
 	opts = append(opts, xrpc.OptFullDuplex(true))
 	go safely.Run(func() {
 		defer close(rec)
@@ -373,6 +399,10 @@ func (c *chatClientRequest) WriteTo(ctx context.Context, w io.Writer, opt xoptio
 }
 
 func (c *chatClientRequest) sendOne(req *Request, w io.Writer, opt xoption.Reader) error {
+	framer, err := OptFramer(opt)
+	if err != nil {
+		return err
+	}
 	if ds, ok := w.(xio.WriteDeadlineSetter); ok {
 		timeout := xoption.WriteTimeout(opt)
 		if err := ds.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
@@ -380,7 +410,7 @@ func (c *chatClientRequest) sendOne(req *Request, w io.Writer, opt xoption.Reade
 		}
 		defer ds.SetWriteDeadline(time.Time{})
 	}
-	return req.Write(w)
+	return writeMessage(w, framer, req)
 }
 
 var _ xrpc.Response = (*chatClientResponse)(nil)
@@ -401,6 +431,10 @@ func (c *chatClientResponse) LoadFrom(ctx context.Context, req xrpc.Request, r i
 			rc.Close()
 		}
 	})
+	framer, err := OptFramer(opt)
+	if err != nil {
+		return err
+	}
 	br := bufio.NewReader(r)
 	for {
 		select {
@@ -408,7 +442,11 @@ func (c *chatClientResponse) LoadFrom(ctx context.Context, req xrpc.Request, r i
 			return context.Cause(ctx)
 		default:
 		}
-		rr, _, err := ReadResponses(br) // 这里由于是同步的，会卡住，该如何实现
+		bf, err := framer.ReadMessage(br) // 这里由于是同步的，会卡住，该如何实现
+		if err != nil {
+			return err
+		}
+		rr, _, err := parseResponses(bf)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,441 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xjsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/xanygo/anygo/ds/xsync"
+	"github.com/xanygo/anygo/safely"
+	"github.com/xanygo/anygo/xcodec"
+)
+
+// MethodCancelRequest 取消请求的通知（同 LSP），参数为 {"id": 请求的 ID}
+const MethodCancelRequest = "$/cancelRequest"
+
+// ErrConnClosed 连接已关闭
+var ErrConnClosed = errors.New("jsonrpc2: connection closed")
+
+// CancelParams $/cancelRequest 的参数
+type CancelParams struct {
+	ID json.RawMessage `json:"id"`
+}
+
+// NewConn 在 rwc 上创建双向的连接，并开始读取消息，消息以换行符分隔，等同于 NewConnWithFramer(ctx, rwc, NewlineFramer, handler)
+func NewConn(ctx context.Context, rwc io.ReadWriteCloser, handler Handler) *Conn {
+	return NewConnWithFramer(ctx, rwc, NewlineFramer, handler)
+}
+
+// NewConnWithFramer 在 rwc 上创建双向的连接，并开始读取消息，消息使用 framer 分帧。
+//
+// handler 用于处理对端发送的请求和通知，为 nil 时，请求都返回 method not found。
+// 每个请求都在独立的 goroutine 中处理，handler 可以使用 ConnFromContext 获取连接，以向对端发起调用。
+// handler 中的 panic 会被 recover，并返回 internal error 的响应
+func NewConnWithFramer(ctx context.Context, rwc io.ReadWriteCloser, framer Framer, handler Handler) *Conn {
+	return NewConnWithOption(ctx, NewFramedStream(rwc, framer), ConnOption{Handler: handler})
+}
+
+// ConnOption NewConnWithOption 的参数
+type ConnOption struct {
+	// Handler 可选，处理对端发送的请求和通知，为 nil 时，请求都返回 method not found
+	Handler Handler
+
+	// CancelRequest 可选，Call 的 ctx 在收到响应之前结束时，用于创建通知对端取消请求的通知，返回 nil 时不通知。
+	// 默认为 $/cancelRequest 通知
+	CancelRequest func(id ID, method string, cause error) (*Request, error)
+
+	// Ordered 可选，返回 true 的通知不会并发处理，而是在读取消息的 goroutine 中按收到的顺序交由 Handler 处理（如进度通知）。
+	// 这些通知的 Handler 需要尽快返回，且不能调用 Call（会死锁）
+	Ordered func(req *Request) bool
+}
+
+// NewConnWithOption 在 stream 上创建双向的连接，并开始读取消息，其他同 NewConnWithFramer
+func NewConnWithOption(ctx context.Context, stream Stream, opt ConnOption) *Conn {
+	handler := opt.Handler
+	if handler == nil {
+		handler = HandlerFunc(NotFound)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	c := &Conn{
+		stream:   stream,
+		handler:  handler,
+		opt:      opt,
+		ctx:      ctx,
+		cancel:   cancel,
+		pending:  make(map[string]chan *Response),
+		incoming: make(map[string]context.CancelCauseFunc),
+		done:     make(chan struct{}),
+	}
+	go c.readLoop()
+	return c
+}
+
+// Conn 双向的 JSON-RPC 2.0 连接，连接的双方都可以发起调用和发送通知（如 LSP、MCP、CDP 等协议）：
+//  1. 使用 Call 发起调用，使用 Notify 发送通知，可以并发调用
+//  2. 对端的请求交由 Handler 并发处理，支持对端使用 $/cancelRequest 取消请求
+//  3. 所有的消息都是完整写入的，多个消息之间不会交错
+//  4. 连接关闭（或者读取失败）后，等待中的调用都会返回错误，正在处理的请求的 ctx 会被取消
+type Conn struct {
+	stream  Stream
+	handler Handler
+	opt     ConnOption
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+
+	lastID atomic.Int64
+
+	mux      sync.Mutex
+	pending  map[string]chan *Response          // 发出的调用，等待响应
+	incoming map[string]context.CancelCauseFunc // 收到的请求，正在处理中
+
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	done      chan struct{}
+	err       xsync.Value[error]
+}
+
+type ctxKeyConn struct{}
+
+// ConnFromContext 在 Handler 中获取当前的连接
+func ConnFromContext(ctx context.Context) *Conn {
+	c, _ := ctx.Value(ctxKeyConn{}).(*Conn)
+	return c
+}
+
+// Call 向对端发起调用，并将结果解析到 result（为 nil 时，不解析）。
+// 若 ctx 在收到响应之前结束，会发送 $/cancelRequest 通知对端
+func (c *Conn) Call(ctx context.Context, method string, params any, result any) error {
+	id := Int64ID(c.lastID.Add(1))
+	req, err := NewRequest(id, method, params)
+	if err != nil {
+		return err
+	}
+	key := string(id.Bytes())
+	ch := make(chan *Response, 1)
+	c.mux.Lock()
+	if c.isClosed() {
+		c.mux.Unlock()
+		return c.Err()
+	}
+	c.pending[key] = ch
+	c.mux.Unlock()
+	defer func() {
+		c.mux.Lock()
+		delete(c.pending, key)
+		c.mux.Unlock()
+	}()
+
+	if err = c.write(ctx, req); err != nil {
+		return err
+	}
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil {
+			return nil
+		}
+		return resp.DecodeResult(result)
+	case <-ctx.Done():
+		c.cancelRequest(id, method, context.Cause(ctx))
+		return context.Cause(ctx)
+	case <-c.done:
+		return c.Err()
+	}
+}
+
+// Notify 向对端发送通知
+func (c *Conn) Notify(ctx context.Context, method string, params any) error {
+	req, err := NewRequest(nil, method, params)
+	if err != nil {
+		return err
+	}
+	return c.write(ctx, req)
+}
+
+// cancelRequest 通知对端取消请求，见 ConnOption.CancelRequest
+func (c *Conn) cancelRequest(id ID, method string, cause error) {
+	var req *Request
+	var err error
+	if c.opt.CancelRequest != nil {
+		req, err = c.opt.CancelRequest(id, method, cause)
+	} else {
+		req, err = NewRequest(nil, MethodCancelRequest, &CancelParams{ID: id.Bytes()})
+	}
+	if err != nil || req == nil {
+		return
+	}
+	_ = c.write(c.ctx, req)
+}
+
+func (c *Conn) write(ctx context.Context, msg interface{ envelope() envelope }) error {
+	bf, err := xcodec.JSON.Encode(msg.envelope())
+	if err != nil {
+		return err
+	}
+	return c.writeRaw(ctx, bf)
+}
+
+func (c *Conn) writeRaw(ctx context.Context, bf []byte) error {
+	if c.isClosed() {
+		return c.Err()
+	}
+	return c.stream.Write(ctx, bf)
+}
+
+func (c *Conn) isClosed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Done 连接关闭后，返回的 chan 会被关闭
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err 返回连接关闭的原因，主动调用 Close 关闭时为 ErrConnClosed，对端关闭时为 io.EOF
+func (c *Conn) Err() error {
+	if err := c.err.Load(); err != nil {
+		return err
+	}
+	return ErrConnClosed
+}
+
+// Close 关闭连接，并等待所有正在处理的请求结束。
+// 由于会等待 Handler 结束，不能在 Handler 中调用（会死锁），Handler 中应该使用 CloseNoWait
+func (c *Conn) Close() error {
+	err := c.closeWithErr(ErrConnClosed)
+	c.wg.Wait()
+	return err
+}
+
+// CloseNoWait 关闭连接，不等待正在处理的请求结束（请求的 ctx 会被取消），可以在 Handler 中调用
+func (c *Conn) CloseNoWait() error {
+	return c.closeWithErr(ErrConnClosed)
+}
+
+func (c *Conn) closeWithErr(cause error) error {
+	var err error
+	c.closeOnce.Do(func() {
+		c.err.Store(cause)
+		c.mux.Lock()
+		close(c.done)
+		c.mux.Unlock()
+		c.cancel(cause)
+		err = c.stream.Close()
+	})
+	return err
+}
+
+func (c *Conn) readLoop() {
+	for {
+		bf, err := c.stream.Read(c.ctx)
+		if err != nil {
+			_ = c.closeWithErr(err)
+			return
+		}
+		if len(bf) == 0 {
+			continue
+		}
+		if bf[0] != '[' {
+			c.dispatch(bf)
+			continue
+		}
+		var batch []json.RawMessage
+		if err = xcodec.JSON.Decode(bf, &batch); err != nil {
+			_ = c.sendError(errors.Join(ErrParse, err))
+			continue
+		}
+		c.dispatchBatch(batch)
+	}
+}
+
+func (c *Conn) sendError(err error) error {
+	resp, e := NewResponse(nil, nil, err)
+	if e != nil {
+		return e
+	}
+	return c.write(c.ctx, resp)
+}
+
+func (c *Conn) dispatch(bf []byte) {
+	req, resp, err := ParseMessage(bf)
+	if err != nil {
+		_ = c.sendError(err)
+		return
+	}
+	if resp != nil {
+		c.handleResponse(resp)
+		return
+	}
+	if req.Method == MethodCancelRequest {
+		c.handleCancel(req)
+		return
+	}
+	if req.NoReply() && c.opt.Ordered != nil && c.opt.Ordered(req) {
+		c.handleRequest(req, &connWriter{conn: c})
+		return
+	}
+	c.goHandle(func() {
+		c.handleRequest(req, &connWriter{conn: c})
+	})
+}
+
+// goHandle 异步执行请求处理函数，连接已关闭时不再执行。
+// 和 closeWithErr 在同一把锁下判断，保证 Close 中的 wg.Wait 之后不会再有 wg.Go
+func (c *Conn) goHandle(fn func()) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.isClosed() {
+		return
+	}
+	c.wg.Go(fn)
+}
+
+func (c *Conn) dispatchBatch(batch []json.RawMessage) {
+	var reqs []*Request
+	for _, msg := range batch {
+		req, resp, err := ParseMessage(msg)
+		if err != nil {
+			_ = c.sendError(err)
+			continue
+		}
+		if resp != nil {
+			c.handleResponse(resp)
+			continue
+		}
+		reqs = append(reqs, req)
+	}
+	if len(reqs) == 0 {
+		return
+	}
+	c.goHandle(func() {
+		resps := make([]json.RawMessage, 0, len(reqs))
+		var mux sync.Mutex
+		var wg sync.WaitGroup
+		for _, req := range reqs {
+			wg.Go(func() {
+				bw := &batchWriter{notifier: &connWriter{conn: c}}
+				c.handleRequest(req, bw)
+				if bw.resp != nil {
+					mux.Lock()
+					resps = append(resps, bw.resp)
+					mux.Unlock()
+				}
+			})
+		}
+		wg.Wait()
+		if len(resps) == 0 {
+			return
+		}
+		bf, err := xcodec.JSON.Encode(resps)
+		if err != nil {
+			return
+		}
+		_ = c.writeRaw(c.ctx, bf)
+	})
+}
+
+func (c *Conn) handleResponse(resp *Response) {
+	c.mux.Lock()
+	ch := c.pending[string(idBytes(resp.ID))]
+	c.mux.Unlock()
+	if ch == nil {
+		return
+	}
+	select {
+	case ch <- resp:
+	default:
+	}
+}
+
+func (c *Conn) handleCancel(req *Request) {
+	params := &CancelParams{}
+	if err := req.DecodeParams(params); err != nil {
+		return
+	}
+	id, err := parserID(params.ID)
+	if err != nil || id == nil {
+		return
+	}
+	c.mux.Lock()
+	cancel := c.incoming[string(id.Bytes())]
+	c.mux.Unlock()
+	if cancel != nil {
+		cancel(context.Canceled)
+	}
+}
+
+func (c *Conn) handleRequest(req *Request, w ResponseWriter) {
+	ctx, cancel := context.WithCancelCause(context.WithValue(c.ctx, ctxKeyConn{}, c))
+	defer cancel(nil)
+	var key string
+	if !req.NoReply() {
+		key = string(req.ID.Bytes())
+		c.mux.Lock()
+		c.incoming[key] = cancel
+		c.mux.Unlock()
+		defer func() {
+			c.mux.Lock()
+			delete(c.incoming, key)
+			c.mux.Unlock()
+		}()
+	}
+	err := safely.RunCtx(ctx, func(ctx context.Context) error {
+		return c.handler.Handle(ctx, w, req)
+	})
+	var pe *safely.PanicErr
+	if errors.As(err, &pe) && !req.NoReply() {
+		resp, _ := NewResponse(req.ID, nil, ErrInternal)
+		_ = w.Write(resp)
+	}
+}
+
+var _ ResponseWriter = (*connWriter)(nil)
+var _ Notifier = (*connWriter)(nil)
+
+type connWriter struct {
+	conn *Conn
+}
+
+func (cw *connWriter) Write(resp *Response) error {
+	return cw.conn.write(cw.conn.ctx, resp)
+}
+
+func (cw *connWriter) Notify(req *Request) error {
+	return cw.conn.write(cw.conn.ctx, req)
+}
+
+var _ ResponseWriter = (*batchWriter)(nil)
+var _ Notifier = (*batchWriter)(nil)
+
+// batchWriter 批量请求中的单个请求，响应会合并后一起发送，通知直接发送
+type batchWriter struct {
+	notifier Notifier
+	resp     json.RawMessage
+}
+
+func (bw *batchWriter) Write(resp *Response) error {
+	bf, err := xcodec.JSON.Encode(resp.envelope())
+	if err != nil {
+		return err
+	}
+	bw.resp = bf
+	return nil
+}
+
+func (bw *batchWriter) Notify(req *Request) error {
+	return bw.notifier.Notify(req)
+}
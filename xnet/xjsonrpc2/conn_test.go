@@ -0,0 +1,186 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xjsonrpc2_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/xnet/xjsonrpc2"
+	"github.com/xanygo/anygo/xt"
+)
+
+func TestConn(t *testing.T) {
+	ca, cb := net.Pipe()
+
+	notified := make(chan string, 1)
+	routerA := xjsonrpc2.NewRouter()
+	routerA.RegisterUnary("echo", func(ctx context.Context, req *xjsonrpc2.Request) (any, error) {
+		var s string
+		err := req.DecodeParams(&s)
+		return s, err
+	})
+	routerA.RegisterUnary("progress", func(ctx context.Context, req *xjsonrpc2.Request) (any, error) {
+		var s string
+		_ = req.DecodeParams(&s)
+		notified <- s
+		return nil, nil
+	})
+
+	cancelled := make(chan error, 1)
+	routerB := xjsonrpc2.NewRouter()
+	routerB.RegisterUnary("hello", func(ctx context.Context, req *xjsonrpc2.Request) (any, error) {
+		// 回调对端
+		conn := xjsonrpc2.ConnFromContext(ctx)
+		if err := conn.Notify(ctx, "progress", "working"); err != nil {
+			return nil, err
+		}
+		var name string
+		if err := conn.Call(ctx, "echo", "anygo", &name); err != nil {
+			return nil, err
+		}
+		return "hello " + name, nil
+	})
+	routerB.RegisterUnary("sleep", func(ctx context.Context, req *xjsonrpc2.Request) (any, error) {
+		<-ctx.Done()
+		cancelled <- context.Cause(ctx)
+		return nil, ctx.Err()
+	})
+
+	routerB.RegisterUnary("panic", func(ctx context.Context, req *xjsonrpc2.Request) (any, error) {
+		panic("handler panic")
+	})
+
+	connA := xjsonrpc2.NewConn(t.Context(), ca, routerA)
+	connB := xjsonrpc2.NewConn(t.Context(), cb, routerB)
+
+	var got string
+	xt.NoError(t, connA.Call(t.Context(), "hello", nil, &got))
+	xt.Equal(t, got, "hello anygo")
+	xt.Equal(t, <-notified, "working")
+
+	err := connA.Call(t.Context(), "not-found", nil, nil)
+	var re *xjsonrpc2.Error
+	xt.True(t, errors.As(err, &re))
+	xt.Equal(t, re.Code, xjsonrpc2.ErrMethodNotFound.Code)
+
+	t.Run("panic", func(t *testing.T) {
+		err := connA.Call(t.Context(), "panic", nil, nil)
+		var re *xjsonrpc2.Error
+		xt.True(t, errors.As(err, &re))
+		xt.Equal(t, re.Code, xjsonrpc2.ErrInternal.Code)
+
+		// panic 之后连接依然可用
+		xt.NoError(t, connA.Call(t.Context(), "hello", nil, &got))
+		xt.Equal(t, <-notified, "working")
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		err := connA.Call(ctx, "sleep", nil, nil)
+		xt.True(t, errors.Is(err, context.DeadlineExceeded))
+		select {
+		case err = <-cancelled:
+			xt.True(t, errors.Is(err, context.Canceled))
+		case <-time.After(time.Second):
+			t.Fatal("request not cancelled")
+		}
+	})
+
+	t.Run("close", func(t *testing.T) {
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- connB.Call(context.Background(), "echo", "x", nil)
+		}()
+		xt.NoError(t, connA.Close())
+		select {
+		case <-connB.Done():
+		case <-time.After(time.Second):
+			t.Fatal("close not propagated")
+		}
+		xt.Error(t, connB.Err())
+		xt.Error(t, <-errCh)
+		xt.Error(t, connA.Call(t.Context(), "hello", nil, nil))
+		xt.NoError(t, connB.Close())
+	})
+}
+
+func TestConn_closeInHandler(t *testing.T) {
+	ca, cb := net.Pipe()
+	closed := make(chan error, 1)
+	router := xjsonrpc2.NewRouter()
+	router.RegisterUnary("quit", func(ctx context.Context, req *xjsonrpc2.Request) (any, error) {
+		closed <- xjsonrpc2.ConnFromContext(ctx).CloseNoWait()
+		return nil, nil
+	})
+	connA := xjsonrpc2.NewConn(t.Context(), ca, nil)
+	connB := xjsonrpc2.NewConn(t.Context(), cb, router)
+	defer connA.Close()
+
+	xt.NoError(t, connA.Notify(t.Context(), "quit", nil))
+	select {
+	case err := <-closed:
+		xt.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("close in handler blocked")
+	}
+	<-connB.Done()
+	xt.NoError(t, connB.Close())
+}
+
+func TestNewConnWithOption(t *testing.T) {
+	ca, cb := net.Pipe()
+	cancelled := make(chan string, 1)
+	var seq []int
+	seqDone := make(chan struct{})
+	router := xjsonrpc2.NewRouter()
+	router.RegisterUnary("slow", func(ctx context.Context, req *xjsonrpc2.Request) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	router.RegisterUnary("cancelled", func(ctx context.Context, req *xjsonrpc2.Request) (any, error) {
+		cancelled <- string(req.Params)
+		return nil, nil
+	})
+	router.RegisterUnary("seq", func(ctx context.Context, req *xjsonrpc2.Request) (any, error) {
+		var n int
+		_ = req.DecodeParams(&n)
+		seq = append(seq, n)
+		if n == 99 {
+			close(seqDone)
+		}
+		return nil, nil
+	})
+	connA := xjsonrpc2.NewConnWithOption(t.Context(), xjsonrpc2.NewFramedStream(ca, nil), xjsonrpc2.ConnOption{
+		CancelRequest: func(id xjsonrpc2.ID, method string, cause error) (*xjsonrpc2.Request, error) {
+			return xjsonrpc2.NewRequest(nil, "cancelled", method)
+		},
+	})
+	connB := xjsonrpc2.NewConnWithOption(t.Context(), xjsonrpc2.NewFramedStream(cb, nil), xjsonrpc2.ConnOption{
+		Handler: router,
+		Ordered: func(req *xjsonrpc2.Request) bool {
+			return req.Method == "seq"
+		},
+	})
+	defer connA.Close()
+	defer connB.Close()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	xt.Error(t, connA.Call(ctx, "slow", nil, nil))
+	xt.Equal(t, <-cancelled, `"slow"`)
+
+	for i := range 100 {
+		xt.NoError(t, connA.Notify(t.Context(), "seq", i))
+	}
+	<-seqDone
+	for i, n := range seq {
+		xt.Equal(t, n, i)
+	}
+}
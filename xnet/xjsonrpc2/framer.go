@@ -0,0 +1,323 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xjsonrpc2
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/xanygo/anygo/ds/xcast"
+	"github.com/xanygo/anygo/ds/xmap"
+	"github.com/xanygo/anygo/ds/xoption"
+	"github.com/xanygo/anygo/xcodec"
+	"github.com/xanygo/anygo/xio"
+	"github.com/xanygo/anygo/xnet/xrpc"
+)
+
+// Framer 消息的分帧方式，即如何在数据流中确定一条消息（单个或者批量）的边界
+type Framer interface {
+	// ReadMessage 读取一条完整的消息，数据流结束时返回 io.EOF
+	ReadMessage(rd *bufio.Reader) ([]byte, error)
+
+	// WriteMessage 写入一条消息，消息需要一次性写入 w，以保证并发写入时，多条消息之间不会交错
+	WriteMessage(w io.Writer, msg []byte) error
+}
+
+// 内置的分帧方式的名称
+const (
+	FramingNewline       = "newline"        // 消息以换行符分隔，默认方式
+	FramingContentLength = "content-length" // 使用 Content-Length 头（同 LSP），如 "Content-Length: 17\r\n\r\n{...}"
+	FramingLengthPrefix  = "length-prefix"  // 使用 4 字节大端序的长度前缀
+)
+
+var (
+	// NewlineFramer 消息以换行符分隔，批量消息可以跨多行，消息最大长度为 DefaultMaxMessageSize
+	NewlineFramer Framer = newlineFramer{}
+
+	// ContentLengthFramer 使用 Content-Length 头分隔消息，同 LSP 的 Base Protocol，消息最大长度为 DefaultMaxMessageSize
+	ContentLengthFramer Framer = contentLengthFramer{}
+
+	// LengthPrefixFramer 使用 4 字节大端序的长度前缀分隔消息，消息最大长度为 DefaultMaxMessageSize
+	LengthPrefixFramer Framer = lengthPrefixFramer{}
+)
+
+// DefaultMaxMessageSize 读取消息时，默认允许的单条消息的最大长度，单位字节
+var DefaultMaxMessageSize = 4 << 20
+
+// ErrMessageTooLarge 读取的消息超过了最大长度
+var ErrMessageTooLarge = errors.New("jsonrpc2: message too large")
+
+// NewNewlineFramer 创建以换行符分隔消息的 Framer，maxSize 为单条消息的最大长度，<= 0 时使用 DefaultMaxMessageSize
+func NewNewlineFramer(maxSize int) Framer {
+	return newlineFramer{maxSize: maxSize}
+}
+
+// NewContentLengthFramer 创建使用 Content-Length 头分隔消息的 Framer，maxSize 为单条消息的最大长度，<= 0 时使用 DefaultMaxMessageSize
+func NewContentLengthFramer(maxSize int) Framer {
+	return contentLengthFramer{maxSize: maxSize}
+}
+
+// NewLengthPrefixFramer 创建使用 4 字节长度前缀分隔消息的 Framer，maxSize 为单条消息的最大长度，<= 0 时使用 DefaultMaxMessageSize
+func NewLengthPrefixFramer(maxSize int) Framer {
+	return lengthPrefixFramer{maxSize: maxSize}
+}
+
+// FramerByName 依据名称返回内置的分帧方式，name 为空时返回 NewlineFramer
+func FramerByName(name string) (Framer, error) {
+	return newFramer(name, 0)
+}
+
+func newFramer(name string, maxSize int) (Framer, error) {
+	switch strings.ToLower(name) {
+	case "", FramingNewline:
+		return NewNewlineFramer(maxSize), nil
+	case FramingContentLength:
+		return NewContentLengthFramer(maxSize), nil
+	case FramingLengthPrefix:
+		return NewLengthPrefixFramer(maxSize), nil
+	default:
+		return nil, fmt.Errorf("unknown framing %q", name)
+	}
+}
+
+func getMaxSize(maxSize int) int {
+	if maxSize <= 0 {
+		return DefaultMaxMessageSize
+	}
+	return maxSize
+}
+
+func errTooLarge(size int64, maxSize int) error {
+	return fmt.Errorf("%w: %d > %d", ErrMessageTooLarge, size, maxSize)
+}
+
+// readBody 读取 length 字节的消息体，数据不足时返回 io.ErrUnexpectedEOF
+func readBody(rd io.Reader, length int) ([]byte, error) {
+	bf := bytes.NewBuffer(make([]byte, 0, min(length, 64<<10)))
+	if _, err := io.CopyN(bf, rd, int64(length)); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return bf.Bytes(), nil
+}
+
+var keyFramer = xoption.NewKey("JSON-RPC2:Framer")
+
+// SetOptFramer 设置分帧方式
+func SetOptFramer(opt xoption.Writer, f Framer) {
+	opt.Set(keyFramer, f)
+}
+
+// OptFramer 读取分帧方式，优先使用 SetOptFramer 设置的值，其次是 service 配置中的 Framing 字段，默认为 NewlineFramer。
+// service 配置中的 MaxMessageSize 字段为单条消息的最大长度，默认为 DefaultMaxMessageSize。
+//
+// service 配置如：
+//
+//	{
+//	  "Name": "gopls",
+//	  "Protocol": "JSON-RPC2",
+//	  "JSON-RPC2": {
+//	    "Framing": "content-length",
+//	    "MaxMessageSize": 16777216
+//	  },
+//	  "DownStream": {
+//	    "Address": ["stdio@{\"Path\":\"gopls\"}"]
+//	  }
+//	}
+func OptFramer(opt xoption.Reader) (Framer, error) {
+	if f, ok := xoption.GetAs[Framer](opt, keyFramer); ok && f != nil {
+		return f, nil
+	}
+	cfg := xoption.Extra(opt, Protocol)
+	var name string
+	var maxSize int
+	var err error
+	xmap.Range[string, any](cfg, func(k string, v any) bool {
+		var ok bool
+		switch k {
+		case "Framing":
+			name, ok = xcast.String(v)
+		case "MaxMessageSize":
+			maxSize, ok = xcast.Integer[int](v)
+		default:
+			return true
+		}
+		if !ok {
+			err = fmt.Errorf("invalid field %s.%s=%#v", Protocol, k, v)
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newFramer(name, maxSize)
+}
+
+// RPCOptFramer 用于 xrpc.Invoke 的参数，设置分帧方式
+func RPCOptFramer(f Framer) xrpc.Option {
+	return xrpc.OptOptionSetter(func(o xoption.Option) {
+		SetOptFramer(o, f)
+	})
+}
+
+// writeMessage 编码消息，使用 f 写入 w，若 w 支持 Flush，会调用 Flush
+func writeMessage(w io.Writer, f Framer, msg interface{ envelope() envelope }) error {
+	bf, err := xcodec.JSON.Encode(msg.envelope())
+	if err != nil {
+		return err
+	}
+	if err = f.WriteMessage(w, bf); err != nil {
+		return err
+	}
+	return xio.TryFlush(w)
+}
+
+var _ Framer = newlineFramer{}
+
+type newlineFramer struct {
+	maxSize int
+}
+
+func (f newlineFramer) ReadMessage(br *bufio.Reader) ([]byte, error) {
+	maxSize := getMaxSize(f.maxSize)
+	for {
+		line, err := readLine(br, maxSize)
+		line = bytes.TrimSpace(line)
+		if err != nil {
+			if len(line) > 0 && errors.Is(err, io.EOF) {
+				return line, nil
+			}
+			return nil, err
+		}
+		if len(line) == 0 {
+			continue
+		}
+		if line[0] != '[' {
+			return line, nil
+		}
+		// 批量消息，可以跨多行
+		var bf bytes.Buffer
+		bf.Write(line)
+		for !bytes.HasSuffix(line, []byte("]")) {
+			line, err = readLine(br, maxSize-bf.Len())
+			if err != nil {
+				return nil, err
+			}
+			bf.Write(line)
+			line = bytes.TrimSpace(line)
+		}
+		return bf.Bytes(), nil
+	}
+}
+
+// readLine 读取一行，包括结尾的换行符，超过 maxSize 时返回 ErrMessageTooLarge
+func readLine(br *bufio.Reader, maxSize int) ([]byte, error) {
+	var line []byte
+	for {
+		frag, err := br.ReadSlice('\n')
+		if len(line)+len(frag) > maxSize {
+			return nil, errTooLarge(int64(len(line)+len(frag)), maxSize)
+		}
+		line = append(line, frag...)
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return line, err
+		}
+	}
+}
+
+func (newlineFramer) WriteMessage(w io.Writer, msg []byte) error {
+	bf := make([]byte, 0, len(msg)+1)
+	bf = append(bf, msg...)
+	bf = append(bf, '\n')
+	_, err := w.Write(bf)
+	return err
+}
+
+var _ Framer = contentLengthFramer{}
+
+type contentLengthFramer struct {
+	maxSize int
+}
+
+func (f contentLengthFramer) ReadMessage(br *bufio.Reader) ([]byte, error) {
+	maxSize := getMaxSize(f.maxSize)
+	length := int64(-1)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) && (line != "" || length >= 0) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if length < 0 {
+				// 消息之间多余的空行
+				continue
+			}
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid header line %q", line)
+		}
+		// 其他的头（如 Content-Type）忽略
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil || length < 0 {
+				return nil, fmt.Errorf("invalid Content-Length %q", value)
+			}
+			if length > int64(maxSize) {
+				return nil, errTooLarge(length, maxSize)
+			}
+		}
+	}
+	return readBody(br, int(length))
+}
+
+func (contentLengthFramer) WriteMessage(w io.Writer, msg []byte) error {
+	header := "Content-Length: " + strconv.Itoa(len(msg)) + "\r\n\r\n"
+	bf := make([]byte, 0, len(header)+len(msg))
+	bf = append(bf, header...)
+	bf = append(bf, msg...)
+	_, err := w.Write(bf)
+	return err
+}
+
+var _ Framer = lengthPrefixFramer{}
+
+type lengthPrefixFramer struct {
+	maxSize int
+}
+
+func (f lengthPrefixFramer) ReadMessage(br *bufio.Reader) ([]byte, error) {
+	var head [4]byte
+	if _, err := io.ReadFull(br, head[:]); err != nil {
+		return nil, err
+	}
+	length := int64(binary.BigEndian.Uint32(head[:]))
+	if maxSize := getMaxSize(f.maxSize); length > int64(maxSize) {
+		return nil, errTooLarge(length, maxSize)
+	}
+	return readBody(br, int(length))
+}
+
+func (lengthPrefixFramer) WriteMessage(w io.Writer, msg []byte) error {
+	bf := make([]byte, 4, 4+len(msg))
+	binary.BigEndian.PutUint32(bf, uint32(len(msg)))
+	bf = append(bf, msg...)
+	_, err := w.Write(bf)
+	return err
+}
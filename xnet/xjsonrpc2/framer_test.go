@@ -0,0 +1,192 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xjsonrpc2_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xoption"
+	"github.com/xanygo/anygo/xnet/internal"
+	"github.com/xanygo/anygo/xnet/xdial"
+	"github.com/xanygo/anygo/xnet/xjsonrpc2"
+	"github.com/xanygo/anygo/xnet/xrpc"
+	"github.com/xanygo/anygo/xnet/xservice"
+	"github.com/xanygo/anygo/xt"
+)
+
+func TestFramer(t *testing.T) {
+	msgs := []string{`{"jsonrpc":"2.0","method":"a"}`, `[{"jsonrpc":"2.0","method":"b"}]`}
+	for _, name := range []string{"", xjsonrpc2.FramingNewline, xjsonrpc2.FramingContentLength, xjsonrpc2.FramingLengthPrefix} {
+		t.Run(name, func(t *testing.T) {
+			framer, err := xjsonrpc2.FramerByName(name)
+			xt.NoError(t, err)
+			bf := &bytes.Buffer{}
+			for _, msg := range msgs {
+				xt.NoError(t, framer.WriteMessage(bf, []byte(msg)))
+			}
+			br := bufio.NewReader(bf)
+			for _, msg := range msgs {
+				got, err := framer.ReadMessage(br)
+				xt.NoError(t, err)
+				xt.Equal(t, string(got), msg)
+			}
+			_, err = framer.ReadMessage(br)
+			xt.ErrorIs(t, err, io.EOF)
+		})
+	}
+
+	t.Run("content-length headers", func(t *testing.T) {
+		input := "Content-Length: 2\r\nContent-Type: application/vscode-jsonrpc; charset=utf-8\r\n\r\n{}" +
+			"content-length:4\r\n\r\nnull"
+		br := bufio.NewReader(strings.NewReader(input))
+		got, err := xjsonrpc2.ContentLengthFramer.ReadMessage(br)
+		xt.NoError(t, err)
+		xt.Equal(t, string(got), "{}")
+		got, err = xjsonrpc2.ContentLengthFramer.ReadMessage(br)
+		xt.NoError(t, err)
+		xt.Equal(t, string(got), "null")
+
+		br = bufio.NewReader(strings.NewReader("Content-Length: 10\r\n\r\n{}"))
+		_, err = xjsonrpc2.ContentLengthFramer.ReadMessage(br)
+		xt.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		br := bufio.NewReader(strings.NewReader("Content-Length: 99999999999999999\r\n\r\n{}"))
+		_, err := xjsonrpc2.ContentLengthFramer.ReadMessage(br)
+		xt.ErrorIs(t, err, xjsonrpc2.ErrMessageTooLarge)
+
+		br = bufio.NewReader(strings.NewReader("\xff\xff\xff\xff{}"))
+		_, err = xjsonrpc2.LengthPrefixFramer.ReadMessage(br)
+		xt.ErrorIs(t, err, xjsonrpc2.ErrMessageTooLarge)
+
+		msg := `{"jsonrpc":"2.0","method":"hello"}`
+		for _, framer := range []xjsonrpc2.Framer{
+			xjsonrpc2.NewNewlineFramer(10),
+			xjsonrpc2.NewContentLengthFramer(10),
+			xjsonrpc2.NewLengthPrefixFramer(10),
+		} {
+			bf := &bytes.Buffer{}
+			xt.NoError(t, framer.WriteMessage(bf, []byte(msg)))
+			_, err = framer.ReadMessage(bufio.NewReaderSize(bf, 16))
+			xt.ErrorIs(t, err, xjsonrpc2.ErrMessageTooLarge)
+		}
+
+		bf := &bytes.Buffer{}
+		framer := xjsonrpc2.NewNewlineFramer(40)
+		xt.NoError(t, framer.WriteMessage(bf, []byte("["+msg+",\n"+msg+"]")))
+		_, err = framer.ReadMessage(bufio.NewReaderSize(bf, 16))
+		xt.ErrorIs(t, err, xjsonrpc2.ErrMessageTooLarge)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := xjsonrpc2.FramerByName("xml")
+		xt.Error(t, err)
+	})
+}
+
+func TestOptFramer(t *testing.T) {
+	opt := xoption.NewSimple()
+	f, err := xjsonrpc2.OptFramer(opt)
+	xt.NoError(t, err)
+	xt.Equal(t, f, xjsonrpc2.NewlineFramer)
+
+	xoption.SetExtra(opt, xjsonrpc2.Protocol, map[string]any{"Framing": "content-length"})
+	f, err = xjsonrpc2.OptFramer(opt)
+	xt.NoError(t, err)
+	xt.Equal(t, f, xjsonrpc2.ContentLengthFramer)
+
+	xoption.SetExtra(opt, xjsonrpc2.Protocol, map[string]any{"Framing": "length-prefix", "MaxMessageSize": 1024})
+	f, err = xjsonrpc2.OptFramer(opt)
+	xt.NoError(t, err)
+	xt.Equal(t, f, xjsonrpc2.NewLengthPrefixFramer(1024))
+
+	xjsonrpc2.SetOptFramer(opt, xjsonrpc2.LengthPrefixFramer)
+	f, err = xjsonrpc2.OptFramer(opt)
+	xt.NoError(t, err)
+	xt.Equal(t, f, xjsonrpc2.LengthPrefixFramer)
+}
+
+func TestRouter_SetFramer(t *testing.T) {
+	router := xjsonrpc2.NewRouter()
+	router.RegisterUnary("ping", pingHandler)
+	router.SetFramer(xjsonrpc2.ContentLengthFramer)
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Minute)
+	defer cancel()
+
+	address, err := internal.HostPortFromURL(ts.URL)
+	xt.NoError(t, err)
+	cfg := &xservice.Config{
+		Name:     "test",
+		ConnPool: &xservice.ConnPoolPart{Name: xdial.Long},
+		SessionInit: &xoption.SessionStarterConfig{
+			Name: "HTTP-Upgrade",
+			Params: map[string]any{
+				"Method":   http.MethodPost,
+				"URI":      "/api",
+				"Protocol": xjsonrpc2.Protocol,
+			},
+		},
+		DownStream: xservice.DownStreamPart{
+			Address: []string{address},
+		},
+		Extra: map[string]any{
+			xjsonrpc2.Protocol: map[string]any{"Framing": "content-length"},
+		},
+	}
+	srv, err := cfg.Parser("test")
+	xt.NoError(t, err)
+	xt.NoError(t, srv.Start(ctx))
+	defer srv.Stop(ctx)
+
+	for i := 1; i < 3; i++ {
+		t.Run(fmt.Sprintf("loop_%d", i), func(t *testing.T) {
+			req := &xjsonrpc2.ClientRequest[string]{
+				ID:     xjsonrpc2.Int64ID(i),
+				Method: "ping",
+				Params: "hello",
+			}
+			resp := &xjsonrpc2.ClientResponse[string]{}
+			xt.NoError(t, xrpc.Invoke(ctx, srv, req, resp))
+			xt.Equal(t, resp.Result, "Ok: hello")
+		})
+	}
+
+	t.Run("batch", func(t *testing.T) {
+		req := xjsonrpc2.ClientRequests[string]{
+			{ID: xjsonrpc2.Int64ID(1), Method: "ping", Params: "a"},
+			{ID: xjsonrpc2.Int64ID(2), Method: "ping", Params: "b"},
+		}
+		resp := &xjsonrpc2.ClientResponses{}
+		xt.NoError(t, xrpc.Invoke(ctx, srv, req, resp))
+		xt.Len(t, resp.Result(), 2)
+	})
+}
+
+func TestConn_framer(t *testing.T) {
+	ca, cb := net.Pipe()
+	router := xjsonrpc2.NewRouter()
+	router.RegisterUnary("ping", pingHandler)
+	connA := xjsonrpc2.NewConnWithFramer(t.Context(), ca, xjsonrpc2.LengthPrefixFramer, nil)
+	connB := xjsonrpc2.NewConnWithFramer(t.Context(), cb, xjsonrpc2.LengthPrefixFramer, router)
+	defer connB.Close()
+	defer connA.Close()
+	var got string
+	xt.NoError(t, connA.Call(t.Context(), "ping", "hello", &got))
+	xt.Equal(t, got, "Ok: hello")
+}
@@ -142,6 +142,61 @@ func parserRequest(bf []byte) (*Request, error) {
 	}, nil
 }
 
+// ParseMessage 解析一条消息，包含 method 字段的为请求（或通知），否则为响应，
+// 用于在同一个连接上同时收发请求和响应的场景（如 MCP）
+func ParseMessage(bf []byte) (*Request, *Response, error) {
+	el := &envelope{}
+	err := xcodec.JSON.Decode(bf, &el)
+	if err != nil {
+		return nil, nil, errors.Join(ErrParse, err)
+	}
+	if el.Version != Version {
+		return nil, nil, errors.Join(ErrInvalidRequest, fmt.Errorf("invalid version %q", el.Version))
+	}
+	id, err := parserID(el.ID)
+	if err != nil {
+		return nil, nil, errors.Join(ErrInvalidRequest, err)
+	}
+	if el.Method != "" {
+		req := &Request{
+			ID:     id,
+			Method: el.Method,
+			Params: el.Params,
+		}
+		return req, nil, nil
+	}
+	resp := &Response{
+		ID:     id,
+		Error:  el.Error,
+		Result: el.Result,
+	}
+	return nil, resp, nil
+}
+
+// parseRequests 解析一条消息中的请求，消息可以是单个请求或者批量请求
+func parseRequests(bf []byte) ([]*Request, bool, error) {
+	if len(bf) == 0 || bf[0] != '[' {
+		req, err := parserRequest(bf)
+		if err != nil {
+			return nil, false, errors.Join(ErrParse, err)
+		}
+		return []*Request{req}, false, nil
+	}
+	var batch []json.RawMessage
+	if err := xcodec.JSON.Decode(bf, &batch); err != nil {
+		return nil, true, errors.Join(ErrParse, err)
+	}
+	result := make([]*Request, len(batch))
+	for i, b := range batch {
+		req, err := parserRequest(b)
+		if err != nil {
+			return nil, true, errors.Join(err, ErrParse)
+		}
+		result[i] = req
+	}
+	return result, true, nil
+}
+
 // ReadRequests 读取请求信息
 //
 // 返回值：请求列表，是否批量，错误
@@ -164,3 +164,27 @@ func ReadResponses(rd *bufio.Reader) ([]*Response, bool, error) {
 	}
 	return result, true, nil
 }
+
+// parseResponses 解析一条消息中的响应，消息可以是单个响应或者批量响应
+func parseResponses(bf []byte) ([]*Response, bool, error) {
+	if len(bf) == 0 || bf[0] != '[' {
+		res, err := parserResponse(bf)
+		if err != nil {
+			return nil, false, err
+		}
+		return []*Response{res}, false, nil
+	}
+	var batch []json.RawMessage
+	if err := xcodec.Decode(xcodec.JSON, bf, &batch); err != nil {
+		return nil, true, err
+	}
+	result := make([]*Response, len(batch))
+	for i, b := range batch {
+		res, err := parserResponse(b)
+		if err != nil {
+			return nil, true, errors.Join(err, ErrParse)
+		}
+		result[i] = res
+	}
+	return result, true, nil
+}
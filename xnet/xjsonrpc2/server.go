@@ -6,10 +6,10 @@ package xjsonrpc2
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"maps"
 	"net/http"
@@ -25,6 +25,12 @@ type ResponseWriter interface {
 	Write(resp *Response) error
 }
 
+// Notifier 可以发送通知消息的 ResponseWriter，Router.Serve 传递给 Handler 的 ResponseWriter 实现了该接口，
+// 可用于在返回结果之前，向对端推送进度等通知
+type Notifier interface {
+	Notify(req *Request) error
+}
+
 type Handler interface {
 	Handle(ctx context.Context, w ResponseWriter, req *Request) error
 }
@@ -60,12 +66,21 @@ func (h UnaryHandlerFunc) Handle(ctx context.Context, w ResponseWriter, req *Req
 var _ ResponseWriter = (*responseWriterImpl)(nil)
 
 type responseWriterImpl struct {
-	w io.Writer
+	w      io.Writer
+	framer Framer
 }
 
 func (rw *responseWriterImpl) Write(resp *Response) error {
-	_, err := resp.WriteTo(rw.w)
-	return err
+	return writeMessage(rw.w, rw.framer, resp)
+}
+
+var _ Notifier = (*responseWriterImpl)(nil)
+
+func (rw *responseWriterImpl) Notify(req *Request) error {
+	if !req.NoReply() {
+		return fmt.Errorf("has id=%s, not notify request", idBytes(req.ID))
+	}
+	return writeMessage(rw.w, rw.framer, req)
 }
 
 func NotFound(ctx context.Context, w ResponseWriter, req *Request) error {
@@ -92,6 +107,8 @@ func NewRouter() *Router {
 type Router struct {
 	handlers map[string]Handler
 	notFound Handler
+	framer   Framer
+	discover *discoverMethods // 使用 RegisterService 注册的方法
 }
 
 func (r *Router) Register(method string, h Handler) {
@@ -105,14 +122,32 @@ func (r *Router) SetNotFound(notFound Handler) {
 	r.notFound = notFound
 }
 
+// SetFramer 设置 Serve 和 ServeHTTP 使用的分帧方式，默认为 NewlineFramer
+func (r *Router) SetFramer(f Framer) {
+	r.framer = f
+}
+
+func (r *Router) getFramer() Framer {
+	if r.framer == nil {
+		return NewlineFramer
+	}
+	return r.framer
+}
+
 func (r *Router) Clone() *Router {
 	if r == nil {
 		return nil
 	}
-	return &Router{
+	nr := &Router{
 		handlers: maps.Clone(r.handlers),
 		notFound: r.notFound,
+		framer:   r.framer,
+	}
+	if r.discover != nil {
+		nr.discover = r.discover.clone()
+		nr.RegisterUnary(MethodDiscover, nr.discover.handle)
 	}
+	return nr
 }
 
 func (r *Router) RegisterUnary(method string, fn func(ctx context.Context, req *Request) (result any, err error)) {
@@ -173,6 +208,7 @@ func (r *Router) Serve(ctx context.Context, rd io.Reader, w io.Writer) error {
 	lbw := &xio.LockedWriter[*bufio.Writer]{
 		Writer: bufio.NewWriter(w),
 	}
+	framer := r.getFramer()
 	var wg xsync.WaitGroup
 	for {
 		select {
@@ -180,11 +216,15 @@ func (r *Router) Serve(ctx context.Context, rd io.Reader, w io.Writer) error {
 			return context.Cause(ctx)
 		default:
 		}
-		reqs, batch, err := ReadRequests(br)
+		bf, err := framer.ReadMessage(br)
 		if err != nil {
 			if errors.Is(err, io.EOF) {
 				break
 			}
+			return err
+		}
+		reqs, batch, err := parseRequests(bf)
+		if err != nil {
 			err1 := r.sendError(lbw, err)
 			return errors.Join(err, err1)
 		}
@@ -206,18 +246,15 @@ func (r *Router) sendError(w *lockedBW, err error) error {
 	if e != nil {
 		return e
 	}
-	err2 := w.WithLock(func(w *bufio.Writer) error {
-		if err1 := resp.Write(w); err1 != nil {
-			return err1
-		}
-		return w.Flush()
+	return w.WithLock(func(w *bufio.Writer) error {
+		return writeMessage(w, r.getFramer(), resp)
 	})
-	return err2
 }
 
 func (r *Router) serveOne(ctx context.Context, w *lockedBW, req *Request) error {
 	ww := &responseWriterImpl{
-		w: w,
+		w:      w,
+		framer: r.getFramer(),
 	}
 	err := r.Handle(ctx, ww, req)
 	if err != nil {
@@ -232,16 +269,19 @@ func (r *Router) serveBatch(ctx context.Context, w *lockedBW, reqs []*Request) e
 	resps := make([]json.RawMessage, 0, len(reqs))
 	var mux sync.Mutex
 	var wg xsync.WaitGroup
+	notifier := &responseWriterImpl{
+		w:      w,
+		framer: r.getFramer(),
+	}
 	for _, req := range reqs {
 		wg.GoCtx(ctx, func(ctx context.Context) {
-			bf := bytes.NewBuffer(nil)
-			ww := &responseWriterImpl{
-				w: bf,
+			ww := &batchWriter{
+				notifier: notifier,
 			}
 			r.Handle(ctx, ww, req)
-			if bf.Len() > 0 {
+			if ww.resp != nil {
 				mux.Lock()
-				resps = append(resps, bf.Bytes())
+				resps = append(resps, ww.resp)
 				mux.Unlock()
 			}
 		})
@@ -258,9 +298,10 @@ func (r *Router) serveBatch(ctx context.Context, w *lockedBW, reqs []*Request) e
 		return nil
 	}
 	bf, _ := xcodec.JSON.Encode(resps)
-	bf = append(bf, '\n')
 	return w.WithLock(func(w *bufio.Writer) error {
-		w.Write(bf)
+		if err := r.getFramer().WriteMessage(w, bf); err != nil {
+			return err
+		}
 		return w.Flush()
 	})
 }
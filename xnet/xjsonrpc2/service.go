@@ -0,0 +1,294 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xjsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/xanygo/anygo/xcodec"
+	"github.com/xanygo/anygo/xerror"
+	"github.com/xanygo/anygo/xhttp/openapi"
+	"github.com/xanygo/anygo/xlog"
+	"github.com/xanygo/anygo/xvalidator"
+)
+
+// MethodDiscover 列出 Router 中使用 RegisterService 注册的方法的信息
+const MethodDiscover = "rpc.discover"
+
+var (
+	contextType = reflect.TypeFor[context.Context]()
+	errorType   = reflect.TypeFor[error]()
+)
+
+// RegisterService 使用反射注册 svc 的所有可导出的方法，方法名为 prefix + "." + 方法名（prefix 为空时，为方法名），
+// 方法的签名需要是以下两种之一，其他的方法会被忽略：
+//
+//	func (s *Svc) Method(ctx context.Context, args *Args) (*Reply, error)
+//	func (s *Svc) Method(ctx context.Context) (*Reply, error)
+//
+// 其中 Args 和 Reply 可以是任意可以 JSON 编解码的类型（Args 可以不是指针）。调用时：
+//  1. 请求的 params 为对象时（by-name），解析到 Args；为数组时（by-position），若 Args 是结构体，
+//     按照字段的顺序解析（同 JSON 字段的顺序），若 Args 是 slice，直接解析，其他类型只允许包含一个元素
+//  2. 使用 xvalidator 校验 Args，解析和校验失败时，返回 invalid params 错误
+//  3. 方法返回的 error 使用 ToError 转换为 JSON-RPC 的错误
+//
+// 同时会注册 rpc.discover 方法，返回所有使用 RegisterService 注册的方法，以及参数和结果的 JSON Schema
+func (r *Router) RegisterService(prefix string, svc any) error {
+	rv := reflect.ValueOf(svc)
+	rt := rv.Type()
+	var count int
+	for i := 0; i < rt.NumMethod(); i++ {
+		m := rt.Method(i)
+		sm, ok := newServiceMethod(rv.Method(i))
+		if !ok {
+			continue
+		}
+		name := m.Name
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		sm.desc.Name = name
+		r.Register(name, UnaryHandlerFunc(sm.handle))
+		r.addDiscover(sm.desc)
+		count++
+	}
+	if count == 0 {
+		return fmt.Errorf("type %s has no exported methods of suitable type", rt)
+	}
+	return nil
+}
+
+// MustRegisterService 同 RegisterService，若失败会 panic
+func (r *Router) MustRegisterService(prefix string, svc any) {
+	if err := r.RegisterService(prefix, svc); err != nil {
+		panic(err)
+	}
+}
+
+func (r *Router) addDiscover(desc *MethodDesc) {
+	if r.discover == nil {
+		r.discover = &discoverMethods{}
+		r.RegisterUnary(MethodDiscover, r.discover.handle)
+	}
+	r.discover.add(desc)
+}
+
+// DiscoverResult rpc.discover 方法的结果
+type DiscoverResult struct {
+	Methods []*MethodDesc `json:"methods"`
+}
+
+// MethodDesc 方法的描述信息
+type MethodDesc struct {
+	Name string `json:"name"`
+
+	// Params 参数的 JSON Schema，方法没有参数时为空
+	Params *openapi.Schema `json:"params,omitempty"`
+
+	// ParamOrder 按位置传参（params 为数组）时，参数的顺序
+	ParamOrder []string `json:"paramOrder,omitempty"`
+
+	// Result 结果的 JSON Schema
+	Result *openapi.Schema `json:"result"`
+}
+
+type discoverMethods struct {
+	mux     sync.RWMutex
+	methods []*MethodDesc
+}
+
+func (d *discoverMethods) add(desc *MethodDesc) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	d.methods = slices.DeleteFunc(d.methods, func(m *MethodDesc) bool {
+		return m.Name == desc.Name
+	})
+	d.methods = append(d.methods, desc)
+	sort.Slice(d.methods, func(i, j int) bool {
+		return d.methods[i].Name < d.methods[j].Name
+	})
+}
+
+func (d *discoverMethods) clone() *discoverMethods {
+	d.mux.RLock()
+	defer d.mux.RUnlock()
+	return &discoverMethods{methods: slices.Clone(d.methods)}
+}
+
+func (d *discoverMethods) handle(ctx context.Context, req *Request) (any, error) {
+	d.mux.RLock()
+	defer d.mux.RUnlock()
+	return &DiscoverResult{Methods: slices.Clone(d.methods)}, nil
+}
+
+type serviceMethod struct {
+	fn      reflect.Value
+	argType reflect.Type // 为 nil 时，表示没有参数
+	fields  []string     // Args 为结构体时，字段的 JSON 名称
+	desc    *MethodDesc
+}
+
+func newServiceMethod(fn reflect.Value) (*serviceMethod, bool) {
+	ft := fn.Type()
+	if ft.NumIn() < 1 || ft.NumIn() > 2 || ft.In(0) != contextType {
+		return nil, false
+	}
+	if ft.NumOut() != 2 || ft.Out(1) != errorType {
+		return nil, false
+	}
+	sm := &serviceMethod{
+		fn: fn,
+		desc: &MethodDesc{
+			Result: openapi.JSONSchema(reflect.New(ft.Out(0)).Interface()),
+		},
+	}
+	if ft.NumIn() == 2 {
+		sm.argType = ft.In(1)
+		at := derefType(sm.argType)
+		sm.desc.Params = openapi.JSONSchema(reflect.New(at).Interface())
+		if at.Kind() == reflect.Struct {
+			sm.fields = jsonFields(at)
+			sm.desc.ParamOrder = sm.fields
+		}
+	}
+	return sm, true
+}
+
+func derefType(rt reflect.Type) reflect.Type {
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	return rt
+}
+
+// jsonFields 结构体的 JSON 字段名，按照字段的顺序，匿名的结构体字段会展开
+func jsonFields(rt reflect.Type) []string {
+	var names []string
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.Tag.Get("json") == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if sf.Anonymous && name == "" {
+			if ft := derefType(sf.Type); ft.Kind() == reflect.Struct {
+				names = append(names, jsonFields(ft)...)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func (sm *serviceMethod) handle(ctx context.Context, req *Request) (any, error) {
+	in := []reflect.Value{reflect.ValueOf(ctx)}
+	if sm.argType != nil {
+		arg, err := sm.decodeParams(req.Params)
+		if err != nil {
+			return nil, errors.Join(ErrInvalidParams, err)
+		}
+		in = append(in, arg)
+	}
+	out := sm.fn.Call(in)
+	if err, _ := out[1].Interface().(error); err != nil {
+		re := ToError(err)
+		if re.Code == ErrInternal.Code {
+			xlog.Warn(ctx, "xjsonrpc2: handle request failed", xlog.String("method", req.Method), xlog.ErrorAttr("error", err))
+		}
+		return nil, re
+	}
+	return out[0].Interface(), nil
+}
+
+func (sm *serviceMethod) decodeParams(params []byte) (reflect.Value, error) {
+	at := derefType(sm.argType)
+	pv := reflect.New(at)
+	if len(params) > 0 && params[0] == '[' && at.Kind() != reflect.Slice && at.Kind() != reflect.Array {
+		var items []json.RawMessage
+		if err := xcodec.JSON.Decode(params, &items); err != nil {
+			return reflect.Value{}, err
+		}
+		if at.Kind() == reflect.Struct {
+			if len(items) > len(sm.fields) {
+				return reflect.Value{}, fmt.Errorf("too many params, expect at most %d", len(sm.fields))
+			}
+			obj := make(map[string]json.RawMessage, len(items))
+			for i, item := range items {
+				obj[sm.fields[i]] = item
+			}
+			bf, err := xcodec.JSON.Encode(obj)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			params = bf
+		} else {
+			if len(items) != 1 {
+				return reflect.Value{}, fmt.Errorf("expect 1 param, got %d", len(items))
+			}
+			params = items[0]
+		}
+	}
+	if len(params) > 0 && string(params) != "null" {
+		if err := xcodec.JSON.Decode(params, pv.Interface()); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	if err := xvalidator.Validate(pv.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	if sm.argType == at {
+		return pv.Elem(), nil
+	}
+	// 转换为方法参数的类型，如 **Args
+	v := pv
+	for v.Type() != sm.argType {
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		v = p
+	}
+	return v, nil
+}
+
+// ToError 将 error 转换为 JSON-RPC 的错误：
+//
+//	*Error:                        原样返回
+//	xerror.InvalidParam（或其错误码）: invalid params（-32602）
+//	有错误码（xerror.HasErrCode）:     使用其错误码
+//	其他:                            internal error（-32603）
+//
+// internal error 的 Message 固定为 ErrInternal.Message，避免将内部错误的细节泄露给对端，
+// 其他情况的 Message 为 err.Error()
+func ToError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var re *Error
+	if errors.As(err, &re) {
+		return re
+	}
+	code, ok := xerror.ErrCode2(err)
+	switch {
+	case xerror.IsInvalidParam(err), code == xerror.CodeInvalidParam:
+		code = ErrInvalidParams.Code
+	case !ok || code == 0:
+		return &Error{Code: ErrInternal.Code, Message: ErrInternal.Message}
+	}
+	return &Error{Code: code, Message: err.Error()}
+}
@@ -0,0 +1,122 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xjsonrpc2_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/xanygo/anygo/xerror"
+	"github.com/xanygo/anygo/xnet/xjsonrpc2"
+	"github.com/xanygo/anygo/xt"
+)
+
+type testArithArgs struct {
+	A int `json:"a"`
+	B int `json:"b" validator:"required"`
+}
+
+type testArith struct{}
+
+func (testArith) Div(ctx context.Context, args *testArithArgs) (int, error) {
+	return args.A / args.B, nil
+}
+
+func (testArith) Sum(ctx context.Context, nums []int) (int, error) {
+	var total int
+	for _, n := range nums {
+		total += n
+	}
+	return total, nil
+}
+
+func (testArith) Square(ctx context.Context, n int) (int, error) {
+	return n * n, nil
+}
+
+func (testArith) Find(ctx context.Context, name string) (string, error) {
+	return "", xerror.NotFound
+}
+
+func (testArith) Fail(ctx context.Context) (string, error) {
+	return "", errors.New("oops")
+}
+
+// NotRPC 签名不符合，会被忽略
+func (testArith) NotRPC(a int) int {
+	return a
+}
+
+func TestRouter_RegisterService(t *testing.T) {
+	router := xjsonrpc2.NewRouter()
+	xt.NoError(t, router.RegisterService("arith", testArith{}))
+	xt.Error(t, router.RegisterService("bad", struct{}{}))
+
+	ca, cb := net.Pipe()
+	client := xjsonrpc2.NewConn(t.Context(), ca, nil)
+	server := xjsonrpc2.NewConn(t.Context(), cb, router)
+	defer server.Close()
+	defer client.Close()
+
+	codeOf := func(err error) int64 {
+		var re *xjsonrpc2.Error
+		xt.True(t, errors.As(err, &re))
+		return re.Code
+	}
+
+	var got int
+	xt.NoError(t, client.Call(t.Context(), "arith.Div", map[string]int{"a": 9, "b": 3}, &got))
+	xt.Equal(t, got, 3)
+	xt.NoError(t, client.Call(t.Context(), "arith.Div", []int{8, 2}, &got))
+	xt.Equal(t, got, 4)
+	xt.NoError(t, client.Call(t.Context(), "arith.Sum", []int{1, 2, 3}, &got))
+	xt.Equal(t, got, 6)
+	xt.NoError(t, client.Call(t.Context(), "arith.Square", 3, &got))
+	xt.Equal(t, got, 9)
+	xt.NoError(t, client.Call(t.Context(), "arith.Square", []int{4}, &got))
+	xt.Equal(t, got, 16)
+
+	// 校验失败：b 为 0
+	err := client.Call(t.Context(), "arith.Div", map[string]int{"a": 1}, &got)
+	xt.Equal(t, codeOf(err), xjsonrpc2.ErrInvalidParams.Code)
+	err = client.Call(t.Context(), "arith.Div", []int{1, 2, 3}, &got)
+	xt.Equal(t, codeOf(err), xjsonrpc2.ErrInvalidParams.Code)
+	err = client.Call(t.Context(), "arith.Div", "abc", &got)
+	xt.Equal(t, codeOf(err), xjsonrpc2.ErrInvalidParams.Code)
+
+	err = client.Call(t.Context(), "arith.Find", "abc", nil)
+	xt.Equal(t, codeOf(err), xerror.CodeNotFound)
+	err = client.Call(t.Context(), "arith.Fail", nil, nil)
+	xt.Equal(t, codeOf(err), xjsonrpc2.ErrInternal.Code)
+	err = client.Call(t.Context(), "arith.NotRPC", nil, nil)
+	xt.Equal(t, codeOf(err), xjsonrpc2.ErrMethodNotFound.Code)
+
+	t.Run("discover", func(t *testing.T) {
+		result := &xjsonrpc2.DiscoverResult{}
+		xt.NoError(t, client.Call(t.Context(), xjsonrpc2.MethodDiscover, nil, result))
+		var names []string
+		for _, m := range result.Methods {
+			names = append(names, m.Name)
+		}
+		xt.Equal(t, names, []string{"arith.Div", "arith.Fail", "arith.Find", "arith.Square", "arith.Sum"})
+		div := result.Methods[0]
+		xt.Equal(t, div.ParamOrder, []string{"a", "b"})
+		xt.Equal(t, div.Params.Type, "object")
+		xt.Equal(t, div.Result.Type, "integer")
+		xt.Nil(t, result.Methods[1].Params)
+	})
+}
+
+func TestToError(t *testing.T) {
+	xt.Nil(t, xjsonrpc2.ToError(nil))
+	xt.Equal(t, xjsonrpc2.ToError(xjsonrpc2.ErrParse), xjsonrpc2.ErrParse)
+	xt.Equal(t, xjsonrpc2.ToError(xerror.InvalidParam).Code, xjsonrpc2.ErrInvalidParams.Code)
+	xt.Equal(t, xjsonrpc2.ToError(xerror.NotFound).Code, xerror.CodeNotFound)
+	xt.Equal(t, xjsonrpc2.ToError(errors.New("x")).Code, xjsonrpc2.ErrInternal.Code)
+	xt.Equal(t, xjsonrpc2.ToError(errors.New("x")).Message, xjsonrpc2.ErrInternal.Message)
+	xt.Equal(t, xjsonrpc2.ToError(xerror.NotFound).Message, xerror.NotFound.Error())
+}
@@ -0,0 +1,71 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-19
+
+package xjsonrpc2
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Stream 双向收发消息的流，每条消息是一个完整的 JSON 对象（批量消息为 JSON 数组）。
+// 数据流（如 stdio、TCP）可以使用 NewFramedStream 创建，
+// HTTP、WebSocket 等以消息为单位的传输方式可以直接实现该接口
+type Stream interface {
+	// Read 读取一条消息，流结束时返回 io.EOF
+	Read(ctx context.Context) (json.RawMessage, error)
+
+	// Write 发送一条消息，可以并发调用
+	Write(ctx context.Context, msg json.RawMessage) error
+
+	Close() error
+}
+
+// NewFramedStream 在数据流 rwc 上创建 Stream，消息使用 framer 分帧，framer 为 nil 时使用 NewlineFramer
+func NewFramedStream(rwc io.ReadWriteCloser, framer Framer) Stream {
+	if framer == nil {
+		framer = NewlineFramer
+	}
+	return &framedStream{
+		rwc:    rwc,
+		framer: framer,
+		br:     bufio.NewReader(rwc),
+		bw:     bufio.NewWriter(rwc),
+	}
+}
+
+var _ Stream = (*framedStream)(nil)
+
+type framedStream struct {
+	rwc    io.ReadWriteCloser
+	framer Framer
+
+	rmux sync.Mutex
+	br   *bufio.Reader
+
+	wmux sync.Mutex
+	bw   *bufio.Writer
+}
+
+func (fs *framedStream) Read(ctx context.Context) (json.RawMessage, error) {
+	fs.rmux.Lock()
+	defer fs.rmux.Unlock()
+	return fs.framer.ReadMessage(fs.br)
+}
+
+func (fs *framedStream) Write(ctx context.Context, msg json.RawMessage) error {
+	fs.wmux.Lock()
+	defer fs.wmux.Unlock()
+	if err := fs.framer.WriteMessage(fs.bw, msg); err != nil {
+		return err
+	}
+	return fs.bw.Flush()
+}
+
+func (fs *framedStream) Close() error {
+	return fs.rwc.Close()
+}
@@ -0,0 +1,324 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xmcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/xanygo/anygo/xlog"
+	"github.com/xanygo/anygo/xnet/xjsonrpc2"
+)
+
+// Client MCP 客户端，使用 Connect 连接服务端并完成初始化握手：
+//
+//	client := &xmcp.Client{Info: xmcp.Implementation{Name: "demo", Version: "1.0"}}
+//	session, err := client.Connect(ctx, &xmcp.CommandTransport{Path: "npx", Args: []string{"-y", "chrome-devtools-mcp@latest"}})
+//	defer session.Close()
+//	result, err := session.CallTool(ctx, "navigate_page", map[string]any{"url": "https://example.com"})
+type Client struct {
+	// Info 必填，客户端的名称和版本
+	Info Implementation
+
+	// Capabilities 可选，客户端的能力
+	Capabilities ClientCapabilities
+
+	// ProtocolVersion 可选，请求使用的协议版本，默认为 LatestProtocolVersion
+	ProtocolVersion string
+
+	// Handler 可选，处理服务端发送的请求和通知，如 notifications/tools/list_changed、notifications/message、roots/list。
+	// ping 请求和 notifications/progress 通知会自动处理。
+	// 若为 nil，服务端发送的请求会返回 method not found 错误，通知会被忽略
+	Handler xjsonrpc2.Handler
+
+	xlog.WithLogger
+}
+
+func (c *Client) getProtocolVersion() string {
+	if c.ProtocolVersion != "" {
+		return c.ProtocolVersion
+	}
+	return LatestProtocolVersion
+}
+
+// Connect 使用 transport 连接服务端，并完成初始化握手（initialize 请求和 notifications/initialized 通知）
+func (c *Client) Connect(ctx context.Context, transport Transport) (*ClientSession, error) {
+	conn, err := transport.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s := newClientSession(c, conn)
+	params := &InitializeParams{
+		ProtocolVersion: c.getProtocolVersion(),
+		Capabilities:    c.Capabilities,
+		ClientInfo:      c.Info,
+	}
+	result := &InitializeResult{}
+	if err = s.Call(ctx, MethodInitialize, params, result); err != nil {
+		_ = s.Close()
+		return nil, fmt.Errorf("xmcp: initialize: %w", err)
+	}
+	if !isSupportedVersion(result.ProtocolVersion) {
+		_ = s.Close()
+		return nil, fmt.Errorf("xmcp: unsupported protocol version %q", result.ProtocolVersion)
+	}
+	s.initResult = result
+	if vs, ok := conn.(versionSetter); ok {
+		vs.setProtocolVersion(result.ProtocolVersion)
+	}
+	if err = s.Notify(ctx, NotificationInitialized, nil); err != nil {
+		_ = s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// ClientSession 客户端和服务端的一个会话，可以并发调用
+type ClientSession struct {
+	client     *Client
+	conn       *xjsonrpc2.Conn
+	initResult *InitializeResult
+
+	nextToken atomic.Int64
+
+	mux      sync.Mutex
+	progress map[string]func(p *ProgressParams)
+}
+
+func newClientSession(c *Client, conn Conn) *ClientSession {
+	s := &ClientSession{
+		client:   c,
+		progress: make(map[string]func(p *ProgressParams)),
+	}
+	s.conn = xjsonrpc2.NewConnWithOption(context.Background(), conn, xjsonrpc2.ConnOption{
+		Handler:       xjsonrpc2.HandlerFunc(s.handle),
+		CancelRequest: cancelRequest,
+		Ordered: func(req *xjsonrpc2.Request) bool {
+			// 保证进度通知的顺序
+			return req.Method == NotificationProgress
+		},
+	})
+	return s
+}
+
+// cancelRequest 调用的 ctx 结束时，发送 notifications/cancelled 通知，initialize 请求不能取消
+func cancelRequest(id xjsonrpc2.ID, method string, cause error) (*xjsonrpc2.Request, error) {
+	if method == MethodInitialize {
+		return nil, nil
+	}
+	params := map[string]any{"requestId": json.RawMessage(id.Bytes()), "reason": cause.Error()}
+	return xjsonrpc2.NewRequest(nil, NotificationCancelled, params)
+}
+
+// InitializeResult 服务端初始化的结果，包括服务端的信息和能力
+func (s *ClientSession) InitializeResult() *InitializeResult {
+	return s.initResult
+}
+
+// Done 会话结束（调用 Close 或者连接断开）时会被关闭
+func (s *ClientSession) Done() <-chan struct{} {
+	return s.conn.Done()
+}
+
+// Err 会话结束的原因，会话未结束时返回 nil
+func (s *ClientSession) Err() error {
+	select {
+	case <-s.conn.Done():
+		return s.conn.Err()
+	default:
+		return nil
+	}
+}
+
+// Close 关闭会话和连接
+func (s *ClientSession) Close() error {
+	return s.conn.CloseNoWait()
+}
+
+func (s *ClientSession) handle(ctx context.Context, w xjsonrpc2.ResponseWriter, req *xjsonrpc2.Request) error {
+	var err error
+	switch {
+	case req.Method == NotificationProgress:
+		s.handleProgress(req)
+	case req.Method == MethodPing:
+		err = reply(w, req, struct{}{})
+	case s.client.Handler != nil:
+		err = s.client.Handler.Handle(ctx, w, req)
+	default:
+		err = xjsonrpc2.NotFound(ctx, w, req)
+	}
+	if err != nil {
+		s.client.AutoLogger().Warn(ctx, "xmcp: handle request failed", xlog.String("method", req.Method), xlog.ErrorAttr("error", err))
+	}
+	return err
+}
+
+func reply(w xjsonrpc2.ResponseWriter, req *xjsonrpc2.Request, data any) error {
+	if req.NoReply() {
+		return nil
+	}
+	resp, err := xjsonrpc2.NewResponse(req.ID, data, nil)
+	if err != nil {
+		return err
+	}
+	return w.Write(resp)
+}
+
+func (s *ClientSession) handleProgress(req *xjsonrpc2.Request) {
+	p := &ProgressParams{}
+	if err := req.DecodeParams(p); err != nil {
+		return
+	}
+	s.mux.Lock()
+	fn := s.progress[fmt.Sprint(p.ProgressToken)]
+	s.mux.Unlock()
+	if fn != nil {
+		fn(p)
+	}
+}
+
+// Call 发送请求并等待响应，result 为 nil 时，忽略响应的结果。
+// 若 ctx 在收到响应之前结束，会发送 notifications/cancelled 通知
+func (s *ClientSession) Call(ctx context.Context, method string, params any, result any) error {
+	return s.conn.Call(ctx, method, params, result)
+}
+
+// Notify 发送通知消息
+func (s *ClientSession) Notify(ctx context.Context, method string, params any) error {
+	return s.conn.Notify(ctx, method, params)
+}
+
+// Ping 检查服务端是否存活
+func (s *ClientSession) Ping(ctx context.Context) error {
+	return s.Call(ctx, MethodPing, nil, nil)
+}
+
+// ListTools 读取所有的工具（会自动读取所有分页）
+func (s *ClientSession) ListTools(ctx context.Context) ([]*Tool, error) {
+	return listAll(ctx, s, MethodToolsList, func(r *ListToolsResult) ([]*Tool, string) {
+		return r.Tools, r.NextCursor
+	})
+}
+
+// CallTool 调用工具，args 为工具的参数，可以是 map 或者结构体。
+// 工具执行失败时，返回的 CallToolResult.IsError 为 true，而不是返回 error。
+//
+// 可以使用 WithProgress 接收处理进度的通知
+func (s *ClientSession) CallTool(ctx context.Context, name string, args any) (*CallToolResult, error) {
+	params := &CallToolParams{Name: name}
+	if args != nil {
+		bf, err := json.Marshal(args)
+		if err != nil {
+			return nil, err
+		}
+		params.Arguments = bf
+	}
+	if fn := progressFromContext(ctx); fn != nil {
+		token := "p" + strconv.FormatInt(s.nextToken.Add(1), 10)
+		params.Meta = &Meta{ProgressToken: token}
+		s.mux.Lock()
+		s.progress[token] = fn
+		s.mux.Unlock()
+		defer func() {
+			s.mux.Lock()
+			delete(s.progress, token)
+			s.mux.Unlock()
+		}()
+	}
+	result := &CallToolResult{}
+	if err := s.Call(ctx, MethodToolsCall, params, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListResources 读取所有的资源（会自动读取所有分页）
+func (s *ClientSession) ListResources(ctx context.Context) ([]*Resource, error) {
+	return listAll(ctx, s, MethodResourcesList, func(r *ListResourcesResult) ([]*Resource, string) {
+		return r.Resources, r.NextCursor
+	})
+}
+
+// ListResourceTemplates 读取所有的资源模板（会自动读取所有分页）
+func (s *ClientSession) ListResourceTemplates(ctx context.Context) ([]*ResourceTemplate, error) {
+	return listAll(ctx, s, MethodResourcesTemplatesList, func(r *ListResourceTemplatesResult) ([]*ResourceTemplate, string) {
+		return r.ResourceTemplates, r.NextCursor
+	})
+}
+
+// ReadResource 读取资源的内容
+func (s *ClientSession) ReadResource(ctx context.Context, uri string) (*ReadResourceResult, error) {
+	result := &ReadResourceResult{}
+	if err := s.Call(ctx, MethodResourcesRead, &ResourceParams{URI: uri}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Subscribe 订阅资源的变更，变更时服务端会发送 notifications/resources/updated 通知（由 Client.Handler 处理）
+func (s *ClientSession) Subscribe(ctx context.Context, uri string) error {
+	return s.Call(ctx, MethodResourcesSubscribe, &ResourceParams{URI: uri}, nil)
+}
+
+// Unsubscribe 取消订阅资源的变更
+func (s *ClientSession) Unsubscribe(ctx context.Context, uri string) error {
+	return s.Call(ctx, MethodResourcesUnsubscribe, &ResourceParams{URI: uri}, nil)
+}
+
+// ListPrompts 读取所有的提示词模板（会自动读取所有分页）
+func (s *ClientSession) ListPrompts(ctx context.Context) ([]*Prompt, error) {
+	return listAll(ctx, s, MethodPromptsList, func(r *ListPromptsResult) ([]*Prompt, string) {
+		return r.Prompts, r.NextCursor
+	})
+}
+
+// GetPrompt 读取提示词
+func (s *ClientSession) GetPrompt(ctx context.Context, name string, args map[string]string) (*GetPromptResult, error) {
+	result := &GetPromptResult{}
+	if err := s.Call(ctx, MethodPromptsGet, &GetPromptParams{Name: name, Arguments: args}, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SetLoggingLevel 设置服务端发送日志通知（notifications/message）的最低等级
+func (s *ClientSession) SetLoggingLevel(ctx context.Context, level LoggingLevel) error {
+	return s.Call(ctx, MethodLoggingSetLevel, &SetLevelParams{Level: level}, nil)
+}
+
+// listAll 读取分页列表的所有数据
+func listAll[R any, T any](ctx context.Context, s *ClientSession, method string, items func(r *R) ([]T, string)) ([]T, error) {
+	var all []T
+	params := &ListParams{}
+	for {
+		result := new(R)
+		if err := s.Call(ctx, method, params, result); err != nil {
+			return nil, err
+		}
+		list, next := items(result)
+		all = append(all, list...)
+		if next == "" || next == params.Cursor {
+			return all, nil
+		}
+		params.Cursor = next
+	}
+}
+
+type ctxKeyProgress struct{}
+
+// WithProgress 返回携带处理进度回调的 ctx，使用该 ctx 调用 ClientSession.CallTool 时，
+// 服务端发送的 notifications/progress 通知会按顺序回调 fn
+func WithProgress(ctx context.Context, fn func(p *ProgressParams)) context.Context {
+	return context.WithValue(ctx, ctxKeyProgress{}, fn)
+}
+
+func progressFromContext(ctx context.Context) func(p *ProgressParams) {
+	fn, _ := ctx.Value(ctxKeyProgress{}).(func(p *ProgressParams))
+	return fn
+}
@@ -0,0 +1,7 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+// Package xmcp 基于 xjsonrpc2 实现的 MCP（Model Context Protocol）客户端和服务端，
+// 支持 stdio、Streamable HTTP 和 HTTP+SSE 三种传输方式
+package xmcp
@@ -0,0 +1,318 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xmcp
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// LatestProtocolVersion 默认使用的 MCP 协议版本
+const LatestProtocolVersion = "2025-06-18"
+
+// SupportedProtocolVersions 支持的 MCP 协议版本，从新到旧
+var SupportedProtocolVersions = []string{LatestProtocolVersion, "2025-03-26", "2024-11-05"}
+
+func isSupportedVersion(version string) bool {
+	return slices.Contains(SupportedProtocolVersions, version)
+}
+
+// MCP 的方法名
+const (
+	MethodInitialize             = "initialize"
+	MethodPing                   = "ping"
+	MethodToolsList              = "tools/list"
+	MethodToolsCall              = "tools/call"
+	MethodResourcesList          = "resources/list"
+	MethodResourcesTemplatesList = "resources/templates/list"
+	MethodResourcesRead          = "resources/read"
+	MethodResourcesSubscribe     = "resources/subscribe"
+	MethodResourcesUnsubscribe   = "resources/unsubscribe"
+	MethodPromptsList            = "prompts/list"
+	MethodPromptsGet             = "prompts/get"
+	MethodLoggingSetLevel        = "logging/setLevel"
+
+	NotificationInitialized          = "notifications/initialized"
+	NotificationProgress             = "notifications/progress"
+	NotificationCancelled            = "notifications/cancelled"
+	NotificationMessage              = "notifications/message"
+	NotificationToolsListChanged     = "notifications/tools/list_changed"
+	NotificationResourcesListChanged = "notifications/resources/list_changed"
+	NotificationResourceUpdated      = "notifications/resources/updated"
+	NotificationPromptsListChanged   = "notifications/prompts/list_changed"
+)
+
+// Implementation 客户端或者服务端的名称和版本
+type Implementation struct {
+	Name    string `json:"name"`
+	Title   string `json:"title,omitempty"`
+	Version string `json:"version"`
+}
+
+// Meta 请求参数中的 _meta 字段
+type Meta struct {
+	// ProgressToken 若不为空，服务端可以使用 notifications/progress 通知处理进度
+	ProgressToken any `json:"progressToken,omitempty"`
+}
+
+type ClientCapabilities struct {
+	Roots        *ListChangedCapability `json:"roots,omitempty"`
+	Sampling     *struct{}              `json:"sampling,omitempty"`
+	Elicitation  *struct{}              `json:"elicitation,omitempty"`
+	Experimental map[string]any         `json:"experimental,omitempty"`
+}
+
+type ServerCapabilities struct {
+	Tools        *ListChangedCapability `json:"tools,omitempty"`
+	Resources    *ResourcesCapability   `json:"resources,omitempty"`
+	Prompts      *ListChangedCapability `json:"prompts,omitempty"`
+	Logging      *struct{}              `json:"logging,omitempty"`
+	Completions  *struct{}              `json:"completions,omitempty"`
+	Experimental map[string]any         `json:"experimental,omitempty"`
+}
+
+type ListChangedCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+type ResourcesCapability struct {
+	Subscribe   bool `json:"subscribe,omitempty"`
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+type InitializeParams struct {
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ClientCapabilities `json:"capabilities"`
+	ClientInfo      Implementation     `json:"clientInfo"`
+}
+
+type InitializeResult struct {
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ServerCapabilities `json:"capabilities"`
+	ServerInfo      Implementation     `json:"serverInfo"`
+	Instructions    string             `json:"instructions,omitempty"`
+}
+
+// ListParams tools/list、resources/list、prompts/list 等分页列表的请求参数
+type ListParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// Tool 工具的定义
+type Tool struct {
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// InputSchema 参数的 JSON Schema，type 必须是 object，使用 AddTool 注册时会自动生成
+	InputSchema json.RawMessage `json:"inputSchema"`
+
+	// OutputSchema 可选，结构化结果（CallToolResult.StructuredContent）的 JSON Schema
+	OutputSchema json.RawMessage `json:"outputSchema,omitempty"`
+
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations 工具行为的提示信息
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    bool   `json:"readOnlyHint,omitempty"`
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"`
+	IdempotentHint  bool   `json:"idempotentHint,omitempty"`
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"`
+}
+
+type ListToolsResult struct {
+	Tools      []*Tool `json:"tools"`
+	NextCursor string  `json:"nextCursor,omitempty"`
+}
+
+type CallToolParams struct {
+	Meta      *Meta           `json:"_meta,omitempty"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// CallToolResult 调用工具的结果，工具执行失败时，IsError 为 true，Content 中为错误信息
+type CallToolResult struct {
+	Content           []*Content `json:"content"`
+	StructuredContent any        `json:"structuredContent,omitempty"`
+	IsError           bool       `json:"isError,omitempty"`
+}
+
+// Text 返回所有文本内容，使用换行符连接
+func (r *CallToolResult) Text() string {
+	var text string
+	for _, c := range r.Content {
+		if c.Type != ContentTypeText {
+			continue
+		}
+		if text != "" {
+			text += "\n"
+		}
+		text += c.Text
+	}
+	return text
+}
+
+// Content 的类型
+const (
+	ContentTypeText         = "text"
+	ContentTypeImage        = "image"
+	ContentTypeAudio        = "audio"
+	ContentTypeResourceLink = "resource_link"
+	ContentTypeResource     = "resource"
+)
+
+// Content 工具结果、提示词消息中的内容，依据 Type 使用不同的字段：
+//
+//	text:           Text
+//	image、audio:   Data（base64 编码）、MimeType
+//	resource_link:  URI、Name、MimeType 等
+//	resource:       Resource
+type Content struct {
+	Type     string            `json:"type"`
+	Text     string            `json:"text,omitempty"`
+	Data     string            `json:"data,omitempty"`
+	MimeType string            `json:"mimeType,omitempty"`
+	URI      string            `json:"uri,omitempty"`
+	Name     string            `json:"name,omitempty"`
+	Resource *ResourceContents `json:"resource,omitempty"`
+}
+
+// TextContent 创建文本类型的 Content
+func TextContent(text string) *Content {
+	return &Content{Type: ContentTypeText, Text: text}
+}
+
+// TextResult 创建只包含一个文本内容的 CallToolResult
+func TextResult(text string) *CallToolResult {
+	return &CallToolResult{Content: []*Content{TextContent(text)}}
+}
+
+// ErrorResult 创建工具执行失败的 CallToolResult
+func ErrorResult(err error) *CallToolResult {
+	return &CallToolResult{Content: []*Content{TextContent(err.Error())}, IsError: true}
+}
+
+// Resource 资源的定义
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+}
+
+// ResourceTemplate 资源模板，URITemplate 如 "file:///logs/{name}"
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+type ListResourcesResult struct {
+	Resources  []*Resource `json:"resources"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+}
+
+type ListResourceTemplatesResult struct {
+	ResourceTemplates []*ResourceTemplate `json:"resourceTemplates"`
+	NextCursor        string              `json:"nextCursor,omitempty"`
+}
+
+// ResourceParams resources/read、resources/subscribe、resources/unsubscribe 的请求参数
+type ResourceParams struct {
+	URI string `json:"uri"`
+}
+
+// ResourceContents 资源的内容，文本类型使用 Text，二进制类型使用 Blob（base64 编码）
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+type ReadResourceResult struct {
+	Contents []*ResourceContents `json:"contents"`
+}
+
+// Prompt 提示词模板的定义
+type Prompt struct {
+	Name        string            `json:"name"`
+	Title       string            `json:"title,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Arguments   []*PromptArgument `json:"arguments,omitempty"`
+}
+
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+type ListPromptsResult struct {
+	Prompts    []*Prompt `json:"prompts"`
+	NextCursor string    `json:"nextCursor,omitempty"`
+}
+
+type GetPromptParams struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+type GetPromptResult struct {
+	Description string           `json:"description,omitempty"`
+	Messages    []*PromptMessage `json:"messages"`
+}
+
+type PromptMessage struct {
+	Role    string   `json:"role"` // user 或者 assistant
+	Content *Content `json:"content"`
+}
+
+// ProgressParams notifications/progress 的参数
+type ProgressParams struct {
+	ProgressToken any     `json:"progressToken"`
+	Progress      float64 `json:"progress"`
+	Total         float64 `json:"total,omitempty"`
+	Message       string  `json:"message,omitempty"`
+}
+
+// LoggingLevel 日志等级，同 syslog
+type LoggingLevel string
+
+const (
+	LoggingDebug     LoggingLevel = "debug"
+	LoggingInfo      LoggingLevel = "info"
+	LoggingNotice    LoggingLevel = "notice"
+	LoggingWarning   LoggingLevel = "warning"
+	LoggingError     LoggingLevel = "error"
+	LoggingCritical  LoggingLevel = "critical"
+	LoggingAlert     LoggingLevel = "alert"
+	LoggingEmergency LoggingLevel = "emergency"
+)
+
+var loggingLevels = []LoggingLevel{LoggingDebug, LoggingInfo, LoggingNotice, LoggingWarning, LoggingError,
+	LoggingCritical, LoggingAlert, LoggingEmergency}
+
+func (l LoggingLevel) index() int {
+	return slices.Index(loggingLevels, l)
+}
+
+type SetLevelParams struct {
+	Level LoggingLevel `json:"level"`
+}
+
+// LoggingMessageParams notifications/message 的参数
+type LoggingMessageParams struct {
+	Level  LoggingLevel `json:"level"`
+	Logger string       `json:"logger,omitempty"`
+	Data   any          `json:"data"`
+}
@@ -0,0 +1,527 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xmcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xanygo/anygo/safely"
+	"github.com/xanygo/anygo/xcodec"
+	"github.com/xanygo/anygo/xhttp/openapi"
+	"github.com/xanygo/anygo/xnet/xjsonrpc2"
+	"github.com/xanygo/anygo/xvalidator"
+)
+
+// ErrResourceNotFound 资源不存在
+var ErrResourceNotFound = &xjsonrpc2.Error{Code: -32002, Message: "resource not found"}
+
+// ToolHandler 处理工具调用，工具执行失败时，应该返回 IsError 为 true 的 CallToolResult（如 ErrorResult），
+// 返回的 error 会作为 JSON-RPC 的错误（如参数错误）
+type ToolHandler func(ctx context.Context, params *CallToolParams) (*CallToolResult, error)
+
+// ResourceHandler 读取资源，uri 为请求的资源地址
+type ResourceHandler func(ctx context.Context, uri string) (*ReadResourceResult, error)
+
+// PromptHandler 读取提示词
+type PromptHandler func(ctx context.Context, params *GetPromptParams) (*GetPromptResult, error)
+
+// NewServer 创建 MCP 服务端
+func NewServer(info Implementation) *Server {
+	return &Server{
+		Info: info,
+	}
+}
+
+// Server MCP 服务端，使用 AddTool、AddResource、AddPrompt 注册能力后，可以：
+//  1. 使用 ServeStdio 或 ServeConn 在 stdio 上提供服务，或者直接使用 Router() 返回的 xjsonrpc2.Router
+//  2. 作为 http.Handler，提供 Streamable HTTP 服务，如 xhttp.Router 中 router.Handle("GET,POST,DELETE /mcp", server)
+//  3. 使用 SSEHandler 提供 HTTP+SSE（2024-11-05 版本）服务
+//
+// 只支持客户端发起的请求，暂不支持向客户端发送请求（如 sampling、roots/list）和列表变更的通知
+type Server struct {
+	// Info 必填，服务端的名称和版本
+	Info Implementation
+
+	// Instructions 可选，服务端的使用说明，会在初始化时返回给客户端
+	Instructions string
+
+	// SessionIdleTimeout 可选，HTTP 服务的会话空闲超时时间，超时后会话失效，默认为 30 分钟
+	SessionIdleTimeout time.Duration
+
+	// MaxSessions 可选，HTTP 服务同时存在的最大会话数，超过后创建会话的请求返回 503，默认为 1000
+	MaxSessions int
+
+	// AllowOrigins 可选，HTTP 服务允许的请求来源（Origin 请求头），如 "https://example.com"，"*" 表示允许所有。
+	// 为空时只允许同源以及 localhost、127.0.0.1、[::1] 的请求。
+	// 不满足的请求返回 403，用于防止 DNS 重绑定攻击。没有 Origin 请求头的请求（非浏览器的客户端）不校验
+	AllowOrigins []string
+
+	once   sync.Once
+	router *xjsonrpc2.Router
+
+	mux       sync.RWMutex
+	tools     []*serverTool
+	resources []*serverResource
+	templates []*serverTemplate
+	prompts   []*serverPrompt
+
+	logLevel atomic.Int32
+
+	sessions     sync.Map // session id -> *httpSession
+	sessionCount atomic.Int64
+}
+
+type serverTool struct {
+	tool    *Tool
+	handler ToolHandler
+}
+
+type serverResource struct {
+	resource *Resource
+	handler  ResourceHandler
+}
+
+type serverTemplate struct {
+	template *ResourceTemplate
+	reg      *regexp.Regexp
+	handler  ResourceHandler
+}
+
+type serverPrompt struct {
+	prompt  *Prompt
+	handler PromptHandler
+}
+
+// AddTool 注册工具，tool.InputSchema 为空时，使用 {"type":"object"}。同名的工具会被替换
+func (s *Server) AddTool(tool *Tool, handler ToolHandler) {
+	if len(tool.InputSchema) == 0 {
+		tool.InputSchema = json.RawMessage(`{"type":"object"}`)
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.tools = replaceOrAppend(s.tools, &serverTool{tool: tool, handler: handler}, func(t *serverTool) bool {
+		return t.tool.Name == tool.Name
+	})
+}
+
+// AddTool 注册类型化的工具：
+//  1. 使用 openapi.JSONSchema 生成 In 的 JSON Schema 作为 InputSchema（规则同 xhttp/openapi，支持 doc 和 validator tag），
+//     Out 为结构体时，生成 OutputSchema
+//  2. 调用时，将参数解析为 In，并使用 xvalidator 校验，失败时返回 invalid params 错误
+//  3. fn 返回 error 时，结果为 IsError 为 true 的 CallToolResult；
+//     否则 Out 为结构体时，同时作为 StructuredContent 和 JSON 文本内容返回，Out 为 CallToolResult 时直接返回
+//
+// 如：
+//
+//	type WeatherArgs struct {
+//		City string `json:"city" validator:"required" doc:"城市名称"`
+//	}
+//	xmcp.AddTool(server, &xmcp.Tool{Name: "weather", Description: "查询天气"}, func(ctx context.Context, args *WeatherArgs) (*Weather, error) {
+//		...
+//	})
+func AddTool[In any, Out any](s *Server, tool *Tool, fn func(ctx context.Context, in *In) (*Out, error)) {
+	if len(tool.InputSchema) == 0 {
+		tool.InputSchema = mustJSONSchema(new(In))
+	}
+	outType := reflect.TypeFor[Out]()
+	structured := outType.Kind() == reflect.Struct && outType != reflect.TypeFor[CallToolResult]()
+	if len(tool.OutputSchema) == 0 && structured {
+		tool.OutputSchema = mustJSONSchema(new(Out))
+	}
+	s.AddTool(tool, func(ctx context.Context, params *CallToolParams) (*CallToolResult, error) {
+		in := new(In)
+		if len(params.Arguments) > 0 && string(params.Arguments) != "null" {
+			if err := xcodec.JSON.Decode(params.Arguments, in); err != nil {
+				return nil, errors.Join(xjsonrpc2.ErrInvalidParams, err)
+			}
+		}
+		if err := xvalidator.Validate(in); err != nil {
+			return nil, errors.Join(xjsonrpc2.ErrInvalidParams, err)
+		}
+		out, err := fn(ctx, in)
+		if err != nil {
+			return ErrorResult(err), nil
+		}
+		if result, ok := any(out).(*CallToolResult); ok && result != nil {
+			return result, nil
+		}
+		if out == nil {
+			return &CallToolResult{Content: []*Content{}}, nil
+		}
+		if str, ok := any(out).(*string); ok {
+			return TextResult(*str), nil
+		}
+		bf, err := json.Marshal(out)
+		if err != nil {
+			return nil, err
+		}
+		result := TextResult(string(bf))
+		if structured {
+			result.StructuredContent = out
+		}
+		return result, nil
+	})
+}
+
+func mustJSONSchema(v any) json.RawMessage {
+	bf, err := json.Marshal(openapi.JSONSchema(v))
+	if err != nil {
+		panic(err)
+	}
+	return bf
+}
+
+// AddResource 注册资源，同 URI 的资源会被替换
+func (s *Server) AddResource(res *Resource, handler ResourceHandler) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.resources = replaceOrAppend(s.resources, &serverResource{resource: res, handler: handler}, func(r *serverResource) bool {
+		return r.resource.URI == res.URI
+	})
+}
+
+// templateVarReg 资源模板（RFC 6570）中的变量，如 {name}、{+path}
+var templateVarReg = regexp.MustCompile(`\{(\+?)[^{}]+}`)
+
+// AddResourceTemplate 注册资源模板，支持简单的变量（如 {name}，不包含 /）和保留字符变量（如 {+path}，可以包含 /）。
+// 读取资源时，若没有匹配的资源，会使用模板匹配
+func (s *Server) AddResourceTemplate(tpl *ResourceTemplate, handler ResourceHandler) {
+	var b strings.Builder
+	b.WriteString("^")
+	last := 0
+	for _, loc := range templateVarReg.FindAllStringSubmatchIndex(tpl.URITemplate, -1) {
+		b.WriteString(regexp.QuoteMeta(tpl.URITemplate[last:loc[0]]))
+		if loc[3] > loc[2] {
+			b.WriteString(".+")
+		} else {
+			b.WriteString("[^/]+")
+		}
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(tpl.URITemplate[last:]))
+	b.WriteString("$")
+	st := &serverTemplate{
+		template: tpl,
+		reg:      regexp.MustCompile(b.String()),
+		handler:  handler,
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.templates = replaceOrAppend(s.templates, st, func(t *serverTemplate) bool {
+		return t.template.URITemplate == tpl.URITemplate
+	})
+}
+
+// AddPrompt 注册提示词模板，同名的会被替换
+func (s *Server) AddPrompt(prompt *Prompt, handler PromptHandler) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.prompts = replaceOrAppend(s.prompts, &serverPrompt{prompt: prompt, handler: handler}, func(p *serverPrompt) bool {
+		return p.prompt.Name == prompt.Name
+	})
+}
+
+func replaceOrAppend[T any](list []T, item T, same func(T) bool) []T {
+	for i, v := range list {
+		if same(v) {
+			list[i] = item
+			return list
+		}
+	}
+	return append(list, item)
+}
+
+// Router 返回处理 MCP 请求的 xjsonrpc2.Router，可以使用其 Serve 方法在任意的双向数据流上提供服务
+func (s *Server) Router() *xjsonrpc2.Router {
+	s.once.Do(s.initRouter)
+	return s.router
+}
+
+// ServeStdio 使用当前进程的 stdin 和 stdout 提供服务，直到 stdin 关闭或者 ctx 结束
+func (s *Server) ServeStdio(ctx context.Context) error {
+	return s.ServeConn(ctx, os.Stdin, os.Stdout)
+}
+
+// ServeConn 从 rd 读取请求，并将响应写入 w，消息以换行符分隔
+func (s *Server) ServeConn(ctx context.Context, rd io.Reader, w io.Writer) error {
+	return s.Router().Serve(ctx, rd, w)
+}
+
+func (s *Server) initRouter() {
+	r := xjsonrpc2.NewRouter()
+	s.router = r
+	s.register(MethodInitialize, s.handleInitialize)
+	s.register(MethodPing, func(ctx context.Context, req *xjsonrpc2.Request) (any, error) {
+		return struct{}{}, nil
+	})
+	s.register(NotificationInitialized, noop)
+	s.register(NotificationCancelled, noop)
+	s.register(MethodToolsList, s.handleToolsList)
+	s.register(MethodToolsCall, s.handleToolsCall)
+	s.register(MethodResourcesList, s.handleResourcesList)
+	s.register(MethodResourcesTemplatesList, s.handleResourceTemplatesList)
+	s.register(MethodResourcesRead, s.handleResourcesRead)
+	s.register(MethodPromptsList, s.handlePromptsList)
+	s.register(MethodPromptsGet, s.handlePromptsGet)
+	s.register(MethodLoggingSetLevel, s.handleSetLevel)
+}
+
+func noop(ctx context.Context, req *xjsonrpc2.Request) (any, error) {
+	return nil, nil
+}
+
+// register 注册处理方法，若 ResponseWriter 支持发送通知，会将其放入 ctx，以支持 NotifyProgress 和 Log
+func (s *Server) register(method string, fn xjsonrpc2.UnaryHandlerFunc) {
+	s.router.Register(method, xjsonrpc2.HandlerFunc(func(ctx context.Context, w xjsonrpc2.ResponseWriter, req *xjsonrpc2.Request) error {
+		if n, ok := w.(xjsonrpc2.Notifier); ok {
+			var params struct {
+				Meta *Meta `json:"_meta"`
+			}
+			_ = json.Unmarshal(req.Params, &params)
+			nc := &notifyContext{server: s, notifier: n}
+			if params.Meta != nil {
+				nc.progressToken = params.Meta.ProgressToken
+			}
+			ctx = context.WithValue(ctx, ctxKeyNotify{}, nc)
+		}
+		return fn.Handle(ctx, w, req)
+	}))
+}
+
+func (s *Server) capabilities() ServerCapabilities {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	c := ServerCapabilities{
+		Logging: &struct{}{},
+	}
+	if len(s.tools) > 0 {
+		c.Tools = &ListChangedCapability{}
+	}
+	if len(s.resources) > 0 || len(s.templates) > 0 {
+		c.Resources = &ResourcesCapability{}
+	}
+	if len(s.prompts) > 0 {
+		c.Prompts = &ListChangedCapability{}
+	}
+	return c
+}
+
+func (s *Server) handleInitialize(ctx context.Context, req *xjsonrpc2.Request) (any, error) {
+	params := &InitializeParams{}
+	if err := req.DecodeParams(params); err != nil {
+		return nil, err
+	}
+	version := params.ProtocolVersion
+	if !isSupportedVersion(version) {
+		version = LatestProtocolVersion
+	}
+	return &InitializeResult{
+		ProtocolVersion: version,
+		Capabilities:    s.capabilities(),
+		ServerInfo:      s.Info,
+		Instructions:    s.Instructions,
+	}, nil
+}
+
+func (s *Server) handleToolsList(ctx context.Context, req *xjsonrpc2.Request) (any, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	result := &ListToolsResult{Tools: make([]*Tool, 0, len(s.tools))}
+	for _, t := range s.tools {
+		result.Tools = append(result.Tools, t.tool)
+	}
+	return result, nil
+}
+
+func (s *Server) handleToolsCall(ctx context.Context, req *xjsonrpc2.Request) (any, error) {
+	params := &CallToolParams{}
+	if err := req.DecodeParams(params); err != nil {
+		return nil, err
+	}
+	s.mux.RLock()
+	var handler ToolHandler
+	for _, t := range s.tools {
+		if t.tool.Name == params.Name {
+			handler = t.handler
+			break
+		}
+	}
+	s.mux.RUnlock()
+	if handler == nil {
+		return nil, errors.Join(xjsonrpc2.ErrInvalidParams, fmt.Errorf("unknown tool %q", params.Name))
+	}
+	var result *CallToolResult
+	err := safely.RunCtx(ctx, func(ctx context.Context) (err error) {
+		result, err = handler(ctx, params)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		result = &CallToolResult{}
+	}
+	if result.Content == nil {
+		result.Content = []*Content{}
+	}
+	return result, nil
+}
+
+func (s *Server) handleResourcesList(ctx context.Context, req *xjsonrpc2.Request) (any, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	result := &ListResourcesResult{Resources: make([]*Resource, 0, len(s.resources))}
+	for _, r := range s.resources {
+		result.Resources = append(result.Resources, r.resource)
+	}
+	return result, nil
+}
+
+func (s *Server) handleResourceTemplatesList(ctx context.Context, req *xjsonrpc2.Request) (any, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	result := &ListResourceTemplatesResult{ResourceTemplates: make([]*ResourceTemplate, 0, len(s.templates))}
+	for _, t := range s.templates {
+		result.ResourceTemplates = append(result.ResourceTemplates, t.template)
+	}
+	return result, nil
+}
+
+func (s *Server) handleResourcesRead(ctx context.Context, req *xjsonrpc2.Request) (any, error) {
+	params := &ResourceParams{}
+	if err := req.DecodeParams(params); err != nil {
+		return nil, err
+	}
+	handler := s.findResource(params.URI)
+	if handler == nil {
+		return nil, ErrResourceNotFound
+	}
+	return handler(ctx, params.URI)
+}
+
+func (s *Server) findResource(uri string) ResourceHandler {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	for _, r := range s.resources {
+		if r.resource.URI == uri {
+			return r.handler
+		}
+	}
+	for _, t := range s.templates {
+		if t.reg.MatchString(uri) {
+			return t.handler
+		}
+	}
+	return nil
+}
+
+func (s *Server) handlePromptsList(ctx context.Context, req *xjsonrpc2.Request) (any, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	result := &ListPromptsResult{Prompts: make([]*Prompt, 0, len(s.prompts))}
+	for _, p := range s.prompts {
+		result.Prompts = append(result.Prompts, p.prompt)
+	}
+	return result, nil
+}
+
+func (s *Server) handlePromptsGet(ctx context.Context, req *xjsonrpc2.Request) (any, error) {
+	params := &GetPromptParams{}
+	if err := req.DecodeParams(params); err != nil {
+		return nil, err
+	}
+	s.mux.RLock()
+	var sp *serverPrompt
+	for _, p := range s.prompts {
+		if p.prompt.Name == params.Name {
+			sp = p
+			break
+		}
+	}
+	s.mux.RUnlock()
+	if sp == nil {
+		return nil, errors.Join(xjsonrpc2.ErrInvalidParams, fmt.Errorf("unknown prompt %q", params.Name))
+	}
+	for _, arg := range sp.prompt.Arguments {
+		if _, ok := params.Arguments[arg.Name]; arg.Required && !ok {
+			return nil, errors.Join(xjsonrpc2.ErrInvalidParams, fmt.Errorf("missing required argument %q", arg.Name))
+		}
+	}
+	return sp.handler(ctx, params)
+}
+
+func (s *Server) handleSetLevel(ctx context.Context, req *xjsonrpc2.Request) (any, error) {
+	params := &SetLevelParams{}
+	if err := req.DecodeParams(params); err != nil {
+		return nil, err
+	}
+	index := params.Level.index()
+	if index < 0 {
+		return nil, errors.Join(xjsonrpc2.ErrInvalidParams, fmt.Errorf("invalid level %q", params.Level))
+	}
+	s.logLevel.Store(int32(index))
+	return struct{}{}, nil
+}
+
+type ctxKeyNotify struct{}
+
+type notifyContext struct {
+	server        *Server
+	notifier      xjsonrpc2.Notifier
+	progressToken any
+}
+
+func notifyFromContext(ctx context.Context) *notifyContext {
+	nc, _ := ctx.Value(ctxKeyNotify{}).(*notifyContext)
+	return nc
+}
+
+// NotifyProgress 在工具等的处理过程中，向客户端发送处理进度的通知（notifications/progress），
+// total 为 0 表示总数未知。若客户端的请求中没有 progressToken，不会发送
+func NotifyProgress(ctx context.Context, progress float64, total float64, message string) error {
+	nc := notifyFromContext(ctx)
+	if nc == nil || nc.progressToken == nil {
+		return nil
+	}
+	req, err := xjsonrpc2.NewRequest(nil, NotificationProgress, &ProgressParams{
+		ProgressToken: nc.progressToken,
+		Progress:      progress,
+		Total:         total,
+		Message:       message,
+	})
+	if err != nil {
+		return err
+	}
+	return nc.notifier.Notify(req)
+}
+
+// Log 在处理请求的过程中，向客户端发送日志通知（notifications/message），
+// 低于客户端使用 logging/setLevel 设置的等级（默认为 debug，对所有会话生效）的日志不会发送
+func Log(ctx context.Context, level LoggingLevel, logger string, data any) error {
+	nc := notifyFromContext(ctx)
+	if nc == nil || level.index() < int(nc.server.logLevel.Load()) {
+		return nil
+	}
+	req, err := xjsonrpc2.NewRequest(nil, NotificationMessage, &LoggingMessageParams{
+		Level:  level,
+		Logger: logger,
+		Data:   data,
+	})
+	if err != nil {
+		return err
+	}
+	return nc.notifier.Notify(req)
+}
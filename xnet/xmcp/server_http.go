@@ -0,0 +1,477 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xmcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xsync"
+	"github.com/xanygo/anygo/safely"
+	"github.com/xanygo/anygo/xhttp"
+	"github.com/xanygo/anygo/xhttp/sse"
+	"github.com/xanygo/anygo/xnet/xjsonrpc2"
+)
+
+// maxBodySize 单个 HTTP 请求消息的最大长度
+const maxBodySize = 16 << 20
+
+var _ http.Handler = (*Server)(nil)
+
+type httpSession struct {
+	lastActive atomic.Int64
+
+	// ch 仅 SSE 传输方式使用，发送给客户端的消息
+	ch   chan json.RawMessage
+	done chan struct{}
+}
+
+func (hs *httpSession) touch() {
+	hs.lastActive.Store(time.Now().UnixNano())
+}
+
+func (s *Server) getSessionIdleTimeout() time.Duration {
+	if s.SessionIdleTimeout > 0 {
+		return s.SessionIdleTimeout
+	}
+	return 30 * time.Minute
+}
+
+func (s *Server) getMaxSessions() int64 {
+	if s.MaxSessions > 0 {
+		return int64(s.MaxSessions)
+	}
+	return 1000
+}
+
+// errTooManySessions 会话数已经达到 MaxSessions
+var errTooManySessions = errors.New("too many sessions")
+
+// newSession 创建并保存会话，会话数超过 MaxSessions 时返回 errTooManySessions
+func (s *Server) newSession(hs *httpSession) (string, error) {
+	s.sweepSessions()
+	if s.sessionCount.Add(1) > s.getMaxSessions() {
+		s.sessionCount.Add(-1)
+		return "", errTooManySessions
+	}
+	hs.touch()
+	id := rand.Text()
+	s.sessions.Store(id, hs)
+	return id, nil
+}
+
+func (s *Server) deleteSession(id any) {
+	if _, ok := s.sessions.LoadAndDelete(id); ok {
+		s.sessionCount.Add(-1)
+	}
+}
+
+// checkOrigin 校验请求的 Origin，见 Server.AllowOrigins
+func (s *Server) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if len(s.AllowOrigins) > 0 {
+		for _, o := range s.AllowOrigins {
+			if o == "*" || strings.EqualFold(o, origin) {
+				return true
+			}
+		}
+		return false
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// sweepSessions 清理空闲超时的会话，SSE 的会话在连接断开时删除
+func (s *Server) sweepSessions() {
+	deadline := time.Now().Add(-s.getSessionIdleTimeout()).UnixNano()
+	s.sessions.Range(func(key, value any) bool {
+		if hs := value.(*httpSession); hs.ch == nil && hs.lastActive.Load() < deadline {
+			s.deleteSession(key)
+		}
+		return true
+	})
+}
+
+func (s *Server) getSession(id string) *httpSession {
+	if id == "" {
+		return nil
+	}
+	v, ok := s.sessions.Load(id)
+	if !ok {
+		return nil
+	}
+	hs := v.(*httpSession)
+	if hs.ch == nil && time.Since(time.Unix(0, hs.lastActive.Load())) > s.getSessionIdleTimeout() {
+		s.deleteSession(id)
+		return nil
+	}
+	hs.touch()
+	return hs
+}
+
+// ServeHTTP 提供 Streamable HTTP 传输方式的服务：
+//   - POST：发送消息，initialize 请求会创建新的会话，并在响应头 Mcp-Session-Id 中返回，之后的请求都需要携带该请求头，
+//     若处理过程中有通知（如进度）且客户端接受 text/event-stream，使用 SSE 流返回，否则返回 JSON
+//   - DELETE：结束会话
+//   - GET：不支持服务端主动推送消息，返回 405
+//
+// 请求的 Origin 不被允许时返回 403，见 Server.AllowOrigins
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.checkOrigin(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	switch r.Method {
+	case http.MethodPost:
+		s.handlePost(w, r)
+	case http.MethodDelete:
+		id := r.Header.Get(HeaderSessionID)
+		if s.getSession(id) == nil {
+			http.Error(w, "session not found", http.StatusNotFound)
+			return
+		}
+		s.deleteSession(id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handlePost(w http.ResponseWriter, r *http.Request) {
+	reqs, batch, err := readHTTPMessages(r)
+	if err != nil {
+		xhttp.WriteJSONStatus(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var initialize bool
+	for _, req := range reqs {
+		if req.Method == MethodInitialize {
+			initialize = true
+			break
+		}
+	}
+	if initialize {
+		if len(reqs) > 1 {
+			http.Error(w, "initialize request must not be part of a batch", http.StatusBadRequest)
+			return
+		}
+		id, err := s.newSession(&httpSession{})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set(HeaderSessionID, id)
+	} else {
+		id := r.Header.Get(HeaderSessionID)
+		if id == "" {
+			http.Error(w, "missing "+HeaderSessionID+" header", http.StatusBadRequest)
+			return
+		}
+		if s.getSession(id) == nil {
+			http.Error(w, "session not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	var hasCall bool
+	for _, req := range reqs {
+		if !req.NoReply() {
+			hasCall = true
+			break
+		}
+	}
+	if !hasCall {
+		// 只有通知和响应
+		for _, req := range reqs {
+			_ = safely.RunCtx(r.Context(), func(ctx context.Context) error {
+				return s.Router().Handle(ctx, discardWriter{}, req)
+			})
+		}
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	hw := &httpResponseWriter{
+		w:         w,
+		acceptSSE: strings.Contains(r.Header.Get("Accept"), "text/event-stream"),
+	}
+	var wg xsync.WaitGroup
+	for _, req := range reqs {
+		wg.GoCtx(r.Context(), func(ctx context.Context) {
+			_ = s.Router().Handle(ctx, hw, req)
+		})
+	}
+	_ = wg.Wait()
+	hw.finish(batch)
+}
+
+// readHTTPMessages 读取 HTTP 请求中的消息，返回值：请求列表（不包含客户端发送的响应），是否批量，错误
+func readHTTPMessages(r *http.Request) ([]*xjsonrpc2.Request, bool, error) {
+	bf, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		return nil, false, err
+	}
+	bf = bytes.TrimSpace(bf)
+	msgs := []json.RawMessage{bf}
+	batch := len(bf) > 0 && bf[0] == '['
+	if batch {
+		msgs = nil
+		if err = json.Unmarshal(bf, &msgs); err != nil {
+			return nil, false, err
+		}
+	}
+	reqs := make([]*xjsonrpc2.Request, 0, len(msgs))
+	for _, msg := range msgs {
+		req, _, err := xjsonrpc2.ParseMessage(msg)
+		if err != nil {
+			return nil, false, err
+		}
+		if req != nil {
+			reqs = append(reqs, req)
+		}
+	}
+	return reqs, batch, nil
+}
+
+func errorResponse(err error) json.RawMessage {
+	resp, _ := xjsonrpc2.NewResponse(nil, nil, err)
+	return encodeMessage(resp)
+}
+
+func encodeMessage(msg io.WriterTo) json.RawMessage {
+	bf := &bytes.Buffer{}
+	_, _ = msg.WriteTo(bf)
+	return bytes.TrimSpace(bf.Bytes())
+}
+
+type discardWriter struct{}
+
+func (discardWriter) Write(resp *xjsonrpc2.Response) error {
+	return nil
+}
+
+var _ xjsonrpc2.ResponseWriter = (*httpResponseWriter)(nil)
+var _ xjsonrpc2.Notifier = (*httpResponseWriter)(nil)
+
+// httpResponseWriter 缓存响应，若有通知，切换为 SSE 流
+type httpResponseWriter struct {
+	w         http.ResponseWriter
+	acceptSSE bool
+
+	mux   sync.Mutex
+	sse   bool
+	resps []json.RawMessage
+}
+
+func (hw *httpResponseWriter) Write(resp *xjsonrpc2.Response) error {
+	hw.mux.Lock()
+	defer hw.mux.Unlock()
+	msg := encodeMessage(resp)
+	if hw.sse {
+		return hw.writeEvent(msg)
+	}
+	hw.resps = append(hw.resps, msg)
+	return nil
+}
+
+func (hw *httpResponseWriter) Notify(req *xjsonrpc2.Request) error {
+	if !hw.acceptSSE {
+		return nil
+	}
+	hw.mux.Lock()
+	defer hw.mux.Unlock()
+	if !hw.sse {
+		hw.sse = true
+		h := hw.w.Header()
+		h.Set("Content-Type", "text/event-stream")
+		h.Set("Cache-Control", "no-cache")
+		h.Set("X-Accel-Buffering", "no")
+		hw.w.WriteHeader(http.StatusOK)
+		for _, msg := range hw.resps {
+			if err := hw.writeEvent(msg); err != nil {
+				return err
+			}
+		}
+		hw.resps = nil
+	}
+	return hw.writeEvent(encodeMessage(req))
+}
+
+func (hw *httpResponseWriter) writeEvent(msg json.RawMessage) error {
+	if _, err := (sse.Event{Event: "message", Data: string(msg)}).WriteTo(hw.w); err != nil {
+		return err
+	}
+	return http.NewResponseController(hw.w).Flush()
+}
+
+func (hw *httpResponseWriter) finish(batch bool) {
+	hw.mux.Lock()
+	defer hw.mux.Unlock()
+	if hw.sse {
+		return
+	}
+	hw.w.Header().Set("Content-Type", "application/json")
+	if !batch && len(hw.resps) == 1 {
+		_, _ = hw.w.Write(hw.resps[0])
+		return
+	}
+	bf, _ := json.Marshal(hw.resps)
+	_, _ = hw.w.Write(bf)
+}
+
+// SSEHandler 返回 HTTP+SSE 传输方式（2024-11-05 版本）的 Handler：
+//   - GET：建立 SSE 长连接，首先发送 endpoint 事件（地址为当前路径加上 sessionId 参数），之后使用 message 事件发送消息
+//   - POST：使用 endpoint 地址发送消息，返回 202，响应和通知通过 SSE 长连接发送
+//
+// 和 ServeHTTP 一样，会校验请求的 Origin，并受 MaxSessions 的限制
+func (s *Server) SSEHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.checkOrigin(r) {
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			s.serveSSEStream(w, r)
+		case http.MethodPost:
+			s.serveSSEMessage(w, r)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func (s *Server) serveSSEStream(w http.ResponseWriter, r *http.Request) {
+	hs := &httpSession{
+		ch:   make(chan json.RawMessage, 64),
+		done: make(chan struct{}),
+	}
+	id, err := s.newSession(hs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer func() {
+		s.deleteSession(id)
+		close(hs.done)
+	}()
+
+	rc := http.NewResponseController(w)
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	endpoint := sse.Event{Event: "endpoint", Data: r.URL.Path + "?sessionId=" + id}
+	if _, err := endpoint.WriteTo(w); err != nil {
+		return
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg := <-hs.ch:
+			if _, err := (sse.Event{Event: "message", Data: string(msg)}).WriteTo(w); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) serveSSEMessage(w http.ResponseWriter, r *http.Request) {
+	hs := s.getSession(r.URL.Query().Get("sessionId"))
+	if hs == nil || hs.ch == nil {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	reqs, _, err := readHTTPMessages(r)
+	if err != nil {
+		xhttp.WriteJSONStatus(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+
+	sw := &sseSessionWriter{session: hs}
+	for _, req := range reqs {
+		go safely.RunVoid(func() {
+			ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+			defer cancel()
+			go func() {
+				select {
+				case <-hs.done:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+			_ = s.Router().Handle(ctx, sw, req)
+		})
+	}
+}
+
+var _ xjsonrpc2.ResponseWriter = (*sseSessionWriter)(nil)
+var _ xjsonrpc2.Notifier = (*sseSessionWriter)(nil)
+
+// sseSessionWriter 将响应和通知发送到 SSE 会话的长连接
+type sseSessionWriter struct {
+	session *httpSession
+}
+
+func (sw *sseSessionWriter) send(msg json.RawMessage) error {
+	select {
+	case sw.session.ch <- msg:
+		return nil
+	case <-sw.session.done:
+		return io.ErrClosedPipe
+	}
+}
+
+func (sw *sseSessionWriter) Write(resp *xjsonrpc2.Response) error {
+	return sw.send(encodeMessage(resp))
+}
+
+func (sw *sseSessionWriter) Notify(req *xjsonrpc2.Request) error {
+	return sw.send(encodeMessage(req))
+}
+
+// Register 将服务注册到 xhttp.Router：path 为 Streamable HTTP 的地址，path+"/sse" 为 HTTP+SSE 的地址，
+// 如 Register(router, "/mcp") 会注册 /mcp 和 /mcp/sse
+func (s *Server) Register(router *xhttp.Router, path string) {
+	path = strings.TrimRight(path, "/")
+	router.MustHandle("GET,POST,DELETE "+path+" meta|openapi=no", s)
+	router.MustHandle("GET,POST "+path+"/sse meta|openapi=no", s.SSEHandler())
+}
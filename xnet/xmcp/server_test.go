@@ -0,0 +1,266 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xmcp_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/xanygo/anygo/xhttp"
+	"github.com/xanygo/anygo/xnet/xjsonrpc2"
+	"github.com/xanygo/anygo/xnet/xmcp"
+	"github.com/xanygo/anygo/xt"
+)
+
+type addArgs struct {
+	A int `json:"a" validator:"required" doc:"第一个数"`
+	B int `json:"b" doc:"第二个数"`
+}
+
+type addResult struct {
+	Sum int `json:"sum"`
+}
+
+func newTestServer() *xmcp.Server {
+	s := xmcp.NewServer(xmcp.Implementation{Name: "test", Version: "1.0"})
+	xmcp.AddTool(s, &xmcp.Tool{Name: "add", Description: "add two numbers"}, func(ctx context.Context, in *addArgs) (*addResult, error) {
+		_ = xmcp.NotifyProgress(ctx, 1, 2, "half")
+		_ = xmcp.Log(ctx, xmcp.LoggingInfo, "add", "adding")
+		return &addResult{Sum: in.A + in.B}, nil
+	})
+	xmcp.AddTool(s, &xmcp.Tool{Name: "fail"}, func(ctx context.Context, in *struct{}) (*xmcp.CallToolResult, error) {
+		return nil, errors.New("something wrong")
+	})
+	s.AddTool(&xmcp.Tool{Name: "empty"}, func(ctx context.Context, params *xmcp.CallToolParams) (*xmcp.CallToolResult, error) {
+		return nil, nil
+	})
+	s.AddTool(&xmcp.Tool{Name: "panic"}, func(ctx context.Context, params *xmcp.CallToolParams) (*xmcp.CallToolResult, error) {
+		panic("tool panic")
+	})
+	s.AddResource(&xmcp.Resource{URI: "file:///readme", Name: "readme"}, func(ctx context.Context, uri string) (*xmcp.ReadResourceResult, error) {
+		return &xmcp.ReadResourceResult{Contents: []*xmcp.ResourceContents{{URI: uri, Text: "hello"}}}, nil
+	})
+	s.AddResourceTemplate(&xmcp.ResourceTemplate{URITemplate: "file:///logs/{+path}", Name: "logs"}, func(ctx context.Context, uri string) (*xmcp.ReadResourceResult, error) {
+		return &xmcp.ReadResourceResult{Contents: []*xmcp.ResourceContents{{URI: uri, Text: "log"}}}, nil
+	})
+	s.AddPrompt(&xmcp.Prompt{Name: "greet", Arguments: []*xmcp.PromptArgument{{Name: "name", Required: true}}},
+		func(ctx context.Context, params *xmcp.GetPromptParams) (*xmcp.GetPromptResult, error) {
+			msg := &xmcp.PromptMessage{Role: "user", Content: xmcp.TextContent("hello " + params.Arguments["name"])}
+			return &xmcp.GetPromptResult{Messages: []*xmcp.PromptMessage{msg}}, nil
+		})
+	return s
+}
+
+type pipeConn struct {
+	io.Reader
+	io.WriteCloser
+}
+
+type logHandler struct {
+	mux  sync.Mutex
+	logs []string
+}
+
+func (h *logHandler) Handle(ctx context.Context, w xjsonrpc2.ResponseWriter, req *xjsonrpc2.Request) error {
+	params := &xmcp.LoggingMessageParams{}
+	if err := req.DecodeParams(params); err != nil {
+		return err
+	}
+	h.mux.Lock()
+	h.logs = append(h.logs, params.Data.(string))
+	h.mux.Unlock()
+	return nil
+}
+
+func testSession(t *testing.T, transport xmcp.Transport, progress bool) {
+	lh := &logHandler{}
+	client := &xmcp.Client{
+		Info:    xmcp.Implementation{Name: "client", Version: "1.0"},
+		Handler: lh,
+	}
+	session, err := client.Connect(t.Context(), transport)
+	xt.NoError(t, err)
+	defer session.Close()
+	xt.Equal(t, session.InitializeResult().ServerInfo.Name, "test")
+	xt.NotNil(t, session.InitializeResult().Capabilities.Tools)
+	xt.NoError(t, session.Ping(t.Context()))
+
+	tools, err := session.ListTools(t.Context())
+	xt.NoError(t, err)
+	xt.Len(t, tools, 4)
+	xt.Equal(t, tools[0].Name, "add")
+	want := `{"type":"object","properties":{"a":{"type":"integer","format":"int64","description":"第一个数"},` +
+		`"b":{"type":"integer","format":"int64","description":"第二个数"}},"required":["a"]}`
+	xt.Equal(t, string(tools[0].InputSchema), want)
+
+	var progresses []float64
+	ctx := xmcp.WithProgress(t.Context(), func(p *xmcp.ProgressParams) {
+		progresses = append(progresses, p.Progress)
+	})
+	result, err := session.CallTool(ctx, "add", map[string]int{"a": 1, "b": 2})
+	xt.NoError(t, err)
+	xt.False(t, result.IsError)
+	xt.Equal(t, result.Text(), `{"sum":3}`)
+	if progress {
+		xt.Equal(t, progresses, []float64{1})
+	}
+
+	result, err = session.CallTool(t.Context(), "fail", nil)
+	xt.NoError(t, err)
+	xt.True(t, result.IsError)
+	xt.Equal(t, result.Text(), "something wrong")
+
+	result, err = session.CallTool(t.Context(), "empty", nil)
+	xt.NoError(t, err)
+	xt.False(t, result.IsError)
+	xt.Empty(t, result.Content)
+
+	_, err = session.CallTool(t.Context(), "panic", nil)
+	xt.Error(t, err)
+
+	_, err = session.CallTool(t.Context(), "add", map[string]int{"b": 2})
+	xt.Error(t, err)
+	_, err = session.CallTool(t.Context(), "not-found", nil)
+	xt.Error(t, err)
+
+	rr, err := session.ReadResource(t.Context(), "file:///readme")
+	xt.NoError(t, err)
+	xt.Equal(t, rr.Contents[0].Text, "hello")
+	rr, err = session.ReadResource(t.Context(), "file:///logs/a/b.log")
+	xt.NoError(t, err)
+	xt.Equal(t, rr.Contents[0].Text, "log")
+	_, err = session.ReadResource(t.Context(), "file:///other")
+	xt.Error(t, err)
+
+	prompt, err := session.GetPrompt(t.Context(), "greet", map[string]string{"name": "anygo"})
+	xt.NoError(t, err)
+	xt.Equal(t, prompt.Messages[0].Content.Text, "hello anygo")
+	_, err = session.GetPrompt(t.Context(), "greet", nil)
+	xt.Error(t, err)
+
+	xt.NoError(t, session.SetLoggingLevel(t.Context(), xmcp.LoggingError))
+	_, err = session.CallTool(t.Context(), "add", map[string]int{"a": 1})
+	xt.NoError(t, err)
+	if progress {
+		lh.mux.Lock()
+		xt.Equal(t, lh.logs, []string{"adding"})
+		lh.mux.Unlock()
+	}
+}
+
+func TestServer_stdio(t *testing.T) {
+	s := newTestServer()
+	cr, sw := io.Pipe()
+	sr, cw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- s.ServeConn(context.Background(), sr, sw)
+		_ = sw.Close()
+	}()
+	testSession(t, &stdioTransport{rwc: &pipeConn{Reader: cr, WriteCloser: cw}}, true)
+	xt.NoError(t, <-done)
+}
+
+type stdioTransport struct {
+	rwc io.ReadWriteCloser
+}
+
+func (st *stdioTransport) Connect(ctx context.Context) (xmcp.Conn, error) {
+	return xmcp.NewStreamConn(st.rwc), nil
+}
+
+func TestServer_streamableHTTP(t *testing.T) {
+	router := xhttp.NewRouter()
+	newTestServer().Register(router, "/mcp")
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+	testSession(t, &xmcp.StreamableHTTPTransport{URL: ts.URL + "/mcp"}, true)
+
+	t.Run("session expired", func(t *testing.T) {
+		var server atomic.Pointer[xmcp.Server]
+		server.Store(newTestServer())
+		ts2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			server.Load().ServeHTTP(w, r)
+		}))
+		defer ts2.Close()
+		client := &xmcp.Client{Info: xmcp.Implementation{Name: "client", Version: "1.0"}}
+		session, err := client.Connect(t.Context(), &xmcp.StreamableHTTPTransport{URL: ts2.URL})
+		xt.NoError(t, err)
+		defer session.Close()
+		xt.NoError(t, session.Ping(t.Context()))
+		server.Store(newTestServer())
+		err = session.Ping(t.Context())
+		xt.True(t, errors.Is(err, xmcp.ErrSessionExpired))
+	})
+
+	t.Run("origin", func(t *testing.T) {
+		post := func(origin string) int {
+			body := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"c","version":"1"}}}`
+			req, err := http.NewRequest(http.MethodPost, ts.URL+"/mcp", strings.NewReader(body))
+			xt.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", "application/json, text/event-stream")
+			if origin != "" {
+				req.Header.Set("Origin", origin)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			xt.NoError(t, err)
+			resp.Body.Close()
+			return resp.StatusCode
+		}
+		xt.Equal(t, post("http://evil.example.com"), http.StatusForbidden)
+		xt.Equal(t, post("http://localhost:8080"), http.StatusOK)
+		xt.Equal(t, post(ts.URL), http.StatusOK)
+		xt.Equal(t, post(""), http.StatusOK)
+	})
+
+	t.Run("max sessions", func(t *testing.T) {
+		server := newTestServer()
+		server.MaxSessions = 1
+		ts2 := httptest.NewServer(server)
+		defer ts2.Close()
+		client := &xmcp.Client{Info: xmcp.Implementation{Name: "client", Version: "1.0"}}
+		session, err := client.Connect(t.Context(), &xmcp.StreamableHTTPTransport{URL: ts2.URL})
+		xt.NoError(t, err)
+		_, err = client.Connect(t.Context(), &xmcp.StreamableHTTPTransport{URL: ts2.URL})
+		xt.Error(t, err)
+		xt.NoError(t, session.Close())
+		session, err = client.Connect(t.Context(), &xmcp.StreamableHTTPTransport{URL: ts2.URL})
+		xt.NoError(t, err)
+		xt.NoError(t, session.Close())
+	})
+}
+
+func TestServer_sse(t *testing.T) {
+	router := xhttp.NewRouter()
+	newTestServer().Register(router, "/mcp")
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+	testSession(t, &xmcp.SSETransport{URL: ts.URL + "/mcp/sse"}, true)
+}
+
+func TestNewStreamConn(t *testing.T) {
+	ca, cb := net.Pipe()
+	conn := xmcp.NewStreamConn(ca)
+	defer conn.Close()
+	go func() {
+		_, _ = cb.Write([]byte("\n{\"jsonrpc\":\"2.0\",\"method\":\"a\"}\n"))
+		_, _ = cb.Write(bytes.Repeat([]byte("a"), xjsonrpc2.DefaultMaxMessageSize+8192))
+	}()
+	msg, err := conn.Read(t.Context())
+	xt.NoError(t, err)
+	xt.Equal(t, string(msg), `{"jsonrpc":"2.0","method":"a"}`)
+	_, err = conn.Read(t.Context())
+	xt.True(t, errors.Is(err, xjsonrpc2.ErrMessageTooLarge))
+}
@@ -0,0 +1,134 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xmcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/xanygo/anygo/xlog"
+	"github.com/xanygo/anygo/xnet/xjsonrpc2"
+	"github.com/xanygo/anygo/xnet/xservice"
+)
+
+// Transport 客户端的传输层，用于创建和服务端的连接
+type Transport interface {
+	Connect(ctx context.Context) (Conn, error)
+}
+
+// Conn 双向收发 JSON-RPC 消息的连接，每条消息是一个完整的 JSON 对象（批量消息为 JSON 数组）
+type Conn = xjsonrpc2.Stream
+
+// NewStreamConn 使用 rwc 创建 Conn，消息以换行符分隔（即 MCP 的 stdio 传输格式），
+// 单条消息的最大长度为 xjsonrpc2.DefaultMaxMessageSize
+func NewStreamConn(rwc io.ReadWriteCloser) Conn {
+	return xjsonrpc2.NewFramedStream(rwc, xjsonrpc2.NewlineFramer)
+}
+
+var _ Transport = (*CommandTransport)(nil)
+
+// CommandTransport 启动子进程，使用其 stdin 和 stdout 通讯（MCP 的 stdio 传输方式），
+// 子进程的 stderr 会输出到日志中。每次 Connect 都会启动一个新的子进程，关闭连接时，子进程会退出
+type CommandTransport struct {
+	// Path 必填，命令
+	Path string
+
+	// Args 可选，命令参数
+	Args []string
+
+	// Dir 可选，工作目录
+	Dir string
+
+	// Env 可选，额外的环境变量，如 "KEY=value"
+	Env []string
+}
+
+func (ct *CommandTransport) Connect(ctx context.Context) (Conn, error) {
+	cmd := exec.Command(ct.Path, ct.Args...)
+	cmd.Dir = ct.Dir
+	if len(ct.Env) > 0 {
+		cmd.Env = append(cmd.Environ(), ct.Env...)
+	}
+	logCtx := xlog.NewContext(ctx)
+	xlog.AddMetaAttr(logCtx, xlog.String("Command", ct.Path))
+	cmd.Stderr = xlog.AsWriter(logCtx, xlog.Default(), xlog.LevelInfo)
+
+	pw, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("get StdinPipe failed: %w", err)
+	}
+	pr, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("get StdoutPipe failed: %w", err)
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+	rwc := &cmdReadWriter{
+		Reader: pr,
+		Writer: pw,
+		close: func() error {
+			// 关闭 stdin 后，子进程应该退出，若超时未退出，则 kill
+			err := pw.Close()
+			select {
+			case <-exited:
+			case <-time.After(5 * time.Second):
+				_ = cmd.Process.Kill()
+				<-exited
+			}
+			return err
+		},
+	}
+	return NewStreamConn(rwc), nil
+}
+
+type cmdReadWriter struct {
+	io.Reader
+	io.Writer
+	once  sync.Once
+	close func() error
+	err   error
+}
+
+func (c *cmdReadWriter) Close() error {
+	c.once.Do(func() {
+		c.err = c.close()
+	})
+	return c.err
+}
+
+var _ Transport = (*ServiceTransport)(nil)
+
+// ServiceTransport 使用 xservice 拨号，并使用换行符分隔消息，
+// 可以使用 xnaming.Stdio（地址如 `stdio@{"Path":"npx","Args":["-y","some-mcp"]}`）或者 TCP 等地址
+type ServiceTransport struct {
+	// Service 必填，服务名称或者 xservice.Service
+	Service any
+}
+
+func (st *ServiceTransport) Connect(ctx context.Context) (Conn, error) {
+	if st.Service == nil {
+		return nil, errors.New("xmcp: empty Service")
+	}
+	srv, err := xservice.FindService(st.Service)
+	if err != nil {
+		return nil, err
+	}
+	rwc, err := xservice.DialerFuncWithServiceName(srv)(ctx, srv.Name())
+	if err != nil {
+		return nil, err
+	}
+	return NewStreamConn(rwc), nil
+}
@@ -0,0 +1,368 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xmcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xsync"
+	"github.com/xanygo/anygo/xerror"
+	"github.com/xanygo/anygo/xhttp/sse"
+	"github.com/xanygo/anygo/xhttp/xhttpc"
+	"github.com/xanygo/anygo/xnet/xrpc"
+	"github.com/xanygo/anygo/xnet/xservice"
+)
+
+// HeaderSessionID Streamable HTTP 传输方式中，会话 ID 的请求头和响应头
+const HeaderSessionID = "Mcp-Session-Id"
+
+// HeaderProtocolVersion Streamable HTTP 传输方式中，初始化之后的请求需要携带的协议版本请求头
+const HeaderProtocolVersion = "Mcp-Protocol-Version"
+
+// ErrSessionExpired 服务端返回 404，会话已经失效，需要重新连接
+var ErrSessionExpired = errors.New("xmcp: session expired")
+
+// versionSetter 初始化之后，由 ClientSession 设置协商后的协议版本
+type versionSetter interface {
+	setProtocolVersion(version string)
+}
+
+var _ Transport = (*StreamableHTTPTransport)(nil)
+
+// StreamableHTTPTransport MCP 的 Streamable HTTP 传输方式（2025-03-26 及之后的版本），
+// 每条消息使用一个 POST 请求发送，服务端使用 JSON 或者 SSE 流返回响应，使用 xhttpc 发送请求。
+//
+// 注意：读取响应的总时长受 ReadTimeout 限制，对于耗时较长的工具，需要在 Opts 中设置足够长的 ReadTimeout
+type StreamableHTTPTransport struct {
+	// URL 必填，MCP 服务的地址，如 http://127.0.0.1:8080/mcp
+	URL string
+
+	// Service 可选，当为空时，会使用 Dummy
+	Service any
+
+	// Header 可选，额外的请求头，如 Authorization
+	Header http.Header
+
+	// Opts 可选，额外的 RPC Client 参数
+	Opts []xrpc.Option
+}
+
+func (st *StreamableHTTPTransport) getService() any {
+	if st.Service == nil {
+		return xservice.GetDummyService()
+	}
+	return st.Service
+}
+
+func (st *StreamableHTTPTransport) Connect(ctx context.Context) (Conn, error) {
+	if st.URL == "" {
+		return nil, errors.New("xmcp: empty URL")
+	}
+	return &httpConn{
+		transport: st,
+		inbox:     newInbox(),
+	}, nil
+}
+
+var _ versionSetter = (*httpConn)(nil)
+
+type httpConn struct {
+	transport *StreamableHTTPTransport
+	inbox     *inbox
+	sessionID xsync.Value[string]
+	version   xsync.Value[string]
+	closeOnce sync.Once
+}
+
+func (hc *httpConn) setProtocolVersion(version string) {
+	hc.version.Store(version)
+}
+
+func (hc *httpConn) newRequest(ctx context.Context, method string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, hc.transport.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(req.Header, hc.transport.Header)
+	if sid := hc.sessionID.Load(); sid != "" {
+		req.Header.Set(HeaderSessionID, sid)
+	}
+	if version := hc.version.Load(); version != "" {
+		req.Header.Set(HeaderProtocolVersion, version)
+	}
+	return req, nil
+}
+
+func (hc *httpConn) Read(ctx context.Context) (json.RawMessage, error) {
+	return hc.inbox.pop(ctx)
+}
+
+func (hc *httpConn) Write(ctx context.Context, msg json.RawMessage) error {
+	req, err := hc.newRequest(ctx, http.MethodPost, msg)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	handler := func(ctx context.Context, resp *http.Response) error {
+		defer resp.Body.Close()
+		if sid := resp.Header.Get(HeaderSessionID); sid != "" {
+			hc.sessionID.Store(sid)
+		}
+		switch {
+		case resp.StatusCode == http.StatusAccepted || resp.StatusCode == http.StatusNoContent:
+			return nil
+		case resp.StatusCode == http.StatusNotFound && hc.sessionID.Load() != "":
+			return ErrSessionExpired
+		case resp.StatusCode < 200 || resp.StatusCode > 299:
+			return xerror.NewStatusError(int64(resp.StatusCode))
+		}
+		mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		if mt == "text/event-stream" {
+			return hc.inbox.pushEvents(bufio.NewReader(resp.Body))
+		}
+		bf, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		return hc.inbox.push(bf)
+	}
+	return xhttpc.Invoke(ctx, hc.transport.getService(), req, handler, hc.transport.Opts...)
+}
+
+// Close 关闭连接，若有会话 ID，会发送 DELETE 请求通知服务端结束会话
+func (hc *httpConn) Close() error {
+	var err error
+	hc.closeOnce.Do(func() {
+		hc.inbox.close()
+		if hc.sessionID.Load() == "" {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		req, err1 := hc.newRequest(ctx, http.MethodDelete, nil)
+		if err1 != nil {
+			err = err1
+			return
+		}
+		err = xhttpc.Invoke(ctx, hc.transport.getService(), req, xhttpc.StatusIn(http.StatusOK, http.StatusNoContent,
+			http.StatusAccepted, http.StatusMethodNotAllowed, http.StatusNotFound), hc.transport.Opts...)
+	})
+	return err
+}
+
+var _ Transport = (*SSETransport)(nil)
+
+// SSETransport MCP 的 HTTP+SSE 传输方式（2024-11-05 版本，已被 Streamable HTTP 取代），
+// 使用 GET 请求建立 SSE 长连接接收消息，使用服务端通过 endpoint 事件返回的地址 POST 发送消息。
+//
+// SSE 长连接默认的 ReadTimeout 为 1 小时，超时后连接会被关闭
+type SSETransport struct {
+	// URL 必填，SSE 的地址，如 http://127.0.0.1:8080/sse
+	URL string
+
+	// Service 可选，当为空时，会使用 Dummy
+	Service any
+
+	// Header 可选，额外的请求头，如 Authorization
+	Header http.Header
+
+	// Opts 可选，额外的 RPC Client 参数
+	Opts []xrpc.Option
+}
+
+func (st *SSETransport) getService() any {
+	if st.Service == nil {
+		return xservice.GetDummyService()
+	}
+	return st.Service
+}
+
+func (st *SSETransport) Connect(ctx context.Context) (Conn, error) {
+	base, err := url.Parse(st.URL)
+	if err != nil {
+		return nil, err
+	}
+	streamCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	sc := &sseConn{
+		transport: st,
+		inbox:     newInbox(),
+		cancel:    cancel,
+	}
+	es := &xhttpc.EventSource{
+		Service: st.getService(),
+		NewRequest: func(ctx context.Context) (*http.Request, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, st.URL, nil)
+			if err == nil {
+				maps.Copy(req.Header, st.Header)
+			}
+			return req, err
+		},
+		Opts:       append([]xrpc.Option{xrpc.OptReadTimeout(time.Hour)}, st.Opts...),
+		MaxRetries: -1, // 重连后服务端会创建新的会话，所以不重连
+	}
+	endpoint := make(chan string, 1)
+	go func() {
+		defer sc.inbox.close()
+		for ev, err := range es.Events(streamCtx) {
+			if err != nil {
+				sc.inbox.setErr(err)
+				return
+			}
+			switch ev.Event {
+			case "endpoint":
+				select {
+				case endpoint <- ev.Data:
+				default:
+				}
+			case "", "message":
+				if sc.inbox.push([]byte(ev.Data)) != nil {
+					return
+				}
+			}
+		}
+	}()
+	select {
+	case <-ctx.Done():
+		_ = sc.Close()
+		return nil, context.Cause(ctx)
+	case <-sc.inbox.done:
+		return nil, errors.Join(errors.New("xmcp: sse stream closed before endpoint event"), sc.inbox.getErr())
+	case ep := <-endpoint:
+		u, err := base.Parse(ep)
+		if err != nil {
+			_ = sc.Close()
+			return nil, err
+		}
+		sc.endpoint = u.String()
+	}
+	return sc, nil
+}
+
+type sseConn struct {
+	transport *SSETransport
+	inbox     *inbox
+	endpoint  string
+	cancel    context.CancelFunc
+}
+
+func (sc *sseConn) Read(ctx context.Context) (json.RawMessage, error) {
+	return sc.inbox.pop(ctx)
+}
+
+func (sc *sseConn) Write(ctx context.Context, msg json.RawMessage) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sc.endpoint, bytes.NewReader(msg))
+	if err != nil {
+		return err
+	}
+	maps.Copy(req.Header, sc.transport.Header)
+	req.Header.Set("Content-Type", "application/json")
+	return xhttpc.Invoke(ctx, sc.transport.getService(), req, xhttpc.StatusRange(200, 299), sc.transport.Opts...)
+}
+
+func (sc *sseConn) Close() error {
+	sc.cancel()
+	sc.inbox.close()
+	return nil
+}
+
+// inbox 收到的消息队列，用于将 HTTP 响应转换为 Conn.Read
+type inbox struct {
+	ch   chan json.RawMessage
+	done chan struct{}
+	once sync.Once
+	err  xsync.Value[error]
+}
+
+func newInbox() *inbox {
+	return &inbox{
+		ch:   make(chan json.RawMessage, 64),
+		done: make(chan struct{}),
+	}
+}
+
+// push 添加一条消息，批量消息（JSON 数组）会拆分为多条
+func (ib *inbox) push(bf []byte) error {
+	bf = bytes.TrimSpace(bf)
+	if len(bf) == 0 {
+		return nil
+	}
+	msgs := []json.RawMessage{bf}
+	if bf[0] == '[' {
+		msgs = nil
+		if err := json.Unmarshal(bf, &msgs); err != nil {
+			return err
+		}
+	}
+	for _, msg := range msgs {
+		select {
+		case ib.ch <- slices.Clone(msg):
+		case <-ib.done:
+			return io.ErrClosedPipe
+		}
+	}
+	return nil
+}
+
+// pushEvents 读取 SSE 流，将每个事件的 data 作为消息添加到队列中
+func (ib *inbox) pushEvents(rd *bufio.Reader) error {
+	for {
+		ev, err := sse.ReadEvent(rd)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if ev.Event != "" && ev.Event != "message" {
+			continue
+		}
+		if err = ib.push([]byte(strings.TrimSpace(ev.Data))); err != nil {
+			return err
+		}
+	}
+}
+
+func (ib *inbox) pop(ctx context.Context) (json.RawMessage, error) {
+	select {
+	case msg := <-ib.ch:
+		return msg, nil
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	case <-ib.done:
+		if err := ib.getErr(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+}
+
+func (ib *inbox) setErr(err error) {
+	ib.err.Store(err)
+}
+
+func (ib *inbox) getErr() error {
+	return ib.err.Load()
+}
+
+func (ib *inbox) close() {
+	ib.once.Do(func() {
+		close(ib.done)
+	})
+}
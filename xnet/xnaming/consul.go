@@ -0,0 +1,204 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xnaming
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xanygo/anygo/xnet"
+)
+
+var _ Naming = (*Consul)(nil)
+var _ Watcher = (*Consul)(nil)
+
+// Consul 使用 Consul 的健康检查 API（/v1/health/service/{name}）发现节点，只返回健康检查通过的节点，地址如：
+//
+//	consul@127.0.0.1:8500/user-service?tag=v1&dc=bj
+//	consul@https://consul.example.com/user-service?token=xxx
+//
+// 路径为服务名，支持的参数：
+//   - tag: 可选，服务的标签，可以有多个
+//   - dc: 可选，数据中心，默认为 Consul agent 所在的数据中心
+//   - token: 可选，ACL Token
+//
+// 节点的 Datacenter 保存在 Addr.Attr() 的 IDC 中，Service 的 Tags 保存在 Tags 中，
+// Weights.Passing 保存在 Weight 中，Service.Meta 原样保存。
+//
+// 在 Worker 中使用时，会使用阻塞查询（Blocking Query）监听节点的变化，变化后立即更新
+type Consul struct {
+	Client   *http.Client  // 可选，默认为 http.DefaultClient
+	WaitTime time.Duration // 可选，阻塞查询的最长等待时间，默认为 5 分钟
+}
+
+func (c *Consul) Scheme() string {
+	return "consul"
+}
+
+func (c *Consul) getClient() *http.Client {
+	if c.Client == nil {
+		return http.DefaultClient
+	}
+	return c.Client
+}
+
+func (c *Consul) getWaitTime() time.Duration {
+	if c.WaitTime <= 0 {
+		return 5 * time.Minute
+	}
+	return c.WaitTime
+}
+
+func (c *Consul) Lookup(ctx context.Context, idc string, address string) ([]xnet.AddrNode, error) {
+	nodes, _, err := c.query(ctx, idc, address, 0)
+	return nodes, err
+}
+
+// Watch 使用阻塞查询监听节点的变化
+func (c *Consul) Watch(ctx context.Context, idc string, address string, update func(nodes []xnet.AddrNode)) error {
+	var index uint64
+	for {
+		nodes, next, err := c.query(ctx, idc, address, index)
+		if err != nil {
+			return err
+		}
+		if next == 0 {
+			return errors.New("consul: missing X-Consul-Index header")
+		}
+		if next != index {
+			update(nodes)
+		}
+		if next < index {
+			// 索引变小时（如 Consul 重建了数据），需要重新开始
+			next = 0
+		}
+		index = next
+	}
+}
+
+type consulEntry struct {
+	Node struct {
+		Address    string `json:"Address"`
+		Datacenter string `json:"Datacenter"`
+	} `json:"Node"`
+	Service struct {
+		Address string            `json:"Address"`
+		Port    int               `json:"Port"`
+		Tags    []string          `json:"Tags"`
+		Meta    map[string]string `json:"Meta"`
+		Weights struct {
+			Passing int `json:"Passing"`
+		} `json:"Weights"`
+	} `json:"Service"`
+}
+
+func (e *consulEntry) nodeInfo() NodeInfo {
+	host := e.Service.Address
+	if host == "" {
+		host = e.Node.Address
+	}
+	return NodeInfo{
+		Address: net.JoinHostPort(host, strconv.Itoa(e.Service.Port)),
+		Weight:  e.Service.Weights.Passing,
+		IDC:     e.Node.Datacenter,
+		Tags:    e.Service.Tags,
+		Meta:    e.Service.Meta,
+	}
+}
+
+// query 查询健康的节点，index > 0 时为阻塞查询，返回新的 X-Consul-Index
+func (c *Consul) query(ctx context.Context, idc string, address string, index uint64) ([]xnet.AddrNode, uint64, error) {
+	u, err := parseRegistryURL(address)
+	if err != nil {
+		return nil, 0, err
+	}
+	service := strings.Trim(u.Path, "/")
+	if service == "" {
+		return nil, 0, fmt.Errorf("consul: empty service name in %q", address)
+	}
+	query := u.Query()
+	params := url.Values{"passing": {"1"}}
+	for _, tag := range query["tag"] {
+		params.Add("tag", tag)
+	}
+	if dc := query.Get("dc"); dc != "" {
+		params.Set("dc", dc)
+	}
+
+	// Consul 会在等待时间上增加最多 1/16 的随机时间
+	timeout := 10 * time.Second
+	if index > 0 {
+		wait := c.getWaitTime()
+		params.Set("index", strconv.FormatUint(index, 10))
+		params.Set("wait", strconv.Itoa(max(int(wait.Seconds()), 1))+"s")
+		timeout += wait + wait/16
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	api := u.Scheme + "://" + u.Host + "/v1/health/service/" + url.PathEscape(service) + "?" + params.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if token := query.Get("token"); token != "" {
+		req.Header.Set("X-Consul-Token", token)
+	}
+	resp, err := c.getClient().Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, registryError("consul", resp)
+	}
+	var entries []consulEntry
+	if err = json.NewDecoder(io.LimitReader(resp.Body, maxRegistryResponse)).Decode(&entries); err != nil {
+		return nil, 0, fmt.Errorf("consul: decode response: %w", err)
+	}
+	next, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	infos := make([]NodeInfo, 0, len(entries))
+	for _, entry := range entries {
+		infos = append(infos, entry.nodeInfo())
+	}
+	nodes, err := nodeInfosToNodes(ctx, idc, infos)
+	return nodes, next, err
+}
+
+// maxRegistryResponse 注册中心响应的最大长度
+const maxRegistryResponse = 64 << 20
+
+// parseRegistryURL 解析注册中心的地址，没有协议时默认为 http，如 127.0.0.1:8500/user-service
+func parseRegistryURL(address string) (*url.URL, error) {
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("empty host in %q", address)
+	}
+	return u, nil
+}
+
+func registryError(name string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s: %s %s", name, resp.Status, strings.TrimSpace(string(body)))
+}
+
+func init() {
+	MustRegister(&Consul{})
+}
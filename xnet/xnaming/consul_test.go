@@ -0,0 +1,132 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xnaming
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/xnet"
+	"github.com/xanygo/anygo/xt"
+)
+
+// testConsul 模拟 Consul 的 /v1/health/service/{name} 接口，支持阻塞查询
+type testConsul struct {
+	mux     sync.Mutex
+	index   int
+	ports   []int
+	changed chan struct{}
+	queries []string
+}
+
+func newTestConsul(ports ...int) *testConsul {
+	return &testConsul{
+		index:   1,
+		ports:   ports,
+		changed: make(chan struct{}),
+	}
+}
+
+func (tc *testConsul) setPorts(ports ...int) {
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+	tc.index++
+	tc.ports = ports
+	close(tc.changed)
+	tc.changed = make(chan struct{})
+}
+
+func (tc *testConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/health/service/web" {
+		http.NotFound(w, r)
+		return
+	}
+	tc.mux.Lock()
+	tc.queries = append(tc.queries, r.URL.RawQuery)
+	changed := tc.changed
+	current := tc.index
+	tc.mux.Unlock()
+
+	if index, _ := strconv.Atoi(r.URL.Query().Get("index")); index == current {
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	tc.mux.Lock()
+	defer tc.mux.Unlock()
+	entries := make([]map[string]any, 0, len(tc.ports))
+	for _, port := range tc.ports {
+		entries = append(entries, map[string]any{
+			"Node": map[string]any{"Address": "10.0.0.1", "Datacenter": "bj"},
+			"Service": map[string]any{
+				"Port":    port,
+				"Tags":    []string{"v1"},
+				"Meta":    map[string]string{"zone": "z1"},
+				"Weights": map[string]int{"Passing": 10, "Warning": 1},
+			},
+		})
+	}
+	w.Header().Set("X-Consul-Index", strconv.Itoa(tc.index))
+	_ = json.NewEncoder(w).Encode(entries)
+}
+
+func TestConsul(t *testing.T) {
+	tc := newTestConsul(8080, 8081)
+	ts := httptest.NewServer(tc)
+	defer ts.Close()
+	host := strings.TrimPrefix(ts.URL, "http://")
+
+	t.Run("lookup", func(t *testing.T) {
+		c := &Consul{}
+		nodes, err := c.Lookup(t.Context(), "", host+"/web?tag=v1&dc=bj")
+		xt.NoError(t, err)
+		testNodesEqual(t, nodes, []string{"10.0.0.1:8080", "10.0.0.1:8081"})
+		attr := nodes[0].Addr.(*xnet.Addr).Attr()
+		xt.Equal(t, attr.GetFirst(AttrIDC), "bj")
+		xt.Equal(t, attr.GetFirst(AttrWeight), "10")
+		xt.Equal(t, attr.Get(AttrTags), []string{"v1"})
+		xt.Equal(t, attr.GetFirst("zone"), "z1")
+		xt.Equal(t, tc.queries[len(tc.queries)-1], "dc=bj&passing=1&tag=v1")
+
+		_, err = c.Lookup(t.Context(), "", host+"/not-found")
+		xt.Error(t, err)
+	})
+
+	t.Run("worker", func(t *testing.T) {
+		w, err := NewWorker("", time.Hour, []string{"consul@" + host + "/web"}, nil)
+		xt.NoError(t, err)
+		xt.NoError(t, w.Start(t.Context()))
+		defer w.Stop(t.Context())
+		testNodesEqual(t, w.Nodes(), []string{"10.0.0.1:8080", "10.0.0.1:8081"})
+
+		// 等待阻塞查询开始
+		for i := 0; i < 100; i++ {
+			tc.mux.Lock()
+			n := len(tc.queries)
+			last := tc.queries[n-1]
+			tc.mux.Unlock()
+			if strings.Contains(last, "index=") {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		tc.setPorts(8082)
+
+		// 周期为 1 小时，节点依然可以立即更新
+		for i := 0; i < 100 && len(w.Nodes()) != 1; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		testNodesEqual(t, w.Nodes(), []string{"10.0.0.1:8082"})
+	})
+}
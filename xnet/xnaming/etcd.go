@@ -0,0 +1,207 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xnaming
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/xanygo/anygo/xnet"
+)
+
+var _ Naming = (*Etcd)(nil)
+var _ Watcher = (*Etcd)(nil)
+
+// Etcd 使用 etcd v3 的 JSON 网关（/v3/kv/range、/v3/watch）发现节点，地址如：
+//
+//	etcd@127.0.0.1:2379/services/user/
+//	etcd@https://etcd.example.com:2379/services/user/?token=xxx
+//
+// 路径为 key 的前缀，前缀下每个 key 的 value 是一个节点，可以是 JSON 格式的 NodeInfo，
+// 如 {"Address":"10.0.0.1:8080","Weight":10}，也可以直接是地址，如 10.0.0.1:8080。
+//
+// 支持的参数：
+//   - token: 可选，认证的 Token（/v3/auth/authenticate 返回的）
+//
+// 在 Worker 中使用时，会使用 /v3/watch 监听前缀下 key 的变化，变化后立即更新
+type Etcd struct {
+	Client *http.Client // 可选，默认为 http.DefaultClient
+}
+
+func (e *Etcd) Scheme() string {
+	return "etcd"
+}
+
+func (e *Etcd) getClient() *http.Client {
+	if e.Client == nil {
+		return http.DefaultClient
+	}
+	return e.Client
+}
+
+func (e *Etcd) Lookup(ctx context.Context, idc string, address string) ([]xnet.AddrNode, error) {
+	u, err := parseRegistryURL(address)
+	if err != nil {
+		return nil, err
+	}
+	nodes, _, err := e.rangeNodes(ctx, idc, u)
+	return nodes, err
+}
+
+// Watch 监听前缀下 key 的变化，有变化时重新读取节点列表
+func (e *Etcd) Watch(ctx context.Context, idc string, address string, update func(nodes []xnet.AddrNode)) error {
+	u, err := parseRegistryURL(address)
+	if err != nil {
+		return err
+	}
+	nodes, revision, err := e.rangeNodes(ctx, idc, u)
+	if err != nil {
+		return err
+	}
+	update(nodes)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	key, end := etcdPrefix(u.Path)
+	body := map[string]any{
+		"create_request": map[string]any{
+			"key":            key,
+			"range_end":      end,
+			"start_revision": revision + 1,
+		},
+	}
+	resp, err := e.post(ctx, u, "/v3/watch", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var msg etcdWatchResponse
+		if err = dec.Decode(&msg); err != nil {
+			return fmt.Errorf("etcd: watch: %w", err)
+		}
+		if msg.Error != nil {
+			return fmt.Errorf("etcd: watch: %s", msg.Error.Message)
+		}
+		if msg.Result.Canceled {
+			return fmt.Errorf("etcd: watch canceled: %s", msg.Result.CancelReason)
+		}
+		if len(msg.Result.Events) == 0 {
+			continue
+		}
+		nodes, _, err = e.rangeNodes(ctx, idc, u)
+		if err != nil {
+			return err
+		}
+		update(nodes)
+	}
+}
+
+type etcdRangeResponse struct {
+	Header struct {
+		Revision int64 `json:"revision,string"`
+	} `json:"header"`
+	Kvs []struct {
+		Key   []byte `json:"key"`
+		Value []byte `json:"value"`
+	} `json:"kvs"`
+}
+
+type etcdWatchResponse struct {
+	Result struct {
+		Canceled     bool   `json:"canceled"`
+		CancelReason string `json:"cancel_reason"`
+		Events       []any  `json:"events"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// rangeNodes 读取前缀下的所有节点，同时返回当前的 revision
+func (e *Etcd) rangeNodes(ctx context.Context, idc string, u *url.URL) ([]xnet.AddrNode, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	key, end := etcdPrefix(u.Path)
+	resp, err := e.post(ctx, u, "/v3/kv/range", map[string]any{"key": key, "range_end": end})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	var result etcdRangeResponse
+	if err = json.NewDecoder(io.LimitReader(resp.Body, maxRegistryResponse)).Decode(&result); err != nil {
+		return nil, 0, fmt.Errorf("etcd: decode response: %w", err)
+	}
+	infos := make([]NodeInfo, 0, len(result.Kvs))
+	for _, kv := range result.Kvs {
+		info, err := parseEtcdValue(kv.Value)
+		if err != nil {
+			return nil, 0, fmt.Errorf("etcd: invalid value of key %q: %w", kv.Key, err)
+		}
+		infos = append(infos, info)
+	}
+	nodes, err := nodeInfosToNodes(ctx, idc, infos)
+	return nodes, result.Header.Revision, err
+}
+
+func (e *Etcd) post(ctx context.Context, u *url.URL, path string, body any) (*http.Response, error) {
+	bf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.Scheme+"://"+u.Host+path, bytes.NewReader(bf))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token := u.Query().Get("token"); token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	resp, err := e.getClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, registryError("etcd", resp)
+	}
+	return resp, nil
+}
+
+// etcdPrefix 返回前缀查询的 key 和 range_end，JSON 网关中 []byte 使用 base64 编码
+func etcdPrefix(prefix string) (key []byte, end []byte) {
+	key = []byte(prefix)
+	end = bytes.Clone(key)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return key, end[:i+1]
+		}
+	}
+	// 前缀全部是 0xff 时，查询所有大于等于 key 的
+	return key, []byte{0}
+}
+
+func parseEtcdValue(value []byte) (NodeInfo, error) {
+	value = bytes.TrimSpace(value)
+	if len(value) > 0 && value[0] == '{' {
+		var info NodeInfo
+		err := json.Unmarshal(value, &info)
+		return info, err
+	}
+	return NodeInfo{Address: string(value)}, nil
+}
+
+func init() {
+	MustRegister(&Etcd{})
+}
@@ -0,0 +1,144 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xnaming
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/xnet"
+	"github.com/xanygo/anygo/xt"
+)
+
+// testEtcd 模拟 etcd v3 的 JSON 网关
+type testEtcd struct {
+	mux      sync.Mutex
+	revision int64
+	kvs      map[string]string
+	changed  chan struct{}
+	watching chan struct{}
+}
+
+func (te *testEtcd) put(key, value string) {
+	te.mux.Lock()
+	defer te.mux.Unlock()
+	te.revision++
+	te.kvs[key] = value
+	close(te.changed)
+	te.changed = make(chan struct{})
+}
+
+func (te *testEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/v3/kv/range":
+		var req struct {
+			Key      []byte `json:"key"`
+			RangeEnd []byte `json:"range_end"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		te.mux.Lock()
+		defer te.mux.Unlock()
+		var kvs []map[string]any
+		for k, v := range te.kvs {
+			if k >= string(req.Key) && k < string(req.RangeEnd) {
+				kvs = append(kvs, map[string]any{"key": []byte(k), "value": []byte(v)})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"header": map[string]any{"revision": strconv.FormatInt(te.revision, 10)},
+			"kvs":    kvs,
+		})
+	case "/v3/watch":
+		te.mux.Lock()
+		changed := te.changed
+		te.mux.Unlock()
+		_, _ = w.Write([]byte(`{"result":{"created":true}}` + "\n"))
+		w.(http.Flusher).Flush()
+		te.watching <- struct{}{}
+		for {
+			select {
+			case <-changed:
+				_, _ = w.Write([]byte(`{"result":{"events":[{"type":"PUT"}]}}` + "\n"))
+				w.(http.Flusher).Flush()
+				te.mux.Lock()
+				changed = te.changed
+				te.mux.Unlock()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestEtcd(t *testing.T) {
+	te := &testEtcd{
+		revision: 1,
+		kvs: map[string]string{
+			"/services/user/1": `{"Address":"10.0.0.1:8080","Weight":5}`,
+			"/services/user/2": "10.0.0.2:8080",
+			"/services/other/": "10.0.0.9:8080",
+		},
+		changed:  make(chan struct{}),
+		watching: make(chan struct{}, 1),
+	}
+	ts := httptest.NewServer(te)
+	defer ts.Close()
+	address := strings.TrimPrefix(ts.URL, "http://") + "/services/user/"
+
+	e := &Etcd{}
+	nodes, err := e.Lookup(t.Context(), "", address)
+	xt.NoError(t, err)
+	xt.Len(t, nodes, 2)
+	got := map[string]string{}
+	for _, node := range nodes {
+		got[node.Addr.String()] = node.Addr.(*xnet.Addr).Attr().GetFirst(AttrWeight)
+	}
+	xt.Equal(t, got, map[string]string{"10.0.0.1:8080": "5", "10.0.0.2:8080": ""})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	updates := make(chan []xnet.AddrNode, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- e.Watch(ctx, "", address, func(nodes []xnet.AddrNode) {
+			updates <- nodes
+		})
+	}()
+	xt.Len(t, <-updates, 2)
+
+	select {
+	case <-te.watching:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch not started")
+	}
+	te.put("/services/user/3", "10.0.0.3:8080")
+	select {
+	case nodes = <-updates:
+		xt.Len(t, nodes, 3)
+	case <-time.After(5 * time.Second):
+		t.Fatal("wait update timeout")
+	}
+	cancel()
+	xt.Error(t, <-done)
+}
+
+func TestEtcdPrefix(t *testing.T) {
+	key, end := etcdPrefix("/a/")
+	xt.Equal(t, string(key), "/a/")
+	xt.Equal(t, string(end), "/a0")
+	_, end = etcdPrefix("a\xff")
+	xt.Equal(t, string(end), "b")
+}
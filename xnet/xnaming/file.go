@@ -7,16 +7,19 @@ package xnaming
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/xanygo/anygo/ds/xmap"
+	"github.com/xanygo/anygo/xcodec"
 	"github.com/xanygo/anygo/xio/xfs"
 	"github.com/xanygo/anygo/xnet"
 )
 
 var _ Naming = (*FileStore)(nil)
+var _ Watcher = (*FileStore)(nil)
 
 // FileStore 解析文件，如  file@server_list.ns
 //
@@ -27,7 +30,21 @@ var _ Naming = (*FileStore)(nil)
 //
 //	# backup node
 //	10.0.0.1:9000  # comment
+//
+// 文件后缀为 .json 时，文件内容为 NodeInfo 列表，可以设置节点的权重和元数据，如：
+//
+//	[
+//	  {"Address":"127.0.0.1:8000","Weight":10,"Meta":{"zone":"a"}},
+//	  {"Address":"127.0.0.2:8000","Weight":5}
+//	]
+//
+// .yaml 、.yml 等其他格式，需要先使用 RegisterFileDecoder 注册对应的解析器。
+//
+// 在 Worker 中使用时，会使用 xfs.Watcher 监听文件的变化，文件变化后立即更新
 type FileStore struct {
+	// WatchInterval 可选，Watch 时检查文件变化的间隔，默认为 1 秒
+	WatchInterval time.Duration
+
 	cache *xmap.LRUReader[string, *cachedFile]
 	once  sync.Once
 }
@@ -55,18 +72,70 @@ func (f *FileStore) Lookup(ctx context.Context, idc string, filename string) ([]
 	return f.cache.Get(filename).fetch()
 }
 
+func (f *FileStore) getWatchInterval() time.Duration {
+	if f.WatchInterval > 0 {
+		return f.WatchInterval
+	}
+	return time.Second
+}
+
+// Watch 监听文件的变化，文件变化后重新解析。若文件内容有误，会保留之前的节点列表
+func (f *FileStore) Watch(ctx context.Context, idc string, filename string, update func(nodes []xnet.AddrNode)) error {
+	interval := f.getWatchInterval()
+	w := &xfs.Watcher{
+		Interval: interval,
+		Delay:    interval,
+	}
+	changed := make(chan struct{}, 1)
+	w.Watch(filename, func(event xfs.WatcherEvent) {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	if err := w.Start(); err != nil {
+		return err
+	}
+	defer w.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-changed:
+			if nodes, err := f.Lookup(ctx, idc, filename); err == nil {
+				update(nodes)
+			}
+		}
+	}
+}
+
 func init() {
 	MustRegister(&FileStore{})
 }
 
+var fileDecoders = &xmap.Sync[string, xcodec.Decoder]{}
+
+func init() {
+	RegisterFileDecoder(".json", xcodec.JSON)
+}
+
+// RegisterFileDecoder 注册 FileStore 解析节点文件的解析器，ext 为文件后缀，如 ".yaml"，
+// 解析的结果为 []NodeInfo。默认已支持 .json
+func RegisterFileDecoder(ext string, decoder xcodec.Decoder) {
+	fileDecoders.Store(strings.ToLower(ext), decoder)
+}
+
 type cachedFile struct {
 	path  string
 	file  *xfs.CachedReader
+	mux   sync.Mutex
 	addrs []xnet.AddrNode
 	err   error
 }
 
 func (cf *cachedFile) fetch() ([]xnet.AddrNode, error) {
+	cf.mux.Lock()
+	defer cf.mux.Unlock()
 	content, fromCache, err := cf.file.ReadFile()
 	if err != nil {
 		return nil, err
@@ -78,10 +147,13 @@ func (cf *cachedFile) fetch() ([]xnet.AddrNode, error) {
 }
 
 func (cf *cachedFile) parser(content []byte) ([]xnet.AddrNode, error) {
-	lines := strings.Split(string(content), "\n")
-	nodes := make([]xnet.AddrNode, 0, len(lines))
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
+	if decoder, ok := fileDecoders.Load(strings.ToLower(filepath.Ext(cf.path))); ok {
+		return cf.decode(ctx, decoder, content)
+	}
+	lines := strings.Split(string(content), "\n")
+	nodes := make([]xnet.AddrNode, 0, len(lines))
 	for _, line := range lines {
 		line, _, _ = strings.Cut(line, "#") // 去掉 # 注释的内容
 		line = strings.TrimSpace(line)
@@ -99,3 +171,18 @@ func (cf *cachedFile) parser(content []byte) ([]xnet.AddrNode, error) {
 	}
 	return nodes, nil
 }
+
+func (cf *cachedFile) decode(ctx context.Context, decoder xcodec.Decoder, content []byte) ([]xnet.AddrNode, error) {
+	var infos []NodeInfo
+	if err := decoder.Decode(content, &infos); err != nil {
+		return nil, fmt.Errorf("decode file %s: %w", cf.path, err)
+	}
+	nodes, err := nodeInfosToNodes(ctx, "", infos)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no hostPort found in file %s", cf.path)
+	}
+	return nodes, nil
+}
@@ -5,7 +5,11 @@
 package xnaming
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/xanygo/anygo/xnet"
 	"github.com/xanygo/anygo/xt"
@@ -26,3 +30,57 @@ func testNodesEqual(t *testing.T, nodes []xnet.AddrNode, want []string) {
 	}
 	xt.Equal(t, addrs, want)
 }
+
+func TestFileStore_JSON(t *testing.T) {
+	f := &FileStore{}
+	nodes, err := f.Lookup(t.Context(), "bj", "testdata/file/server_list_1.json")
+	xt.NoError(t, err)
+	testNodesEqual(t, nodes, []string{"127.0.0.1:8000", "127.0.0.2:8000"})
+	attr := nodes[0].Addr.(*xnet.Addr).Attr()
+	xt.Equal(t, attr.GetFirst(AttrWeight), "10")
+	xt.Equal(t, attr.GetFirst(AttrIDC), "bj")
+	xt.Equal(t, attr.Get(AttrTags), []string{"a", "b"})
+	xt.Equal(t, attr.GetFirst("zone"), "z1")
+	xt.False(t, nodes[1].Addr.(*xnet.Addr).Attr().Has(AttrWeight))
+}
+
+func TestFileStore_Watch(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "nodes.txt")
+	xt.NoError(t, os.WriteFile(fp, []byte("127.0.0.1:8000"), 0644))
+
+	f := &FileStore{WatchInterval: 10 * time.Millisecond}
+	updates := make(chan []xnet.AddrNode, 10)
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() {
+		done <- f.Watch(ctx, "", fp, func(nodes []xnet.AddrNode) {
+			updates <- nodes
+		})
+	}()
+
+	waitNodes := func(want []string) {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case nodes := <-updates:
+				if len(nodes) == len(want) {
+					testNodesEqual(t, nodes, want)
+					return
+				}
+			case <-timeout:
+				t.Fatalf("wait nodes %v timeout", want)
+			}
+		}
+	}
+	waitNodes([]string{"127.0.0.1:8000"})
+
+	// 修改时间需要和之前不同
+	xt.NoError(t, os.WriteFile(fp, []byte("127.0.0.1:8000\n127.0.0.2:8000"), 0644))
+	mt := time.Now().Add(-time.Hour)
+	xt.NoError(t, os.Chtimes(fp, mt, mt))
+	waitNodes([]string{"127.0.0.1:8000", "127.0.0.2:8000"})
+
+	cancel()
+	xt.Error(t, <-done)
+}
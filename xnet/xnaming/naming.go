@@ -22,6 +22,13 @@ type Naming interface {
 	Lookup(ctx context.Context, idc string, address string) ([]xnet.AddrNode, error)
 }
 
+// Watcher Naming 可选实现的接口，节点变化时主动推送最新的节点列表。
+// 在 Worker 中使用时，除了周期性地调用 Lookup 外，还会调用 Watch，节点变化后可以立即生效
+type Watcher interface {
+	// Watch 监听节点的变化，直到 ctx 结束或者出错。节点列表变化时调用 update
+	Watch(ctx context.Context, idc string, address string, update func(nodes []xnet.AddrNode)) error
+}
+
 var factories = map[string]Naming{}
 
 func Register(n Naming) error {
@@ -51,16 +58,21 @@ func Lookup(ctx context.Context, scheme string, idc string, address string) ([]x
 	return n.Lookup(ctx, idc, address)
 }
 
+// splitRaw 将 "scheme@address" 拆分为 scheme 和 address，没有 "@" 时 scheme 为空
+func splitRaw(str string) (scheme string, address string) {
+	scheme, address, found := strings.Cut(strings.TrimSpace(str), "@")
+	if !found {
+		return "", scheme
+	}
+	return scheme, address
+}
+
 func LookupRaw(ctx context.Context, idc string, str string) ([]xnet.AddrNode, error) {
 	str = strings.TrimSpace(str)
 	if str == "" {
 		return nil, nil
 	}
-	scheme, after, found := strings.Cut(str, "@")
-	if !found {
-		scheme = ""
-		after = str
-	}
+	scheme, after := splitRaw(str)
 	return Lookup(ctx, scheme, idc, after)
 }
 
@@ -7,6 +7,8 @@ package xnaming
 import (
 	"crypto/md5"
 	"encoding/hex"
+	"maps"
+	"slices"
 	"sort"
 
 	"github.com/xanygo/anygo/ds/xbus"
@@ -63,6 +65,16 @@ func (nw *nodeProducer) genSign(nodes []xnet.AddrNode) string {
 	for _, node := range nodes {
 		_, _ = h.Write([]byte(node.Addr.String()))
 		_, _ = h.Write([]byte(node.HostPort))
+		// 权重等属性变化时，也需要更新
+		if a, ok := node.Addr.(*xnet.Addr); ok {
+			attr := a.Attr().Map(true)
+			for _, k := range slices.Sorted(maps.Keys(attr)) {
+				_, _ = h.Write([]byte(k))
+				for _, v := range attr[k] {
+					_, _ = h.Write([]byte(v))
+				}
+			}
+		}
 	}
 	return hex.EncodeToString(h.Sum(nil))
 }
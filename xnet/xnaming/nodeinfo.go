@@ -0,0 +1,76 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xnaming
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/xanygo/anygo/xnet"
+)
+
+// 节点的附加属性，保存在 xnet.Addr.Attr() 中
+const (
+	AttrWeight = "Weight" // 节点的权重
+	AttrIDC    = "IDC"    // 节点所在的机房，如 Consul 的 Datacenter
+	AttrTags   = "Tags"   // 节点的标签，有多个值
+)
+
+// NodeInfo 带有权重和元数据的节点，用于 JSON、YAML 格式的节点文件，以及 etcd 中存储的节点
+type NodeInfo struct {
+	Address string            `json:"Address" yaml:"Address"` // 必填，如 127.0.0.1:8000，也可以是其他 Naming 支持的格式，如 dns@example.com:80
+	Weight  int               `json:"Weight"  yaml:"Weight"`  // 可选，权重
+	IDC     string            `json:"IDC"     yaml:"IDC"`     // 可选，所在机房
+	Tags    []string          `json:"Tags"    yaml:"Tags"`    // 可选，标签
+	Meta    map[string]string `json:"Meta"    yaml:"Meta"`    // 可选，其他元数据，原样保存在 Attr 中
+}
+
+// Nodes 解析 Address 并将属性设置到每个节点上
+func (n NodeInfo) Nodes(ctx context.Context, idc string) ([]xnet.AddrNode, error) {
+	if n.Address == "" {
+		return nil, fmt.Errorf("empty Address in node %v", n)
+	}
+	nodes, err := LookupRaw(ctx, idc, n.Address)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		n.setAttr(node.Addr)
+	}
+	return nodes, nil
+}
+
+func (n NodeInfo) setAttr(addr any) {
+	a, ok := addr.(*xnet.Addr)
+	if !ok {
+		return
+	}
+	attr := a.Attr()
+	for k, v := range n.Meta {
+		attr.Set(k, v)
+	}
+	if n.Weight > 0 {
+		attr.Set(AttrWeight, strconv.Itoa(n.Weight))
+	}
+	if n.IDC != "" {
+		attr.Set(AttrIDC, n.IDC)
+	}
+	if len(n.Tags) > 0 {
+		attr.Set(AttrTags, n.Tags...)
+	}
+}
+
+func nodeInfosToNodes(ctx context.Context, idc string, infos []NodeInfo) ([]xnet.AddrNode, error) {
+	nodes := make([]xnet.AddrNode, 0, len(infos))
+	for _, info := range infos {
+		ns, err := info.Nodes(ctx, idc)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, ns...)
+	}
+	return nodes, nil
+}
@@ -7,10 +7,12 @@ package xnaming
 import (
 	"context"
 	"errors"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/xanygo/anygo/ds/xbus"
+	"github.com/xanygo/anygo/ds/xctx"
 	"github.com/xanygo/anygo/xnet"
 	"github.com/xanygo/anygo/xpp"
 )
@@ -43,6 +45,11 @@ type Worker struct {
 	worker        *xpp.CycleWorker
 	once          sync.Once
 	producer      *nodeProducer
+
+	doMux     sync.Mutex
+	mux       sync.Mutex
+	watched   map[string][]xnet.AddrNode // 通过 Watcher 获取到的节点列表，key 为 item
+	stopWatch context.CancelFunc
 }
 
 func (n *Worker) Name() string {
@@ -65,7 +72,61 @@ func (n *Worker) initOnce() {
 
 func (n *Worker) Start(ctx context.Context) error {
 	n.once.Do(n.initOnce)
-	return n.worker.Start(ctx)
+	if err := n.worker.Start(ctx); err != nil {
+		return err
+	}
+	n.startWatch(ctx)
+	return nil
+}
+
+// startWatch 对于 Naming 实现了 Watcher 的地址，监听节点的变化，变化后立即更新
+func (n *Worker) startWatch(ctx context.Context) {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	if n.stopWatch != nil {
+		return
+	}
+	ctx, n.stopWatch = context.WithCancel(ctx)
+	for _, item := range slices.Concat(n.itemsPrimary, n.itemsFallback) {
+		scheme, address := splitRaw(item)
+		if w, ok := factories[scheme].(Watcher); ok {
+			go n.watch(ctx, w, item, address)
+		}
+	}
+}
+
+func (n *Worker) watch(ctx context.Context, w Watcher, item string, address string) {
+	for {
+		_ = w.Watch(ctx, n.idc, address, func(nodes []xnet.AddrNode) {
+			n.setWatched(item, nodes)
+			_ = n.do(ctx)
+		})
+		// 监听中断后，使用 Lookup 的结果，直到重新监听成功
+		n.setWatched(item, nil)
+		if ctx.Err() != nil {
+			return
+		}
+		xctx.Sleep(ctx, time.Second)
+	}
+}
+
+func (n *Worker) setWatched(item string, nodes []xnet.AddrNode) {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	if len(nodes) == 0 {
+		delete(n.watched, item)
+		return
+	}
+	if n.watched == nil {
+		n.watched = make(map[string][]xnet.AddrNode)
+	}
+	n.watched[item] = nodes
+}
+
+func (n *Worker) getWatched(item string) []xnet.AddrNode {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	return n.watched[item]
 }
 
 func (n *Worker) Messages() <-chan xbus.Message {
@@ -74,6 +135,9 @@ func (n *Worker) Messages() <-chan xbus.Message {
 }
 
 func (n *Worker) do(ctx context.Context) error {
+	n.doMux.Lock()
+	defer n.doMux.Unlock()
+
 	primaryNodes, err1 := n.search(ctx, n.idc, n.itemsPrimary)
 	if len(primaryNodes) > 0 {
 		n.producer.Update(primaryNodes)
@@ -95,6 +159,10 @@ func (n *Worker) search(ctx context.Context, idc string, items []string) ([]xnet
 	var allNodes []xnet.AddrNode
 	var errs []error
 	for _, item := range items {
+		if nodes := n.getWatched(item); len(nodes) > 0 {
+			allNodes = append(allNodes, nodes...)
+			continue
+		}
 		nodes, err := LookupRaw(ctx, idc, item)
 		if err != nil {
 			errs = append(errs, err)
@@ -113,6 +181,12 @@ func (n *Worker) search(ctx context.Context, idc string, items []string) ([]xnet
 
 func (n *Worker) Stop(ctx context.Context) error {
 	n.once.Do(n.initOnce)
+	n.mux.Lock()
+	if n.stopWatch != nil {
+		n.stopWatch()
+		n.stopWatch = nil
+	}
+	n.mux.Unlock()
 
 	return n.worker.Stop(ctx)
 }
@@ -0,0 +1,89 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xpolicy
+
+import (
+	"sync"
+	"time"
+)
+
+// RetryBudget 重试预算，基于令牌桶：每个请求存入 Ratio 个令牌，每秒额外补充 MinPerSecond 个令牌，
+// 每次重试或者对冲请求消耗 1 个令牌，令牌不足时不再重试。
+//
+// 用于避免下游故障时，重试和对冲请求成倍地放大请求量。
+// 令牌统计在 RetryBudget 对象上，所以同一个下游服务应使用同一个 RetryBudget 对象。
+// 为 nil 时不限制
+type RetryBudget struct {
+	// Ratio 可选，每个请求存入的令牌数，默认为 0.1，即重试和对冲的请求量最多为正常请求量的 10%
+	Ratio float64
+
+	// MinPerSecond 可选，每秒固定补充的令牌数，默认为 10，以保证请求量很小时也可以重试
+	MinPerSecond float64
+
+	// Burst 可选，桶的容量，默认为 100
+	Burst float64
+
+	mux    sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func (b *RetryBudget) getRatio() float64 {
+	if b.Ratio <= 0 {
+		return 0.1
+	}
+	return b.Ratio
+}
+
+func (b *RetryBudget) getMinPerSecond() float64 {
+	if b.MinPerSecond <= 0 {
+		return 10
+	}
+	return b.MinPerSecond
+}
+
+func (b *RetryBudget) getBurst() float64 {
+	if b.Burst <= 0 {
+		return 100
+	}
+	return b.Burst
+}
+
+// refill 按时间补充令牌，调用方需持有锁
+func (b *RetryBudget) refill(now time.Time) {
+	if b.last.IsZero() {
+		b.tokens = b.getMinPerSecond()
+	} else if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * b.getMinPerSecond()
+	}
+	b.tokens = min(b.tokens, b.getBurst())
+	b.last = now
+}
+
+// Deposit 发送一个新请求（不含重试和对冲）时调用
+func (b *RetryBudget) Deposit() {
+	if b == nil {
+		return
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.refill(time.Now())
+	b.tokens = min(b.tokens+b.getRatio(), b.getBurst())
+}
+
+// Withdraw 重试或者发送对冲请求前调用，返回 false 表示预算已耗尽，不应再重试
+func (b *RetryBudget) Withdraw() bool {
+	if b == nil {
+		return true
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.refill(time.Now())
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,111 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xpolicy
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Hedging 对冲请求策略：请求发出后，若等待 Delay 后仍未成功，向另外一个节点发送相同的请求，使用最先成功的结果。
+//
+// 只对幂等的请求生效（见 IsIdempotent）。
+// 使用 Percentile 时，耗时统计在 Hedging 对象上，所以同一个下游服务应使用同一个 Hedging 对象
+type Hedging struct {
+	// Delay 可选，发送对冲请求前等待的时间，默认为 100ms。
+	// 若设置了 Percentile，在统计的样本足够之前使用此值
+	Delay time.Duration
+
+	// Percentile 可选，取值 (0,1)，如 0.95 表示使用最近成功请求耗时的 P95 作为等待时间
+	Percentile float64
+
+	// MaxHedges 可选，最多发送的对冲请求数（不含原请求），默认为 1
+	MaxHedges int
+
+	latency latencyWindow
+}
+
+func (h *Hedging) GetMaxHedges() int {
+	if h.MaxHedges <= 0 {
+		return 1
+	}
+	return h.MaxHedges
+}
+
+// GetDelay 发送对冲请求前等待的时间
+func (h *Hedging) GetDelay() time.Duration {
+	if h.Percentile > 0 && h.Percentile < 1 {
+		if d, ok := h.latency.percentile(h.Percentile); ok {
+			return d
+		}
+	}
+	if h.Delay <= 0 {
+		return 100 * time.Millisecond
+	}
+	return h.Delay
+}
+
+// Observe 记录一次成功请求的耗时，用于计算 Percentile
+func (h *Hedging) Observe(cost time.Duration) {
+	if h.Percentile > 0 {
+		h.latency.add(cost)
+	}
+}
+
+const (
+	latencySamples    = 512 // 保留最近的样本数
+	latencyMinSamples = 20  // 样本数少于此值时，不计算分位值
+	latencyRefresh    = 32  // 每新增多少个样本，重新计算一次分位值
+)
+
+// latencyWindow 最近请求耗时的滑动窗口
+type latencyWindow struct {
+	mux     sync.Mutex
+	samples [latencySamples]time.Duration
+	total   int // 累计的样本数
+
+	cached    time.Duration
+	cachedP   float64
+	cachedAt  int // 计算 cached 时的 total
+	hasCached bool
+}
+
+func (w *latencyWindow) add(cost time.Duration) {
+	w.mux.Lock()
+	w.samples[w.total%latencySamples] = cost
+	w.total++
+	w.mux.Unlock()
+}
+
+func (w *latencyWindow) percentile(p float64) (time.Duration, bool) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	if w.total < latencyMinSamples {
+		return 0, false
+	}
+	if w.hasCached && w.cachedP == p && w.total-w.cachedAt < latencyRefresh {
+		return w.cached, true
+	}
+	items := slices.Clone(w.samples[:min(w.total, latencySamples)])
+	slices.Sort(items)
+	idx := min(int(float64(len(items))*p), len(items)-1)
+	w.cached = items[idx]
+	w.cachedP = p
+	w.cachedAt = w.total
+	w.hasCached = true
+	return w.cached, true
+}
+
+// IsIdempotent 判断请求是否幂等：优先使用 req 实现的 Idempotent 接口，其次使用 ctx 中的标记（见 ContextWithIdempotent），
+// 都没有时返回 false
+func IsIdempotent(ctx context.Context, req any) bool {
+	if ri, ok := req.(Idempotent); ok {
+		return ri.Idempotent()
+	}
+	ok, _ := IdempotentFromCtx(ctx)
+	return ok
+}
@@ -0,0 +1,58 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xpolicy_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/xnet/xpolicy"
+	"github.com/xanygo/anygo/xt"
+)
+
+func TestHedging(t *testing.T) {
+	h := &xpolicy.Hedging{}
+	xt.Equal(t, h.GetDelay(), 100*time.Millisecond)
+	xt.Equal(t, h.GetMaxHedges(), 1)
+
+	h = &xpolicy.Hedging{Delay: 30 * time.Millisecond, Percentile: 0.95}
+	for i := 1; i <= 10; i++ {
+		h.Observe(time.Duration(i) * time.Millisecond)
+	}
+	// 样本不足时使用 Delay
+	xt.Equal(t, h.GetDelay(), 30*time.Millisecond)
+	for i := 11; i <= 100; i++ {
+		h.Observe(time.Duration(i) * time.Millisecond)
+	}
+	xt.Equal(t, h.GetDelay(), 96*time.Millisecond)
+}
+
+func TestIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	xt.False(t, xpolicy.IsIdempotent(ctx, "req"))
+	xt.True(t, xpolicy.IsIdempotent(xpolicy.ContextWithIdempotent(ctx, true), "req"))
+}
+
+func TestRetryBudget(t *testing.T) {
+	var nb *xpolicy.RetryBudget
+	nb.Deposit()
+	xt.True(t, nb.Withdraw())
+
+	b := &xpolicy.RetryBudget{Ratio: 0.5, MinPerSecond: 0.001, Burst: 2}
+	xt.False(t, b.Withdraw())
+	b.Deposit()
+	b.Deposit()
+	xt.True(t, b.Withdraw())
+	xt.False(t, b.Withdraw())
+
+	// 令牌数不会超过 Burst
+	for range 10 {
+		b.Deposit()
+	}
+	xt.True(t, b.Withdraw())
+	xt.True(t, b.Withdraw())
+	xt.False(t, b.Withdraw())
+}
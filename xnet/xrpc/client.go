@@ -48,6 +48,24 @@ type Response interface {
 	Unwrap() any
 }
 
+// ConnHolder Response 可选实现的接口，LoadFrom 成功后，若 HoldConn 返回 true，Invoke 不会回收连接，
+// 由 Response 负责在使用完后调用连接的 Close 方法（如流式读取 HTTP 响应的 Body）
+type ConnHolder interface {
+	HoldConn() bool
+}
+
+// Hedger Response 可选实现的接口，实现后才能发送对冲请求（见 OptHedging）。
+//
+// 对冲时，原请求和对冲请求都使用 NewHedge 创建的 Response 读取响应，这些请求会并发执行，
+// 最先成功的请求的 Response 会传给原 Response 的 AcceptHedge
+type Hedger interface {
+	// NewHedge 创建用于对冲请求的 Response，返回 nil 表示不支持对冲
+	NewHedge() Response
+
+	// AcceptHedge 使用最先成功的请求的结果
+	AcceptHedge(ctx context.Context, winner Response) error
+}
+
 type HasOptionReader interface {
 	OptionReader(ctx context.Context, rd xoption.Reader) xoption.Reader
 }
@@ -150,6 +168,22 @@ func OptRetryWithPolicy(retry int, policy *xpolicy.Retry) Option {
 	})
 }
 
+// OptRetryBudget 设置重试预算，重试和对冲请求都会消耗预算，预算耗尽后不再重试，
+// 以避免下游故障时放大请求量
+func OptRetryBudget(budget *xpolicy.RetryBudget) Option {
+	return optionFunc(func(o *config) {
+		xoption.SetRetryBudget(o.opt, budget)
+	})
+}
+
+// OptHedging 设置对冲请求策略：对于幂等的请求，等待一段时间后仍未成功，向另外一个节点发送相同的请求，
+// 使用最先成功的结果。Response 需要实现 Hedger 接口
+func OptHedging(policy *xpolicy.Hedging) Option {
+	return optionFunc(func(o *config) {
+		xoption.SetHedging(o.opt, policy)
+	})
+}
+
 func OptAddr(addr ...net.Addr) Option {
 	return optionFunc(func(o *config) {
 		o.ap = xbalance.NewStaticByAddr(addr...)
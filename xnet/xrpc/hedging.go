@@ -0,0 +1,138 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xrpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xctx"
+	"github.com/xanygo/anygo/ds/xoption"
+	"github.com/xanygo/anygo/xnet"
+	"github.com/xanygo/anygo/xnet/xbalance"
+	"github.com/xanygo/anygo/xnet/xpolicy"
+	"github.com/xanygo/anygo/xnet/xservice"
+)
+
+var ctxKeyHedgeNodes = xctx.NewKey()
+
+// hedgeNodes 同一组对冲请求已经使用的节点
+type hedgeNodes struct {
+	mux  sync.Mutex
+	used map[string]bool
+}
+
+// add 记录节点，若节点已经被使用过，返回 false
+func (hn *hedgeNodes) add(key string) bool {
+	hn.mux.Lock()
+	defer hn.mux.Unlock()
+	if hn.used[key] {
+		return false
+	}
+	if hn.used == nil {
+		hn.used = make(map[string]bool)
+	}
+	hn.used[key] = true
+	return true
+}
+
+// pickAddress 选择节点，对冲请求会尽量选择和同组的其他请求不同的节点
+func pickAddress(ctx context.Context, ap xbalance.Reader) (*xnet.AddrNode, error) {
+	hn, _ := ctx.Value(ctxKeyHedgeNodes).(*hedgeNodes)
+	if hn == nil {
+		return xbalance.Pick(ctx, ap)
+	}
+	var addr *xnet.AddrNode
+	var err error
+	for range 3 {
+		addr, err = xbalance.Pick(ctx, ap)
+		if err != nil || hn.add(addr.HostPort) {
+			return addr, err
+		}
+	}
+	// 节点数很少时，可能一直选到相同的节点，此时依然发送到此节点
+	return addr, err
+}
+
+// getHedger 请求可以对冲时，返回 Response 的 Hedger
+func getHedger(ctx context.Context, req Request, resp Response, opt xoption.Reader) (*xpolicy.Hedging, Hedger) {
+	policy := xoption.Hedging(opt)
+	if policy == nil || !xpolicy.IsIdempotent(ctx, req) {
+		return nil, nil
+	}
+	hg, ok := resp.(Hedger)
+	if !ok {
+		return nil, nil
+	}
+	return policy, hg
+}
+
+type hedgeResult struct {
+	resp Response
+	cost time.Duration
+	err  error
+}
+
+// tryHedged 发送请求，若等待一段时间后仍未成功，向其他节点发送对冲请求，使用最先成功的结果。
+//
+// 所有请求共享 ctx 的截止时间，返回时会取消未完成的请求
+func (c *Feilian) tryHedged(ctx context.Context, cfg *config, req Request, resp Response, serviceName string, service xservice.Service,
+	its []Interceptor, opt xoption.Reader, policy *xpolicy.Hedging, hg Hedger, budget *xpolicy.RetryBudget) error {
+	first := hg.NewHedge()
+	if first == nil {
+		return c.tryOnce(ctx, cfg, req, resp, serviceName, service, its, opt)
+	}
+
+	hctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	hctx = context.WithValue(hctx, ctxKeyHedgeNodes, &hedgeNodes{})
+
+	maxHedges := policy.GetMaxHedges()
+	results := make(chan hedgeResult, maxHedges+1)
+	send := func(attempt Response) {
+		go func() {
+			start := time.Now()
+			err := c.tryOnce(hctx, cfg, req, attempt, serviceName, service, its, opt)
+			results <- hedgeResult{resp: attempt, cost: time.Since(start), err: err}
+		}()
+	}
+	send(first)
+
+	delay := policy.GetDelay()
+	tm := time.NewTimer(delay)
+	defer tm.Stop()
+
+	pending := 1
+	var hedges int
+	var lastErr error
+	for pending > 0 {
+		select {
+		case <-tm.C:
+			if hedges >= maxHedges || !budget.Withdraw() {
+				continue
+			}
+			next := hg.NewHedge()
+			if next == nil {
+				continue
+			}
+			hedges++
+			pending++
+			send(next)
+			if hedges < maxHedges {
+				tm.Reset(delay)
+			}
+		case ret := <-results:
+			pending--
+			if ret.err == nil {
+				policy.Observe(ret.cost)
+				cancel()
+				return hg.AcceptHedge(ctx, ret.resp)
+			}
+			lastErr = ret.err
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,137 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xrpc_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xtype"
+	"github.com/xanygo/anygo/xhttp/xhttpc"
+	"github.com/xanygo/anygo/xnet/xpolicy"
+	"github.com/xanygo/anygo/xnet/xrpc"
+	"github.com/xanygo/anygo/xnet/xservice"
+	"github.com/xanygo/anygo/xt"
+)
+
+func TestHedging(t *testing.T) {
+	var total atomic.Int64
+	var mux sync.Mutex
+	var nodes []string
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mux.Lock()
+			nodes = append(nodes, name)
+			mux.Unlock()
+			if total.Add(1) == 1 {
+				// 第一个请求很慢
+				select {
+				case <-time.After(time.Second):
+				case <-r.Context().Done():
+					return
+				}
+			}
+			w.Write([]byte(name))
+		}))
+	}
+	ts1 := newServer("s1")
+	defer ts1.Close()
+	ts2 := newServer("s2")
+	defer ts2.Close()
+
+	reset := func() {
+		total.Store(0)
+		mux.Lock()
+		nodes = nil
+		mux.Unlock()
+	}
+	hostOf := func(ts *httptest.Server) string {
+		u, err := url.Parse(ts.URL)
+		xt.NoError(t, err)
+		return u.Host
+	}
+	cfg := &xservice.Config{
+		Name: "hedging",
+		DownStream: xservice.DownStreamPart{
+			Address: []string{hostOf(ts1), hostOf(ts2)},
+		},
+		Hedging: &xservice.HedgingPart{
+			Delay: xtype.Duration(50 * time.Millisecond),
+		},
+	}
+	srv, err := cfg.Parser("test")
+	xt.NoError(t, err)
+	xt.NoError(t, srv.Start(t.Context()))
+	defer srv.Stop(t.Context())
+
+	t.Run("idempotent", func(t *testing.T) {
+		reset()
+		start := time.Now()
+		got, err := xhttpc.GetBody(t.Context(), srv, "/get")
+		xt.NoError(t, err)
+		xt.Less(t, time.Since(start), 500*time.Millisecond)
+		mux.Lock()
+		defer mux.Unlock()
+		xt.Len(t, nodes, 2)
+		// 对冲请求发送到了另外一个节点
+		xt.NotEqual(t, nodes[0], nodes[1])
+		xt.Equal(t, string(got), nodes[1])
+	})
+
+	t.Run("not idempotent", func(t *testing.T) {
+		reset()
+		start := time.Now()
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, "/post", strings.NewReader("body"))
+		xt.NoError(t, err)
+		resp := &http.Response{}
+		err = xhttpc.Invoke(t.Context(), srv, req, xhttpc.FetchResponse(resp), xrpc.OptRetry(0))
+		xt.NoError(t, err)
+		xt.GreaterOrEqual(t, time.Since(start), time.Second)
+		xt.Equal(t, total.Load(), int64(1))
+		_ = resp.Body.Close()
+	})
+
+	t.Run("budget", func(t *testing.T) {
+		reset()
+		// 预算已耗尽，不会发送对冲请求
+		budget := &xpolicy.RetryBudget{MinPerSecond: 0.001, Burst: 1}
+		xt.False(t, budget.Withdraw())
+		got, err := xhttpc.GetBody(t.Context(), srv, "/get", xrpc.OptRetryBudget(budget))
+		xt.NoError(t, err)
+		xt.Equal(t, total.Load(), int64(1))
+		mux.Lock()
+		defer mux.Unlock()
+		xt.Equal(t, string(got), nodes[0])
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		reset()
+		var calls atomic.Int64
+		var body string
+		resp := &xhttpc.Response{
+			Handler: func(ctx context.Context, resp *http.Response) error {
+				calls.Add(1)
+				bf, err := io.ReadAll(resp.Body)
+				body = string(bf)
+				return err
+			},
+		}
+		req := &xhttpc.Request{Method: http.MethodGet, Path: "/get"}
+		err := xrpc.Invoke(t.Context(), srv, req, resp)
+		xt.NoError(t, err)
+		// Handler 只处理最先成功的响应
+		xt.Equal(t, calls.Load(), int64(1))
+		xt.Equal(t, resp.ErrCode(), int64(http.StatusOK))
+		xt.NotEmpty(t, body)
+	})
+}
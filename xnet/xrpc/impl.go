@@ -18,7 +18,6 @@ import (
 	"github.com/xanygo/anygo/ds/xsync"
 	"github.com/xanygo/anygo/xnet"
 	"github.com/xanygo/anygo/xnet/dsession"
-	"github.com/xanygo/anygo/xnet/xbalance"
 	"github.com/xanygo/anygo/xnet/xdial"
 	"github.com/xanygo/anygo/xnet/xpolicy"
 	"github.com/xanygo/anygo/xnet/xservice"
@@ -144,13 +143,24 @@ func (c *Feilian) Invoke(ctx context.Context, srv any, req Request, resp Respons
 		retryPolicy = xoption.RetryPolicy(opt)
 	}
 
+	// 重试和对冲请求都会消耗预算
+	budget := xoption.RetryBudget(opt)
+	budget.Deposit()
+
+	hedging, hedger := getHedger(ctx, req, resp, opt)
+
 	for attempt := range attemptTotal {
 		ctxTry := ctx
 		if attempt > 0 {
 			ctxTry = ContextWithRetryCount(ctx, attempt)
 		}
-		result = c.tryOnce(ctxTry, cfg, req, resp, serviceName, service, its, opt)
-		if result == nil || attempt >= attemptTotal-1 || ctxTry.Err() != nil || !retryPolicy.IsRetryable(ctxTry, req, attempt, result) {
+		if hedger != nil {
+			result = c.tryHedged(ctxTry, cfg, req, resp, serviceName, service, its, opt, hedging, hedger, budget)
+		} else {
+			result = c.tryOnce(ctxTry, cfg, req, resp, serviceName, service, its, opt)
+		}
+		if result == nil || attempt >= attemptTotal-1 || ctxTry.Err() != nil || !retryPolicy.IsRetryable(ctxTry, req, attempt, result) ||
+			!budget.Withdraw() {
 			return result
 		}
 		if backoff := retryPolicy.GetBackoff(attempt); backoff > 0 {
@@ -184,7 +194,7 @@ func (c *Feilian) tryOnce(ctx context.Context, cfg *config, req Request, resp Re
 	if ap == nil {
 		ap = service.Balancer()
 	}
-	addr, err := xbalance.Pick(ctx, ap)
+	addr, err := pickAddress(ctx, ap)
 	for _, it := range its {
 		if it.AfterPickAddress != nil {
 			it.AfterPickAddress(ctx, serviceName, addr, err)
@@ -229,6 +239,9 @@ func (c *Feilian) tryOnce(ctx context.Context, cfg *config, req Request, resp Re
 			}
 		}
 		wrSpan.End()
+		if ch, ok := resp.(ConnHolder); ok && err == nil && ch.HoldConn() {
+			return
+		}
 		_ = conn.Close()
 	}()
 
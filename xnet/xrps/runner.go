@@ -0,0 +1,466 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xrps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xtype"
+	"github.com/xanygo/anygo/xattr"
+	"github.com/xanygo/anygo/xlog"
+	"github.com/xanygo/anygo/xnet/xjsonrpc2"
+	"github.com/xanygo/anygo/xnet/xservice"
+	"github.com/xanygo/anygo/xpp"
+)
+
+// EnvInheritListeners 热重启时，父进程通过此环境变量告知子进程继承的 listener，
+// 格式为 "name:fd,name:fd"，如 "http:3,rpc:4"
+const EnvInheritListeners = "ANYGO_INHERIT_LISTENERS"
+
+// GracefulServer 可以被 Runner 管理的 server，*http.Server 已实现该接口。
+// 若 Shutdown 超时返回错误，且 server 实现了 io.Closer，会调用 Close 方法强制关闭
+type GracefulServer interface {
+	Serve(l net.Listener) error
+	CanShutdown
+}
+
+var (
+	_ GracefulServer = (*http.Server)(nil)
+	_ GracefulServer = (*tcpServer)(nil)
+)
+
+// NewHTTPServer 使用 http.Handler（如 *xhttp.Router）创建 GracefulServer
+func NewHTTPServer(h http.Handler) GracefulServer {
+	return &http.Server{
+		Handler: h,
+	}
+}
+
+// NewTCPServer 将 TCPAnyServer 包装为 GracefulServer，Shutdown 时会先关闭 listener，
+// 不再接收新连接，然后等待已有连接处理完成
+func NewTCPServer(as *TCPAnyServer) GracefulServer {
+	return &tcpServer{
+		as: as,
+	}
+}
+
+// NewJSONRPCServer 使用 xjsonrpc2.Router 创建 GracefulServer，每个 TCP 连接使用 Router.Serve 处理。
+// 长连接在客户端关闭之前不会结束，所以优雅退出时，会等待至 DrainTimeout 后强制关闭
+func NewJSONRPCServer(router *xjsonrpc2.Router) GracefulServer {
+	handler := HandleFunc[net.Conn](func(ctx context.Context, conn net.Conn) {
+		defer conn.Close()
+		_ = router.Serve(ctx, conn, conn)
+	})
+	return NewTCPServer(&TCPAnyServer{Handler: handler})
+}
+
+type tcpServer struct {
+	as     *TCPAnyServer
+	mux    sync.Mutex
+	ln     net.Listener
+	closed bool
+}
+
+func (ts *tcpServer) Serve(l net.Listener) error {
+	ts.mux.Lock()
+	if ts.closed {
+		ts.mux.Unlock()
+		return ErrShutdown
+	}
+	ts.ln = l
+	ts.mux.Unlock()
+	return ts.as.Serve(l)
+}
+
+func (ts *tcpServer) Shutdown(ctx context.Context) error {
+	ts.mux.Lock()
+	ts.closed = true
+	ln := ts.ln
+	ts.mux.Unlock()
+	if ln != nil {
+		_ = ln.Close()
+	}
+	return ts.as.Shutdown(ctx)
+}
+
+// RunnerConfig Runner 的配置，可以使用 xcfg.Parse 从配置文件读取
+type RunnerConfig struct {
+	// Listen 服务的监听地址，可选，key 为服务名称，value 为地址，如 {"http": ":8080", "rpc": "unix:/tmp/rpc.sock"}，
+	// 若没有配置，会使用 xattr.AppMain().GetListen(name)
+	Listen map[string]string `json:"Listen" yaml:"Listen"`
+
+	// DrainTimeout 优雅退出时，等待已有请求处理完成的最长时间，可选，如 "30s"，默认为 30s
+	DrainTimeout xtype.Duration `json:"DrainTimeout" yaml:"DrainTimeout"`
+
+	// ShutdownDelay 收到退出信号后，先将状态设置为未就绪（Ready 返回 false），
+	// 等待此时间（让负载均衡摘除流量）后再关闭 server，可选，如 "5s"，默认为 0
+	ShutdownDelay xtype.Duration `json:"ShutdownDelay" yaml:"ShutdownDelay"`
+
+	// HotRestart 是否在收到 SIGHUP 信号时热重启，可选，默认为 false。
+	// 热重启时会启动新的进程并将 listener 传递给它，然后当前进程优雅退出
+	HotRestart bool `json:"HotRestart" yaml:"HotRestart"`
+}
+
+func (c *RunnerConfig) validate() error {
+	if c.DrainTimeout < 0 {
+		return fmt.Errorf("invalid DrainTimeout %s", c.DrainTimeout)
+	}
+	if c.ShutdownDelay < 0 {
+		return fmt.Errorf("invalid ShutdownDelay %s", c.ShutdownDelay)
+	}
+	return nil
+}
+
+func (c *RunnerConfig) getDrainTimeout() time.Duration {
+	if c.DrainTimeout > 0 {
+		return c.DrainTimeout.Duration()
+	}
+	return 30 * time.Second
+}
+
+func (c *RunnerConfig) getShutdownDelay() time.Duration {
+	return max(c.ShutdownDelay.Duration(), 0)
+}
+
+// Runner 应用级别的 server 管理器，管理多个 server（如 xhttp.Router、xjsonrpc2.Router、TCPAnyServer）的生命周期：
+//  1. 启动：依次启动 Workers，监听端口（或者继承热重启前的 listener），启动所有 server，然后设置为就绪状态
+//  2. 运行：直到 ctx 结束、收到 SIGINT/SIGTERM 信号、或者任意 server 异常退出
+//  3. 退出：设置为未就绪状态，等待 ShutdownDelay，在 DrainTimeout 内优雅关闭所有 server，
+//     然后依次停止 Workers 和 Loader
+//
+// 使用示例：
+//
+//	runner := &xrps.Runner{Workers: []any{worker}, Loader: loader}
+//	runner.Add("http", xrps.NewHTTPServer(router))
+//	runner.Add("rpc", xrps.NewJSONRPCServer(rpcRouter))
+//	err := runner.Run(context.Background())
+type Runner struct {
+	// Config 配置，可选
+	Config RunnerConfig
+
+	// Workers 后台任务，可选，启动 server 之前使用 xpp.TryStartWorker 启动，
+	// 所有 server 退出之后使用 xpp.TryStopWorker 停止
+	Workers []any
+
+	// Loader 下游服务的加载器，可选，退出时调用其 Stop 方法
+	Loader *xservice.Loader
+
+	xlog.WithLogger
+
+	servers  []*runnerEntry
+	errs     chan error
+	ready    atomic.Bool
+	stopOnce sync.Once
+	stopErr  error
+}
+
+type runnerEntry struct {
+	name   string
+	server GracefulServer
+	ln     net.Listener
+}
+
+// Add 添加 server，name 用于读取监听地址（见 RunnerConfig.Listen），需要在 Start 之前调用
+func (r *Runner) Add(name string, server GracefulServer) {
+	r.servers = append(r.servers, &runnerEntry{name: name, server: server})
+}
+
+// Ready 是否处于就绪状态：所有 server 都已启动并且没有开始退出
+func (r *Runner) Ready() bool {
+	return r.ready.Load()
+}
+
+// ReadyHandler 就绪检查的 http.Handler，就绪时状态码为 200，否则为 503，
+// 可用于负载均衡的健康检查，如 router.Get("/ready", runner.ReadyHandler())
+func (r *Runner) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if r.Ready() {
+			_, _ = io.WriteString(w, "ok")
+			return
+		}
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+	})
+}
+
+// Addr 返回 server 的监听地址，若 server 不存在或者未启动，返回 nil
+func (r *Runner) Addr(name string) net.Addr {
+	for _, e := range r.servers {
+		if e.name == name && e.ln != nil {
+			return e.ln.Addr()
+		}
+	}
+	return nil
+}
+
+func (r *Runner) getListen(name string) (string, error) {
+	if addr, ok := r.Config.Listen[name]; ok {
+		return strings.TrimSpace(addr), nil
+	}
+	return xattr.AppMain().GetListen(name)
+}
+
+// Start 启动 Workers 和所有 server，不会阻塞。若有失败，已经启动的会被停止
+func (r *Runner) Start(ctx context.Context) error {
+	if len(r.servers) == 0 {
+		return errors.New("no server to run")
+	}
+	if err := r.Config.validate(); err != nil {
+		return err
+	}
+	if err := xpp.TryStartWorker(ctx, r.Workers...); err != nil {
+		return err
+	}
+	inherited := parseInheritListeners(os.Getenv(EnvInheritListeners))
+	_ = os.Unsetenv(EnvInheritListeners)
+
+	closeAll := func() {
+		for _, e := range r.servers {
+			if e.ln != nil {
+				_ = e.ln.Close()
+				e.ln = nil
+			}
+		}
+		for _, f := range inherited {
+			_ = f.Close()
+		}
+	}
+
+	for _, e := range r.servers {
+		ln, err := r.listen(e.name, inherited)
+		if err != nil {
+			closeAll()
+			return errors.Join(fmt.Errorf("listen %s: %w", e.name, err), xpp.TryStopWorker(ctx, r.Workers...))
+		}
+		e.ln = ln
+	}
+	for _, f := range inherited {
+		_ = f.Close()
+	}
+
+	lg := r.AutoLogger()
+	r.errs = make(chan error, len(r.servers))
+	for _, e := range r.servers {
+		go func() {
+			err := e.server.Serve(e.ln)
+			if !isServerClosed(err) {
+				r.errs <- fmt.Errorf("server %s: %w", e.name, err)
+			}
+		}()
+		lg.Info(ctx, "server started", xlog.String("name", e.name), xlog.String("addr", e.ln.Addr().String()))
+	}
+	r.ready.Store(true)
+	return nil
+}
+
+func (r *Runner) listen(name string, inherited map[string]*os.File) (net.Listener, error) {
+	if f, ok := inherited[name]; ok {
+		delete(inherited, name)
+		defer f.Close()
+		return net.FileListener(f)
+	}
+	addr, err := r.getListen(name)
+	if err != nil {
+		return nil, err
+	}
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		return net.Listen("unix", path)
+	}
+	return net.Listen("tcp", addr)
+}
+
+func isServerClosed(err error) bool {
+	return err == nil ||
+		errors.Is(err, http.ErrServerClosed) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, ErrShutdown)
+}
+
+// Run 启动并运行，直到 ctx 结束、收到 SIGINT/SIGTERM 信号、或者任意 server 异常退出，然后优雅退出。
+// 若开启了热重启（RunnerConfig.HotRestart），收到 SIGHUP 信号时，启动新的进程后退出，
+// 若新进程启动失败，会继续运行
+func (r *Runner) Run(ctx context.Context) error {
+	if err := r.Start(ctx); err != nil {
+		return err
+	}
+	signals := []os.Signal{os.Interrupt, syscall.SIGTERM}
+	if r.Config.HotRestart {
+		signals = append(signals, syscall.SIGHUP)
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	defer signal.Stop(ch)
+
+	lg := r.AutoLogger()
+	var runErr error
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			lg.Info(ctx, "context done, shutting down")
+			break loop
+		case runErr = <-r.errs:
+			lg.Warn(ctx, "server exited, shutting down", xlog.ErrorAttr("error", runErr))
+			break loop
+		case sig := <-ch:
+			if sig == syscall.SIGHUP {
+				p, err := r.Restart()
+				if err != nil {
+					lg.Warn(ctx, "hot restart failed", xlog.ErrorAttr("error", err))
+					continue
+				}
+				lg.Info(ctx, "new process started, shutting down", xlog.Int("pid", p.Pid))
+				break loop
+			}
+			lg.Info(ctx, "received signal, shutting down", xlog.String("signal", sig.String()))
+			break loop
+		}
+	}
+	return errors.Join(runErr, r.Shutdown(context.WithoutCancel(ctx)))
+}
+
+// Shutdown 优雅退出，只会执行一次：
+//  1. 设置为未就绪状态，并等待 ShutdownDelay
+//  2. 在 DrainTimeout 内并发关闭所有 server，超时后强制关闭
+//  3. 使用 xpp.TryStopWorker 停止 Workers
+//  4. 停止 Loader
+func (r *Runner) Shutdown(ctx context.Context) error {
+	r.stopOnce.Do(func() {
+		r.stopErr = r.doShutdown(ctx)
+	})
+	return r.stopErr
+}
+
+func (r *Runner) doShutdown(ctx context.Context) error {
+	r.ready.Store(false)
+	if d := r.Config.getShutdownDelay(); d > 0 {
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+
+	dctx, cancel := context.WithTimeout(ctx, r.Config.getDrainTimeout())
+	defer cancel()
+
+	lg := r.AutoLogger()
+	var mux sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	for _, e := range r.servers {
+		if e.ln == nil {
+			continue
+		}
+		wg.Go(func() {
+			err := e.server.Shutdown(dctx)
+			if err != nil {
+				if c, ok := e.server.(io.Closer); ok {
+					_ = c.Close()
+				}
+				err = fmt.Errorf("shutdown server %s: %w", e.name, err)
+				mux.Lock()
+				errs = append(errs, err)
+				mux.Unlock()
+			}
+			lg.Info(ctx, "server stopped", xlog.String("name", e.name), xlog.ErrorAttr("error", err))
+		})
+	}
+	wg.Wait()
+
+	if err := xpp.TryStopWorker(ctx, r.Workers...); err != nil {
+		errs = append(errs, err)
+	}
+	if r.Loader != nil {
+		r.Loader.Stop(ctx)
+	}
+	return errors.Join(errs...)
+}
+
+// Restart 热重启：使用当前进程的启动参数启动新的进程，并将所有 listener 传递给它（通过 EnvInheritListeners），
+// 新进程使用 Start 或 Run 时会继承这些 listener，从而不会中断服务。
+// 调用成功后，当前进程应该调用 Shutdown 优雅退出（Run 方法会自动处理），
+// 此时 unix socket 的 listener 关闭时不会再删除 socket 文件，因为新进程还在使用它
+func (r *Runner) Restart() (*os.Process, error) {
+	type filer interface {
+		File() (*os.File, error)
+	}
+	var files []*os.File
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	var pairs []string
+	for _, e := range r.servers {
+		if e.ln == nil {
+			return nil, fmt.Errorf("server %s not started", e.name)
+		}
+		fl, ok := e.ln.(filer)
+		if !ok {
+			return nil, fmt.Errorf("listener of %s (%T) not support File()", e.name, e.ln)
+		}
+		f, err := fl.File()
+		if err != nil {
+			return nil, fmt.Errorf("get file of %s: %w", e.name, err)
+		}
+		files = append(files, f)
+		// ExtraFiles 中的第 i 个文件，在子进程中的 fd 为 3+i
+		pairs = append(pairs, e.name+":"+strconv.Itoa(2+len(files)))
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Env = append(os.Environ(), EnvInheritListeners+"="+strings.Join(pairs, ","))
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = files
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+	for _, e := range r.servers {
+		if ul, ok := e.ln.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+	return cmd.Process, nil
+}
+
+// parseInheritListeners 解析 EnvInheritListeners 环境变量的值，返回 name -> 文件
+func parseInheritListeners(value string) map[string]*os.File {
+	if value == "" {
+		return nil
+	}
+	result := make(map[string]*os.File)
+	for _, item := range strings.Split(value, ",") {
+		name, fdStr, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok {
+			continue
+		}
+		fd, err := strconv.Atoi(fdStr)
+		if err != nil || fd < 3 {
+			continue
+		}
+		result[name] = os.NewFile(uintptr(fd), name)
+	}
+	return result
+}
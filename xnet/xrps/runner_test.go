@@ -0,0 +1,167 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xrps_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/ds/xtype"
+	"github.com/xanygo/anygo/xnet/xrps"
+	"github.com/xanygo/anygo/xt"
+)
+
+type testWorker struct {
+	mux    sync.Mutex
+	events []string
+}
+
+func (w *testWorker) Start(ctx context.Context) error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	w.events = append(w.events, "start")
+	return nil
+}
+
+func (w *testWorker) Stop(ctx context.Context) error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	w.events = append(w.events, "stop")
+	return nil
+}
+
+func (w *testWorker) Events() []string {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	return append([]string(nil), w.events...)
+}
+
+func TestRunner(t *testing.T) {
+	worker := &testWorker{}
+	runner := &xrps.Runner{
+		Config: xrps.RunnerConfig{
+			Listen: map[string]string{
+				"http": "127.0.0.1:0",
+				"tcp":  "127.0.0.1:0",
+			},
+			DrainTimeout: xtype.Duration(time.Second),
+		},
+		Workers: []any{worker},
+	}
+	runner.Add("http", xrps.NewHTTPServer(runner.ReadyHandler()))
+	runner.Add("tcp", xrps.NewTCPServer(&xrps.TCPAnyServer{Handler: xrps.HandleFunc[net.Conn](echoHandler)}))
+	xt.False(t, runner.Ready())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- runner.Run(ctx)
+	}()
+	for i := 0; i < 100 && !runner.Ready(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	xt.True(t, runner.Ready())
+	xt.Equal(t, worker.Events(), []string{"start"})
+
+	resp, err := http.Get("http://" + runner.Addr("http").String())
+	xt.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	xt.Equal(t, resp.StatusCode, http.StatusOK)
+	xt.Equal(t, string(body), "ok")
+
+	conn, err := net.Dial("tcp", runner.Addr("tcp").String())
+	xt.NoError(t, err)
+	_, err = conn.Write([]byte("hello\n"))
+	xt.NoError(t, err)
+	line, _, err := bufio.NewReader(conn).ReadLine()
+	xt.NoError(t, err)
+	xt.Equal(t, string(line), `resp:"hello"`)
+
+	cancel()
+	start := time.Now()
+	select {
+	case err = <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Run not returned")
+	}
+	// tcp 长连接未关闭，等待 DrainTimeout 后强制关闭
+	xt.NoError(t, err)
+	xt.True(t, time.Since(start) >= 900*time.Millisecond)
+	xt.False(t, runner.Ready())
+	xt.Equal(t, worker.Events(), []string{"start", "stop"})
+
+	_, err = conn.Read(make([]byte, 1))
+	xt.Error(t, err)
+	_ = conn.Close()
+}
+
+func TestRunner_inherit(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	xt.NoError(t, err)
+	f, err := ln.(*net.TCPListener).File()
+	xt.NoError(t, err)
+	_ = ln.Close()
+
+	t.Setenv(xrps.EnvInheritListeners, "http:"+strconv.FormatUint(uint64(f.Fd()), 10))
+	runner := &xrps.Runner{}
+	runner.Add("http", xrps.NewHTTPServer(runner.ReadyHandler()))
+	xt.NoError(t, runner.Start(context.Background()))
+	xt.Equal(t, runner.Addr("http").String(), ln.Addr().String())
+	xt.Empty(t, os.Getenv(xrps.EnvInheritListeners))
+
+	resp, err := http.Get("http://" + ln.Addr().String())
+	xt.NoError(t, err)
+	_ = resp.Body.Close()
+	xt.Equal(t, resp.StatusCode, http.StatusOK)
+
+	xt.NoError(t, runner.Shutdown(context.Background()))
+	xt.False(t, runner.Ready())
+}
+
+func TestRunner_listenError(t *testing.T) {
+	worker := &testWorker{}
+	runner := &xrps.Runner{
+		Config: xrps.RunnerConfig{
+			Listen: map[string]string{
+				"http":  "127.0.0.1:0",
+				"other": "127.0.0.1:-1",
+			},
+		},
+		Workers: []any{worker},
+	}
+	runner.Add("http", xrps.NewHTTPServer(http.NotFoundHandler()))
+	runner.Add("other", xrps.NewHTTPServer(http.NotFoundHandler()))
+	xt.Error(t, runner.Start(context.Background()))
+	xt.Nil(t, runner.Addr("http"))
+	xt.Equal(t, worker.Events(), []string{"start", "stop"})
+}
+
+func TestRunnerConfig(t *testing.T) {
+	var cfg xrps.RunnerConfig
+	xt.NoError(t, json.Unmarshal([]byte(`{"DrainTimeout":"10s","ShutdownDelay":500}`), &cfg))
+	xt.Equal(t, cfg.DrainTimeout.Duration(), 10*time.Second)
+	xt.Equal(t, cfg.ShutdownDelay.Duration(), 500*time.Millisecond)
+	xt.Error(t, json.Unmarshal([]byte(`{"DrainTimeout":"10x"}`), &cfg))
+
+	runner := &xrps.Runner{
+		Config: xrps.RunnerConfig{
+			Listen:       map[string]string{"http": "127.0.0.1:0"},
+			DrainTimeout: xtype.Duration(-time.Second),
+		},
+	}
+	runner.Add("http", xrps.NewHTTPServer(runner.ReadyHandler()))
+	xt.Error(t, runner.Start(context.Background()))
+	xt.False(t, runner.Ready())
+}
@@ -18,9 +18,11 @@ import (
 	"github.com/xanygo/anygo/ds/xtype"
 	"github.com/xanygo/anygo/xcfg"
 	"github.com/xanygo/anygo/xcodec"
+	"github.com/xanygo/anygo/xhttp/xcookiejar"
 	"github.com/xanygo/anygo/xnet/xbalance"
 	"github.com/xanygo/anygo/xnet/xdial"
 	"github.com/xanygo/anygo/xnet/xnaming"
+	"github.com/xanygo/anygo/xnet/xpolicy"
 	"github.com/xanygo/anygo/xnet/xproxy"
 )
 
@@ -34,7 +36,7 @@ type Config struct {
 	ReadTimeout      xtype.Duration  `json:"ReadTimeout"      yaml:"ReadTimeout"`      // 读超时时间，单位毫秒
 	HandshakeTimeout xtype.Duration  `json:"HandshakeTimeout" yaml:"HandshakeTimeout"` // 握手超时时间，单位毫秒
 	Retry            int             `json:"Retry"             yaml:"Retry"`           // 重试次数，可选，默认 0
-	Protocol         string          `json:"Protocol"         yaml:"Protocol"`         // 交互协议
+	Protocol         string          `json:"Protocol"         yaml:"Protocol"`         // 交互协议，可选，如 HTTP2（此时会使用协议专用的连接池）
 	MaxResponseSize  xtype.ByteCount `json:"MaxResponseSize"   yaml:"MaxResponseSize"` // 响应最大限制，可选
 	UseProxy         string          `json:"UseProxy" yaml:"UseProxy"`                 // 将另外一个service 当做代理
 	WorkerCycle      xtype.Duration  `json:"WorkerCycle"         yaml:"WorkerCycle"`   // 后台任务运行周期，可选，如 "3s"
@@ -47,6 +49,9 @@ type Config struct {
 
 	SessionInit *xoption.SessionStarterConfig `json:"SessionInit"   yaml:"SessionInit"`
 
+	Hedging     *HedgingPart     `json:"Hedging"     yaml:"Hedging"`     // 对冲请求配置，可选
+	RetryBudget *RetryBudgetPart `json:"RetryBudget" yaml:"RetryBudget"` // 重试预算配置，可选，重试和对冲请求都会消耗预算
+
 	Extra map[string]any // 其他字段，配置里配置了，但是在此 Config 里没有定义的字段会解析到此处
 }
 
@@ -66,6 +71,31 @@ type ConnPoolPart struct {
 	MaxPoolIdleTime xtype.Duration `json:"MaxPoolIdleTime" yaml:"MaxPoolIdleTime"` // 单位毫秒，当超过此时长未被使用后,关闭并清理对应的 Pool,<=0 时使用默认值 10 minute
 }
 
+// HedgingPart 对冲请求配置，只对幂等的请求生效，见 xpolicy.Hedging
+type HedgingPart struct {
+	Delay      xtype.Duration `json:"Delay"      yaml:"Delay"`      // 发送对冲请求前等待的时间，可选，默认 100ms
+	Percentile float64        `json:"Percentile" yaml:"Percentile"` // 可选，如 0.95 表示使用最近请求耗时的 P95 作为等待时间
+	MaxHedges  int            `json:"MaxHedges"  yaml:"MaxHedges"`  // 最多发送的对冲请求数，可选，默认 1
+}
+
+func (hp *HedgingPart) toPolicy() (*xpolicy.Hedging, error) {
+	if hp.Percentile < 0 || hp.Percentile >= 1 {
+		return nil, fmt.Errorf("invalid Percentile=%v", hp.Percentile)
+	}
+	return &xpolicy.Hedging{
+		Delay:      hp.Delay.Duration(),
+		Percentile: hp.Percentile,
+		MaxHedges:  hp.MaxHedges,
+	}, nil
+}
+
+// RetryBudgetPart 重试预算配置，见 xpolicy.RetryBudget
+type RetryBudgetPart struct {
+	Ratio        float64 `json:"Ratio"        yaml:"Ratio"`        // 每个请求存入的令牌数，可选，默认 0.1
+	MinPerSecond float64 `json:"MinPerSecond" yaml:"MinPerSecond"` // 每秒固定补充的令牌数，可选，默认 10
+	Burst        float64 `json:"Burst"        yaml:"Burst"`        // 桶的容量，可选，默认 100
+}
+
 func (cp *ConnPoolPart) GetName() string {
 	if cp == nil || cp.Name == "" {
 		return xdial.Short
@@ -106,13 +136,64 @@ type DownStreamIDCPart struct {
 type HTTPPart struct {
 	Host   string      `json:"Host" yaml:"Host"` // 主机名，可选
 	Header http.Header `json:"Header" yaml:"Header"`
+
+	// Redirect 自动跟随重定向的最大次数，可选，默认为 0-不跟随（直接返回 3xx 响应）
+	// 只有使用 xhttpc.Invoke 系列方法发送请求时才生效
+	Redirect int `json:"Redirect" yaml:"Redirect"`
+
+	// CookieJar 是否启用 Cookie，可选，启用后，同一个 service 的所有请求共享一个 Cookie Jar（在内存中）
+	CookieJar bool `json:"CookieJar" yaml:"CookieJar"`
+
+	// Auth 身份认证，可选
+	Auth *HTTPAuthPart `json:"Auth" yaml:"Auth"`
 }
 
 func (ho *HTTPPart) Clone() *HTTPPart {
-	return &HTTPPart{
-		Host:   ho.Host,
-		Header: ho.Header.Clone(),
+	c := &HTTPPart{
+		Host:      ho.Host,
+		Header:    ho.Header.Clone(),
+		Redirect:  ho.Redirect,
+		CookieJar: ho.CookieJar,
+	}
+	if ho.Auth != nil {
+		auth := *ho.Auth
+		c.Auth = &auth
 	}
+	return c
+}
+
+const (
+	AuthBasic  = "Basic"
+	AuthBearer = "Bearer"
+	AuthDigest = "Digest"
+)
+
+// HTTPAuthPart HTTP 身份认证配置
+type HTTPAuthPart struct {
+	// Type 认证类型，必填，可选值：Basic、Bearer、Digest
+	//  Basic  : 每个请求都发送 Authorization: Basic base64(Username:Password)
+	//  Bearer : 每个请求都发送 Authorization: Bearer Token
+	//  Digest : 收到 401 响应后，依据 WWW-Authenticate 计算摘要，并重新发送请求
+	Type     string `json:"Type" yaml:"Type" validator:"required"`
+	Username string `json:"Username" yaml:"Username"` // 用户名，Basic 和 Digest 使用
+	Password string `json:"Password" yaml:"Password"` // 密码，Basic 和 Digest 使用
+	Token    string `json:"Token" yaml:"Token"`       // Bearer 使用
+}
+
+func (a *HTTPAuthPart) check() error {
+	switch a.Type {
+	case AuthBasic, AuthDigest:
+		if a.Username == "" {
+			return fmt.Errorf("empty Username for %s auth", a.Type)
+		}
+	case AuthBearer:
+		if a.Token == "" {
+			return errors.New("empty Token for Bearer auth")
+		}
+	default:
+		return fmt.Errorf("invalid auth Type %q", a.Type)
+	}
+	return nil
 }
 
 // Parser 解析为 Service 类型（需要Start 后才能使用）
@@ -179,13 +260,36 @@ func (c *Config) Parser(idc string) (Service, error) {
 		xproxy.SetOptConfig(opt, c.Proxy)
 	}
 	if c.HTTP != nil {
+		if c.HTTP.Auth != nil {
+			if err := c.HTTP.Auth.check(); err != nil {
+				return nil, fmt.Errorf("invalid HTTP.Auth for service %q: %w", c.Name, err)
+			}
+		}
 		SetOptHTTP(opt, *c.HTTP)
+		if c.HTTP.CookieJar {
+			SetOptCookieJar(opt, xcookiejar.New(nil))
+		}
 	}
 
 	if c.SessionInit != nil {
 		xoption.SetSessionStarter(opt, c.SessionInit)
 	}
 
+	if c.Hedging != nil {
+		policy, err := c.Hedging.toPolicy()
+		if err != nil {
+			return nil, fmt.Errorf("invalid Hedging for service %q: %w", c.Name, err)
+		}
+		xoption.SetHedging(opt, policy)
+	}
+	if c.RetryBudget != nil {
+		xoption.SetRetryBudget(opt, &xpolicy.RetryBudget{
+			Ratio:        c.RetryBudget.Ratio,
+			MinPerSecond: c.RetryBudget.MinPerSecond,
+			Burst:        c.RetryBudget.Burst,
+		})
+	}
+
 	for k, v := range c.Extra {
 		xoption.SetExtra(opt, k, v)
 	}
@@ -213,7 +317,12 @@ func (c *Config) Parser(idc string) (Service, error) {
 	impl.connector = &connector{}
 
 	poolOpt := c.ConnPool.GetOption()
-	pool, err := xdial.NewGroupPool(c.ConnPool.GetName(), &poolOpt, impl.connector)
+	poolName := c.ConnPool.GetName()
+	if c.Protocol != "" && xdial.HasGroupPool(c.Protocol) {
+		// 协议有专用的连接池，如 HTTP2 需要多路复用
+		poolName = c.Protocol
+	}
+	pool, err := xdial.NewGroupPool(poolName, &poolOpt, impl.connector)
 	if err != nil {
 		return nil, err
 	}
@@ -172,7 +172,9 @@ func (l *Loader) loadOneStart(ctx context.Context, name string) (Service, error)
 
 func (l *Loader) Stop(ctx context.Context) {
 	l.once.Do(l.initOnce)
-	l.reloadWorker.Stop(ctx)
+	if l.reloadWorker != nil {
+		l.reloadWorker.Stop(ctx)
+	}
 }
 
 var defaultLoader = &xsync.OnceInit[*Loader]{
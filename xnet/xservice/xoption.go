@@ -5,12 +5,15 @@
 package xservice
 
 import (
+	"net/http"
+
 	"github.com/xanygo/anygo/ds/xoption"
 )
 
 var (
 	xOptKeyHTTP  = xoption.NewKey("HTTP")
 	xOptConnPool = xoption.NewKey("ConnPool")
+	xOptCookie   = xoption.NewKey("CookieJar")
 )
 
 func SetOptHTTP(opt xoption.Writer, val HTTPPart) {
@@ -32,3 +35,18 @@ func SetOptConnPool(opt xoption.Writer, val *ConnPoolPart) {
 func OptConnPool(opt xoption.Reader) *ConnPoolPart {
 	return xoption.GetAsDefault[*ConnPoolPart](opt, xOptConnPool, nil)
 }
+
+// SetOptCookieJar 设置 HTTP 请求使用的 Cookie Jar。
+//
+// 配置了 HTTP.CookieJar 的 service，会使用内存中的 Jar，若需要持久化，可以替换为使用 xkv 存储的 Jar：
+//
+//	if w, ok := srv.Option().(xoption.Writer); ok {
+//	    xservice.SetOptCookieJar(w, xcookiejar.New(store.Hash("cookie:"+srv.Name())))
+//	}
+func SetOptCookieJar(opt xoption.Writer, jar http.CookieJar) {
+	opt.Set(xOptCookie, jar)
+}
+
+func OptCookieJar(opt xoption.Reader) http.CookieJar {
+	return xoption.GetAsDefault[http.CookieJar](opt, xOptCookie, nil)
+}
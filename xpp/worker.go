@@ -72,7 +72,7 @@ func TryStartWorker(ctx context.Context, workers ...any) error {
 			successList = append(successList, worker)
 		}
 	}
-	if len(errs) > 0 {
+	if len(errs) == 0 {
 		return nil
 	}
 	if err := TryStopWorker(ctx, successList...); err != nil {
@@ -0,0 +1,60 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xpp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/xanygo/anygo/xt"
+)
+
+type testWorker struct {
+	name     string
+	startErr error
+	running  bool
+}
+
+func (w *testWorker) Name() string {
+	return w.name
+}
+
+func (w *testWorker) Start(ctx context.Context) error {
+	if w.startErr != nil {
+		return w.startErr
+	}
+	w.running = true
+	return nil
+}
+
+func (w *testWorker) Stop(ctx context.Context) error {
+	w.running = false
+	return nil
+}
+
+func TestTryStartWorker(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		w1 := &testWorker{name: "w1"}
+		w2 := &testWorker{name: "w2"}
+		xt.NoError(t, TryStartWorker(context.Background(), w1, w2))
+		xt.True(t, w1.running)
+		xt.True(t, w2.running)
+		xt.NoError(t, TryStopWorker(context.Background(), w1, w2))
+		xt.False(t, w1.running)
+		xt.False(t, w2.running)
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		w1 := &testWorker{name: "w1"}
+		w2 := &testWorker{name: "w2", startErr: errors.New("bad")}
+		w3 := &testWorker{name: "w3"}
+		err := TryStartWorker(context.Background(), w1, w2, w3)
+		xt.Error(t, err)
+		xt.Equal(t, err.Error(), "start worker w2: bad")
+		xt.False(t, w1.running)
+		xt.False(t, w3.running)
+	})
+}
@@ -0,0 +1,352 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xvalidator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// DefaultTagName Engine 默认使用的结构体 tag 名称
+const DefaultTagName = "validator"
+
+// NewEngine 创建新的基于结构体 tag 的校验器
+func NewEngine() *Engine {
+	return &Engine{}
+}
+
+var _ Validator = (*Engine)(nil)
+
+// Engine 基于结构体 tag 的规则校验器
+//
+// 多个规则使用逗号分隔，规则参数使用 "=" 连接，如：
+//
+//	type User struct {
+//		Name   string            `validator:"required,min=2,max=32"`
+//		Email  string            `validator:"omitempty,email"`
+//		Role   string            `validator:"oneof=admin user guest"`
+//		Site   string            `validator:"url|ip"`              // 使用 | 表示满足其中任意一个规则即可
+//		Tags   []string          `validator:"max=10,dive,required"` // dive 之后的规则，用于校验 slice、map 的每一个元素
+//		Ext    map[string]string `validator:"dive,max=64"`
+//		Pass1  string            `validator:"required"`
+//		Pass2  string            `validator:"eqfield=Pass1"`       // 跨字段校验
+//		Addr   *Address                                            // 嵌套的结构体（或其指针）会自动递归校验
+//	}
+//
+// 规则参数中若需要使用逗号或 |，可分别使用 0x2C 和 0x7C 代替，如 `validator:"regexp=^[a-z]+(0x2C[a-z]+)*$"`。
+//
+// 内置的规则：
+//
+//	required:                                 必填，值不能为零值，slice、map 长度不能为 0，指针不能为 nil
+//	omitempty:                                值为零值时，跳过后续所有的规则
+//	len、min、max、eq、ne、gt、gte、lt、lte:     对于数字类型，比较其值大小，time.Duration 类型的参数可以是 "1s" 这样的格式；
+//	                                          对于字符串、slice、map 类型，比较其长度（字符串为字符个数）
+//	oneof:                                    值必须是参数中的一个，多个参数使用空格分隔
+//	email、url、http_url、ip、ipv4、ipv6、cidr:  格式校验
+//	regexp:                                   值必须匹配参数中的正则表达式
+//	eqfield、nefield、gtfield、gtefield、ltfield、ltefield: 和同一个结构体中的另外一个字段比较，
+//	                                          gtfield 等大小比较的规则只支持数字、字符串、time.Time 类型
+//
+// 可以使用 RegisterRule 和 Engine.RegisterRule 注册自定义的规则。
+//
+// slice、map 中的结构体元素，只有使用 dive 时才会被校验。
+type Engine struct {
+	// TagName 可选，结构体 tag 的名称，默认为 DefaultTagName
+	TagName string
+
+	rules sync.Map // name -> *Rule
+}
+
+// RegisterRule 注册只对当前 Engine 生效的规则，优先级高于使用全局的 RegisterRule 注册的规则
+func (e *Engine) RegisterRule(rules ...*Rule) {
+	for _, r := range rules {
+		e.rules.Store(r.Name, r)
+	}
+}
+
+func (e *Engine) findRule(name string) *Rule {
+	if v, ok := e.rules.Load(name); ok {
+		return v.(*Rule)
+	}
+	return findGlobalRule(name)
+}
+
+func (e *Engine) getTagName() string {
+	if e.TagName == "" {
+		return DefaultTagName
+	}
+	return e.TagName
+}
+
+// Validate 校验 val，val 一般是结构体或者结构体指针，其他类型的值总是返回 nil。
+//
+// 校验不通过时，返回的 error 类型为 Errors
+func (e *Engine) Validate(val any) error {
+	rv := indirect(reflect.ValueOf(val))
+	if !rv.IsValid() || rv.Kind() != reflect.Struct {
+		return nil
+	}
+	w := &walker{engine: e, tagName: e.getTagName()}
+	if err := w.walkStruct(rv, ""); err != nil {
+		return err
+	}
+	if len(w.errs) > 0 {
+		return w.errs
+	}
+	return nil
+}
+
+type walker struct {
+	engine  *Engine
+	tagName string
+	errs    Errors
+}
+
+func (w *walker) walkStruct(rv reflect.Value, prefix string) error {
+	meta, err := getStructMeta(rv.Type(), w.tagName)
+	if err != nil {
+		return err
+	}
+	for _, fm := range meta.fields {
+		fv := rv.Field(fm.index)
+		path := prefix + fm.name
+		if fm.embedded {
+			path = strings.TrimSuffix(prefix, ".")
+		}
+		if err = w.walkValue(fv, path, rv, fm.chain); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walker) walkValue(v reflect.Value, path string, parent reflect.Value, chain *ruleChain) error {
+	if chain != nil {
+		if chain.omitEmpty && isEmpty(v) {
+			return nil
+		}
+		for _, alts := range chain.rules {
+			ok, err := w.check(v, path, parent, alts)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
+		}
+		if chain.dive != nil {
+			if err := w.dive(v, path, parent, chain.dive); err != nil {
+				return err
+			}
+		}
+	}
+	sv := indirect(v)
+	if sv.IsValid() && sv.Kind() == reflect.Struct && sv.Type() != timeType {
+		sub := path
+		if sub != "" {
+			sub += "."
+		}
+		return w.walkStruct(sv, sub)
+	}
+	return nil
+}
+
+func (w *walker) dive(v reflect.Value, path string, parent reflect.Value, chain *ruleChain) error {
+	v = indirect(v)
+	if !v.IsValid() {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := w.walkValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), parent, chain); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := w.walkValue(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key()), parent, chain); err != nil {
+				return err
+			}
+		}
+	}
+	// 其他类型（如结构体）不支持 dive，忽略 dive 之后的规则
+	return nil
+}
+
+// check 校验一组“或”关系的规则，满足任意一个即可
+func (w *walker) check(v reflect.Value, path string, parent reflect.Value, alts []ruleCall) (bool, error) {
+	for _, rc := range alts {
+		rule := w.engine.findRule(rc.name)
+		if rule == nil {
+			return false, fmt.Errorf("xvalidator: unknown rule %q on %s", rc.name, path)
+		}
+		f := Field{
+			Path:   path,
+			Value:  v,
+			Param:  rc.param,
+			Parent: parent,
+		}
+		if rule.Func(f) {
+			return true, nil
+		}
+	}
+	fe := newFieldError(v, path, alts)
+	if len(alts) == 1 {
+		fe.rule = w.engine.findRule(alts[0].name)
+	}
+	w.errs = append(w.errs, fe)
+	return false, nil
+}
+
+func newFieldError(v reflect.Value, path string, alts []ruleCall) *FieldError {
+	fe := &FieldError{
+		Field: path,
+		Rule:  alts[0].name,
+		Param: alts[0].param,
+	}
+	if v.IsValid() && v.CanInterface() {
+		fe.Value = v.Interface()
+	}
+	if len(alts) > 1 {
+		names := make([]string, len(alts))
+		for i, rc := range alts {
+			names[i] = rc.name
+		}
+		fe.Rule = strings.Join(names, "|")
+		fe.Param = ""
+	}
+	fe.lengthKind = isLengthKind(indirect(v).Kind())
+	return fe
+}
+
+func isEmpty(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Chan:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+type ruleCall struct {
+	name  string
+	param string
+}
+
+// ruleChain 解析后的字段 tag
+type ruleChain struct {
+	omitEmpty bool
+	rules     [][]ruleCall // 每个元素是一组“或”关系的规则
+	dive      *ruleChain   // dive 之后的规则
+}
+
+var paramReplacer = strings.NewReplacer("0x2C", ",", "0x7C", "|")
+
+func parseRuleChain(tag string) (*ruleChain, error) {
+	chain := &ruleChain{}
+	items := strings.Split(tag, ",")
+	for i, item := range items {
+		item = strings.TrimSpace(item)
+		switch item {
+		case "":
+			continue
+		case "omitempty":
+			chain.omitEmpty = true
+			continue
+		case "dive":
+			dive, err := parseRuleChain(strings.Join(items[i+1:], ","))
+			if err != nil {
+				return nil, err
+			}
+			chain.dive = dive
+			return chain, nil
+		}
+		var alts []ruleCall
+		for _, one := range strings.Split(item, "|") {
+			name, param, _ := strings.Cut(one, "=")
+			name = strings.TrimSpace(name)
+			if name == "" {
+				return nil, fmt.Errorf("invalid rule %q", item)
+			}
+			alts = append(alts, ruleCall{name: name, param: paramReplacer.Replace(param)})
+		}
+		chain.rules = append(chain.rules, alts)
+	}
+	return chain, nil
+}
+
+type fieldMeta struct {
+	index    int
+	name     string
+	embedded bool
+	chain    *ruleChain
+}
+
+type structMeta struct {
+	fields []fieldMeta
+}
+
+type metaKey struct {
+	typ     reflect.Type
+	tagName string
+}
+
+var structMetaCache sync.Map // metaKey -> *structMeta
+
+func getStructMeta(rt reflect.Type, tagName string) (*structMeta, error) {
+	key := metaKey{typ: rt, tagName: tagName}
+	if v, ok := structMetaCache.Load(key); ok {
+		return v.(*structMeta), nil
+	}
+	meta := &structMeta{}
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		embedded := sf.Anonymous && indirectType(sf.Type).Kind() == reflect.Struct
+		if !sf.IsExported() && !embedded {
+			continue
+		}
+		tag, has := sf.Tag.Lookup(tagName)
+		if tag == "-" {
+			continue
+		}
+		fm := fieldMeta{
+			index:    i,
+			name:     sf.Name,
+			embedded: embedded && !has,
+		}
+		if has {
+			chain, err := parseRuleChain(tag)
+			if err != nil {
+				return nil, fmt.Errorf("xvalidator: %s.%s: %w", rt.String(), sf.Name, err)
+			}
+			fm.chain = chain
+		} else if !isNestedType(sf.Type) {
+			continue
+		}
+		meta.fields = append(meta.fields, fm)
+	}
+	structMetaCache.Store(key, meta)
+	return meta, nil
+}
+
+func indirectType(rt reflect.Type) reflect.Type {
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	return rt
+}
+
+// isNestedType 没有 tag 的字段，是否需要递归校验
+func isNestedType(rt reflect.Type) bool {
+	rt = indirectType(rt)
+	return rt.Kind() == reflect.Struct && rt != timeType
+}
@@ -0,0 +1,206 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xvalidator_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xanygo/anygo/xerror"
+	"github.com/xanygo/anygo/xi18n"
+	"github.com/xanygo/anygo/xt"
+	"github.com/xanygo/anygo/xvalidator"
+)
+
+type testAddress struct {
+	City string `validator:"required"`
+	Zip  string `validator:"omitempty,len=6"`
+}
+
+type testBase struct {
+	ID int `validator:"gt=0"`
+}
+
+type testUser struct {
+	testBase
+	Name     string                 `validator:"required,min=2,max=8"`
+	Age      int                    `validator:"gte=18,lte=150"`
+	Role     string                 `validator:"oneof=admin user"`
+	Email    string                 `validator:"omitempty,email"`
+	Site     string                 `validator:"omitempty,url|ip"`
+	Net      string                 `validator:"omitempty,cidr"`
+	Code     string                 `validator:"omitempty,regexp=^[a-z]+(0x2C[a-z]+)*$"`
+	Tags     []string               `validator:"max=3,dive,required"`
+	Ext      map[string]int         `validator:"dive,min=1"`
+	Timeout  time.Duration          `validator:"omitempty,min=1s"`
+	Pass     string                 `validator:"required"`
+	Pass2    string                 `validator:"eqfield=Pass"`
+	Start    time.Time              `validator:"-"`
+	End      time.Time              `validator:"omitempty,gtfield=Start"`
+	Addr     *testAddress           `validator:"required"`
+	Others   []testAddress          `validator:"dive"`
+	NoDive   []testAddress          // slice 中的结构体，不使用 dive 时不校验
+	Group    map[string]testAddress `validator:"dive"`
+	internal string                 `validator:"required"`
+}
+
+func newTestUser() *testUser {
+	return &testUser{
+		testBase: testBase{ID: 1},
+		Name:     "hello",
+		Age:      20,
+		Role:     "admin",
+		Email:    "a@example.com",
+		Site:     "127.0.0.1",
+		Net:      "10.0.0.0/8",
+		Code:     "abc,de",
+		Tags:     []string{"a"},
+		Ext:      map[string]int{"a": 1},
+		Timeout:  time.Second,
+		Pass:     "123",
+		Pass2:    "123",
+		Start:    time.Now(),
+		End:      time.Now().Add(time.Hour),
+		Addr:     &testAddress{City: "bj"},
+		Others:   []testAddress{{City: "sh"}},
+		NoDive:   []testAddress{{}},
+	}
+}
+
+func TestEngine(t *testing.T) {
+	xt.NoError(t, xvalidator.Validate(newTestUser()))
+	xt.NoError(t, xvalidator.Validate(*newTestUser()))
+	xt.NoError(t, xvalidator.Validate("hello"))
+	xt.NoError(t, xvalidator.Validate(nil))
+
+	cases := []struct {
+		name  string
+		fn    func(u *testUser)
+		field string
+		rule  string
+	}{
+		{name: "embedded", fn: func(u *testUser) { u.ID = 0 }, field: "ID", rule: "gt"},
+		{name: "required", fn: func(u *testUser) { u.Name = "" }, field: "Name", rule: "required"},
+		{name: "min length", fn: func(u *testUser) { u.Name = "你" }, field: "Name", rule: "min"},
+		{name: "max length", fn: func(u *testUser) { u.Name = "123456789" }, field: "Name", rule: "max"},
+		{name: "gte", fn: func(u *testUser) { u.Age = 10 }, field: "Age", rule: "gte"},
+		{name: "oneof", fn: func(u *testUser) { u.Role = "guest" }, field: "Role", rule: "oneof"},
+		{name: "email", fn: func(u *testUser) { u.Email = "a@" }, field: "Email", rule: "email"},
+		{name: "or", fn: func(u *testUser) { u.Site = "abc" }, field: "Site", rule: "url|ip"},
+		{name: "cidr", fn: func(u *testUser) { u.Net = "10.0.0.1" }, field: "Net", rule: "cidr"},
+		{name: "regexp", fn: func(u *testUser) { u.Code = "abc,1" }, field: "Code", rule: "regexp"},
+		{name: "slice max", fn: func(u *testUser) { u.Tags = []string{"a", "b", "c", "d"} }, field: "Tags", rule: "max"},
+		{name: "dive slice", fn: func(u *testUser) { u.Tags = []string{"a", ""} }, field: "Tags[1]", rule: "required"},
+		{name: "dive map", fn: func(u *testUser) { u.Ext = map[string]int{"k": 0} }, field: "Ext[k]", rule: "min"},
+		{name: "duration", fn: func(u *testUser) { u.Timeout = time.Millisecond }, field: "Timeout", rule: "min"},
+		{name: "eqfield", fn: func(u *testUser) { u.Pass2 = "456" }, field: "Pass2", rule: "eqfield"},
+		{name: "gtfield", fn: func(u *testUser) { u.End = u.Start.Add(-time.Hour) }, field: "End", rule: "gtfield"},
+		{name: "nil ptr", fn: func(u *testUser) { u.Addr = nil }, field: "Addr", rule: "required"},
+		{name: "nested", fn: func(u *testUser) { u.Addr.Zip = "123" }, field: "Addr.Zip", rule: "len"},
+		{name: "dive struct", fn: func(u *testUser) { u.Others = append(u.Others, testAddress{}) }, field: "Others[1].City", rule: "required"},
+		{name: "dive map struct", fn: func(u *testUser) { u.Group = map[string]testAddress{"g1": {}} }, field: "Group[g1].City", rule: "required"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newTestUser()
+			tt.fn(u)
+			err := xvalidator.Validate(u)
+			xt.Error(t, err)
+			xt.True(t, xerror.IsInvalidParam(err))
+			var es xvalidator.Errors
+			xt.True(t, errors.As(err, &es))
+			xt.Len(t, es, 1)
+			xt.Equal(t, es[0].Field, tt.field)
+			xt.Equal(t, es[0].Rule, tt.rule)
+		})
+	}
+}
+
+func TestEngine_fieldRule(t *testing.T) {
+	type flags struct {
+		A bool
+		B bool `validator:"gtfield=A"`
+		C bool `validator:"eqfield=A"`
+		D bool `validator:"nefield=A"`
+	}
+	err := xvalidator.Validate(&flags{A: false, B: true, C: false, D: true})
+	var es xvalidator.Errors
+	xt.True(t, errors.As(err, &es))
+	xt.Len(t, es, 1)
+	xt.Equal(t, es[0].Field, "B")
+	xt.Equal(t, es[0].Rule, "gtfield")
+
+	err = xvalidator.Validate(&flags{A: true, B: false, C: false, D: true})
+	xt.True(t, errors.As(err, &es))
+	xt.Len(t, es, 3)
+}
+
+func TestEngine_Messages(t *testing.T) {
+	u := newTestUser()
+	u.Name = "a"
+	u.Age = 1
+	u.Tags = []string{""}
+	err := xvalidator.Validate(u)
+	var es xvalidator.Errors
+	xt.True(t, errors.As(err, &es))
+	xt.Equal(t, es.Messages(), map[string]string{
+		"Name":    "length of Name must be at least 2",
+		"Age":     "Age must be greater than or equal to 18",
+		"Tags[0]": "Tags[0] is required",
+	})
+	xt.Equal(t, es.Field("Name").MessageKey(), "min_len")
+	xt.Equal(t, es.Field("Name").Value, any("a"))
+	xt.Contains(t, err.Error(), "Age must be greater than or equal to 18")
+
+	b := xi18n.NewBundle()
+	b.MustLocalize(xi18n.LangZh).MustAdd("validator",
+		&xi18n.Message{Key: "required", Other: "{0} 不能为空"},
+		&xi18n.Message{Key: "min_len", Other: "{0} 的长度不能小于 {1}"},
+	)
+	xt.Equal(t, es.Translate(b, []xi18n.Language{xi18n.LangZh}, "validator"), map[string]string{
+		"Name":    "Name 的长度不能小于 2",
+		"Age":     "Age must be greater than or equal to 18",
+		"Tags[0]": "Tags[0] 不能为空",
+	})
+}
+
+func TestEngine_RegisterRule(t *testing.T) {
+	type order struct {
+		No string `validator:"order_no"`
+	}
+	e := xvalidator.NewEngine()
+	xt.ErrorContains(t, e.Validate(order{}), `unknown rule "order_no"`)
+
+	e.RegisterRule(&xvalidator.Rule{
+		Name: "order_no",
+		Func: func(f xvalidator.Field) bool {
+			return strings.HasPrefix(f.Value.String(), "NO")
+		},
+		Message: "{0} must start with NO",
+	})
+	xt.NoError(t, e.Validate(order{No: "NO1"}))
+	err := e.Validate(order{No: "1"})
+	xt.Equal(t, err.Error(), "No must start with NO")
+
+	// 全局注册的规则不影响已有 Engine 中的同名规则
+	xvalidator.RegisterRule(&xvalidator.Rule{
+		Name: "order_no",
+		Func: func(f xvalidator.Field) bool {
+			return f.Value.Kind() == reflect.String
+		},
+	})
+	xt.Error(t, e.Validate(order{No: "1"}))
+	xt.NoError(t, xvalidator.Validate(order{No: "1"}))
+
+	type custom struct {
+		Name string `check:"required"`
+	}
+	e2 := &xvalidator.Engine{TagName: "check"}
+	xt.Error(t, e2.Validate(custom{}))
+	xt.NoError(t, xvalidator.Validate(custom{}))
+}
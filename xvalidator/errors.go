@@ -0,0 +1,135 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xvalidator
+
+import (
+	"strings"
+
+	"github.com/xanygo/anygo/xerror"
+	"github.com/xanygo/anygo/xi18n"
+)
+
+var _ error = (*FieldError)(nil)
+
+// FieldError 单个字段的校验错误
+type FieldError struct {
+	Field string // 字段路径，如 "Address.City"、"Tags[0]"
+	Rule  string // 未通过的规则名称，如 "required"，若是“或”关系的规则，则为 "email|url" 这样的格式
+	Param string // 规则参数，如 `validator:"min=3"` 中的 "3"
+	Value any    // 字段的值
+
+	rule       *Rule
+	lengthKind bool
+}
+
+// MessageKey 错误信息的 key，也是使用 xi18n 本地化时使用的 key。
+//
+// 一般和 Rule 相同，对于字符串、slice、map 类型的长度校验规则（如 min、max），为 Rule + "_len"，如 "min_len"
+func (e *FieldError) MessageKey() string {
+	if e.lengthKind {
+		if _, ok := sizeMessages[e.Rule]; ok {
+			return e.Rule + "_len"
+		}
+	}
+	return e.Rule
+}
+
+// Message 英文的错误信息
+func (e *FieldError) Message() string {
+	var tpl string
+	if e.lengthKind {
+		tpl = sizeMessages[e.Rule]
+	}
+	if tpl == "" {
+		if r := e.getRule(); r != nil {
+			tpl = r.Message
+		}
+	}
+	return renderMessage(tpl, e.Field, e.Param)
+}
+
+func (e *FieldError) getRule() *Rule {
+	if e.rule != nil {
+		return e.rule
+	}
+	return findGlobalRule(e.Rule)
+}
+
+func (e *FieldError) Error() string {
+	return e.Message()
+}
+
+// Translate 使用 xi18n 本地化错误信息，若找不到对应的消息，则返回 Message()
+//
+// 消息的 key 为 MessageKey()，消息模版中 {0} 为字段路径，{1} 为规则参数（仅当规则有参数时）。如：
+//
+//	Key: required
+//	Other: "{0} 不能为空"
+//
+//	Key: min_len
+//	Other: "{0} 的长度不能小于 {1}"
+func (e *FieldError) Translate(b *xi18n.Bundle, languages []xi18n.Language, namespace string) string {
+	if b == nil {
+		return e.Message()
+	}
+	msg := xi18n.FindMessage(b, languages, namespace, e.MessageKey())
+	if msg == nil {
+		return e.Message()
+	}
+	args := []any{e.Field}
+	if e.Param != "" {
+		args = append(args, e.Param)
+	}
+	str, err := msg.Render(args...)
+	if err != nil {
+		return e.Message()
+	}
+	return str
+}
+
+var _ error = Errors(nil)
+
+// Errors 校验不通过的字段错误列表，可使用 xerror.IsInvalidParam 判断
+type Errors []*FieldError
+
+func (es Errors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Message()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (es Errors) Is(target error) bool {
+	return target == xerror.InvalidParam
+}
+
+// Field 查找指定字段路径的错误，不存在时返回 nil
+func (es Errors) Field(path string) *FieldError {
+	for _, e := range es {
+		if e.Field == path {
+			return e
+		}
+	}
+	return nil
+}
+
+// Messages 返回 字段路径 -> 英文错误信息
+func (es Errors) Messages() map[string]string {
+	result := make(map[string]string, len(es))
+	for _, e := range es {
+		result[e.Field] = e.Message()
+	}
+	return result
+}
+
+// Translate 返回 字段路径 -> 本地化的错误信息，详见 FieldError.Translate
+func (es Errors) Translate(b *xi18n.Bundle, languages []xi18n.Language, namespace string) map[string]string {
+	result := make(map[string]string, len(es))
+	for _, e := range es {
+		result[e.Field] = e.Translate(b, languages, namespace)
+	}
+	return result
+}
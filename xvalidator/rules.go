@@ -0,0 +1,348 @@
+//  Copyright(C) 2026 github.com/hidu  All Rights Reserved.
+//  Author: hidu <duv123+git@gmail.com>
+//  Date: 2026-10-18
+
+package xvalidator
+
+import (
+	"fmt"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Field 传递给校验规则的字段信息
+type Field struct {
+	// Path 字段路径，如 "Address.City"、"Tags[0]"
+	Path string
+
+	// Value 待校验的值
+	Value reflect.Value
+
+	// Param 规则参数，如 `validator:"min=3"` 中的 "3"
+	Param string
+
+	// Parent 字段所在的结构体，用于跨字段校验（如 eqfield）
+	Parent reflect.Value
+}
+
+// ParentField 读取同一个结构体中的另外一个字段
+func (f Field) ParentField(name string) (reflect.Value, bool) {
+	p := indirect(f.Parent)
+	if p.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	fv := p.FieldByName(name)
+	return fv, fv.IsValid()
+}
+
+// RuleFunc 校验规则，返回 false 表示校验不通过
+type RuleFunc func(f Field) bool
+
+// Rule 一条校验规则
+type Rule struct {
+	// Name 规则名称，即在 tag 中使用的名称，必填
+	Name string
+
+	// Func 校验函数，必填
+	Func RuleFunc
+
+	// Message 可选，校验失败时的错误信息模版，{0} 为字段路径，{1} 为规则参数
+	Message string
+}
+
+var globalRules sync.Map // name -> *Rule
+
+// RegisterRule 注册全局的校验规则，所有的 Engine 都可以使用。
+// 若和已有规则同名，则会替换已有规则
+func RegisterRule(rules ...*Rule) {
+	for _, r := range rules {
+		globalRules.Store(r.Name, r)
+	}
+}
+
+func findGlobalRule(name string) *Rule {
+	v, ok := globalRules.Load(name)
+	if !ok {
+		return nil
+	}
+	return v.(*Rule)
+}
+
+func init() {
+	RegisterRule(
+		&Rule{Name: "required", Func: ruleRequired, Message: "{0} is required"},
+		&Rule{Name: "len", Func: sizeRule(func(c int) bool { return c == 0 }), Message: "{0} must be {1}"},
+		&Rule{Name: "min", Func: sizeRule(func(c int) bool { return c >= 0 }), Message: "{0} must be at least {1}"},
+		&Rule{Name: "max", Func: sizeRule(func(c int) bool { return c <= 0 }), Message: "{0} must be at most {1}"},
+		&Rule{Name: "eq", Func: sizeRule(func(c int) bool { return c == 0 }), Message: "{0} must be equal to {1}"},
+		&Rule{Name: "ne", Func: sizeRule(func(c int) bool { return c != 0 }), Message: "{0} must not be equal to {1}"},
+		&Rule{Name: "gt", Func: sizeRule(func(c int) bool { return c > 0 }), Message: "{0} must be greater than {1}"},
+		&Rule{Name: "gte", Func: sizeRule(func(c int) bool { return c >= 0 }), Message: "{0} must be greater than or equal to {1}"},
+		&Rule{Name: "lt", Func: sizeRule(func(c int) bool { return c < 0 }), Message: "{0} must be less than {1}"},
+		&Rule{Name: "lte", Func: sizeRule(func(c int) bool { return c <= 0 }), Message: "{0} must be less than or equal to {1}"},
+		&Rule{Name: "oneof", Func: ruleOneOf, Message: "{0} must be one of [{1}]"},
+		&Rule{Name: "email", Func: stringRule(isEmail), Message: "{0} must be a valid email address"},
+		&Rule{Name: "url", Func: stringRule(isURL), Message: "{0} must be a valid URL"},
+		&Rule{Name: "http_url", Func: stringRule(IsHTTPURL), Message: "{0} must be a valid HTTP URL"},
+		&Rule{Name: "ip", Func: stringRule(isIP(nil)), Message: "{0} must be a valid IP address"},
+		&Rule{Name: "ipv4", Func: stringRule(isIP(netip.Addr.Is4)), Message: "{0} must be a valid IPv4 address"},
+		&Rule{Name: "ipv6", Func: stringRule(isIP(netip.Addr.Is6)), Message: "{0} must be a valid IPv6 address"},
+		&Rule{Name: "cidr", Func: stringRule(isCIDR), Message: "{0} must be a valid CIDR"},
+		&Rule{Name: "regexp", Func: ruleRegexp, Message: "{0} must match {1}"},
+		&Rule{Name: "eqfield", Func: fieldRule(false, func(c int) bool { return c == 0 }), Message: "{0} must be equal to {1}"},
+		&Rule{Name: "nefield", Func: fieldRule(false, func(c int) bool { return c != 0 }), Message: "{0} must not be equal to {1}"},
+		&Rule{Name: "gtfield", Func: fieldRule(true, func(c int) bool { return c > 0 }), Message: "{0} must be greater than {1}"},
+		&Rule{Name: "gtefield", Func: fieldRule(true, func(c int) bool { return c >= 0 }), Message: "{0} must be greater than or equal to {1}"},
+		&Rule{Name: "ltfield", Func: fieldRule(true, func(c int) bool { return c < 0 }), Message: "{0} must be less than {1}"},
+		&Rule{Name: "ltefield", Func: fieldRule(true, func(c int) bool { return c <= 0 }), Message: "{0} must be less than or equal to {1}"},
+	)
+}
+
+// sizeMessages 用于字符串、slice、map 等类型的长度校验的错误信息
+var sizeMessages = map[string]string{
+	"len": "length of {0} must be {1}",
+	"min": "length of {0} must be at least {1}",
+	"max": "length of {0} must be at most {1}",
+	"eq":  "length of {0} must be equal to {1}",
+	"ne":  "length of {0} must not be equal to {1}",
+	"gt":  "length of {0} must be greater than {1}",
+	"gte": "length of {0} must be greater than or equal to {1}",
+	"lt":  "length of {0} must be less than {1}",
+	"lte": "length of {0} must be less than or equal to {1}",
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func isLengthKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array, reflect.Chan:
+		return true
+	default:
+		return false
+	}
+}
+
+func ruleRequired(f Field) bool {
+	v := f.Value
+	if !v.IsValid() {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return !v.IsNil()
+	case reflect.Slice, reflect.Map, reflect.Chan:
+		return v.Len() > 0
+	case reflect.Struct:
+		// 非指针的结构体总是存在的，其字段由嵌套校验处理
+		return true
+	default:
+		return !v.IsZero()
+	}
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// compareParam 比较 v 和参数的大小，对于字符串、slice、map 等类型比较的是长度
+func compareParam(v reflect.Value, param string) (int, bool) {
+	v = indirect(v)
+	if !v.IsValid() {
+		return 0, false
+	}
+	switch v.Kind() {
+	case reflect.String:
+		n, err := strconv.Atoi(param)
+		return compareNum(utf8.RuneCountInString(v.String()), n), err == nil
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.Chan:
+		n, err := strconv.Atoi(param)
+		return compareNum(v.Len(), n), err == nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			d, err := time.ParseDuration(param)
+			return compareNum(v.Int(), int64(d)), err == nil
+		}
+		n, err := strconv.ParseInt(param, 10, 64)
+		return compareNum(v.Int(), n), err == nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(param, 10, 64)
+		return compareNum(v.Uint(), n), err == nil
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(param, 64)
+		return compareNum(v.Float(), n), err == nil
+	default:
+		return 0, false
+	}
+}
+
+func compareNum[T int | int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func sizeRule(fn func(c int) bool) RuleFunc {
+	return func(f Field) bool {
+		c, ok := compareParam(f.Value, f.Param)
+		return ok && fn(c)
+	}
+}
+
+func ruleOneOf(f Field) bool {
+	str, ok := valueString(f.Value)
+	if !ok {
+		return false
+	}
+	for _, item := range strings.Fields(f.Param) {
+		if item == str {
+			return true
+		}
+	}
+	return false
+}
+
+// valueString 将字符串、数字、bool 类型的值转换为字符串
+func valueString(v reflect.Value) (string, bool) {
+	v = indirect(v)
+	if !v.IsValid() {
+		return "", false
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	default:
+		return "", false
+	}
+}
+
+func stringRule(fn func(str string) bool) RuleFunc {
+	return func(f Field) bool {
+		v := indirect(f.Value)
+		if !v.IsValid() || v.Kind() != reflect.String {
+			return false
+		}
+		return fn(v.String())
+	}
+}
+
+func isEmail(str string) bool {
+	addr, err := mail.ParseAddress(str)
+	return err == nil && addr.Address == str
+}
+
+func isURL(str string) bool {
+	u, err := url.Parse(str)
+	return err == nil && u.Scheme != "" && (u.Host != "" || u.Opaque != "")
+}
+
+func isIP(check func(netip.Addr) bool) func(str string) bool {
+	return func(str string) bool {
+		addr, err := netip.ParseAddr(str)
+		if err != nil {
+			return false
+		}
+		return check == nil || check(addr)
+	}
+}
+
+func isCIDR(str string) bool {
+	_, err := netip.ParsePrefix(str)
+	return err == nil
+}
+
+var regexpCache sync.Map // pattern -> *regexp.Regexp
+
+func ruleRegexp(f Field) bool {
+	var reg *regexp.Regexp
+	if v, ok := regexpCache.Load(f.Param); ok {
+		reg = v.(*regexp.Regexp)
+	} else {
+		var err error
+		reg, err = regexp.Compile(f.Param)
+		if err != nil {
+			return false
+		}
+		regexpCache.Store(f.Param, reg)
+	}
+	str, ok := valueString(f.Value)
+	return ok && reg.MatchString(str)
+}
+
+// fieldRule 和其他字段比较的规则，ordered 为 true 时是大小比较的规则，只支持有大小顺序的类型
+func fieldRule(ordered bool, fn func(c int) bool) RuleFunc {
+	return func(f Field) bool {
+		other, ok := f.ParentField(f.Param)
+		if !ok {
+			return false
+		}
+		c, ok := compareValue(f.Value, other, ordered)
+		return ok && fn(c)
+	}
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// compareValue 比较两个相同类型的值的大小，ordered 为 true 时，
+// 没有大小顺序的类型（如 bool、struct）不能比较，否则只比较是否相等，不相等时返回 1
+func compareValue(a reflect.Value, b reflect.Value, ordered bool) (int, bool) {
+	a, b = indirect(a), indirect(b)
+	if !a.IsValid() || !b.IsValid() || a.Kind() != b.Kind() {
+		return 0, false
+	}
+	switch a.Kind() {
+	case reflect.String:
+		return strings.Compare(a.String(), b.String()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareNum(a.Int(), b.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return compareNum(a.Uint(), b.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return compareNum(a.Float(), b.Float()), true
+	case reflect.Struct:
+		if a.Type() == timeType && b.Type() == timeType {
+			return a.Interface().(time.Time).Compare(b.Interface().(time.Time)), true
+		}
+	}
+	if !ordered && a.Type() == b.Type() && a.Comparable() {
+		if a.Equal(b) {
+			return 0, true
+		}
+		return 1, true
+	}
+	return 0, false
+}
+
+// renderMessage 使用 {0} 字段路径、{1} 规则参数 渲染错误信息
+func renderMessage(tpl string, field string, param string) string {
+	if tpl == "" {
+		return fmt.Sprintf("%s is invalid", field)
+	}
+	return strings.NewReplacer("{0}", field, "{1}", param).Replace(tpl)
+}
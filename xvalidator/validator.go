@@ -15,10 +15,9 @@ type Validator interface {
 	Validate(val any) error
 }
 
-// Default 默认的 Validator，为 nil，在使用前可以基于
-// github.com/go-playground/validator/v10 初始化。
+// Default 默认的 Validator，为使用结构体 tag "validator" 的 Engine，详见 Engine。
 //
-// 初始化之后，可以采用如下设置，以让所有字段都是必填的：
+// 如下所有字段都是必填的：
 //
 //	type Address struct {
 //		Street string `validator:"required"`
@@ -26,15 +25,9 @@ type Validator interface {
 //		Planet string `validator:"required"`
 //		Phone  string `validator:"required"`
 //	}
-var Default Validator = &empty{}
-
-var _ Validator = (*empty)(nil)
-
-type empty struct{}
-
-func (a empty) Validate(val any) error {
-	return nil
-}
+//
+// 也可以替换为基于 github.com/go-playground/validator/v10 等的实现
+var Default Validator = NewEngine()
 
 func Validate(val any) error {
 	if err := Default.Validate(val); err != nil {